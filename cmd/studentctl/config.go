@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Profile struct to hold the settings for one API server
+type Profile struct {
+	Server     string `json:"server"`
+	APIKey     string `json:"api_key,omitempty"`
+	AdminToken string `json:"admin_token,omitempty"`
+	Tenant     string `json:"tenant,omitempty"`
+}
+
+// cliConfig struct to hold the saved profiles and the one used by default
+type cliConfig struct {
+	Current  string             `json:"current"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// cliConfigPath returns the config file location, honoring STUDENTCTL_CONFIG
+func cliConfigPath() (string, error) {
+	if path := os.Getenv("STUDENTCTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "studentctl", "config.json"), nil
+}
+
+// loadCLIConfig reads the config file; a missing file is an empty config
+func loadCLIConfig() (cliConfig, error) {
+	cfg := cliConfig{Profiles: make(map[string]Profile)}
+	path, err := cliConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]Profile)
+	}
+	return cfg, nil
+}
+
+// saveCLIConfig writes the config file readable only by the user, since it holds credentials
+func saveCLIConfig(cfg cliConfig) error {
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// profileCommand builds "studentctl profile" with set, use, list and delete subcommands
+func profileCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "profile", Short: "Manage server profiles"}
+
+	var profile Profile
+	set := &cobra.Command{
+		Use:   "set NAME",
+		Short: "Create or update a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCLIConfig()
+			if err != nil {
+				return err
+			}
+			existing := cfg.Profiles[args[0]]
+			// Only the given flags change, so a profile can be edited one setting at a time
+			for flag, field := range map[string][2]*string{
+				"server":    {&existing.Server, &profile.Server},
+				"key":       {&existing.APIKey, &profile.APIKey},
+				"token":     {&existing.AdminToken, &profile.AdminToken},
+				"tenant-id": {&existing.Tenant, &profile.Tenant},
+			} {
+				if cmd.Flags().Changed(flag) {
+					*field[0] = *field[1]
+				}
+			}
+			cfg.Profiles[args[0]] = existing
+			if cfg.Current == "" {
+				cfg.Current = args[0]
+			}
+			return saveCLIConfig(cfg)
+		},
+	}
+	set.Flags().StringVar(&profile.Server, "server", "", "API base URL")
+	set.Flags().StringVar(&profile.APIKey, "key", "", "API key")
+	set.Flags().StringVar(&profile.AdminToken, "token", "", "admin token")
+	set.Flags().StringVar(&profile.Tenant, "tenant-id", "", "tenant ID")
+
+	use := &cobra.Command{
+		Use:   "use NAME",
+		Short: "Select the default profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCLIConfig()
+			if err != nil {
+				return err
+			}
+			if _, exists := cfg.Profiles[args[0]]; !exists {
+				return fmt.Errorf("unknown profile %q", args[0])
+			}
+			cfg.Current = args[0]
+			return saveCLIConfig(cfg)
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCLIConfig()
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(cfg.Profiles))
+			for name := range cfg.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(out, "CURRENT\tNAME\tSERVER\tTENANT")
+			for _, name := range names {
+				current := ""
+				if name == cfg.Current {
+					current = "*"
+				}
+				fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", current, name, cfg.Profiles[name].Server, cfg.Profiles[name].Tenant)
+			}
+			return out.Flush()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadCLIConfig()
+			if err != nil {
+				return err
+			}
+			delete(cfg.Profiles, args[0])
+			if cfg.Current == args[0] {
+				cfg.Current = ""
+			}
+			return saveCLIConfig(cfg)
+		},
+	}
+
+	cmd.AddCommand(set, use, list, remove)
+	return cmd
+}
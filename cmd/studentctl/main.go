@@ -0,0 +1,156 @@
+// Command studentctl is a command-line client for the student API.
+//
+// Servers are configured as named profiles in ~/.config/studentctl/config.json; the --server,
+// --api-key, --admin-token and --tenant flags override the selected profile.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// options struct to hold the global flags
+type options struct {
+	Profile    string
+	Server     string
+	APIKey     string
+	AdminToken string
+	Tenant     string
+	Output     string
+}
+
+var opts options
+
+func main() {
+	root := &cobra.Command{
+		Use:           "studentctl",
+		Short:         "Manage students through the student API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Output != "table" && opts.Output != "json" {
+				return fmt.Errorf("--output must be table or json")
+			}
+			return nil
+		},
+	}
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.Profile, "profile", "", "profile to use (default: the current profile)")
+	flags.StringVar(&opts.Server, "server", "", "API base URL, overriding the profile")
+	flags.StringVar(&opts.APIKey, "api-key", "", "API key, overriding the profile")
+	flags.StringVar(&opts.AdminToken, "admin-token", "", "admin token, overriding the profile")
+	flags.StringVar(&opts.Tenant, "tenant", "", "tenant ID, overriding the profile")
+	flags.StringVarP(&opts.Output, "output", "o", "table", "output format: table or json")
+
+	root.AddCommand(
+		listCommand(), getCommand(), createCommand(), updateCommand(), deleteCommand(),
+		importCommand(), exportCommand(), summaryCommand(), profileCommand(),
+	)
+
+	if err := root.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// client struct to hold the connection settings resolved from the profile and flags
+type client struct {
+	Profile
+	http *http.Client
+}
+
+// newClient resolves the profile and flag overrides into a client
+func newClient() (*client, error) {
+	cfg, err := loadCLIConfig()
+	if err != nil {
+		return nil, err
+	}
+	name := firstNonEmpty(opts.Profile, cfg.Current, "default")
+	profile, exists := cfg.Profiles[name]
+	if !exists && opts.Profile != "" {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	profile.Server = firstNonEmpty(opts.Server, profile.Server, "http://localhost:8081")
+	profile.APIKey = firstNonEmpty(opts.APIKey, profile.APIKey)
+	profile.AdminToken = firstNonEmpty(opts.AdminToken, profile.AdminToken)
+	profile.Tenant = firstNonEmpty(opts.Tenant, profile.Tenant)
+	return &client{Profile: profile, http: &http.Client{Timeout: 2 * time.Minute}}, nil
+}
+
+// do sends a request and returns the response body, turning non-2xx responses into errors that
+// carry the server's message
+func (c *client) do(method, path string, query url.Values, body interface{}, accept string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := strings.TrimRight(c.Server, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", firstNonEmpty(accept, "application/json"))
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	if c.AdminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AdminToken)
+	}
+	if c.Tenant != "" {
+		req.Header.Set("X-Tenant-ID", c.Tenant)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// printJSON writes a response body indented
+func printJSON(data []byte) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(os.Stdout)
+	return err
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
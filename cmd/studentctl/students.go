@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// student struct to hold the fields studentctl shows and sends; other fields pass through the
+// JSON output untouched
+type student struct {
+	ID          int      `json:"id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	Phone       string   `json:"phone,omitempty"`
+	DateOfBirth string   `json:"date_of_birth,omitempty"`
+	Gender      string   `json:"gender,omitempty"`
+	Nationality string   `json:"nationality,omitempty"`
+	Age         int      `json:"age,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	GradeLevel  int      `json:"grade_level,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// printStudents writes students as a table or JSON according to --output
+func printStudents(data []byte) error {
+	if opts.Output == "json" {
+		return printJSON(data)
+	}
+	var list []student
+	if err := json.Unmarshal(data, &list); err != nil {
+		var single student
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		list = []student{single}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "ID\tNAME\tEMAIL\tAGE\tSTATUS\tGRADE\tTAGS")
+	for _, s := range list {
+		fmt.Fprintf(out, "%d\t%s\t%s\t%d\t%s\t%d\t%s\n", s.ID, s.Name, s.Email, s.Age, s.Status, s.GradeLevel, strings.Join(s.Tags, ","))
+	}
+	return out.Flush()
+}
+
+// filterQuery turns repeated --filter key=value flags into list query parameters
+func filterQuery(filters []string) (url.Values, error) {
+	query := url.Values{}
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid filter %q, expected key=value", filter)
+		}
+		query.Set(key, value)
+	}
+	return query, nil
+}
+
+// studentFlags registers the field flags of create and update
+func studentFlags(cmd *cobra.Command, s *student, file *string) {
+	cmd.Flags().StringVarP(file, "file", "f", "", "read the student as JSON from a file (- for stdin)")
+	cmd.Flags().StringVar(&s.Name, "name", "", "name")
+	cmd.Flags().StringVar(&s.Email, "email", "", "email")
+	cmd.Flags().StringVar(&s.Phone, "phone", "", "phone")
+	cmd.Flags().StringVar(&s.DateOfBirth, "date-of-birth", "", "date of birth (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&s.Gender, "gender", "", "gender")
+	cmd.Flags().StringVar(&s.Nationality, "nationality", "", "nationality (ISO country code)")
+	cmd.Flags().IntVar(&s.Age, "age", 0, "age, for students without a date of birth")
+	cmd.Flags().StringVar(&s.Status, "status", "", "initial status (create only)")
+	cmd.Flags().IntVar(&s.GradeLevel, "grade-level", 0, "grade level")
+	cmd.Flags().StringSliceVar(&s.Tags, "tag", nil, "tag (repeatable)")
+}
+
+// studentBody returns the request body of create and update: the --file contents when given,
+// otherwise the field flags
+func studentBody(s student, file string) (interface{}, error) {
+	if file == "" {
+		return s, nil
+	}
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return body, nil
+}
+
+// listCommand builds "studentctl list"
+func listCommand() *cobra.Command {
+	var filters []string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List students, optionally filtered",
+		Example: "  studentctl list --filter status=enrolled --filter min_age=18\n" +
+			"  studentctl list --filter custom.house=red -o json",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query, err := filterQuery(filters)
+			if err != nil {
+				return err
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			data, err := c.do("GET", "/students", query, nil, "")
+			if err != nil {
+				return err
+			}
+			return printStudents(data)
+		},
+	}
+	cmd.Flags().StringArrayVar(&filters, "filter", nil, "list filter as key=value, e.g. status=enrolled (repeatable)")
+	return cmd
+}
+
+// getCommand builds "studentctl get"
+func getCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Show a student",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			data, err := c.do("GET", "/students/"+url.PathEscape(args[0]), nil, nil, "")
+			if err != nil {
+				return err
+			}
+			return printStudents(data)
+		},
+	}
+}
+
+// createCommand builds "studentctl create"
+func createCommand() *cobra.Command {
+	var s student
+	var file string
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create a student",
+		Example: "  studentctl create --name \"Ada Lovelace\" --email ada@example.com --date-of-birth 2008-12-10\n  studentctl create -f student.json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body, err := studentBody(s, file)
+			if err != nil {
+				return err
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			data, err := c.do("POST", "/students", nil, body, "")
+			if err != nil {
+				return err
+			}
+			return printStudents(data)
+		},
+	}
+	studentFlags(cmd, &s, &file)
+	return cmd
+}
+
+// updateCommand builds "studentctl update"; only the given fields change
+func updateCommand() *cobra.Command {
+	var s student
+	var file string
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Update the given fields of a student",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body, err := studentBody(s, file)
+			if err != nil {
+				return err
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			data, err := c.do("PUT", "/students/"+url.PathEscape(args[0]), nil, body, "")
+			if err != nil {
+				return err
+			}
+			return printStudents(data)
+		},
+	}
+	studentFlags(cmd, &s, &file)
+	return cmd
+}
+
+// deleteCommand builds "studentctl delete"
+func deleteCommand() *cobra.Command {
+	var purge bool
+	cmd := &cobra.Command{
+		Use:   "delete ID...",
+		Short: "Delete students",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			query := url.Values{}
+			if purge {
+				query.Set("purge", "true")
+			}
+			for _, id := range args {
+				if _, err := c.do("DELETE", "/students/"+url.PathEscape(id), query, nil, ""); err != nil {
+					return err
+				}
+				fmt.Println("Deleted student", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&purge, "purge", false, "also delete alumni")
+	return cmd
+}
+
+// importCommand builds "studentctl import", creating a student for every entry of a JSON array
+// or CSV file whose header names the student fields
+func importCommand() *cobra.Command {
+	var continueOnError bool
+	cmd := &cobra.Command{
+		Use:     "import FILE",
+		Short:   "Create students from a JSON or CSV file",
+		Example: "  studentctl import roster.csv\n  studentctl import roster.json --continue-on-error",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			records, err := readImportFile(args[0])
+			if err != nil {
+				return err
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			created, failed := 0, 0
+			for i, record := range records {
+				if _, err := c.do("POST", "/students", nil, record, ""); err != nil {
+					failed++
+					fmt.Fprintf(os.Stderr, "Entry %d: %v\n", i+1, err)
+					if !continueOnError {
+						break
+					}
+					continue
+				}
+				created++
+			}
+			fmt.Printf("Created %d of %d students\n", created, len(records))
+			if failed > 0 {
+				return fmt.Errorf("%d entries failed", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep importing after a failed entry")
+	return cmd
+}
+
+// readImportFile reads the entries of an import file; CSV numbers become JSON numbers and a
+// tags column is split on ";"
+func readImportFile(path string) ([]map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []map[string]interface{}
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		if err := json.NewDecoder(file).Decode(&records); err != nil {
+			return nil, fmt.Errorf("reading %s: expected a JSON array of students: %w", path, err)
+		}
+		return records, nil
+	}
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := rows[0]
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{})
+		for i, column := range header {
+			if i >= len(row) || row[i] == "" {
+				continue
+			}
+			switch column = strings.TrimSpace(column); column {
+			case "age", "grade_level":
+				n, err := strconv.Atoi(row[i])
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s %q", column, row[i])
+				}
+				record[column] = n
+			case "tags":
+				record[column] = strings.Split(row[i], ";")
+			default:
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// exportCommand builds "studentctl export", downloading the CSV export
+func exportCommand() *cobra.Command {
+	var filters []string
+	var out string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Download students as CSV",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query, err := filterQuery(filters)
+			if err != nil {
+				return err
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			data, err := c.do("GET", "/students/export", query, nil, "text/csv")
+			if err != nil {
+				return err
+			}
+			if out == "" || out == "-" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			return os.WriteFile(out, data, 0o644)
+		},
+	}
+	cmd.Flags().StringArrayVar(&filters, "filter", nil, "list filter as key=value (repeatable)")
+	cmd.Flags().StringVar(&out, "out", "", "write to a file instead of stdout")
+	return cmd
+}
+
+// summaryCommand builds "studentctl summary"
+func summaryCommand() *cobra.Command {
+	var refresh bool
+	var tone, length, language string
+	cmd := &cobra.Command{
+		Use:   "summary ID",
+		Short: "Show the generated summary of a student",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{}
+			for key, value := range map[string]string{"tone": tone, "length": length, "language": language} {
+				if value != "" {
+					query.Set(key, value)
+				}
+			}
+			if refresh {
+				query.Set("refresh", "true")
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			data, err := c.do("GET", "/students/"+url.PathEscape(args[0])+"/summary", query, nil, "")
+			if err != nil {
+				return err
+			}
+			if opts.Output == "json" {
+				return printJSON(data)
+			}
+			var summary struct {
+				Summary string `json:"summary"`
+			}
+			if err := json.Unmarshal(data, &summary); err != nil {
+				return err
+			}
+			fmt.Println(summary.Summary)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "generate a new summary instead of the cached one")
+	cmd.Flags().StringVar(&tone, "tone", "", "summary tone")
+	cmd.Flags().StringVar(&length, "length", "", "summary length")
+	cmd.Flags().StringVar(&language, "language", "", "summary language")
+	return cmd
+}
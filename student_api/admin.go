@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// adminSessionCookie names the cookie the admin UI sign-in sets to the ADMIN_TOKEN
+const adminSessionCookie = "admin_session"
+
+// isAdminToken reports whether a token matches the configured ADMIN_TOKEN
+func (s *Server) isAdminToken(token string) bool {
+	return s.config.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) == 1
+}
+
+// adminInsecureDev reports whether every caller is an admin because ADMIN_INSECURE_DEV is set
+// and no ADMIN_TOKEN is configured
+func (s *Server) adminInsecureDev() bool {
+	return s.config.AdminToken == "" && s.config.AdminInsecureDev
+}
+
+// hasAdminToken reports whether a request carries the ADMIN_TOKEN as a bearer token or in the
+// admin UI session cookie, or ADMIN_INSECURE_DEV makes every caller an admin
+func (s *Server) hasAdminToken(r *http.Request) bool {
+	if s.adminInsecureDev() {
+		return true
+	}
+	if s.isAdminToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+		return true
+	}
+	cookie, err := r.Cookie(adminSessionCookie)
+	return err == nil && s.isAdminToken(cookie.Value)
+}
+
+// requireAdmin rejects requests that do not carry the ADMIN_TOKEN. Without a token the admin
+// routes are unavailable unless ADMIN_INSECURE_DEV opens them for local development
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.AdminToken == "" && !s.config.AdminInsecureDev {
+			http.Error(w, "Admin routes are disabled until ADMIN_TOKEN is set", http.StatusServiceUnavailable)
+			return
+		}
+		if !s.hasAdminToken(r) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callerRole returns the role of the caller: "admin" for requests carrying the ADMIN_TOKEN, the
+// role API_KEY_ROLES assigns to the caller's X-API-Key, or "staff"
+func (s *Server) callerRole(r *http.Request) string {
+	if s.hasAdminToken(r) {
+		return "admin"
+	}
+	if role, ok := s.config.APIKeyRoles[apiKeyFromContext(r.Context())]; ok {
+		return role
+	}
+	return "staff"
+}
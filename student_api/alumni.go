@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// AlumniCohort struct to hold the alumni who graduated in one year
+type AlumniCohort struct {
+	Year   int       `json:"year"`
+	Count  int       `json:"count"`
+	Alumni []Student `json:"alumni"`
+}
+
+// alumniFromRequest returns the alumni matching the request's list filters, most recent
+// graduates first
+func (s *Server) alumniFromRequest(r *http.Request) ([]Student, error) {
+	filter, err := studentFilterFromQuery(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	alumni := true
+	filter.Alumni = &alumni
+
+	s.store.Lock()
+	matched := s.filterStudents(filter)
+	s.store.Unlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].GraduationYear != matched[j].GraduationYear {
+			return matched[i].GraduationYear > matched[j].GraduationYear
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched, nil
+}
+
+// getAlumni handles GET /alumni to list graduated students; it accepts the same filters as
+// GET /students, including ?graduation_year=
+func (s *Server) getAlumni(w http.ResponseWriter, r *http.Request) {
+	alumni, err := s.alumniFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if alumni == nil {
+		alumni = []Student{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alumni)
+}
+
+// getAlumniCohorts handles GET /alumni/cohorts to group the filtered alumni by graduation year
+func (s *Server) getAlumniCohorts(w http.ResponseWriter, r *http.Request) {
+	alumni, err := s.alumniFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cohorts := []AlumniCohort{}
+	for _, student := range alumni {
+		if n := len(cohorts); n == 0 || cohorts[n-1].Year != student.GraduationYear {
+			cohorts = append(cohorts, AlumniCohort{Year: student.GraduationYear})
+		}
+		cohort := &cohorts[len(cohorts)-1]
+		cohort.Alumni = append(cohort.Alumni, student)
+		cohort.Count++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cohorts)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	attachments      = make(map[int]*Attachment)
+	attachmentsMu    sync.Mutex
+	nextAttachmentID int
+)
+
+// Attachment struct to hold the metadata of a file uploaded for a student
+type Attachment struct {
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	Kind        string    `json:"kind"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  string    `json:"uploaded_by"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+	DownloadURL string    `json:"download_url"`
+	// Searchable is set when the file's text grounds summaries and chat answers; only text files
+	// are read, PDFs and images are stored but never used by the LLM features
+	Searchable bool `json:"searchable"`
+	key        string
+}
+
+// withDownloadURL sets the API route a client downloads the attachment from
+func (a Attachment) withDownloadURL() Attachment {
+	a.DownloadURL = fmt.Sprintf("/students/%d/attachments/%d/download", a.StudentID, a.ID)
+	return a
+}
+
+// upload struct to hold a file read from a multipart request along with its other form fields
+type upload struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Fields      map[string]string
+}
+
+// readUpload reads the "file" part and short text fields of a multipart request, enforcing the
+// size limit and sniffing the content type from the data rather than trusting the client
+func readUpload(w http.ResponseWriter, r *http.Request, maxBytes int64, allowedTypes []string) (upload, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return upload{}, http.StatusBadRequest, errors.New("Expected a multipart/form-data upload")
+	}
+
+	result := upload{Fields: make(map[string]string)}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return upload{}, http.StatusBadRequest, errors.New("Invalid multipart body")
+		}
+
+		if part.FormName() != "file" {
+			value, err := ioutil.ReadAll(io.LimitReader(part, 1024))
+			if err != nil {
+				return upload{}, http.StatusBadRequest, errors.New("Invalid multipart body")
+			}
+			result.Fields[part.FormName()] = strings.TrimSpace(string(value))
+			continue
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(part, maxBytes+1))
+		if err != nil {
+			return upload{}, http.StatusRequestEntityTooLarge, errors.New("File is too large")
+		}
+		if int64(len(data)) > maxBytes {
+			return upload{}, http.StatusRequestEntityTooLarge, fmt.Errorf("File exceeds the %d byte limit", maxBytes)
+		}
+		result.Filename = filepath.Base(part.FileName())
+		result.Data = data
+	}
+
+	if len(result.Data) == 0 {
+		return upload{}, http.StatusBadRequest, errors.New("Missing or empty file part")
+	}
+	result.ContentType = strings.SplitN(http.DetectContentType(result.Data), ";", 2)[0]
+	if !containsString(allowedTypes, result.ContentType) {
+		return upload{}, http.StatusUnsupportedMediaType, fmt.Errorf("File type %s is not allowed", result.ContentType)
+	}
+	return result, 0, nil
+}
+
+// uploadAttachment handles POST /students/{id}/attachments to store a file sent as the "file"
+// part of a multipart form, with an optional "kind" field (e.g. transcript, id_proof)
+func (s *Server) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	file, status, err := readUpload(w, r, s.config.AttachmentMaxBytes, s.config.AttachmentTypes)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	attachmentsMu.Lock()
+	nextAttachmentID++
+	attachment := Attachment{
+		ID:          nextAttachmentID,
+		StudentID:   id,
+		Kind:        firstNonEmpty(file.Fields["kind"], "other"),
+		Filename:    file.Filename,
+		ContentType: file.ContentType,
+		Size:        int64(len(file.Data)),
+		UploadedBy:  callerID(r.Context()),
+		UploadedAt:  time.Now(),
+		key:         fmt.Sprintf("students/%d/attachments/%d", id, nextAttachmentID),
+	}
+	attachmentsMu.Unlock()
+
+	if err := s.blobs.Put(r.Context(), attachment.key, file.Data, file.ContentType); err != nil {
+		log.Printf("Storing attachment %d failed: %v", attachment.ID, err)
+		http.Error(w, "Error storing attachment", http.StatusBadGateway)
+		return
+	}
+
+	attachment.Searchable = s.indexAttachmentText(attachment, file.Data)
+	attachmentsMu.Lock()
+	attachments[attachment.ID] = &attachment
+	attachmentsMu.Unlock()
+	if attachment.Searchable {
+		// The cached summary was written without the new passages
+		invalidateSummary(id)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment.withDownloadURL())
+}
+
+// getAttachments handles GET /students/{id}/attachments to list a student's attachments
+func (s *Server) getAttachments(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	attachmentsMu.Lock()
+	list := []Attachment{}
+	for _, attachment := range attachments {
+		if attachment.StudentID == id {
+			list = append(list, attachment.withDownloadURL())
+		}
+	}
+	attachmentsMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// studentAttachment looks up the {attachment} route variable for the student in the URL
+func studentAttachment(w http.ResponseWriter, r *http.Request) (Attachment, bool) {
+	id := extractIDFromURL(r.URL.Path)
+	attachmentID, err := strconv.Atoi(mux.Vars(r)["attachment"])
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return Attachment{}, false
+	}
+
+	attachmentsMu.Lock()
+	attachment, exists := attachments[attachmentID]
+	attachmentsMu.Unlock()
+	if !exists || attachment.StudentID != id {
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+		return Attachment{}, false
+	}
+	return *attachment, true
+}
+
+// downloadAttachment handles GET /students/{id}/attachments/{attachment}/download, redirecting
+// to a short-lived signed URL when the store supports one and streaming the file otherwise
+func (s *Server) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := studentAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	if signer, ok := s.blobs.(URLSigner); ok {
+		url, err := signer.SignedURL(attachment.key, s.config.AttachmentURLExpiry)
+		if err == nil {
+			http.Redirect(w, r, url, http.StatusFound)
+			return
+		}
+		log.Printf("Signing download URL for attachment %d failed: %v", attachment.ID, err)
+	}
+
+	body, err := s.blobs.Get(r.Context(), attachment.key)
+	if errors.Is(err, ErrBlobNotFound) {
+		http.Error(w, "Attachment content is missing", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Reading attachment %d failed: %v", attachment.ID, err)
+		http.Error(w, "Error reading attachment", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.Filename))
+	io.Copy(w, body)
+}
+
+// deleteAttachment handles DELETE /students/{id}/attachments/{attachment} to remove an attachment
+// and its stored file
+func (s *Server) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := studentAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.blobs.Delete(r.Context(), attachment.key); err != nil {
+		log.Printf("Deleting attachment %d failed: %v", attachment.ID, err)
+		http.Error(w, "Error deleting attachment", http.StatusBadGateway)
+		return
+	}
+
+	attachmentsMu.Lock()
+	delete(attachments, attachment.ID)
+	attachmentsMu.Unlock()
+	if deleteDocumentChunks(attachment.ID) {
+		// The cached summary may cite the deleted passages
+		invalidateSummary(attachment.StudentID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteAttachments removes a deleted student's attachments, deleting the stored files in the
+// background; the student's summaries are deleted with the student, so nothing is invalidated
+func (s *Server) deleteAttachments(id int) {
+	attachmentsMu.Lock()
+	var keys []string
+	var ids []int
+	for attachmentID, attachment := range attachments {
+		if attachment.StudentID == id {
+			keys = append(keys, attachment.key)
+			ids = append(ids, attachmentID)
+			delete(attachments, attachmentID)
+		}
+	}
+	attachmentsMu.Unlock()
+	for _, attachmentID := range ids {
+		deleteDocumentChunks(attachmentID)
+	}
+
+	go func() {
+		for _, key := range keys {
+			if err := s.blobs.Delete(context.Background(), key); err != nil {
+				log.Printf("Deleting blob %s failed: %v", key, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// attendanceMu guards attendance; take it after the student store and coursesMu when several locks are needed
+var (
+	attendance   = make(map[attendanceKey]AttendanceRecord)
+	attendanceMu sync.Mutex
+
+	// Accepted values for AttendanceRecord.Status
+	attendanceStatuses = map[string]bool{"present": true, "absent": true, "late": true, "excused": true}
+)
+
+// attendanceKey identifies one attendance mark; CourseID 0 is daily (homeroom) attendance and
+// Period 0 covers the whole day
+type attendanceKey struct {
+	StudentID int
+	CourseID  int
+	Date      string
+	Period    int
+}
+
+// AttendanceRecord struct to hold one attendance mark for a student
+type AttendanceRecord struct {
+	StudentID  int       `json:"student_id"`
+	CourseID   int       `json:"course_id,omitempty"`
+	Date       string    `json:"date"`
+	Period     int       `json:"period,omitempty"`
+	Status     string    `json:"status"`
+	Note       string    `json:"note,omitempty"`
+	RecordedBy string    `json:"recorded_by"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// AttendanceSummary struct to hold attendance totals; excused absences do not count against the
+// percentage, and late arrivals count as attended
+type AttendanceSummary struct {
+	StudentID  int     `json:"student_id"`
+	Name       string  `json:"name,omitempty"`
+	Total      int     `json:"total"`
+	Present    int     `json:"present"`
+	Late       int     `json:"late"`
+	Absent     int     `json:"absent"`
+	Excused    int     `json:"excused"`
+	Percentage float64 `json:"percentage"`
+}
+
+// AttendanceQuery struct to hold the date range and course a query is restricted to
+type AttendanceQuery struct {
+	From     string
+	To       string
+	CourseID int
+}
+
+// attendanceQueryFromURL reads the ?from=, ?to= and ?course_id= parameters; ?term= sets the
+// range to the term's dates
+func attendanceQueryFromURL(query url.Values) (AttendanceQuery, error) {
+	q := AttendanceQuery{From: query.Get("from"), To: query.Get("to")}
+	if name := query.Get("term"); name != "" {
+		start, end, exists := termDates(name)
+		if !exists {
+			return q, errors.New("Unknown term " + name)
+		}
+		q.From, q.To = start, end
+	}
+	for _, date := range []string{q.From, q.To} {
+		if _, err := time.Parse(dateLayout, date); date != "" && err != nil {
+			return q, errors.New("from and to must be in YYYY-MM-DD format")
+		}
+	}
+	if value := query.Get("course_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return q, errors.New("Invalid course_id")
+		}
+		q.CourseID = id
+	}
+	return q, nil
+}
+
+// matches reports whether a record falls inside the query's range and course
+func (q AttendanceQuery) matches(record AttendanceRecord) bool {
+	// Dates are YYYY-MM-DD, so they compare correctly as strings
+	if (q.From != "" && record.Date < q.From) || (q.To != "" && record.Date > q.To) {
+		return false
+	}
+	return q.CourseID == 0 || record.CourseID == q.CourseID
+}
+
+// validateAttendance checks the fields of a mark submitted by a client
+func validateAttendance(record AttendanceRecord) error {
+	if _, err := time.Parse(dateLayout, record.Date); err != nil {
+		return errors.New("date must be in YYYY-MM-DD format")
+	}
+	if record.Period < 0 {
+		return errors.New("period must not be negative")
+	}
+	if !attendanceStatuses[record.Status] {
+		return errors.New("status must be present, absent, late or excused")
+	}
+	return nil
+}
+
+// storeAttendance records a mark, replacing any earlier mark for the same slot; the caller must
+// hold attendanceMu
+func storeAttendance(record AttendanceRecord) {
+	attendance[attendanceKey{record.StudentID, record.CourseID, record.Date, record.Period}] = record
+}
+
+// recordAttendance handles POST /students/{id}/attendance to mark a student for a day or period
+func (s *Server) recordAttendance(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var record AttendanceRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateAttendance(record); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Alerts are queued once the locks below are released
+	var alerts []AttendanceRecord
+	defer func() { s.sendAbsenceAlerts(r.Context(), alerts) }()
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if _, exists := s.store.Get(id); !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+	if record.CourseID != 0 {
+		coursesMu.Lock()
+		_, enrolled := enrollments[record.CourseID][id]
+		coursesMu.Unlock()
+		if !enrolled {
+			http.Error(w, "Student is not enrolled in this course", http.StatusConflict)
+			return
+		}
+	}
+
+	record.StudentID = id
+	record.RecordedBy = callerID(r.Context())
+	record.RecordedAt = time.Now()
+	attendanceMu.Lock()
+	storeAttendance(record)
+	attendanceMu.Unlock()
+	alerts = []AttendanceRecord{record}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(record)
+}
+
+// markCourseAttendance handles POST /courses/{id}/attendance to mark a whole class at once. Listed
+// students get their own status; when default_status is set every other enrolled student gets it.
+func (s *Server) markCourseAttendance(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := courseIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Date          string             `json:"date"`
+		Period        int                `json:"period"`
+		DefaultStatus string             `json:"default_status"`
+		Records       []AttendanceRecord `json:"records"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if req.DefaultStatus != "" && !attendanceStatuses[req.DefaultStatus] {
+		http.Error(w, "default_status must be present, absent, late or excused", http.StatusBadRequest)
+		return
+	}
+
+	// Alerts are queued once coursesMu is released
+	var alerts []AttendanceRecord
+	defer func() { s.sendAbsenceAlerts(r.Context(), alerts) }()
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+	if _, exists := courses[courseID]; !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	marks := make(map[int]AttendanceRecord)
+	if req.DefaultStatus != "" {
+		for studentID := range enrollments[courseID] {
+			marks[studentID] = AttendanceRecord{StudentID: studentID, Status: req.DefaultStatus}
+		}
+	}
+	for _, record := range req.Records {
+		if _, enrolled := enrollments[courseID][record.StudentID]; !enrolled {
+			http.Error(w, "Student "+strconv.Itoa(record.StudentID)+" is not enrolled in this course", http.StatusConflict)
+			return
+		}
+		marks[record.StudentID] = record
+	}
+
+	now := time.Now()
+	recorded := []AttendanceRecord{}
+	for studentID, record := range marks {
+		record.StudentID = studentID
+		record.CourseID = courseID
+		record.Date = req.Date
+		record.Period = req.Period
+		record.RecordedBy = callerID(r.Context())
+		record.RecordedAt = now
+		if err := validateAttendance(record); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		recorded = append(recorded, record)
+	}
+	sort.Slice(recorded, func(i, j int) bool { return recorded[i].StudentID < recorded[j].StudentID })
+
+	attendanceMu.Lock()
+	for _, record := range recorded {
+		storeAttendance(record)
+	}
+	attendanceMu.Unlock()
+	alerts = recorded
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(recorded)
+}
+
+// getStudentAttendance handles GET /students/{id}/attendance?from=&to=&course_id= to list a
+// student's marks with their attendance percentage over the range
+func (s *Server) getStudentAttendance(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	query, err := attendanceQueryFromURL(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	records := []AttendanceRecord{}
+	attendanceMu.Lock()
+	for _, record := range attendance {
+		if record.StudentID == id && query.matches(record) {
+			records = append(records, record)
+		}
+	}
+	attendanceMu.Unlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Date != records[j].Date {
+			return records[i].Date < records[j].Date
+		}
+		if records[i].Period != records[j].Period {
+			return records[i].Period < records[j].Period
+		}
+		return records[i].CourseID < records[j].CourseID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"summary": summarizeAttendance(id, records),
+		"records": records,
+	})
+}
+
+// getLowAttendanceReport handles GET /reports/attendance/low?threshold=75&from=&to=&course_id= to
+// list students whose attendance percentage is below the threshold
+func (s *Server) getLowAttendanceReport(w http.ResponseWriter, r *http.Request) {
+	query, err := attendanceQueryFromURL(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	threshold := s.config.LowAttendanceThreshold
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		if threshold, err = strconv.ParseFloat(value, 64); err != nil || threshold < 0 || threshold > 100 {
+			http.Error(w, "threshold must be a percentage", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"threshold": threshold,
+		"from":      query.From,
+		"to":        query.To,
+		"students":  s.lowAttendance(query, threshold),
+	})
+}
+
+// lowAttendance returns the students whose attendance over the query is below threshold, lowest
+// first; the caller must not hold the student store or attendanceMu
+func (s *Server) lowAttendance(query AttendanceQuery, threshold float64) []AttendanceSummary {
+	byStudent := make(map[int][]AttendanceRecord)
+	attendanceMu.Lock()
+	for _, record := range attendance {
+		if query.matches(record) {
+			byStudent[record.StudentID] = append(byStudent[record.StudentID], record)
+		}
+	}
+	attendanceMu.Unlock()
+
+	report := []AttendanceSummary{}
+	s.store.Lock()
+	for studentID, records := range byStudent {
+		summary := summarizeAttendance(studentID, records)
+		if summary.Percentage < threshold {
+			student, _ := s.store.Get(studentID)
+			summary.Name = student.Name
+			report = append(report, summary)
+		}
+	}
+	s.store.Unlock()
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Percentage != report[j].Percentage {
+			return report[i].Percentage < report[j].Percentage
+		}
+		return report[i].StudentID < report[j].StudentID
+	})
+	return report
+}
+
+// summarizeAttendance totals a student's marks
+func summarizeAttendance(studentID int, records []AttendanceRecord) AttendanceSummary {
+	summary := AttendanceSummary{StudentID: studentID, Total: len(records), Percentage: 100}
+	for _, record := range records {
+		switch record.Status {
+		case "present":
+			summary.Present++
+		case "late":
+			summary.Late++
+		case "absent":
+			summary.Absent++
+		case "excused":
+			summary.Excused++
+		}
+	}
+	if counted := summary.Total - summary.Excused; counted > 0 {
+		summary.Percentage = math.Round(float64(summary.Present+summary.Late)/float64(counted)*10000) / 100
+	}
+	return summary
+}
+
+// deleteAttendance drops a deleted student's attendance marks
+func deleteAttendance(id int) {
+	attendanceMu.Lock()
+	defer attendanceMu.Unlock()
+	for key := range attendance {
+		if key.StudentID == id {
+			delete(attendance, key)
+		}
+	}
+}
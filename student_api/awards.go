@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	awards      = make(map[int][]Award)
+	awardsMu    sync.Mutex
+	nextAwardID int
+
+	// Accepted values for Award.Type
+	awardTypes = map[string]bool{"scholarship": true, "bursary": true, "grant": true, "prize": true, "award": true}
+)
+
+// Award struct to hold a scholarship or award granted to a student; Amount is in minor units of
+// FEES_CURRENCY and the period is an inclusive date range
+type Award struct {
+	ID          int    `json:"id"`
+	StudentID   int    `json:"student_id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Amount      int64  `json:"amount,omitempty"`
+	Currency    string `json:"currency,omitempty"`
+	PeriodStart string `json:"period_start,omitempty"`
+	PeriodEnd   string `json:"period_end,omitempty"`
+	Criteria    string `json:"criteria,omitempty"`
+}
+
+// validateAward checks an award submitted by a client
+func (s *Server) validateAward(award Award) error {
+	if strings.TrimSpace(award.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !awardTypes[award.Type] {
+		return errors.New("type must be one of scholarship, bursary, grant, prize or award")
+	}
+	if award.Amount < 0 {
+		return errors.New("amount must not be negative")
+	}
+	if err := s.checkCurrency(award.Currency); err != nil {
+		return err
+	}
+	for _, date := range []string{award.PeriodStart, award.PeriodEnd} {
+		if _, err := time.Parse(dateLayout, date); date != "" && err != nil {
+			return errors.New("period_start and period_end must be in YYYY-MM-DD format")
+		}
+	}
+	if award.PeriodStart != "" && award.PeriodEnd != "" && award.PeriodEnd < award.PeriodStart {
+		return errors.New("period_end must not be before period_start")
+	}
+	return nil
+}
+
+// createAward handles POST /students/{id}/awards to record a scholarship or award
+func (s *Server) createAward(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var award Award
+	if err := json.NewDecoder(r.Body).Decode(&award); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	award.Type = strings.ToLower(strings.TrimSpace(award.Type))
+	if award.Type == "" {
+		award.Type = "award"
+	}
+	if err := s.validateAward(award); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	awardsMu.Lock()
+	nextAwardID++
+	award.ID = nextAwardID
+	award.StudentID = id
+	award.Currency = ""
+	if award.Amount > 0 {
+		award.Currency = s.config.FeesCurrency
+	}
+	awards[id] = append(awards[id], award)
+	awardsMu.Unlock()
+	invalidateSummary(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(award)
+}
+
+// getAwards handles GET /students/{id}/awards?type= to list a student's awards, most recent
+// period first
+func (s *Server) getAwards(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	awardType := strings.ToLower(r.URL.Query().Get("type"))
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	list := []Award{}
+	for _, award := range studentAwards(id) {
+		if awardType == "" || award.Type == awardType {
+			list = append(list, award)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// deleteAward handles DELETE /students/{id}/awards/{award} to remove an award
+func deleteAward(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	awardID, err := strconv.Atoi(mux.Vars(r)["award"])
+	if err != nil {
+		http.Error(w, "Invalid award ID", http.StatusBadRequest)
+		return
+	}
+
+	awardsMu.Lock()
+	defer awardsMu.Unlock()
+
+	list := awards[id]
+	for i, award := range list {
+		if award.ID == awardID {
+			awards[id] = append(list[:i], list[i+1:]...)
+			invalidateSummary(id)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	http.Error(w, "Award not found", http.StatusNotFound)
+}
+
+// studentAwards returns a copy of a student's awards, most recent period first
+func studentAwards(id int) []Award {
+	awardsMu.Lock()
+	list := append([]Award{}, awards[id]...)
+	awardsMu.Unlock()
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].PeriodStart > list[j].PeriodStart })
+	return list
+}
+
+// hasScholarship reports whether a student holds any award of type scholarship
+func hasScholarship(id int) bool {
+	awardsMu.Lock()
+	defer awardsMu.Unlock()
+	for _, award := range awards[id] {
+		if award.Type == "scholarship" {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteAwards drops a deleted student's awards
+func deleteAwards(id int) {
+	awardsMu.Lock()
+	defer awardsMu.Unlock()
+	delete(awards, id)
+}
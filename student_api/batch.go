@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// BatchSummaryRequest struct to hold the body of POST /students/summaries
+type BatchSummaryRequest struct {
+	IDs    []int          `json:"ids"`
+	Filter *StudentFilter `json:"filter"`
+}
+
+// BatchSummaryResult struct to hold the outcome for one student in a batch
+type BatchSummaryResult struct {
+	Summary *Summary `json:"summary,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// createBatchSummaryJob handles POST /students/summaries to summarize many students in the background
+func (s *Server) createBatchSummaryJob(w http.ResponseWriter, r *http.Request) {
+	var req BatchSummaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 && req.Filter == nil {
+		http.Error(w, "Either ids or filter is required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	refresh := r.URL.Query().Get("refresh") == "true"
+
+	var ids []int
+	found := 0
+	s.store.Lock()
+	seen := make(map[int]bool)
+	if req.Filter != nil {
+		for _, student := range s.filterStudents(*req.Filter) {
+			seen[student.ID] = true
+			ids = append(ids, student.ID)
+			found++
+		}
+	}
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		if _, exists := s.store.Get(id); exists {
+			found++
+		}
+	}
+	s.store.Unlock()
+
+	job, err := s.enqueueJob(backgroundContext(r), "batch-summary", batchSummaryPayload{IDs: ids, Options: opts, Refresh: refresh}, JobOptions{})
+	if err != nil {
+		http.Error(w, "Error queueing job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+strconv.Itoa(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"job_id": job.ID, "status": job.Status, "students": found})
+}
+
+// batchSummaryPayload struct to hold the payload of a batch summary job; the filter is resolved
+// to IDs when the job is queued
+type batchSummaryPayload struct {
+	IDs     []int          `json:"ids"`
+	Options SummaryOptions `json:"options"`
+	Refresh bool           `json:"refresh,omitempty"`
+}
+
+// runBatchSummaryJob summarizes the students of a batch, reporting IDs that no longer exist
+func (s *Server) runBatchSummaryJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var req batchSummaryPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]BatchSummaryResult)
+	var batch []Student
+	s.store.Lock()
+	for _, id := range req.IDs {
+		student, exists := s.store.Get(id)
+		if !exists {
+			results[strconv.Itoa(id)] = BatchSummaryResult{Error: "Student not found"}
+			continue
+		}
+		batch = append(batch, student)
+	}
+	s.store.Unlock()
+
+	s.summarizeBatch(ctx, batch, req.Options, req.Refresh, results)
+	return json.Marshal(results)
+}
+
+// summarizeBatch generates summaries for the students using a pool of workers, recording each
+// outcome in results keyed by student ID
+func (s *Server) summarizeBatch(ctx context.Context, batch []Student, opts SummaryOptions, refresh bool, results map[string]BatchSummaryResult) {
+	queue := make(chan Student)
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.config.BatchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for student := range queue {
+				summary, err := s.studentSummary(ctx, student, opts, refresh)
+				if errors.Is(err, ErrCircuitOpen) && s.config.DegradedMode == "template" {
+					summary, err = fallbackSummary(student), nil
+				}
+
+				result := BatchSummaryResult{Summary: &summary}
+				if err != nil {
+					result = BatchSummaryResult{Error: err.Error()}
+				}
+				resultsMu.Lock()
+				results[strconv.Itoa(student.ID)] = result
+				resultsMu.Unlock()
+			}
+		}()
+	}
+
+	for _, student := range batch {
+		queue <- student
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Birthday struct to hold one entry of GET /students/birthdays
+type Birthday struct {
+	StudentID   int    `json:"student_id"`
+	Name        string `json:"name"`
+	DateOfBirth string `json:"date_of_birth"`
+	Day         int    `json:"day"`
+	// Turning is the age the student reaches on this year's birthday
+	Turning int `json:"turning"`
+}
+
+// birthdayIn returns the date of a birthday in the given year; February 29 birthdays fall on
+// February 28 in common years
+func birthdayIn(dob time.Time, year int) time.Time {
+	day := time.Date(year, dob.Month(), dob.Day(), 0, 0, 0, 0, time.Local)
+	if day.Month() != dob.Month() {
+		day = time.Date(year, dob.Month()+1, 0, 0, 0, 0, 0, time.Local)
+	}
+	return day
+}
+
+// birthdaysOn returns the students with a date of birth whose birthday this year falls in the
+// given month, and on the given day when day is non-zero; the caller must hold the student store
+func (s *Server) birthdaysOn(year int, month time.Month, day int) []Birthday {
+	list := []Birthday{}
+	for _, student := range s.store.List() {
+		dob, err := time.Parse(dateLayout, student.DateOfBirth)
+		if err != nil {
+			continue
+		}
+		birthday := birthdayIn(dob, year)
+		if birthday.Month() != month || (day != 0 && birthday.Day() != day) {
+			continue
+		}
+		list = append(list, Birthday{
+			StudentID:   student.ID,
+			Name:        student.Name,
+			DateOfBirth: student.DateOfBirth,
+			Day:         birthday.Day(),
+			Turning:     year - dob.Year(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Day != list[j].Day {
+			return list[i].Day < list[j].Day
+		}
+		return list[i].StudentID < list[j].StudentID
+	})
+	return list
+}
+
+// getBirthdays handles GET /students/birthdays?month=5&day= to list the students whose birthday
+// falls in a month (the current month by default), ordered by day
+func (s *Server) getBirthdays(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	month, day := int(now.Month()), 0
+	for key, target := range map[string]*int{"month": &month, "day": &day} {
+		if value := r.URL.Query().Get(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, "Invalid "+key, http.StatusBadRequest)
+				return
+			}
+			*target = n
+		}
+	}
+	if month < 1 || month > 12 || day < 0 || day > 31 {
+		http.Error(w, "month must be 1-12 and day 1-31", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	list := s.birthdaysOn(now.Year(), time.Month(month), day)
+	s.store.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// startBirthdayNotifications schedules birthday greetings when BIRTHDAY_NOTIFICATION_SCHEDULE is set
+func (s *Server) startBirthdayNotifications() error {
+	if s.config.BirthdayNotificationSchedule == "" {
+		return nil
+	}
+
+	schedule, err := ParseCron(s.config.BirthdayNotificationSchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("birthday-notifications", schedule, s.sendBirthdayNotifications)
+	return nil
+}
+
+// sendBirthdayNotifications queues a greeting for every student whose birthday is today, by email
+// unless their notification preferences say otherwise, failing when any could not be queued
+func (s *Server) sendBirthdayNotifications() error {
+	now := time.Now()
+	s.store.Lock()
+	list := s.birthdaysOn(now.Year(), now.Month(), now.Day())
+	recipients := make(map[int]notificationRecipient, len(list))
+	for _, birthday := range list {
+		student, _ := s.store.Get(birthday.StudentID)
+		recipients[birthday.StudentID] = notificationRecipient{StudentID: student.ID, Email: student.Email, Phone: student.Phone}
+	}
+	s.store.Unlock()
+
+	failed := 0
+	for _, birthday := range list {
+		body := "Happy birthday, " + birthday.Name + "! Best wishes on turning " + strconv.Itoa(birthday.Turning) + "."
+		if _, _, err := s.sendNotification(context.Background(), recipients[birthday.StudentID], ChannelEmail, "Happy birthday!", body, false); err != nil {
+			log.Printf("Error queueing birthday greeting to student %d: %v", birthday.StudentID, err)
+			failed++
+		}
+	}
+
+	log.Printf("Birthday notifications finished: %d of %d queued", len(list)-failed, len(list))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d birthday greetings could not be queued", failed, len(list))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"student_api/student_api/internal/llm"
+)
+
+// ErrCircuitOpen is returned while the breaker is rejecting LLM calls
+var ErrCircuitOpen = errors.New("llm circuit breaker is open")
+
+// circuitOpenError is the ErrCircuitOpen of one breaker, telling the caller when it will admit a
+// trial call
+type circuitOpenError struct {
+	retryAfter time.Duration
+}
+
+func (e *circuitOpenError) Error() string { return ErrCircuitOpen.Error() }
+func (e *circuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+// circuitRetryAfter returns how long the breaker that rejected a call will stay open
+func circuitRetryAfter(err error) time.Duration {
+	var open *circuitOpenError
+	if errors.As(err, &open) {
+		return open.retryAfter
+	}
+	return 0
+}
+
+// CircuitBreakerProvider stops calling the wrapped provider after repeated failures and
+// lets a single trial call through once the cooldown has elapsed. The default provider chain and
+// each tenant's provider have their own breaker, so one failing tenant cannot shut out the others
+type CircuitBreakerProvider struct {
+	Provider  LLMProvider
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// Generate calls the wrapped provider unless the breaker is open
+func (b *CircuitBreakerProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	allowed, trial := b.allow()
+	if !allowed {
+		return LLMResponse{}, b.openError()
+	}
+
+	resp, err := b.Provider.Generate(ctx, req)
+	b.record(err, trial)
+	return resp, err
+}
+
+// Stream relays the wrapped provider's stream unless the breaker is open
+func (b *CircuitBreakerProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	allowed, trial := b.allow()
+	if !allowed {
+		return LLMResponse{}, b.openError()
+	}
+
+	resp, err := streamFrom(ctx, b.Provider, req, chunks)
+	b.record(err, trial)
+	return resp, err
+}
+
+// Embed embeds text with the wrapped provider unless the breaker is open
+func (b *CircuitBreakerProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	allowed, trial := b.allow()
+	if !allowed {
+		return nil, b.openError()
+	}
+
+	vector, err := embedFrom(ctx, b.Provider, model, text)
+	b.record(err, trial)
+	return vector, err
+}
+
+// allow reports whether a call may proceed, admitting one trial call after the cooldown; trial
+// is set for that call
+func (b *CircuitBreakerProvider) allow() (allowed, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return true, false
+	}
+	if b.trial || time.Since(b.openedAt) < b.Cooldown {
+		return false, false
+	}
+	b.trial = true
+	return true, true
+}
+
+// record updates the breaker with the outcome of a call. Only a success closes it and only a
+// transient failure counts against it; a cancelled call or a full queue says nothing about the
+// provider, and a rejected request (a 4xx) neither closes nor opens it. The trial call's outcome
+// ends the trial, so another can be admitted if it did not close the breaker
+func (b *CircuitBreakerProvider) record(err error, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+	}
+	switch {
+	case err == nil:
+		b.failures = 0
+	case llm.IsTransient(err):
+		b.failures++
+		if b.failures >= b.Threshold {
+			b.openedAt = time.Now()
+		}
+	}
+}
+
+// openError returns the error of a call the breaker rejected
+func (b *CircuitBreakerProvider) openError() error {
+	return &circuitOpenError{retryAfter: b.RetryAfter()}
+}
+
+// RetryAfter returns how long until the breaker will admit a trial call
+func (b *CircuitBreakerProvider) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return 0
+	}
+	if remaining := b.Cooldown - time.Since(b.openedAt); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
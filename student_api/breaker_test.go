@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingProvider answers each call with the next error of errs, succeeding once they run out
+type failingProvider struct {
+	errs []error
+}
+
+func (p *failingProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	if len(p.errs) == 0 {
+		return LLMResponse{Text: "ok"}, nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return LLMResponse{}, err
+}
+
+func TestCircuitBreakerCountsOnlyProviderFailures(t *testing.T) {
+	unavailable := &LLMStatusError{StatusCode: 503}
+	badRequest := &LLMStatusError{StatusCode: 400}
+	provider := &failingProvider{errs: []error{unavailable, context.Canceled, ErrLLMQueueFull, badRequest, unavailable}}
+	breaker := &CircuitBreakerProvider{Provider: provider, Threshold: 2, Cooldown: time.Hour}
+
+	for range 5 {
+		breaker.Generate(context.Background(), LLMRequest{})
+	}
+	if _, err := breaker.Generate(context.Background(), LLMRequest{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after two 503s with other errors between them: got %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreakerTrialDecides(t *testing.T) {
+	unavailable := &LLMStatusError{StatusCode: 503}
+	provider := &failingProvider{errs: []error{unavailable, unavailable, &LLMStatusError{StatusCode: 400}}}
+	breaker := &CircuitBreakerProvider{Provider: provider, Threshold: 2, Cooldown: time.Millisecond}
+
+	breaker.Generate(context.Background(), LLMRequest{})
+	breaker.Generate(context.Background(), LLMRequest{})
+	time.Sleep(5 * time.Millisecond)
+
+	// A rejected request proves nothing about the provider, so the trial leaves the breaker open
+	if _, err := breaker.Generate(context.Background(), LLMRequest{}); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("trial call was not admitted after the cooldown")
+	}
+	if breaker.failures < breaker.Threshold {
+		t.Fatal("a 4xx trial closed the breaker")
+	}
+
+	if _, err := breaker.Generate(context.Background(), LLMRequest{}); err != nil {
+		t.Fatalf("second trial: %v", err)
+	}
+	if breaker.failures != 0 {
+		t.Errorf("a successful trial left the breaker open")
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxChangesPage bounds the changes returned by one GET /changes call
+const maxChangesPage = 1000
+
+// The change log holds every student event, oldest first, numbered by the event ID. Entries are
+// added by publishStudentEvent, appended to CHANGE_LOG_FILE when one is set and only dropped by
+// the retention task; eventSubscribersMu guards it
+var (
+	changeLog     []StudentEvent
+	changeLogFile *os.File
+)
+
+// ChangesPage struct to hold one page of GET /changes; NextSince is the since to pass for the
+// following page
+type ChangesPage struct {
+	Changes   []StudentEvent `json:"changes"`
+	NextSince int64          `json:"next_since"`
+	HasMore   bool           `json:"has_more"`
+}
+
+// startChangeLog reloads CHANGE_LOG_FILE so sequence numbers continue where they stopped and
+// opens it for appending; it must run before any student event is published
+func (s *Server) startChangeLog() error {
+	if s.config.ChangeLogFile == "" {
+		return nil
+	}
+	entries, err := readChangeLog(s.config.ChangeLogFile)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.config.ChangeLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	eventSubscribersMu.Lock()
+	changeLog = entries
+	changeLogFile = f
+	if len(entries) > 0 {
+		nextEventID = entries[len(entries)-1].ID
+	}
+	eventSubscribersMu.Unlock()
+	log.Printf("Change log loaded with %d changes, next sequence %d", len(entries), nextEventID+1)
+	return nil
+}
+
+// readChangeLog reads the JSON lines of a change log file; a missing file is an empty log
+func readChangeLog(path string) ([]StudentEvent, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []StudentEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event StudentEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", path, line, err)
+		}
+		entries = append(entries, event)
+	}
+	return entries, scanner.Err()
+}
+
+// addToChangeLog records an event in the change log; the caller must hold eventSubscribersMu
+func addToChangeLog(event StudentEvent) {
+	changeLog = append(changeLog, event)
+	if changeLogFile == nil {
+		return
+	}
+	if err := json.NewEncoder(changeLogFile).Encode(event); err != nil {
+		log.Printf("Error writing change %d to the change log file: %v", event.ID, err)
+	}
+}
+
+// pruneChangeLog drops the changes older than CHANGE_LOG_RETENTION, rewriting CHANGE_LOG_FILE
+// without them; readers asking for pruned changes are told to resync
+func (s *Server) pruneChangeLog() (int, error) {
+	if s.config.ChangeLogRetention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.config.ChangeLogRetention)
+	eventSubscribersMu.Lock()
+	defer eventSubscribersMu.Unlock()
+	// The newest change is always kept so sequence numbers still continue after a restart
+	dropped := 0
+	for dropped < len(changeLog)-1 && changeLog[dropped].At.Before(cutoff) {
+		dropped++
+	}
+	if dropped == 0 {
+		return 0, nil
+	}
+	changeLog = append([]StudentEvent{}, changeLog[dropped:]...)
+	if changeLogFile == nil {
+		return dropped, nil
+	}
+
+	tmp, err := os.OpenFile(s.config.ChangeLogFile+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return dropped, err
+	}
+	w := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(w)
+	for _, event := range changeLog {
+		if err := encoder.Encode(event); err != nil {
+			tmp.Close()
+			return dropped, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return dropped, err
+	}
+	if err := tmp.Close(); err != nil {
+		return dropped, err
+	}
+	if err := os.Rename(s.config.ChangeLogFile+".tmp", s.config.ChangeLogFile); err != nil {
+		return dropped, err
+	}
+	f, err := os.OpenFile(s.config.ChangeLogFile, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return dropped, err
+	}
+	changeLogFile.Close()
+	changeLogFile = f
+	return dropped, nil
+}
+
+// getChanges handles GET /changes?since=&limit= to list the changes with a sequence number above
+// since, oldest first, so clients can sync incrementally by passing back next_since. A since
+// older than the retained log returns 410 Gone and the client must resync from GET /students
+func getChanges(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = strconv.ParseInt(value, 10, 64); err != nil || since < 0 {
+			http.Error(w, "since must be a sequence number", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxChangesPage {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxChangesPage), http.StatusBadRequest)
+			return
+		}
+	}
+
+	eventSubscribersMu.Lock()
+	oldest := nextEventID + 1
+	if len(changeLog) > 0 {
+		oldest = changeLog[0].ID
+	}
+	if since < oldest-1 {
+		eventSubscribersMu.Unlock()
+		http.Error(w, fmt.Sprintf("Changes before sequence %d are no longer retained; resync from GET /students", oldest), http.StatusGone)
+		return
+	}
+	page := ChangesPage{Changes: []StudentEvent{}, NextSince: since}
+	for _, event := range changeLog {
+		if event.ID <= since {
+			continue
+		}
+		if len(page.Changes) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Changes = append(page.Changes, event)
+		page.NextSince = event.ID
+	}
+	eventSubscribersMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	chatSessions   = make(map[string]*ChatSession)
+	chatSessionsMu sync.Mutex
+)
+
+// ChatSession struct to hold a counselor's conversation about one student
+type ChatSession struct {
+	ID        string       `json:"session_id"`
+	StudentID int          `json:"student_id"`
+	Model     string       `json:"model"`
+	Messages  []LLMMessage `json:"messages"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+
+	// busy is set while a turn is waiting for the model
+	busy bool
+}
+
+// ChatRequest struct to hold the body of POST /students/{id}/chat
+type ChatRequest struct {
+	SessionID string `json:"session_id"`
+	Message   string `json:"message"`
+}
+
+// chatWithStudent handles POST /students/{id}/chat to ask follow-up questions about a student,
+// starting a new session when no session_id is given
+func (s *Server) chatWithStudent(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		http.Error(w, "Message is required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+	if err := s.checkLLMConsent(id); err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	// A session takes one turn at a time, so concurrent turns cannot drop each other's messages
+	chatSessionsMu.Lock()
+	session, ok := chatSessions[req.SessionID]
+	switch {
+	case req.SessionID == "":
+		session = &ChatSession{
+			ID:        newSessionID(),
+			StudentID: id,
+			Model:     opts.Model,
+			Messages:  []LLMMessage{{Role: "system", Content: chatSystemPrompt(student)}},
+			CreatedAt: time.Now(),
+		}
+	case !ok || session.StudentID != id:
+		chatSessionsMu.Unlock()
+		http.Error(w, "Chat session not found", http.StatusNotFound)
+		return
+	case session.busy:
+		chatSessionsMu.Unlock()
+		http.Error(w, "Chat session is answering another message", http.StatusConflict)
+		return
+	}
+	session.busy = true
+	messages := append(append([]LLMMessage{}, session.Messages...), LLMMessage{Role: "user", Content: req.Message})
+	chatSessionsMu.Unlock()
+
+	// Passages from the student's documents ground this turn only; they are not kept in the history
+	prompt := messages
+	citations, err := s.retrieveDocuments(r.Context(), id, req.Message)
+	if err != nil {
+		log.Printf("Retrieving documents for student %d failed: %v", id, err)
+	}
+	if len(citations) > 0 {
+		prompt = append(append([]LLMMessage{}, messages[:len(messages)-1]...),
+			LLMMessage{Role: "system", Content: documentContext(citations)}, messages[len(messages)-1])
+	}
+
+	resp, err := s.llm.Generate(r.Context(), LLMRequest{Model: session.Model, Messages: prompt})
+
+	chatSessionsMu.Lock()
+	session.busy = false
+	if err == nil {
+		session.Messages = append(messages, LLMMessage{Role: "assistant", Content: resp.Text})
+		session.UpdatedAt = time.Now()
+		if req.SessionID == "" {
+			chatSessions[session.ID] = session
+		}
+	}
+	chatSessionsMu.Unlock()
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	reply := map[string]interface{}{
+		"session_id": session.ID,
+		"reply":      resp.Text,
+	}
+	if len(citations) > 0 {
+		reply["citations"] = citations
+	}
+	json.NewEncoder(w).Encode(reply)
+}
+
+// getChatSession handles GET /students/{id}/chat/{session} to return a session's history
+func getChatSession(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	chatSessionsMu.Lock()
+	defer chatSessionsMu.Unlock()
+
+	session, ok := chatSessions[mux.Vars(r)["session"]]
+	if !ok || session.StudentID != id {
+		http.Error(w, "Chat session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// deleteChatSessions removes every chat session about a deleted student
+func deleteChatSessions(studentID int) {
+	chatSessionsMu.Lock()
+	defer chatSessionsMu.Unlock()
+	for id, session := range chatSessions {
+		if session.StudentID == studentID {
+			delete(chatSessions, id)
+		}
+	}
+}
+
+// chatSystemPrompt grounds a chat session in the student's record
+func chatSystemPrompt(student Student) string {
+	return fmt.Sprintf("You are assisting a school counselor with questions about one student. "+
+		"Only use the record below and say so when the answer is not in it.\n"+
+		"Student record: Name: %s, Age: %d, Email: %s", student.Name, student.Age(), student.Email)
+}
+
+// newSessionID returns a random hex identifier
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Chat event types that can be posted to Slack or Teams
+const (
+	ChatStudentCreated = "student.created"
+	ChatImportFailed   = "import.failed"
+	ChatLLMOutage      = "llm.outage"
+	ChatDigest         = "digest"
+)
+
+// chatEventTypes lists the accepted chat event types
+var chatEventTypes = []string{ChatStudentCreated, ChatImportFailed, ChatLLMOutage, ChatDigest}
+
+// chatJobPayload struct to hold the payload of a chat job
+type chatJobPayload struct {
+	Event string `json:"event"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// startChatNotifications checks the chat settings and, when student.created is routed anywhere,
+// follows student events to post new students
+func (s *Server) startChatNotifications() error {
+	if s.config.ChatProvider == "" {
+		return nil
+	}
+	if s.config.ChatProvider != "slack" && s.config.ChatProvider != "teams" {
+		return fmt.Errorf("CHAT_PROVIDER must be slack or teams, not %q", s.config.ChatProvider)
+	}
+	for _, event := range s.config.ChatEvents {
+		if !containsString(chatEventTypes, event) {
+			return fmt.Errorf("unknown chat event %q in CHAT_EVENTS", event)
+		}
+	}
+	for event := range s.config.ChatRoutes {
+		if !containsString(chatEventTypes, event) {
+			return fmt.Errorf("unknown chat event %q in CHAT_ROUTES", event)
+		}
+	}
+	if s.chatWebhookFor(ChatStudentCreated) == "" {
+		return nil
+	}
+
+	events, _ := subscribeStudentEvents()
+	go func() {
+		for event := range events {
+			if event.Type == EventStudentCreated {
+				s.postChatEvent(ChatStudentCreated, "New student",
+					fmt.Sprintf("%s (ID %d) was added with status %s.", event.Student.Name, event.StudentID, event.Student.Status))
+			}
+		}
+	}()
+	return nil
+}
+
+// chatWebhookFor returns the incoming webhook an event is posted to: its CHAT_ROUTES entry, or
+// CHAT_WEBHOOK_URL when the event is listed in CHAT_EVENTS; "" means the event is not posted
+func (s *Server) chatWebhookFor(event string) string {
+	if s.config.ChatProvider == "" {
+		return ""
+	}
+	if url := s.config.ChatRoutes[event]; url != "" {
+		return url
+	}
+	if containsString(s.config.ChatEvents, event) {
+		return s.config.ChatWebhookURL
+	}
+	return ""
+}
+
+// postChatEvent queues a message for the event's channel, retried by the job queue with the chat
+// retry policy of the event
+func (s *Server) postChatEvent(event, title, text string) {
+	url := s.chatWebhookFor(event)
+	if url == "" {
+		return
+	}
+	payload := chatJobPayload{Event: event, URL: url, Title: title, Text: text}
+	if _, err := s.enqueueJob(context.Background(), "chat", payload, s.config.retryPolicy("chat", event).jobOptions()); err != nil {
+		log.Printf("Error queueing %s chat message: %v", event, err)
+	}
+}
+
+// chatMessage returns the webhook body for the configured provider: Slack takes mrkdwn text and
+// Teams a MessageCard
+func (s *Server) chatMessage(title, text string) interface{} {
+	if s.config.ChatProvider == "teams" {
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  title,
+			"title":    title,
+			"text":     text,
+		}
+	}
+	return map[string]string{"text": "*" + title + "*\n" + text}
+}
+
+// runChatJob posts one queued message to its incoming webhook
+func (s *Server) runChatJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var msg chatJobPayload
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(s.chatMessage(msg.Title, msg.Text))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", msg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: s.config.retryPolicy("chat", msg.Event).timeout()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s webhook returned %s", s.config.ChatProvider, resp.Status)
+	}
+	return json.Marshal("Posted " + msg.Event)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// gateProvider holds each call open until a value is sent on gate, signalling started as each
+// call begins
+type gateProvider struct {
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (p *gateProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	p.started <- struct{}{}
+	select {
+	case <-p.gate:
+		return LLMResponse{Model: req.Model, Text: "Ann is doing well."}, nil
+	case <-ctx.Done():
+		return LLMResponse{}, ctx.Err()
+	}
+}
+
+func TestChatRejectsConcurrentTurns(t *testing.T) {
+	provider := &gateProvider{started: make(chan struct{}, 1), gate: make(chan struct{})}
+	srv := newTestServer(t, provider)
+	// Runs before the server's cleanup, releasing any call a failed test left open
+	t.Cleanup(func() { close(provider.gate) })
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+
+	// turn sends a chat message in the background once the provider is ready for it
+	turn := func(body string) chan string {
+		replies := make(chan string, 1)
+		go func() {
+			status, resp := request(t, "POST", srv.URL+"/students/1/chat", body, nil)
+			if status != http.StatusOK {
+				t.Errorf("POST /students/1/chat: %d %s", status, resp)
+			}
+			replies <- resp
+		}()
+		select {
+		case <-provider.started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("chat turn never reached the provider")
+		}
+		return replies
+	}
+
+	first := turn(`{"message":"How is Ann doing?"}`)
+	provider.gate <- struct{}{}
+	var reply struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal([]byte(<-first), &reply); err != nil || reply.SessionID == "" {
+		t.Fatalf("first turn returned no session: %v", err)
+	}
+
+	second := turn(`{"session_id":"` + reply.SessionID + `","message":"Any concerns?"}`)
+	status, body := request(t, "POST", srv.URL+"/students/1/chat", `{"session_id":"`+reply.SessionID+`","message":"And grades?"}`, nil)
+	if status != http.StatusConflict {
+		t.Errorf("turn during another turn: %d %s, want 409", status, body)
+	}
+	provider.gate <- struct{}{}
+	<-second
+
+	status, body = request(t, "GET", srv.URL+"/students/1/chat/"+reply.SessionID, "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET chat session: %d %s", status, body)
+	}
+	var session ChatSession
+	if err := json.Unmarshal([]byte(body), &session); err != nil {
+		t.Fatalf("GET chat session returned %s", body)
+	}
+	// The system prompt, then a question and an answer for each of the two turns
+	if len(session.Messages) != 5 {
+		t.Errorf("session has %d messages, want 5: %+v", len(session.Messages), session.Messages)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// StudentComparison struct to hold the structured comparison returned by the model
+type StudentComparison struct {
+	Similarities   []string `json:"similarities"`
+	Differences    []string `json:"differences"`
+	MentoringNotes string   `json:"mentoring_notes"`
+}
+
+// compareStudentsInstructions describes the JSON reply expected from the model
+const compareStudentsInstructions = `
+Respond with a single JSON object and nothing else, using exactly these keys:
+{"similarities": ["<similarity>", ...], "differences": ["<difference>", ...], "mentoring_notes": "<advice for pairing these students in a mentoring assignment>"}`
+
+// compareStudents handles GET /students/compare?ids=3,7 to produce an LLM comparison of two students
+func (s *Server) compareStudents(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Query().Get("ids"), ",")
+	if len(parts) != 2 {
+		http.Error(w, "Exactly two ids are required", http.StatusBadRequest)
+		return
+	}
+	var ids [2]int
+	for i, part := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			http.Error(w, "Invalid student ID", http.StatusBadRequest)
+			return
+		}
+		ids[i] = id
+	}
+	if ids[0] == ids[1] {
+		http.Error(w, "Cannot compare a student with themselves", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	first, firstExists := s.store.Get(ids[0])
+	second, secondExists := s.store.Get(ids[1])
+	s.store.Unlock()
+	if !firstExists || !secondExists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+	for _, id := range ids {
+		if err := s.checkLLMConsent(id); err != nil {
+			s.writeLLMError(w, err)
+			return
+		}
+	}
+
+	prompt := fmt.Sprintf("Compare the following two students to help an advisor plan mentoring assignments.\n"+
+		"Student A: Name: %s, Age: %d, Email: %s\nStudent B: Name: %s, Age: %d, Email: %s\n%s",
+		first.Name, first.Age(), first.Email, second.Name, second.Age(), second.Email, compareStudentsInstructions)
+
+	resp, err := s.llm.Generate(r.Context(), LLMRequest{Model: opts.Model, Prompt: prompt, JSON: true})
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	var comparison StudentComparison
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp.Text)), &comparison); err != nil {
+		s.writeLLMError(w, fmt.Errorf("%w: %v", ErrInvalidLLMOutput, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"students":   []Student{first, second},
+		"model":      opts.Model,
+		"comparison": comparison,
+	})
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"student_api/student_api/internal/store"
+)
+
+var (
+	computedFields   = make(map[string]ComputedField)
+	computedFieldsMu sync.Mutex
+
+	// Variables a computed field expression may refer to besides custom.<field>
+	computedVariables = map[string]func(Student) interface{}{
+		"id":              func(s Student) interface{} { return float64(s.ID) },
+		"age":             func(s Student) interface{} { return float64(s.Age()) },
+		"grade_level":     func(s Student) interface{} { return float64(s.GradeLevel) },
+		"status":          func(s Student) interface{} { return s.Status },
+		"gender":          func(s Student) interface{} { return s.Gender },
+		"nationality":     func(s Student) interface{} { return s.Nationality },
+		"alumni":          func(s Student) interface{} { return s.Alumni },
+		"graduation_year": func(s Student) interface{} { return float64(s.GraduationYear) },
+		"email_verified":  func(s Student) interface{} { return s.EmailVerified },
+		"has_scholarship": func(s Student) interface{} { return hasScholarship(s.ID) },
+		"attendance_pct":  func(s Student) interface{} { return attendancePercentage(s.ID) },
+		"city": func(s Student) interface{} {
+			if s.Address == nil {
+				return ""
+			}
+			return s.Address.City
+		},
+	}
+)
+
+// ComputedField struct to hold an admin-defined field evaluated from an expression each time a
+// student is read, e.g. {"name": "is_adult", "expression": "age >= 18"}
+type ComputedField struct {
+	Name       string `json:"name"`
+	Label      string `json:"label,omitempty"`
+	Expression string `json:"expression"`
+
+	expr Expr
+}
+
+// attendancePercentage returns a student's attendance percentage over every recorded mark
+func attendancePercentage(id int) float64 {
+	attendanceMu.Lock()
+	var records []AttendanceRecord
+	for key, record := range attendance {
+		if key.StudentID == id {
+			records = append(records, record)
+		}
+	}
+	attendanceMu.Unlock()
+	return summarizeAttendance(id, records).Percentage
+}
+
+// withComputed returns a student with the values of its computed fields, as the API returns it
+func withComputed(student Student) store.WithComputed {
+	return store.WithComputed{Student: student, Computed: computedValues(student)}
+}
+
+// checkExprVariables rejects expressions referring to unknown variables or custom fields
+func checkExprVariables(expr Expr) error {
+	switch e := expr.(type) {
+	case variableExpr:
+		if name := strings.TrimPrefix(e.name, "custom."); name != e.name {
+			customFieldsMu.Lock()
+			_, defined := customFields[name]
+			customFieldsMu.Unlock()
+			if !defined {
+				return fmt.Errorf("Unknown custom field %q", name)
+			}
+		} else if computedVariables[e.name] == nil {
+			return fmt.Errorf("Unknown variable %q", e.name)
+		}
+	case unaryExpr:
+		return checkExprVariables(e.operand)
+	case binaryExpr:
+		if err := checkExprVariables(e.left); err != nil {
+			return err
+		}
+		return checkExprVariables(e.right)
+	}
+	return nil
+}
+
+// computedValues evaluates every computed field for a student; a field whose expression fails
+// evaluates to null. It returns nil when no fields are defined
+func computedValues(student Student) map[string]interface{} {
+	computedFieldsMu.Lock()
+	fields := make([]ComputedField, 0, len(computedFields))
+	for _, field := range computedFields {
+		fields = append(fields, field)
+	}
+	computedFieldsMu.Unlock()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// Variables are resolved at most once per student
+	resolved := make(map[string]interface{})
+	vars := func(name string) interface{} {
+		if value, ok := resolved[name]; ok {
+			return value
+		}
+		var value interface{}
+		if custom := strings.TrimPrefix(name, "custom."); custom != name {
+			switch v := student.Custom[custom].(type) {
+			case string, float64, bool:
+				value = v
+			}
+		} else if variable := computedVariables[name]; variable != nil {
+			value = variable(student)
+		}
+		resolved[name] = value
+		return value
+	}
+
+	values := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		value, err := field.expr.Eval(vars)
+		if err != nil {
+			value = nil
+		}
+		values[field.Name] = value
+	}
+	return values
+}
+
+// getComputedFields handles GET /admin/computed-fields to list the computed field definitions
+func getComputedFields(w http.ResponseWriter, r *http.Request) {
+	computedFieldsMu.Lock()
+	list := make([]ComputedField, 0, len(computedFields))
+	for _, field := range computedFields {
+		list = append(list, field)
+	}
+	computedFieldsMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// putComputedField handles PUT /admin/computed-fields/{name} to define or redefine a computed
+// field; the expression is parsed and its variables checked up front
+func putComputedField(w http.ResponseWriter, r *http.Request) {
+	var field ComputedField
+	if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	field.Name = mux.Vars(r)["name"]
+	if !customFieldNamePattern.MatchString(field.Name) {
+		http.Error(w, "name must be lower-case letters, digits and underscores, starting with a letter", http.StatusBadRequest)
+		return
+	}
+	expr, err := ParseExpr(field.Expression)
+	if err != nil {
+		http.Error(w, "Invalid expression: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := checkExprVariables(expr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	field.expr = expr
+
+	computedFieldsMu.Lock()
+	computedFields[field.Name] = field
+	computedFieldsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(field)
+}
+
+// deleteComputedField handles DELETE /admin/computed-fields/{name} to remove a computed field
+func deleteComputedField(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	computedFieldsMu.Lock()
+	defer computedFieldsMu.Unlock()
+
+	if _, exists := computedFields[name]; !exists {
+		http.Error(w, "Computed field not found", http.StatusNotFound)
+		return
+	}
+	delete(computedFields, name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
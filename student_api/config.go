@@ -0,0 +1,525 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config struct to hold settings read from the environment
+type Config struct {
+	DefaultModel  string
+	AllowedModels []string
+	LLMProvider   string
+	OllamaURL     string
+	OpenAIURL     string
+	OpenAIKey     string
+
+	LLMFallbackChain []string
+	MockLLMTemplate  string
+	LLMTimeout       time.Duration
+	LLMRetries       int
+	LLMBackoff       time.Duration
+
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	DegradedMode     string
+
+	LLMConcurrency int
+	LLMQueueDepth  int
+
+	PromptTemplateFile string
+	BatchWorkers       int
+	EmbeddingModel     string
+	// Text attachments are split into passages of RAGChunkWords words; summaries and chat
+	// answers are grounded in the RAGTopK passages closest to the question (0 disables this)
+	RAGChunkWords int
+	RAGTopK       int
+
+	LLMWarmup         bool
+	LLMHealthInterval time.Duration
+	// ReadyzRequireLLM makes /readyz fail while no provider of the default chain answers
+	ReadyzRequireLLM bool
+
+	// Cost per 1000 tokens, used to estimate spend in /admin/llm/usage
+	PromptTokenCost     float64
+	CompletionTokenCost float64
+
+	SummaryRefreshSchedule string
+	SummaryMaxAge          time.Duration
+
+	ModerationBlocklist []string
+	ModerationModel     string
+
+	TTSProvider string
+	TTSURL      string
+	TTSAPIKey   string
+	TTSModel    string
+	TTSVoice    string
+
+	LLMAuditFile       string
+	LLMAuditRedact     string
+	LLMAuditMaxEntries int
+
+	AdminToken string
+	// AdminInsecureDev opens the admin routes to every caller when no AdminToken is set; for
+	// local development only
+	AdminInsecureDev bool
+	SystemPrompt     string
+	SecretKey        string
+	// APIKeyRoles maps X-API-Key values to roles such as counselor or nurse
+	APIKeyRoles map[string]string
+	// APIKeyTenants maps X-API-Key values to the tenant whose LLM and SMS settings they use;
+	// other keys use the deployment defaults
+	APIKeyTenants map[string]string
+
+	IncidentViewRoles  []string
+	LLMConsentRequired bool
+	HealthRecordRoles  []string
+
+	StreamBufferChunks int
+	StreamWriteTimeout time.Duration
+
+	GradingScalesFile   string
+	DefaultGradingScale string
+
+	LowAttendanceThreshold float64
+
+	BlobStorage         string
+	BlobDir             string
+	S3Endpoint          string
+	S3Bucket            string
+	S3Region            string
+	S3AccessKey         string
+	S3SecretKey         string
+	AttachmentMaxBytes  int64
+	AttachmentTypes     []string
+	AttachmentURLExpiry time.Duration
+	PhotoMaxBytes       int64
+
+	FeesCurrency           string
+	SiblingDiscountPercent int
+
+	GraduationMinCredits    float64
+	GraduationPassScore     float64
+	GraduationRequirePaid   bool
+	CertificateTemplateFile string
+	ReportTemplateFile      string
+
+	MailProvider         string
+	MailFrom             string
+	SMTPAddr             string
+	SMTPUsername         string
+	SMTPPassword         string
+	SMTPImplicitTLS      bool
+	PublicBaseURL        string
+	EmailVerificationTTL time.Duration
+
+	// Notifications are all off by default; NotificationTemplateDir holds welcome.tmpl,
+	// advisor_change.tmpl and admin_digest.tmpl overriding the built-in templates. The admin
+	// digest is mailed to AdminDigestTo and posted to the "digest" chat channel if one is routed
+	NotifyWelcome           bool
+	NotifyAdvisors          bool
+	AdminDigestSchedule     string
+	AdminDigestTo           []string
+	NotificationTemplateDir string
+	NotificationMaxAttempts int
+
+	// SMS goes through SMSProvider ("twilio" or "http"), or is only logged without one; tenants
+	// can set their own credentials under /admin/tenants/{tenant}/sms
+	SMSProvider         string
+	SMSFrom             string
+	TwilioAccountSID    string
+	TwilioAuthToken     string
+	TwilioAPIURL        string
+	SMSHTTPURL          string
+	SMSHTTPToken        string
+	SMSAttendanceAlerts bool
+
+	// Chat messages go to Slack or Teams incoming webhooks: CHAT_EVENTS are posted to
+	// ChatWebhookURL and ChatRoutes sends an event type to its own channel's webhook
+	ChatProvider   string
+	ChatWebhookURL string
+	ChatEvents     []string
+	ChatRoutes     map[string]string
+
+	BirthdayNotificationSchedule string
+
+	Geocoder          string
+	GeocoderURL       string
+	GeocoderUserAgent string
+
+	DataQualitySchedule string
+	DuplicateThreshold  float64
+	OutlierStdDevs      float64
+
+	// GRPCAddr is where the gRPC StudentService listens, such as ":9090"; empty (the default) disables it
+	GRPCAddr string
+
+	// PublicAddr is where the public read-only directory listens; empty disables it.
+	// PublicRateLimit is requests per minute per client IP
+	PublicAddr           string
+	PublicRateLimit      int
+	PublicRequireConsent bool
+
+	// EventHistorySize is how many student events are kept for GET /students/events to resume from
+	EventHistorySize int
+
+	// WSAllowedOrigins lists the browser origins allowed to open /ws; "*" allows any
+	WSAllowedOrigins []string
+
+	// JobWorkers is how many background jobs run at once; JobsFile, when set, keeps the jobs
+	// across restarts, and finished jobs are forgotten after JobRetention
+	JobWorkers   int
+	JobsFile     string
+	JobRetention time.Duration
+
+	// ImportDir keeps the files uploaded to POST /students/import until their import job finishes;
+	// ImportMaxBytes caps one upload
+	ImportDir      string
+	ImportMaxBytes int
+
+	// SISWebhookSecret enables POST /integrations/sis/webhook; SISFieldMap maps SIS fields to
+	// student fields and SISSystem names the external ID the SIS's student IDs are stored under
+	SISWebhookSecret      string
+	SISSignatureTolerance time.Duration
+	SISSystem             string
+	SISFieldMap           map[string]string
+	SISLogSize            int
+
+	// ChangeLogFile persists the change log served by GET /changes as JSON lines
+	ChangeLogFile      string
+	ChangeLogRetention time.Duration
+
+	// Recurring tasks listed by GET /admin/schedules; an empty schedule disables the task
+	SnapshotSchedule    string
+	SnapshotDir         string
+	SnapshotKeep        int
+	RetentionSchedule   string
+	WithdrawnRetention  time.Duration
+	LLMAuditRetention   time.Duration
+	ReportEmailSchedule string
+	ReportEmailTo       []string
+
+	// RetryPolicies overrides the attempts, backoff and timeout of outbound calls per kind of
+	// destination or per destination, over the older settings such as WEBHOOK_MAX_ATTEMPTS
+	RetryPolicies map[string]RetryPolicy
+
+	// WebhookMaxAttempts bounds deliveries of one event to a webhook; the wait between attempts
+	// starts at WebhookRetryBackoff and doubles. WebhookLogSize is the deliveries kept per webhook
+	// and WebhookDeadLetterSize the events kept per webhook once all attempts failed
+	WebhookMaxAttempts    int
+	WebhookRetryBackoff   time.Duration
+	WebhookTimeout        time.Duration
+	WebhookLogSize        int
+	WebhookDeadLetterSize int
+
+	// EventBroker is where student events are published: "kafka" (through a REST Proxy at
+	// KafkaRESTURL), "nats" or empty to disable. EventFormat is "json" or "avro"
+	EventBroker  string
+	EventTopic   string
+	EventFormat  string
+	KafkaRESTURL string
+	NATSURL      string
+	// EventOutboxMax is how many events wait for the broker at most; later ones are dropped, and
+	// counted in /admin/outbox, until it catches up
+	EventOutboxMax int
+
+	LDAPURL          string
+	LDAPStartTLS     bool
+	LDAPBindDN       string
+	LDAPBindPassword string
+	LDAPBaseDN       string
+	LDAPFilter       string
+	// LDAPAttributeMap maps student fields to LDAP attributes, e.g. name=displayName,id=sAMAccountName
+	LDAPAttributeMap   map[string]string
+	LDAPImportSchedule string
+	LDAPDeleteMissing  bool
+
+	// CSVImportURL is a remote CSV file or a shared Google Sheet to import students from
+	CSVImportURL string
+	// CSVImportColumns maps student fields to column headers, e.g. name=Full Name,email=Email
+	CSVImportColumns       map[string]string
+	CSVImportKey           string
+	CSVImportConflict      string
+	CSVImportSchedule      string
+	CSVImportDeleteMissing bool
+}
+
+// loadConfig reads the service configuration from environment variables
+func loadConfig() Config {
+	cfg := Config{
+		DefaultModel: getEnv("LLM_MODEL", "llama2"),
+		LLMProvider:  getEnv("LLM_PROVIDER", "ollama"),
+		OllamaURL:    getEnv("OLLAMA_URL", "http://localhost:11411"),
+		OpenAIURL:    getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1"),
+		OpenAIKey:    os.Getenv("OPENAI_API_KEY"),
+
+		LLMFallbackChain: splitList(os.Getenv("LLM_FALLBACK_CHAIN")),
+		MockLLMTemplate:  os.Getenv("MOCK_LLM_TEMPLATE"),
+		LLMTimeout:       getEnvDuration("LLM_TIMEOUT", 60*time.Second),
+		LLMRetries:       getEnvInt("LLM_MAX_RETRIES", 2),
+		LLMBackoff:       getEnvDuration("LLM_RETRY_BACKOFF", 500*time.Millisecond),
+
+		BreakerThreshold: getEnvInt("LLM_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  getEnvDuration("LLM_BREAKER_COOLDOWN", 30*time.Second),
+		DegradedMode:     getEnv("LLM_DEGRADED_MODE", "template"),
+
+		LLMConcurrency: getEnvInt("LLM_MAX_CONCURRENCY", 1),
+		LLMQueueDepth:  getEnvInt("LLM_QUEUE_DEPTH", 10),
+
+		PromptTemplateFile: os.Getenv("PROMPT_TEMPLATE_FILE"),
+		BatchWorkers:       getEnvInt("BATCH_SUMMARY_WORKERS", 4),
+		EmbeddingModel:     getEnv("EMBEDDING_MODEL", "nomic-embed-text"),
+		RAGChunkWords:      getEnvInt("RAG_CHUNK_WORDS", 200),
+		RAGTopK:            getEnvInt("RAG_TOP_K", 3),
+
+		LLMWarmup:         getEnv("LLM_WARMUP", "false") == "true",
+		LLMHealthInterval: getEnvDuration("LLM_HEALTH_INTERVAL", 30*time.Second),
+		ReadyzRequireLLM:  getEnv("READYZ_REQUIRE_LLM", "true") == "true",
+
+		PromptTokenCost:     getEnvFloat("LLM_PROMPT_COST_PER_1K", 0),
+		CompletionTokenCost: getEnvFloat("LLM_COMPLETION_COST_PER_1K", 0),
+
+		SummaryRefreshSchedule: os.Getenv("SUMMARY_REFRESH_SCHEDULE"),
+		SummaryMaxAge:          getEnvDuration("SUMMARY_MAX_AGE", 7*24*time.Hour),
+
+		ModerationBlocklist: splitList(os.Getenv("MODERATION_BLOCKLIST")),
+		ModerationModel:     os.Getenv("MODERATION_MODEL"),
+
+		TTSProvider: os.Getenv("TTS_PROVIDER"),
+		TTSURL:      getEnv("TTS_URL", "https://api.openai.com/v1"),
+		TTSAPIKey:   os.Getenv("TTS_API_KEY"),
+		TTSModel:    getEnv("TTS_MODEL", "tts-1"),
+		TTSVoice:    getEnv("TTS_VOICE", "alloy"),
+
+		LLMAuditFile:       os.Getenv("LLM_AUDIT_FILE"),
+		LLMAuditRedact:     getEnv("LLM_AUDIT_REDACT", "pii"),
+		LLMAuditMaxEntries: getEnvInt("LLM_AUDIT_MAX_ENTRIES", 10000),
+
+		AdminToken:       os.Getenv("ADMIN_TOKEN"),
+		AdminInsecureDev: getEnv("ADMIN_INSECURE_DEV", "false") == "true",
+		SystemPrompt:     os.Getenv("LLM_SYSTEM_PROMPT"),
+		SecretKey:        os.Getenv("SECRET_KEY"),
+		APIKeyRoles:      splitPairs(os.Getenv("API_KEY_ROLES")),
+		APIKeyTenants:    splitPairs(os.Getenv("API_KEY_TENANTS")),
+
+		IncidentViewRoles:  splitList(getEnv("INCIDENT_VIEW_ROLES", "admin,counselor")),
+		LLMConsentRequired: getEnv("LLM_CONSENT_REQUIRED", "true") == "true",
+		HealthRecordRoles:  splitList(getEnv("HEALTH_RECORD_ROLES", "admin,nurse")),
+
+		StreamBufferChunks: getEnvInt("STREAM_BUFFER_CHUNKS", 16),
+		StreamWriteTimeout: getEnvDuration("STREAM_WRITE_TIMEOUT", 10*time.Second),
+
+		GradingScalesFile:   os.Getenv("GRADING_SCALES_FILE"),
+		DefaultGradingScale: getEnv("GPA_DEFAULT_SCALE", "4.0"),
+
+		LowAttendanceThreshold: getEnvFloat("ATTENDANCE_LOW_THRESHOLD", 75),
+
+		BlobStorage:         getEnv("BLOB_STORAGE", "local"),
+		BlobDir:             getEnv("BLOB_DIR", "data/blobs"),
+		S3Endpoint:          os.Getenv("S3_ENDPOINT"),
+		S3Bucket:            os.Getenv("S3_BUCKET"),
+		S3Region:            getEnv("S3_REGION", "us-east-1"),
+		S3AccessKey:         os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:         os.Getenv("S3_SECRET_KEY"),
+		AttachmentMaxBytes:  int64(getEnvInt("ATTACHMENT_MAX_BYTES", 10<<20)),
+		AttachmentTypes:     splitList(getEnv("ATTACHMENT_TYPES", "application/pdf,image/jpeg,image/png,text/plain")),
+		AttachmentURLExpiry: getEnvDuration("ATTACHMENT_URL_EXPIRY", 15*time.Minute),
+		PhotoMaxBytes:       int64(getEnvInt("PHOTO_MAX_BYTES", 5<<20)),
+
+		FeesCurrency:           strings.ToUpper(getEnv("FEES_CURRENCY", "USD")),
+		SiblingDiscountPercent: getEnvInt("SIBLING_DISCOUNT_PERCENT", 0),
+
+		GraduationMinCredits:    getEnvFloat("GRADUATION_MIN_CREDITS", 120),
+		GraduationPassScore:     getEnvFloat("GRADUATION_PASS_SCORE", 60),
+		GraduationRequirePaid:   getEnv("GRADUATION_REQUIRE_PAID", "true") == "true",
+		CertificateTemplateFile: os.Getenv("CERTIFICATE_TEMPLATE_FILE"),
+		ReportTemplateFile:      os.Getenv("REPORT_TEMPLATE_FILE"),
+
+		MailProvider:         os.Getenv("MAIL_PROVIDER"),
+		MailFrom:             getEnv("MAIL_FROM", "no-reply@localhost"),
+		SMTPAddr:             getEnv("SMTP_ADDR", "localhost:25"),
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		SMTPImplicitTLS:      getEnv("SMTP_TLS", "false") == "true",
+		PublicBaseURL:        getEnv("PUBLIC_BASE_URL", "http://localhost:8081"),
+		EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 72*time.Hour),
+
+		NotifyWelcome:           getEnv("NOTIFY_WELCOME", "false") == "true",
+		NotifyAdvisors:          getEnv("NOTIFY_ADVISORS", "false") == "true",
+		AdminDigestSchedule:     os.Getenv("ADMIN_DIGEST_SCHEDULE"),
+		AdminDigestTo:           splitList(os.Getenv("ADMIN_DIGEST_TO")),
+		NotificationTemplateDir: os.Getenv("NOTIFICATION_TEMPLATE_DIR"),
+		NotificationMaxAttempts: getEnvInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+
+		SMSProvider:         os.Getenv("SMS_PROVIDER"),
+		SMSFrom:             os.Getenv("SMS_FROM"),
+		TwilioAccountSID:    os.Getenv("TWILIO_ACCOUNT_SID"),
+		TwilioAuthToken:     os.Getenv("TWILIO_AUTH_TOKEN"),
+		TwilioAPIURL:        getEnv("TWILIO_API_URL", "https://api.twilio.com"),
+		SMSHTTPURL:          os.Getenv("SMS_HTTP_URL"),
+		SMSHTTPToken:        os.Getenv("SMS_HTTP_TOKEN"),
+		SMSAttendanceAlerts: getEnv("SMS_ATTENDANCE_ALERTS", "false") == "true",
+
+		ChatProvider:   os.Getenv("CHAT_PROVIDER"),
+		ChatWebhookURL: os.Getenv("CHAT_WEBHOOK_URL"),
+		ChatEvents:     splitList(getEnv("CHAT_EVENTS", "student.created,import.failed,llm.outage")),
+		ChatRoutes:     splitPairs(os.Getenv("CHAT_ROUTES")),
+
+		BirthdayNotificationSchedule: os.Getenv("BIRTHDAY_NOTIFICATION_SCHEDULE"),
+
+		Geocoder:          os.Getenv("GEOCODER"),
+		GeocoderURL:       getEnv("GEOCODER_URL", "https://nominatim.openstreetmap.org"),
+		GeocoderUserAgent: getEnv("GEOCODER_USER_AGENT", "student_api"),
+
+		DataQualitySchedule: os.Getenv("DATA_QUALITY_SCHEDULE"),
+		DuplicateThreshold:  getEnvFloat("DUPLICATE_SIMILARITY_THRESHOLD", 0.95),
+		OutlierStdDevs:      getEnvFloat("OUTLIER_STDDEVS", 2),
+
+		GRPCAddr: os.Getenv("GRPC_ADDR"),
+
+		PublicAddr:           os.Getenv("PUBLIC_ADDR"),
+		PublicRateLimit:      getEnvInt("PUBLIC_RATE_LIMIT", 60),
+		PublicRequireConsent: getEnv("PUBLIC_REQUIRE_CONSENT", "true") == "true",
+
+		EventHistorySize: getEnvInt("EVENT_HISTORY_SIZE", 1000),
+		WSAllowedOrigins: splitList(os.Getenv("WS_ALLOWED_ORIGINS")),
+
+		JobWorkers:   getEnvInt("JOB_WORKERS", 8),
+		JobsFile:     os.Getenv("JOBS_FILE"),
+		JobRetention: getEnvDuration("JOB_RETENTION", 7*24*time.Hour),
+
+		ImportDir:      getEnv("IMPORT_DIR", "data/imports"),
+		ImportMaxBytes: getEnvInt("IMPORT_MAX_BYTES", 100<<20),
+
+		SISWebhookSecret:      os.Getenv("SIS_WEBHOOK_SECRET"),
+		SISSignatureTolerance: getEnvDuration("SIS_SIGNATURE_TOLERANCE", 5*time.Minute),
+		SISSystem:             getEnv("SIS_SYSTEM", "sis"),
+		SISFieldMap:           splitPairs(getEnv("SIS_FIELD_MAP", "name=name,email=email,phone=phone,date_of_birth=date_of_birth,gender=gender,nationality=nationality,grade_level=grade_level,status=status")),
+		SISLogSize:            getEnvInt("SIS_LOG_SIZE", 10000),
+
+		ChangeLogFile:      os.Getenv("CHANGE_LOG_FILE"),
+		ChangeLogRetention: getEnvDuration("CHANGE_LOG_RETENTION", 0),
+
+		SnapshotSchedule:    os.Getenv("SNAPSHOT_SCHEDULE"),
+		SnapshotDir:         getEnv("SNAPSHOT_DIR", "snapshots"),
+		SnapshotKeep:        getEnvInt("SNAPSHOT_KEEP", 7),
+		RetentionSchedule:   getEnv("RETENTION_SCHEDULE", "@hourly"),
+		WithdrawnRetention:  getEnvDuration("WITHDRAWN_RETENTION", 0),
+		LLMAuditRetention:   getEnvDuration("LLM_AUDIT_RETENTION", 0),
+		ReportEmailSchedule: os.Getenv("REPORT_EMAIL_SCHEDULE"),
+		ReportEmailTo:       splitList(os.Getenv("REPORT_EMAIL_TO")),
+
+		RetryPolicies: parseRetryPolicies(os.Getenv("RETRY_POLICIES")),
+
+		WebhookMaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 6),
+		WebhookRetryBackoff:   getEnvDuration("WEBHOOK_RETRY_BACKOFF", 5*time.Second),
+		WebhookTimeout:        getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookLogSize:        getEnvInt("WEBHOOK_LOG_SIZE", 100),
+		WebhookDeadLetterSize: getEnvInt("WEBHOOK_DEAD_LETTER_SIZE", 1000),
+
+		EventBroker:  os.Getenv("EVENT_BROKER"),
+		EventTopic:   getEnv("EVENT_TOPIC", "students.events"),
+		EventFormat:  getEnv("EVENT_FORMAT", "json"),
+		KafkaRESTURL: os.Getenv("KAFKA_REST_URL"),
+		NATSURL:      getEnv("NATS_URL", "nats://127.0.0.1:4222"),
+
+		EventOutboxMax: getEnvInt("EVENT_OUTBOX_MAX", 10000),
+
+		LDAPURL:            os.Getenv("LDAP_URL"),
+		LDAPStartTLS:       getEnv("LDAP_START_TLS", "false") == "true",
+		LDAPBindDN:         os.Getenv("LDAP_BIND_DN"),
+		LDAPBindPassword:   os.Getenv("LDAP_BIND_PASSWORD"),
+		LDAPBaseDN:         os.Getenv("LDAP_BASE_DN"),
+		LDAPFilter:         getEnv("LDAP_FILTER", "(objectClass=person)"),
+		LDAPAttributeMap:   splitPairs(getEnv("LDAP_ATTRIBUTE_MAP", "id=uid,name=cn,email=mail,phone=telephoneNumber")),
+		LDAPImportSchedule: os.Getenv("LDAP_IMPORT_SCHEDULE"),
+		LDAPDeleteMissing:  getEnv("LDAP_DELETE_MISSING", "false") == "true",
+
+		CSVImportURL:           os.Getenv("CSV_IMPORT_URL"),
+		CSVImportColumns:       splitPairs(os.Getenv("CSV_IMPORT_COLUMNS")),
+		CSVImportKey:           strings.ToLower(getEnv("CSV_IMPORT_KEY", "email")),
+		CSVImportConflict:      getEnv("CSV_IMPORT_CONFLICT", "overwrite"),
+		CSVImportSchedule:      os.Getenv("CSV_IMPORT_SCHEDULE"),
+		CSVImportDeleteMissing: getEnv("CSV_IMPORT_DELETE_MISSING", "false") == "true",
+	}
+	cfg.AllowedModels = splitList(getEnv("LLM_ALLOWED_MODELS", cfg.DefaultModel))
+	if !containsString(cfg.AllowedModels, cfg.DefaultModel) {
+		cfg.AllowedModels = append(cfg.AllowedModels, cfg.DefaultModel)
+	}
+	// Pools with no workers would never drain their queues
+	cfg.BatchWorkers = max(cfg.BatchWorkers, 1)
+	cfg.JobWorkers = max(cfg.JobWorkers, 1)
+	// Attachments cannot be split into passages of fewer than one word
+	cfg.RAGChunkWords = max(cfg.RAGChunkWords, 1)
+	return cfg
+}
+
+// getEnv returns the value of an environment variable or a fallback when unset
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt returns an integer environment variable or a fallback when unset or invalid
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvFloat returns a floating point environment variable or a fallback when unset or invalid
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvDuration returns a duration environment variable (e.g. "30s") or a fallback when unset or invalid
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs parses a comma-separated list of key=value pairs, dropping malformed entries
+func splitPairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range splitList(value) {
+		if key, val, ok := strings.Cut(item, "="); ok && strings.TrimSpace(key) != "" {
+			pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return pairs
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
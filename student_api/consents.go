@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ErrNoLLMConsent is returned when a student's data would be sent to the LLM without their consent
+var ErrNoLLMConsent = errors.New("student has not consented to LLM processing")
+
+// Consent types
+const (
+	ConsentPhoto         = "photo"
+	ConsentDataSharing   = "data_sharing"
+	ConsentLLMProcessing = "llm_processing"
+)
+
+// consentsMu guards consentRecords and consentTexts; records are append-only so the history of
+// every grant and withdrawal is kept
+var (
+	consentRecords = make(map[int][]ConsentRecord)
+	consentTexts   = make(map[string][]ConsentText)
+	consentsMu     sync.Mutex
+
+	consentTypes = map[string]bool{ConsentPhoto: true, ConsentDataSharing: true, ConsentLLMProcessing: true}
+)
+
+// ConsentText struct to hold one version of the wording a consent is given against
+type ConsentText struct {
+	Type      string    `json:"type"`
+	Version   string    `json:"version"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConsentRecord struct to hold a student's grant or withdrawal of one consent
+type ConsentRecord struct {
+	StudentID   int       `json:"student_id"`
+	Type        string    `json:"type"`
+	Granted     bool      `json:"granted"`
+	TextVersion string    `json:"text_version,omitempty"`
+	GivenBy     string    `json:"given_by,omitempty"`
+	RecordedBy  string    `json:"recorded_by"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// currentConsent returns the latest record of a consent type for a student; the caller must
+// hold consentsMu
+func currentConsent(id int, consentType string) (ConsentRecord, bool) {
+	records := consentRecords[id]
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Type == consentType {
+			return records[i], true
+		}
+	}
+	return ConsentRecord{}, false
+}
+
+// hasConsent reports whether a student's latest record of a consent type grants it
+func hasConsent(id int, consentType string) bool {
+	consentsMu.Lock()
+	defer consentsMu.Unlock()
+	record, ok := currentConsent(id, consentType)
+	return ok && record.Granted
+}
+
+// checkLLMConsent returns ErrNoLLMConsent when LLM_CONSENT_REQUIRED is set and the student has
+// not granted llm_processing
+func (s *Server) checkLLMConsent(id int) error {
+	if s.config.LLMConsentRequired && !hasConsent(id, ConsentLLMProcessing) {
+		return ErrNoLLMConsent
+	}
+	return nil
+}
+
+// createConsent handles POST /students/{id}/consents to record a grant or withdrawal; the text
+// version defaults to the latest published wording of that consent type
+func (s *Server) createConsent(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var record ConsentRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	record.Type = strings.ToLower(strings.TrimSpace(record.Type))
+	if !consentTypes[record.Type] {
+		http.Error(w, "type must be photo, data_sharing or llm_processing", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	consentsMu.Lock()
+	texts := consentTexts[record.Type]
+	if record.TextVersion == "" && len(texts) > 0 {
+		record.TextVersion = texts[len(texts)-1].Version
+	}
+	known := len(texts) == 0
+	for _, text := range texts {
+		known = known || text.Version == record.TextVersion
+	}
+	if !known {
+		consentsMu.Unlock()
+		http.Error(w, "Unknown consent text version", http.StatusBadRequest)
+		return
+	}
+	record.StudentID = id
+	record.GivenBy = strings.TrimSpace(record.GivenBy)
+	record.RecordedBy = callerID(r.Context())
+	record.RecordedAt = time.Now()
+	consentRecords[id] = append(consentRecords[id], record)
+	consentsMu.Unlock()
+	invalidateSummary(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(record)
+}
+
+// getConsents handles GET /students/{id}/consents to return the current state of each consent;
+// ?history=true lists every recorded change instead
+func (s *Server) getConsents(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	consentsMu.Lock()
+	list := []ConsentRecord{}
+	if r.URL.Query().Get("history") == "true" {
+		list = append(list, consentRecords[id]...)
+	} else {
+		for consentType := range consentTypes {
+			if record, ok := currentConsent(id, consentType); ok {
+				list = append(list, record)
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	}
+	consentsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getConsentTexts handles GET /admin/consent-texts/{type} to list the published versions of a
+// consent's wording, oldest first
+func getConsentTexts(w http.ResponseWriter, r *http.Request) {
+	consentType := mux.Vars(r)["type"]
+	if !consentTypes[consentType] {
+		http.Error(w, "Unknown consent type", http.StatusNotFound)
+		return
+	}
+
+	consentsMu.Lock()
+	list := append([]ConsentText{}, consentTexts[consentType]...)
+	consentsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// createConsentText handles POST /admin/consent-texts/{type} to publish a new version of a
+// consent's wording; versions cannot be changed once published
+func createConsentText(w http.ResponseWriter, r *http.Request) {
+	consentType := mux.Vars(r)["type"]
+	if !consentTypes[consentType] {
+		http.Error(w, "Unknown consent type", http.StatusNotFound)
+		return
+	}
+
+	var text ConsentText
+	if err := json.NewDecoder(r.Body).Decode(&text); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	text.Version = strings.TrimSpace(text.Version)
+	if text.Version == "" || strings.TrimSpace(text.Text) == "" {
+		http.Error(w, "version and text are required", http.StatusBadRequest)
+		return
+	}
+
+	consentsMu.Lock()
+	defer consentsMu.Unlock()
+	for _, existing := range consentTexts[consentType] {
+		if existing.Version == text.Version {
+			http.Error(w, "This version already exists", http.StatusConflict)
+			return
+		}
+	}
+	text.Type = consentType
+	text.CreatedAt = time.Now()
+	consentTexts[consentType] = append(consentTexts[consentType], text)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(text)
+}
+
+// deleteConsents drops a deleted student's consent records
+func deleteConsents(id int) {
+	consentsMu.Lock()
+	defer consentsMu.Unlock()
+	delete(consentRecords, id)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	contacts      = make(map[int][]Contact)
+	contactsMu    sync.Mutex
+	nextContactID int
+
+	// Accepted values for Contact.Relationship
+	contactRelationships = map[string]bool{
+		"mother": true, "father": true, "guardian": true, "grandparent": true,
+		"sibling": true, "spouse": true, "relative": true, "other": true,
+	}
+)
+
+// Contact struct to hold a guardian or emergency contact of a student
+type Contact struct {
+	ID           int    `json:"id"`
+	StudentID    int    `json:"student_id"`
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone,omitempty"`
+	Email        string `json:"email,omitempty"`
+	Primary      bool   `json:"primary"`
+}
+
+// validateContact checks a contact submitted by a client
+func validateContact(contact Contact) error {
+	if strings.TrimSpace(contact.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !contactRelationships[contact.Relationship] {
+		return errors.New("relationship must be one of mother, father, guardian, grandparent, sibling, spouse, relative or other")
+	}
+	if contact.Phone == "" && contact.Email == "" {
+		return errors.New("A phone number or email is required")
+	}
+	if contact.Phone != "" && !phoneNumberPattern.MatchString(contact.Phone) {
+		return errors.New("Invalid phone number")
+	}
+	if contact.Email != "" && emailPattern.FindString(contact.Email) != contact.Email {
+		return errors.New("Invalid email")
+	}
+	return nil
+}
+
+// createContact handles POST /students/{id}/contacts to add a contact; the first contact, or one
+// sent with "primary": true, becomes the student's primary contact
+func (s *Server) createContact(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var contact Contact
+	if err := json.NewDecoder(r.Body).Decode(&contact); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	contact.Relationship = strings.ToLower(strings.TrimSpace(contact.Relationship))
+	contact.Phone = strings.TrimSpace(contact.Phone)
+	contact.Email = strings.TrimSpace(contact.Email)
+	if err := validateContact(contact); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if _, exists := s.store.Get(id); !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	contactsMu.Lock()
+	defer contactsMu.Unlock()
+
+	list := contacts[id]
+	if len(list) == 0 {
+		contact.Primary = true
+	}
+	if contact.Primary {
+		for i := range list {
+			list[i].Primary = false
+		}
+	}
+	nextContactID++
+	contact.ID = nextContactID
+	contact.StudentID = id
+	contacts[id] = append(list, contact)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contact)
+}
+
+// getContacts handles GET /students/{id}/contacts to list a student's contacts, primary first
+func (s *Server) getContacts(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	contactsMu.Lock()
+	list := append([]Contact{}, contacts[id]...)
+	contactsMu.Unlock()
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Primary && !list[j].Primary })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// deleteContact handles DELETE /students/{id}/contacts/{contact} to remove a contact; when the
+// primary contact is removed the oldest remaining contact becomes primary
+func deleteContact(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	contactID, err := strconv.Atoi(mux.Vars(r)["contact"])
+	if err != nil {
+		http.Error(w, "Invalid contact ID", http.StatusBadRequest)
+		return
+	}
+
+	contactsMu.Lock()
+	defer contactsMu.Unlock()
+
+	list := contacts[id]
+	for i, contact := range list {
+		if contact.ID != contactID {
+			continue
+		}
+		list = append(list[:i], list[i+1:]...)
+		if contact.Primary && len(list) > 0 {
+			list[0].Primary = true
+		}
+		contacts[id] = list
+		forgetNotifyPrefs(id, contactID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	http.Error(w, "Contact not found", http.StatusNotFound)
+}
+
+// deleteContacts drops a deleted student's contacts
+func deleteContacts(id int) {
+	contactsMu.Lock()
+	defer contactsMu.Unlock()
+	delete(contacts, id)
+}
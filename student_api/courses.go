@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// coursesMu guards courses, enrollments and waitlists; when both are needed, take the student store before coursesMu
+var (
+	courses      = make(map[int]Course)
+	enrollments  = make(map[int]map[int]Enrollment)
+	coursesMu    sync.Mutex
+	nextCourseID int
+)
+
+// Course struct to hold course data; Capacity 0 means unlimited
+type Course struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Credits are the credit hours used to weight the course in GPA calculations
+	Credits  float64 `json:"credits"`
+	Capacity int     `json:"capacity"`
+	Enrolled int     `json:"enrolled"`
+	// Meetings are the weekly class times checked for clashes on enrollment
+	Meetings []Meeting `json:"meetings,omitempty"`
+}
+
+// Enrollment struct to hold a student's enrollment in a course
+type Enrollment struct {
+	StudentID  int       `json:"student_id"`
+	CourseID   int       `json:"course_id"`
+	Term       string    `json:"term,omitempty"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+// validateCourse checks the fields a client may set on a course
+func validateCourse(course Course) error {
+	if strings.TrimSpace(course.Code) == "" || strings.TrimSpace(course.Title) == "" {
+		return errors.New("Invalid course data")
+	}
+	if course.Capacity < 0 {
+		return errors.New("capacity must not be negative")
+	}
+	if course.Credits < 0 {
+		return errors.New("credits must not be negative")
+	}
+	return validateMeetings(course.Meetings)
+}
+
+// withEnrolled sets the course's enrollment count; the caller must hold coursesMu
+func withEnrolled(course Course) Course {
+	course.Enrolled = len(enrollments[course.ID])
+	return course
+}
+
+// courseIDFromRequest parses the {id} route variable of a course route
+func courseIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// createCourse handles POST /courses to create a new course
+func createCourse(w http.ResponseWriter, r *http.Request) {
+	var course Course
+	if err := json.NewDecoder(r.Body).Decode(&course); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	course.Code = strings.ToUpper(strings.TrimSpace(course.Code))
+	normalizeMeetings(course.Meetings)
+	if err := validateCourse(course); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+	for _, existing := range courses {
+		if existing.Code == course.Code {
+			http.Error(w, "A course with this code already exists", http.StatusConflict)
+			return
+		}
+	}
+	nextCourseID++
+	course.ID = nextCourseID
+	course.Enrolled = 0
+	courses[course.ID] = course
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(course)
+}
+
+// getAllCourses handles GET /courses to fetch all courses
+func getAllCourses(w http.ResponseWriter, r *http.Request) {
+	coursesMu.Lock()
+	all := []Course{}
+	for _, course := range courses {
+		all = append(all, withEnrolled(course))
+	}
+	coursesMu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(all)
+}
+
+// getCourseByID handles GET /courses/{id} to fetch a course by ID
+func getCourseByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := courseIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+
+	course, exists := courses[id]
+	if !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withEnrolled(course))
+}
+
+// updateCourse handles PUT /courses/{id} to update a course; capacity cannot drop below the
+// number of students already enrolled, and added seats are filled from the waitlist
+func (s *Server) updateCourse(w http.ResponseWriter, r *http.Request) {
+	id, ok := courseIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var updated Course
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+
+	course, exists := courses[id]
+	if !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	// Update fields
+	if updated.Code != "" {
+		course.Code = strings.ToUpper(strings.TrimSpace(updated.Code))
+	}
+	if updated.Title != "" {
+		course.Title = updated.Title
+	}
+	if updated.Description != "" {
+		course.Description = updated.Description
+	}
+	if updated.Credits != 0 {
+		course.Credits = updated.Credits
+	}
+	if updated.Capacity != 0 {
+		course.Capacity = updated.Capacity
+	}
+	if updated.Meetings != nil {
+		normalizeMeetings(updated.Meetings)
+		course.Meetings = updated.Meetings
+	}
+	if err := validateCourse(course); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, existing := range courses {
+		if existing.ID != id && existing.Code == course.Code {
+			http.Error(w, "A course with this code already exists", http.StatusConflict)
+			return
+		}
+	}
+	if course.Capacity > 0 && len(enrollments[id]) > course.Capacity {
+		http.Error(w, "Capacity is below the number of enrolled students", http.StatusConflict)
+		return
+	}
+	courses[id] = course
+	s.promoteWaitlist(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withEnrolled(course))
+}
+
+// deleteCourse handles DELETE /courses/{id} to delete a course with no enrolled students
+func deleteCourse(w http.ResponseWriter, r *http.Request) {
+	id, ok := courseIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+
+	if _, exists := courses[id]; !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if len(enrollments[id]) > 0 {
+		http.Error(w, "Course still has enrolled students", http.StatusConflict)
+		return
+	}
+	gradesMu.Lock()
+	graded := courseHasGrades(id)
+	gradesMu.Unlock()
+	if graded {
+		http.Error(w, "Course has recorded grades", http.StatusConflict)
+		return
+	}
+
+	delete(courses, id)
+	delete(enrollments, id)
+	delete(waitlists, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getCourseStudents handles GET /courses/{id}/students to list the students enrolled in a course
+func (s *Server) getCourseStudents(w http.ResponseWriter, r *http.Request) {
+	id, ok := courseIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	s.store.Lock()
+	coursesMu.Lock()
+	_, exists := courses[id]
+	enrolled := []Student{}
+	for studentID := range enrollments[id] {
+		student, _ := s.store.Get(studentID)
+		enrolled = append(enrolled, student)
+	}
+	coursesMu.Unlock()
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	sort.Slice(enrolled, func(i, j int) bool { return enrolled[i].ID < enrolled[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrolled)
+}
+
+// createEnrollment handles POST /students/{id}/enrollments to enroll a student in a course,
+// rejecting duplicate enrollments and timetable clashes with 409; the term defaults to the
+// current term. When the course is full the student joins its waitlist and 202 is returned
+func (s *Server) createEnrollment(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var req struct {
+		CourseID int    `json:"course_id"`
+		Term     string `json:"term"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CourseID <= 0 {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	term, err := resolveTerm(strings.TrimSpace(req.Term))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if _, exists := s.store.Get(id); !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+
+	course, exists := courses[req.CourseID]
+	if !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if _, enrolled := enrollments[course.ID][id]; enrolled {
+		http.Error(w, "Student is already enrolled in this course", http.StatusConflict)
+		return
+	}
+	if other, clash := scheduleClash(id, course); clash {
+		http.Error(w, "Course clashes with "+other.Code+" in the student's schedule", http.StatusConflict)
+		return
+	}
+	if course.Capacity > 0 && len(enrollments[course.ID]) >= course.Capacity {
+		if waitlistPosition(course.ID, id) != 0 {
+			http.Error(w, "Student is already on the waitlist for this course", http.StatusConflict)
+			return
+		}
+		entry := addToWaitlist(WaitlistEntry{StudentID: id, CourseID: course.ID, Term: term, AddedAt: time.Now()})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(entry)
+		return
+	}
+
+	enrollment := Enrollment{StudentID: id, CourseID: course.ID, Term: term, EnrolledAt: time.Now()}
+	if enrollments[course.ID] == nil {
+		enrollments[course.ID] = make(map[int]Enrollment)
+	}
+	enrollments[course.ID][id] = enrollment
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// getStudentEnrollments handles GET /students/{id}/enrollments?term= to list a student's enrollments
+func (s *Server) getStudentEnrollments(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	term := r.URL.Query().Get("term")
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	coursesMu.Lock()
+	list := []Enrollment{}
+	for _, enrollment := range studentEnrollments(id) {
+		if term == "" || enrollment.Term == term {
+			list = append(list, enrollment)
+		}
+	}
+	coursesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// deleteEnrollment handles DELETE /students/{id}/enrollments/{course} to withdraw a student from a
+// course, giving the freed seat to the waitlist
+func (s *Server) deleteEnrollment(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	courseID, err := strconv.Atoi(mux.Vars(r)["course"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+
+	if _, enrolled := enrollments[courseID][id]; !enrolled {
+		http.Error(w, "Enrollment not found", http.StatusNotFound)
+		return
+	}
+	delete(enrollments[courseID], id)
+	s.promoteWaitlist(courseID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// studentEnrollments returns a student's enrollments ordered by course; the caller must hold coursesMu
+func studentEnrollments(id int) []Enrollment {
+	list := []Enrollment{}
+	for _, enrolled := range enrollments {
+		if enrollment, ok := enrolled[id]; ok {
+			list = append(list, enrollment)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CourseID < list[j].CourseID })
+	return list
+}
+
+// deleteEnrollments withdraws a deleted student from every course and waitlist
+func (s *Server) deleteEnrollments(id int) {
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+	for courseID, list := range waitlists {
+		if position := waitlistPosition(courseID, id); position != 0 {
+			waitlists[courseID] = append(list[:position-1], list[position:]...)
+		}
+	}
+	for courseID, enrolled := range enrollments {
+		if _, ok := enrolled[id]; ok {
+			delete(enrolled, id)
+			s.promoteWaitlist(courseID)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// cronMacros maps the supported shorthand schedules to their five-field form
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// CronSchedule is a parsed five-field cron expression (minute hour day-of-month month day-of-week)
+type CronSchedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// ParseCron parses a standard five-field cron expression supporting *, lists, ranges and steps
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	fieldsExpr := expr
+	if macro, ok := cronMacros[expr]; ok {
+		fieldsExpr = macro
+	}
+
+	fields := strings.Fields(fieldsExpr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	schedule := &CronSchedule{expr: expr}
+	bounds := []struct {
+		target   *uint64
+		min, max int
+	}{
+		{&schedule.minute, 0, 59},
+		{&schedule.hour, 0, 23},
+		{&schedule.dom, 1, 31},
+		{&schedule.month, 1, 12},
+		{&schedule.dow, 0, 7},
+	}
+	for i, field := range fields {
+		bits, err := parseCronField(field, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %v", expr, err)
+		}
+		*bounds[i].target = bits
+	}
+
+	// Sunday may be written as 0 or 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domRestricted = fields[2] != "*"
+	schedule.dowRestricted = fields[4] != "*"
+	return schedule, nil
+}
+
+// parseCronField returns a bitmask of the values selected by one cron field
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value out of range in %q", part)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Matches reports whether the schedule fires during the minute containing t
+func (c *CronSchedule) Matches(t time.Time) bool {
+	if c.minute&(1<<uint(t.Minute())) == 0 || c.hour&(1<<uint(t.Hour())) == 0 || c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	// As in classic cron, when both day fields are restricted either one may match
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Next returns the first minute after t at which the schedule fires, or the zero time if none
+// occurs within five years
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	for limit := next.AddDate(5, 0, 0); next.Before(limit); next = next.Add(time.Minute) {
+		if c.Matches(next) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// String returns the original expression
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+// Outcomes of a scheduled task run
+const (
+	TaskRunning = "running"
+	TaskOK      = "ok"
+	TaskFailed  = "failed"
+)
+
+// scheduledTasksMu guards scheduledTasks and the run state of each task
+var (
+	scheduledTasks   = make(map[string]*ScheduledTask)
+	scheduledTasksMu sync.Mutex
+)
+
+// ScheduledTask struct to hold a recurring task and the outcome of its last run
+type ScheduledTask struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastTrigger    string     `json:"last_trigger,omitempty"`
+	LastStatus     string     `json:"last_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastDurationMS int64      `json:"last_duration_ms"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+
+	fn func() error
+}
+
+// runOnSchedule registers a task and calls fn in the background every time schedule fires; a run
+// is skipped while the previous one is still going
+func runOnSchedule(name string, schedule *CronSchedule, fn func() error) {
+	task := &ScheduledTask{Name: name, Schedule: schedule.String(), fn: fn}
+	scheduledTasksMu.Lock()
+	scheduledTasks[name] = task
+	scheduledTasksMu.Unlock()
+
+	go func() {
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				log.Printf("Schedule %s (%s) never fires, stopping", name, schedule)
+				return
+			}
+			scheduledTasksMu.Lock()
+			task.NextRunAt = &next
+			scheduledTasksMu.Unlock()
+
+			time.Sleep(time.Until(next))
+			if !runScheduledTask(task, "schedule") {
+				log.Printf("Skipping scheduled task %s, the previous run has not finished", name)
+			}
+		}
+	}()
+}
+
+// runScheduledTask runs a task now and records the outcome, returning false without running it
+// when it is already running
+func runScheduledTask(task *ScheduledTask, trigger string) bool {
+	scheduledTasksMu.Lock()
+	if task.LastStatus == TaskRunning {
+		scheduledTasksMu.Unlock()
+		return false
+	}
+	started := time.Now()
+	task.LastRunAt, task.LastTrigger, task.LastStatus, task.LastError = &started, trigger, TaskRunning, ""
+	scheduledTasksMu.Unlock()
+
+	log.Printf("Running scheduled task %s", task.Name)
+	err := task.fn()
+
+	scheduledTasksMu.Lock()
+	defer scheduledTasksMu.Unlock()
+	task.Runs++
+	task.LastDurationMS = time.Since(started).Milliseconds()
+	task.LastStatus = TaskOK
+	if err != nil {
+		task.Failures++
+		task.LastStatus, task.LastError = TaskFailed, err.Error()
+		log.Printf("Scheduled task %s failed: %v", task.Name, err)
+	}
+	return true
+}
+
+// getScheduledTasks handles GET /admin/schedules to list the configured recurring tasks with the
+// outcome of their last run
+func getScheduledTasks(w http.ResponseWriter, r *http.Request) {
+	scheduledTasksMu.Lock()
+	list := make([]ScheduledTask, 0, len(scheduledTasks))
+	for _, task := range scheduledTasks {
+		list = append(list, *task)
+	}
+	scheduledTasksMu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// runScheduledTaskNow handles POST /admin/schedules/{name}/run to start a recurring task outside
+// its schedule; the run continues in the background and its outcome shows in GET /admin/schedules
+func runScheduledTaskNow(w http.ResponseWriter, r *http.Request) {
+	scheduledTasksMu.Lock()
+	task, exists := scheduledTasks[mux.Vars(r)["name"]]
+	running := exists && task.LastStatus == TaskRunning
+	scheduledTasksMu.Unlock()
+	if !exists {
+		http.Error(w, "Scheduled task not found", http.StatusNotFound)
+		return
+	}
+	if running {
+		http.Error(w, "The task is already running", http.StatusConflict)
+		return
+	}
+
+	go runScheduledTask(task, "admin")
+	w.WriteHeader(http.StatusAccepted)
+}
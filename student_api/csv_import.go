@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	csvImportReport *CSVImportReport
+	csvImportMu     sync.Mutex
+
+	// csvImportRunning is held for the duration of an import so runs never overlap
+	csvImportRunning sync.Mutex
+
+	// Accepted values for CSV_IMPORT_CONFLICT: overwrite updates existing students from the sheet,
+	// keep only creates new students and fill only sets fields that are empty in the store
+	csvConflictRules = map[string]bool{"overwrite": true, "keep": true, "fill": true}
+
+	googleSheetPattern = regexp.MustCompile(`^https://docs\.google\.com/spreadsheets/d/([\w-]+)`)
+	sheetGIDPattern    = regexp.MustCompile(`gid=(\d+)`)
+)
+
+// csvImportMaxBytes caps the size of a downloaded sheet
+const csvImportMaxBytes = 20 << 20
+
+// CSVImportReport struct to hold the outcome of a CSV import; Skipped lists the rows that could
+// not be mapped to a student, numbered from the first data row
+type CSVImportReport struct {
+	Trigger    string          `json:"trigger"`
+	Source     string          `json:"source"`
+	Conflict   string          `json:"conflict"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Rows       int             `json:"rows"`
+	Skipped    []CSVSkippedRow `json:"skipped"`
+	Result     *SyncResult     `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// CSVSkippedRow struct to hold a row left out of an import and why
+type CSVSkippedRow struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// checkCSVImportConfig validates the CSV import settings
+func (s *Server) checkCSVImportConfig() error {
+	if s.config.CSVImportURL == "" {
+		return errors.New("CSV_IMPORT_URL must be set")
+	}
+	if !csvConflictRules[s.config.CSVImportConflict] {
+		return errors.New("CSV_IMPORT_CONFLICT must be overwrite, keep or fill")
+	}
+	if system, ok := strings.CutPrefix(s.config.CSVImportKey, "external:"); s.config.CSVImportKey != "email" && (!ok || system == "") {
+		return errors.New("CSV_IMPORT_KEY must be email or external:<system>")
+	}
+	return nil
+}
+
+// startCSVImports schedules the CSV import when CSV_IMPORT_SCHEDULE is set
+func (s *Server) startCSVImports() error {
+	if s.config.CSVImportSchedule == "" {
+		return nil
+	}
+	if err := s.checkCSVImportConfig(); err != nil {
+		return err
+	}
+	schedule, err := ParseCron(s.config.CSVImportSchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("csv-import", schedule, func() error {
+		_, err := s.enqueueJob(context.Background(), "csv-import", nil, JobOptions{})
+		return err
+	})
+	return nil
+}
+
+// csvExportURL turns the link of a shared Google Sheet into its CSV export URL, keeping the
+// selected tab; other URLs are returned unchanged
+func csvExportURL(raw string) string {
+	match := googleSheetPattern.FindStringSubmatch(raw)
+	if match == nil || strings.Contains(raw, "/export?") || strings.Contains(raw, "/pub?") {
+		return raw
+	}
+	export := "https://docs.google.com/spreadsheets/d/" + match[1] + "/export?format=csv"
+	if gid := sheetGIDPattern.FindStringSubmatch(raw); gid != nil {
+		export += "&gid=" + gid[1]
+	}
+	return export
+}
+
+// fetchCSVRows downloads the sheet and returns its header and data rows
+func (s *Server) fetchCSVRows(source string) ([]string, [][]string, error) {
+	client := &http.Client{Timeout: s.config.LLMTimeout}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("downloading CSV: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("downloading CSV: %s returned %s", source, resp.Status)
+	}
+
+	reader := csv.NewReader(io.LimitReader(resp.Body, csvImportMaxBytes))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, errors.New("The CSV has no header row")
+	}
+	return rows[0], rows[1:], nil
+}
+
+// studentFromCSVRow maps a row to a student through CSV_IMPORT_COLUMNS; fields without a mapping
+// are read from the column named after the field, tags are separated by semicolons and
+// external_ids.<system> fields fill the student's external IDs
+func (s *Server) studentFromCSVRow(columns map[string]int, row []string) (Student, error) {
+	value := func(field string) string {
+		header := firstNonEmpty(s.config.CSVImportColumns[field], field)
+		if i, ok := columns[strings.ToLower(strings.TrimSpace(header))]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	student := Student{
+		Name:        value("name"),
+		Email:       value("email"),
+		Phone:       value("phone"),
+		DateOfBirth: value("date_of_birth"),
+		Gender:      value("gender"),
+		Nationality: value("nationality"),
+		Status:      strings.ToLower(value("status")),
+	}
+	for field, target := range map[string]*int{"grade_level": &student.GradeLevel, "age": &student.RecordedAge} {
+		if text := value(field); text != "" {
+			number, err := strconv.Atoi(text)
+			if err != nil {
+				return Student{}, fmt.Errorf("Invalid %s %q", field, text)
+			}
+			*target = number
+		}
+	}
+	if tags := value("tags"); tags != "" {
+		student.Tags = trimList(strings.Split(tags, ";"))
+	}
+
+	fields := []string{}
+	if system, ok := strings.CutPrefix(s.config.CSVImportKey, "external:"); ok {
+		fields = append(fields, "external_ids."+system)
+	}
+	for field := range s.config.CSVImportColumns {
+		if strings.HasPrefix(field, "external_ids.") && !containsString(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range fields {
+		if id := value(field); id != "" {
+			if student.ExternalIDs == nil {
+				student.ExternalIDs = make(map[string]string)
+			}
+			student.ExternalIDs[strings.TrimPrefix(field, "external_ids.")] = id
+		}
+	}
+	normalizeStudent(&student)
+
+	if syncKey(student, s.config.CSVImportKey) == "" {
+		return Student{}, errors.New("Missing value for the import key " + s.config.CSVImportKey)
+	}
+	return student, nil
+}
+
+// resolveCSVConflicts applies the CSV_IMPORT_CONFLICT rule to the rows that match a stored
+// student: keep leaves the stored student as it is and fill drops the values the store already has
+func (s *Server) resolveCSVConflicts(desired []Student, key, rule string) {
+	if rule == "overwrite" {
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	current := make(map[string]Student, s.store.Len())
+	for _, student := range s.store.List() {
+		if k := syncKey(student, key); k != "" {
+			current[k] = student
+		}
+	}
+
+	for i := range desired {
+		existing, exists := current[syncKey(desired[i], key)]
+		if !exists {
+			continue
+		}
+		if rule == "keep" {
+			desired[i] = Student{Email: existing.Email, ExternalIDs: existing.ExternalIDs}
+			continue
+		}
+		row := &desired[i]
+		row.Email = existing.Email
+		for _, field := range []struct{ stored, row *string }{
+			{&existing.Name, &row.Name}, {&existing.Phone, &row.Phone}, {&existing.DateOfBirth, &row.DateOfBirth},
+			{&existing.Gender, &row.Gender}, {&existing.Nationality, &row.Nationality},
+		} {
+			if *field.stored != "" {
+				*field.row = ""
+			}
+		}
+		if existing.GradeLevel != 0 {
+			row.GradeLevel = 0
+		}
+		if existing.DateOfBirth != "" || existing.RecordedAge > 0 {
+			row.RecordedAge = 0
+		}
+		if len(existing.Tags) > 0 {
+			row.Tags = nil
+		}
+		for system := range existing.ExternalIDs {
+			if system != strings.TrimPrefix(key, "external:") {
+				delete(row.ExternalIDs, system)
+			}
+		}
+	}
+}
+
+// runCSVImportJob runs a scheduled CSV import as a background job
+func (s *Server) runCSVImportJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	report, started := s.runCSVImport("schedule", false)
+	if !started {
+		return nil, errors.New("an CSV import is already running")
+	}
+	if report.Error != "" {
+		return nil, errors.New(report.Error)
+	}
+	return json.Marshal(report)
+}
+
+// runCSVImport downloads CSV_IMPORT_URL and reconciles its rows into the store, matching on
+// CSV_IMPORT_KEY and resolving differences with CSV_IMPORT_CONFLICT; students missing from the
+// sheet are only deleted with CSV_IMPORT_DELETE_MISSING. It returns false when another import is
+// already running
+func (s *Server) runCSVImport(trigger string, dryRun bool) (CSVImportReport, bool) {
+	if !csvImportRunning.TryLock() {
+		return CSVImportReport{}, false
+	}
+	defer csvImportRunning.Unlock()
+
+	report := CSVImportReport{Trigger: trigger, Conflict: s.config.CSVImportConflict, StartedAt: time.Now(), Skipped: []CSVSkippedRow{}}
+	if err := s.checkCSVImportConfig(); err != nil {
+		report.Error = err.Error()
+		report.FinishedAt = time.Now()
+		s.postChatEvent(ChatImportFailed, "CSV import failed", report.Error)
+		return report, true
+	}
+	report.Source = csvExportURL(s.config.CSVImportURL)
+
+	header, rows, err := s.fetchCSVRows(report.Source)
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.Rows = len(rows)
+		columns := make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+
+		desired := []Student{}
+		seen := make(map[string]int)
+		for i, row := range rows {
+			student, err := s.studentFromCSVRow(columns, row)
+			if err != nil {
+				report.Skipped = append(report.Skipped, CSVSkippedRow{Row: i + 1, Reason: err.Error()})
+				continue
+			}
+			k := syncKey(student, s.config.CSVImportKey)
+			if other, duplicate := seen[k]; duplicate {
+				report.Skipped = append(report.Skipped, CSVSkippedRow{Row: i + 1, Reason: fmt.Sprintf("Duplicate key %s also used by row %d", k, other)})
+				continue
+			}
+			seen[k] = i + 1
+			desired = append(desired, student)
+		}
+
+		s.resolveCSVConflicts(desired, s.config.CSVImportKey, s.config.CSVImportConflict)
+		result := s.applyStudentSync(desired, SyncOptions{
+			Key:         s.config.CSVImportKey,
+			DryRun:      dryRun,
+			Partial:     true,
+			KeepMissing: !s.config.CSVImportDeleteMissing,
+		})
+		report.Result = &result
+	}
+	report.FinishedAt = time.Now()
+
+	if !dryRun {
+		csvImportMu.Lock()
+		csvImportReport = &report
+		csvImportMu.Unlock()
+	}
+	if report.Error != "" {
+		s.postChatEvent(ChatImportFailed, "CSV import failed", report.Error)
+	}
+	return report, true
+}
+
+// getCSVImport handles GET /admin/csv-import to return the report of the latest import
+func getCSVImport(w http.ResponseWriter, r *http.Request) {
+	csvImportMu.Lock()
+	report := csvImportReport
+	csvImportMu.Unlock()
+	if report == nil {
+		http.Error(w, "No CSV import has run yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// runCSVImportNow handles POST /admin/csv-import/run?dry_run= to import the sheet immediately;
+// a dry run returns the change report without applying it
+func (s *Server) runCSVImportNow(w http.ResponseWriter, r *http.Request) {
+	report, started := s.runCSVImport("admin", r.URL.Query().Get("dry_run") == "true")
+	if !started {
+		http.Error(w, "A CSV import is already running", http.StatusConflict)
+		return
+	}
+	status := http.StatusOK
+	if report.Error != "" {
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
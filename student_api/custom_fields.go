@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	customFields   = make(map[string]CustomField)
+	customFieldsMu sync.Mutex
+
+	customFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+	// Accepted values for CustomField.Type
+	customFieldTypes = map[string]bool{"string": true, "number": true, "boolean": true, "date": true, "enum": true}
+)
+
+// CustomField struct to hold an admin-defined extra field stored in Student.Custom; Options
+// lists the accepted values of an enum field
+type CustomField struct {
+	Name     string   `json:"name"`
+	Label    string   `json:"label,omitempty"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// validateCustomFieldDefinition checks a field definition submitted by an admin
+func validateCustomFieldDefinition(field CustomField) error {
+	if !customFieldNamePattern.MatchString(field.Name) {
+		return errors.New("name must be lower-case letters, digits and underscores, starting with a letter")
+	}
+	if !customFieldTypes[field.Type] {
+		return errors.New("type must be one of string, number, boolean, date or enum")
+	}
+	if field.Type == "enum" && len(field.Options) == 0 {
+		return errors.New("enum fields require options")
+	}
+	if field.Type != "enum" && len(field.Options) > 0 {
+		return errors.New("options are only allowed on enum fields")
+	}
+	return nil
+}
+
+// sortedCustomFields returns the field definitions ordered by name
+func sortedCustomFields() []CustomField {
+	customFieldsMu.Lock()
+	list := make([]CustomField, 0, len(customFields))
+	for _, field := range customFields {
+		list = append(list, field)
+	}
+	customFieldsMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// validateCustomValues checks a student's custom values against the current definitions,
+// rejecting unknown fields, values of the wrong type and missing required fields
+func validateCustomValues(values map[string]interface{}) error {
+	customFieldsMu.Lock()
+	defer customFieldsMu.Unlock()
+
+	for name, value := range values {
+		field, defined := customFields[name]
+		if !defined {
+			return fmt.Errorf("Unknown custom field %q", name)
+		}
+		ok := false
+		switch field.Type {
+		case "string":
+			_, ok = value.(string)
+		case "number":
+			_, ok = value.(float64)
+		case "boolean":
+			_, ok = value.(bool)
+		case "date":
+			text, isString := value.(string)
+			_, err := time.Parse(dateLayout, text)
+			ok = isString && err == nil
+		case "enum":
+			text, isString := value.(string)
+			ok = isString && containsString(field.Options, text)
+		}
+		if !ok {
+			return fmt.Errorf("Invalid value for custom field %q of type %s", name, field.Type)
+		}
+	}
+	for name, field := range customFields {
+		if _, set := values[name]; field.Required && !set {
+			return fmt.Errorf("Custom field %q is required", name)
+		}
+	}
+	return nil
+}
+
+// mergeCustomValues applies an update to a student's custom values; a null value removes the field
+func mergeCustomValues(current, update map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(current)+len(update))
+	for name, value := range current {
+		merged[name] = value
+	}
+	for name, value := range update {
+		if value == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// customValueText renders a custom value for filtering and export
+func customValueText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// getCustomFields handles GET /admin/custom-fields to list the custom field definitions
+func getCustomFields(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sortedCustomFields())
+}
+
+// putCustomField handles PUT /admin/custom-fields/{name} to define or redefine a custom field;
+// existing values are not migrated and are re-checked the next time a student is saved
+func putCustomField(w http.ResponseWriter, r *http.Request) {
+	var field CustomField
+	if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	field.Name = mux.Vars(r)["name"]
+	field.Type = strings.ToLower(strings.TrimSpace(field.Type))
+	if err := validateCustomFieldDefinition(field); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	customFieldsMu.Lock()
+	customFields[field.Name] = field
+	customFieldsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(field)
+}
+
+// deleteCustomField handles DELETE /admin/custom-fields/{name} to remove a definition and the
+// values stored under it
+func (s *Server) deleteCustomField(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	customFieldsMu.Lock()
+	_, exists := customFields[name]
+	delete(customFields, name)
+	customFieldsMu.Unlock()
+	if !exists {
+		http.Error(w, "Custom field not found", http.StatusNotFound)
+		return
+	}
+
+	holders := s.students.List(func(student Student) bool {
+		_, set := student.Custom[name]
+		return set
+	})
+	for _, holder := range holders {
+		_, err := s.students.Change(holder.ID, func(student *Student) error {
+			student.Custom = mergeCustomValues(student.Custom, map[string]interface{}{name: nil})
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrStudentNotFound) {
+			log.Printf("Error removing custom field %s from student %d: %v", name, holder.ID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// exportStudents handles GET /students/export to download the students matching the list
+// filters as CSV, with a column per custom field definition; ?include_health=true adds the
+// allergies of students whose health record allows sharing in exports
+func (s *Server) exportStudents(w http.ResponseWriter, r *http.Request) {
+	filter, err := studentFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	includeHealth := r.URL.Query().Get("include_health") == "true"
+	if includeHealth && !s.canAccessHealth(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	fields := sortedCustomFields()
+
+	s.store.Lock()
+	matched := s.filterStudents(filter)
+	s.store.Unlock()
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="students-`+today()+`.csv"`)
+
+	out := csv.NewWriter(w)
+	header := []string{"id", "name", "email", "age", "status"}
+	for _, field := range fields {
+		header = append(header, field.Name)
+	}
+	if includeHealth {
+		header = append(header, "allergies")
+	}
+	out.Write(header)
+	for _, student := range matched {
+		row := []string{strconv.Itoa(student.ID), csvText(student.Name), csvText(student.Email), strconv.Itoa(student.Age()), student.Status}
+		for _, field := range fields {
+			row = append(row, csvText(customValueText(student.Custom[field.Name])))
+		}
+		if includeHealth {
+			allergies := ""
+			if health, ok := healthRecordFor(student.ID); ok && health.ShareInExports {
+				allergies = strings.Join(health.Allergies, "; ")
+			}
+			row = append(row, csvText(allergies))
+		}
+		out.Write(row)
+	}
+	out.Flush()
+}
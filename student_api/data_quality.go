@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	dataQualityReport *DataQualityReport
+	dataQualityMu     sync.Mutex
+)
+
+// DataQualityReport struct to hold the findings of the latest data quality run
+type DataQualityReport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Students    int                `json:"students"`
+	Duplicates  []DuplicateFinding `json:"duplicates"`
+	Outliers    []OutlierFinding   `json:"outliers"`
+	Errors      []string           `json:"errors,omitempty"`
+}
+
+// DuplicateFinding struct to hold a pair of probably duplicate records
+type DuplicateFinding struct {
+	StudentIDs [2]int  `json:"student_ids"`
+	Similarity float64 `json:"similarity"`
+}
+
+// OutlierFinding struct to hold a record that is unlike the rest of the dataset
+type OutlierFinding struct {
+	StudentID          int     `json:"student_id"`
+	Name               string  `json:"name"`
+	CentroidSimilarity float64 `json:"centroid_similarity"`
+}
+
+// startDataQualityChecks schedules the data quality job when DATA_QUALITY_SCHEDULE is set
+func (s *Server) startDataQualityChecks() error {
+	if s.config.DataQualitySchedule == "" {
+		return nil
+	}
+	schedule, err := ParseCron(s.config.DataQualitySchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("data-quality", schedule, func() error {
+		if report := s.runDataQualityCheck(context.Background()); len(report.Errors) > 0 {
+			return errors.New(strings.Join(report.Errors, "; "))
+		}
+		return nil
+	})
+	return nil
+}
+
+// runDataQualityCheck embeds every student and flags near-duplicate pairs and records far from
+// the dataset's centroid
+func (s *Server) runDataQualityCheck(ctx context.Context) DataQualityReport {
+	s.store.Lock()
+	var all []Student
+	for _, student := range s.store.List() {
+		all = append(all, student)
+	}
+	s.store.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	report := DataQualityReport{GeneratedAt: time.Now(), Students: len(all), Duplicates: []DuplicateFinding{}, Outliers: []OutlierFinding{}}
+
+	var embedded []Student
+	var vectors [][]float64
+	for _, student := range all {
+		vector, err := s.studentEmbedding(ctx, student)
+		if errors.Is(err, ErrNoLLMConsent) {
+			continue
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		embedded = append(embedded, student)
+		vectors = append(vectors, vector)
+	}
+
+	for i := range vectors {
+		for j := i + 1; j < len(vectors); j++ {
+			if similarity := cosineSimilarity(vectors[i], vectors[j]); similarity >= s.config.DuplicateThreshold {
+				report.Duplicates = append(report.Duplicates, DuplicateFinding{
+					StudentIDs: [2]int{embedded[i].ID, embedded[j].ID},
+					Similarity: similarity,
+				})
+			}
+		}
+	}
+	report.Outliers = s.findOutliers(embedded, vectors)
+
+	dataQualityMu.Lock()
+	dataQualityReport = &report
+	dataQualityMu.Unlock()
+
+	log.Printf("Data quality check finished: %d duplicates, %d outliers", len(report.Duplicates), len(report.Outliers))
+	return report
+}
+
+// findOutliers flags records whose similarity to the centroid is more than OUTLIER_STDDEVS
+// standard deviations below the mean
+func (s *Server) findOutliers(embedded []Student, vectors [][]float64) []OutlierFinding {
+	outliers := []OutlierFinding{}
+	if len(vectors) < 3 {
+		return outliers
+	}
+
+	centroid := make([]float64, len(vectors[0]))
+	for _, vector := range vectors {
+		for i := range centroid {
+			if i < len(vector) {
+				centroid[i] += vector[i] / float64(len(vectors))
+			}
+		}
+	}
+
+	similarities := make([]float64, len(vectors))
+	mean := 0.0
+	for i, vector := range vectors {
+		similarities[i] = cosineSimilarity(vector, centroid)
+		mean += similarities[i] / float64(len(vectors))
+	}
+	variance := 0.0
+	for _, similarity := range similarities {
+		variance += (similarity - mean) * (similarity - mean) / float64(len(vectors))
+	}
+	cutoff := mean - s.config.OutlierStdDevs*math.Sqrt(variance)
+
+	for i, similarity := range similarities {
+		if similarity < cutoff {
+			outliers = append(outliers, OutlierFinding{StudentID: embedded[i].ID, Name: embedded[i].Name, CentroidSimilarity: similarity})
+		}
+	}
+	return outliers
+}
+
+// getDataQuality handles GET /admin/data-quality to return the latest findings
+func getDataQuality(w http.ResponseWriter, r *http.Request) {
+	dataQualityMu.Lock()
+	report := dataQualityReport
+	dataQualityMu.Unlock()
+	if report == nil {
+		http.Error(w, "No data quality report has been generated yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// runDataQuality handles POST /admin/data-quality/run to run the check immediately
+func (s *Server) runDataQuality(w http.ResponseWriter, r *http.Request) {
+	report := s.runDataQualityCheck(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	documentChunks   = make(map[int][]*DocumentChunk)
+	documentChunksMu sync.Mutex
+)
+
+// DocumentChunk struct to hold a passage of a text attachment and, once computed, its embedding
+type DocumentChunk struct {
+	AttachmentID int
+	StudentID    int
+	Filename     string
+	Part         int
+	Text         string
+	Vector       []float64
+}
+
+// DocumentCitation struct to hold a passage an answer was grounded in; Ref is the number the
+// model cites it by
+type DocumentCitation struct {
+	Ref          int     `json:"ref"`
+	AttachmentID int     `json:"attachment_id"`
+	Filename     string  `json:"filename"`
+	Part         int     `json:"part"`
+	Excerpt      string  `json:"excerpt"`
+	Score        float64 `json:"score"`
+}
+
+// indexAttachmentText splits a text attachment into passages for retrieval, reporting whether
+// any were stored. Other files (PDFs, images) are not indexed since there is no text extraction
+// for them. Passages are embedded lazily the first time they are searched.
+func (s *Server) indexAttachmentText(attachment Attachment, data []byte) bool {
+	if !strings.HasPrefix(attachment.ContentType, "text/") {
+		return false
+	}
+	words := strings.Fields(string(data))
+	var chunks []*DocumentChunk
+	for start := 0; start < len(words); start += s.config.RAGChunkWords {
+		end := min(start+s.config.RAGChunkWords, len(words))
+		chunks = append(chunks, &DocumentChunk{
+			AttachmentID: attachment.ID,
+			StudentID:    attachment.StudentID,
+			Filename:     attachment.Filename,
+			Part:         len(chunks) + 1,
+			Text:         strings.Join(words[start:end], " "),
+		})
+	}
+	if len(chunks) == 0 {
+		return false
+	}
+
+	documentChunksMu.Lock()
+	defer documentChunksMu.Unlock()
+	documentChunks[attachment.ID] = chunks
+	return true
+}
+
+// deleteDocumentChunks removes the passages of a deleted attachment, reporting whether it had any
+func deleteDocumentChunks(attachmentID int) bool {
+	documentChunksMu.Lock()
+	defer documentChunksMu.Unlock()
+	_, indexed := documentChunks[attachmentID]
+	delete(documentChunks, attachmentID)
+	return indexed
+}
+
+// retrieveDocuments returns the RAG_TOP_K passages of a student's text attachments closest to
+// query, or nothing when retrieval is disabled, the student has no text attachments or the
+// provider cannot produce embeddings
+func (s *Server) retrieveDocuments(ctx context.Context, studentID int, query string) ([]DocumentCitation, error) {
+	if s.config.RAGTopK <= 0 {
+		return nil, nil
+	}
+
+	documentChunksMu.Lock()
+	var chunks []*DocumentChunk
+	var missing []*DocumentChunk
+	for _, attachmentChunks := range documentChunks {
+		for _, chunk := range attachmentChunks {
+			if chunk.StudentID != studentID {
+				continue
+			}
+			chunks = append(chunks, chunk)
+			if chunk.Vector == nil {
+				missing = append(missing, chunk)
+			}
+		}
+	}
+	documentChunksMu.Unlock()
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	vector, err := s.embedText(ctx, query)
+	if errors.Is(err, ErrEmbeddingsUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, chunk := range missing {
+		chunkVector, err := s.embedText(ctx, chunk.Text)
+		if err != nil {
+			return nil, err
+		}
+		documentChunksMu.Lock()
+		chunk.Vector = chunkVector
+		documentChunksMu.Unlock()
+	}
+
+	documentChunksMu.Lock()
+	citations := make([]DocumentCitation, 0, len(chunks))
+	for _, chunk := range chunks {
+		citations = append(citations, DocumentCitation{
+			AttachmentID: chunk.AttachmentID,
+			Filename:     chunk.Filename,
+			Part:         chunk.Part,
+			Excerpt:      chunk.Text,
+			Score:        cosineSimilarity(vector, chunk.Vector),
+		})
+	}
+	documentChunksMu.Unlock()
+
+	sort.Slice(citations, func(i, j int) bool {
+		if citations[i].Score != citations[j].Score {
+			return citations[i].Score > citations[j].Score
+		}
+		if citations[i].AttachmentID != citations[j].AttachmentID {
+			return citations[i].AttachmentID < citations[j].AttachmentID
+		}
+		return citations[i].Part < citations[j].Part
+	})
+	if len(citations) > s.config.RAGTopK {
+		citations = citations[:s.config.RAGTopK]
+	}
+	for i := range citations {
+		citations[i].Ref = i + 1
+	}
+	return citations, nil
+}
+
+// documentContext renders retrieved passages for a prompt, asking the model to cite them by number
+func documentContext(citations []DocumentCitation) string {
+	var b strings.Builder
+	b.WriteString("Excerpts from documents on file for this student. Use them where relevant and cite each one you use as [n]:\n")
+	for _, citation := range citations {
+		fmt.Fprintf(&b, "[%d] %s (part %d): %s\n", citation.Ref, citation.Filename, citation.Part, citation.Excerpt)
+	}
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// embeddingProvider is a recordingProvider that also embeds text, as one dimension per topic
+// word the text mentions
+type embeddingProvider struct {
+	recordingProvider
+}
+
+func (p *embeddingProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	var vector []float64
+	for _, topic := range []string{"chess", "debate"} {
+		vector = append(vector, float64(strings.Count(strings.ToLower(text), topic)))
+	}
+	return vector, nil
+}
+
+// waitForNoFiles waits until no file is left under dir
+func waitForNoFiles(t *testing.T, dir string) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		files := 0
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				files++
+			}
+			return nil
+		})
+		if files == 0 {
+			return
+		}
+	}
+	t.Errorf("files under %s were not deleted", dir)
+}
+
+// uploadText attaches a text file to a student, returning the stored attachment
+func uploadText(t *testing.T, url, filename, text string) Attachment {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", filename)
+	part.Write([]byte(text))
+	form.Close()
+
+	resp, err := http.Post(url, form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	var attachment Attachment
+	if resp.StatusCode != http.StatusCreated || json.NewDecoder(resp.Body).Decode(&attachment) != nil {
+		t.Fatalf("POST %s: %d", url, resp.StatusCode)
+	}
+	return attachment
+}
+
+func TestSummaryAndChatCiteTextAttachments(t *testing.T) {
+	provider := &embeddingProvider{}
+	var blobDir string
+	srv := newTestServer(t, provider, func(cfg *Config) {
+		cfg.RAGChunkWords = 8
+		cfg.RAGTopK = 1
+		blobDir = cfg.BlobDir
+	})
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	// Summaries and attachments outlive the test server, so leave nothing behind for later tests.
+	// The files are deleted in the background; wait for that before the blob directory is removed
+	t.Cleanup(func() {
+		request(t, "DELETE", srv.URL+"/students/1", "", nil)
+		waitForNoFiles(t, blobDir)
+	})
+	uploadText(t, srv.URL+"/students/1/attachments", "report.txt",
+		"Ann won the regional chess championship this spring. "+
+			"She captains the school debate team every year.")
+
+	status, body := request(t, "GET", srv.URL+"/students/1/summary", "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET /students/1/summary: %d %s", status, body)
+	}
+	var summary Summary
+	if err := json.Unmarshal([]byte(body), &summary); err != nil {
+		t.Fatalf("GET /students/1/summary returned %s", body)
+	}
+	if len(summary.Citations) != 1 || summary.Citations[0].Ref != 1 || summary.Citations[0].Filename != "report.txt" {
+		t.Fatalf("summary citations = %+v, want one passage of report.txt", summary.Citations)
+	}
+
+	status, body = request(t, "POST", srv.URL+"/students/1/chat", `{"message":"Which chess championship did Ann win this spring?"}`, nil)
+	if status != http.StatusOK {
+		t.Fatalf("POST /students/1/chat: %d %s", status, body)
+	}
+	var reply struct {
+		SessionID string             `json:"session_id"`
+		Citations []DocumentCitation `json:"citations"`
+	}
+	if err := json.Unmarshal([]byte(body), &reply); err != nil {
+		t.Fatalf("POST /students/1/chat returned %s", body)
+	}
+	if len(reply.Citations) != 1 || !strings.Contains(reply.Citations[0].Excerpt, "chess championship") {
+		t.Fatalf("chat citations = %+v, want the chess passage", reply.Citations)
+	}
+
+	provider.mu.Lock()
+	requests := append([]LLMRequest{}, provider.requests...)
+	provider.mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("provider saw %d requests, want 2", len(requests))
+	}
+	if !strings.Contains(requests[0].Prompt, "[1] report.txt (part ") {
+		t.Errorf("summary prompt does not include the passage:\n%s", requests[0].Prompt)
+	}
+	var grounded bool
+	for _, message := range requests[1].Messages {
+		grounded = grounded || strings.Contains(message.Content, "regional chess championship")
+	}
+	if !grounded {
+		t.Error("chat request does not include the passage")
+	}
+
+	status, body = request(t, "GET", srv.URL+"/students/1/chat/"+reply.SessionID, "", nil)
+	if status != http.StatusOK || strings.Contains(body, "regional chess championship") {
+		t.Errorf("passage was kept in the chat history: %d %s", status, body)
+	}
+}
+
+func TestAttachmentChangesInvalidateSummary(t *testing.T) {
+	provider := &embeddingProvider{}
+	var blobDir string
+	srv := newTestServer(t, provider, func(cfg *Config) {
+		cfg.RAGTopK = 1
+		blobDir = cfg.BlobDir
+	})
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	t.Cleanup(func() {
+		request(t, "DELETE", srv.URL+"/students/1", "", nil)
+		waitForNoFiles(t, blobDir)
+	})
+	summaryCitations := func() int {
+		status, body := request(t, "GET", srv.URL+"/students/1/summary", "", nil)
+		var summary Summary
+		if status != http.StatusOK || json.Unmarshal([]byte(body), &summary) != nil {
+			t.Fatalf("GET /students/1/summary: %d %s", status, body)
+		}
+		return len(summary.Citations)
+	}
+
+	if n := summaryCitations(); n != 0 {
+		t.Fatalf("summary before any upload cites %d passages", n)
+	}
+	attachment := uploadText(t, srv.URL+"/students/1/attachments", "report.txt", "Ann won the chess championship.")
+	if !attachment.Searchable {
+		t.Error("text attachment is not reported as searchable")
+	}
+	if n := summaryCitations(); n != 1 {
+		t.Fatalf("summary after an upload cites %d passages, want 1", n)
+	}
+	url := fmt.Sprintf("%s/students/1/attachments/%d", srv.URL, attachment.ID)
+	if status, body := request(t, "DELETE", url, "", nil); status != http.StatusNoContent {
+		t.Fatalf("DELETE %s: %d %s", url, status, body)
+	}
+	if n := summaryCitations(); n != 0 {
+		t.Errorf("summary after the attachment was deleted cites %d passages", n)
+	}
+}
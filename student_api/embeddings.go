@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"student_api/student_api/internal/llm"
+)
+
+// ErrEmbeddingsUnsupported is returned when the configured provider cannot produce embeddings
+var ErrEmbeddingsUnsupported = llm.ErrEmbeddingsUnsupported
+
+var (
+	embeddings   = make(map[int]StudentEmbedding)
+	embeddingsMu sync.Mutex
+)
+
+// StudentEmbedding struct to hold the vector computed for a version of a student record
+type StudentEmbedding struct {
+	Document string
+	Vector   []float64
+}
+
+// SimilarStudent struct to hold a nearest-neighbor match
+type SimilarStudent struct {
+	Student Student `json:"student"`
+	Score   float64 `json:"score"`
+}
+
+// studentDocument renders the text that is embedded for a student
+func studentDocument(student Student) string {
+	return fmt.Sprintf("Name: %s\nAge: %d\nEmail: %s", student.Name, student.Age(), student.Email)
+}
+
+// embeddingQueue struct to hold the students waiting to be embedded in the background. A student
+// is queued once however often it changes before its turn, so the queue never outgrows the roster
+type embeddingQueue struct {
+	mu      sync.Mutex
+	pending map[int]bool
+	wake    chan struct{}
+}
+
+// newEmbeddingQueue returns an empty queue
+func newEmbeddingQueue() *embeddingQueue {
+	return &embeddingQueue{pending: make(map[int]bool), wake: make(chan struct{}, 1)}
+}
+
+// add queues a student, waking the indexer if it is idle
+func (q *embeddingQueue) add(id int) {
+	q.mu.Lock()
+	q.pending[id] = true
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take removes and returns a queued student, reporting false when none is left
+func (q *embeddingQueue) take() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id := range q.pending {
+		delete(q.pending, id)
+		return id, true
+	}
+	return 0, false
+}
+
+// startEmbeddingIndexer embeds queued students one at a time, reading each record when its turn
+// comes so only its latest version is embedded
+func (s *Server) startEmbeddingIndexer() {
+	go func() {
+		for range s.embeddings.wake {
+			for id, ok := s.embeddings.take(); ok; id, ok = s.embeddings.take() {
+				if student, exists := s.students.Get(id); exists {
+					s.indexStudentEmbedding(student)
+				}
+			}
+		}
+	}()
+}
+
+// indexStudentEmbedding computes and stores the embedding of a student, logging failures
+func (s *Server) indexStudentEmbedding(student Student) {
+	_, err := s.studentEmbedding(context.Background(), student)
+	if err != nil && !errors.Is(err, ErrEmbeddingsUnsupported) && !errors.Is(err, ErrNoLLMConsent) {
+		log.Printf("Error embedding student %d: %v", student.ID, err)
+	}
+}
+
+// studentEmbedding returns the stored embedding for a student, computing it if the record changed
+func (s *Server) studentEmbedding(ctx context.Context, student Student) ([]float64, error) {
+	if err := s.checkLLMConsent(student.ID); err != nil {
+		return nil, err
+	}
+	document := studentDocument(student)
+
+	embeddingsMu.Lock()
+	stored, ok := embeddings[student.ID]
+	embeddingsMu.Unlock()
+	if ok && stored.Document == document {
+		return stored.Vector, nil
+	}
+
+	vector, err := s.embedText(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddingsMu.Lock()
+	embeddings[student.ID] = StudentEmbedding{Document: document, Vector: vector}
+	embeddingsMu.Unlock()
+	return vector, nil
+}
+
+// embedText embeds arbitrary text with the configured embedding model, through the same limiter,
+// breaker, usage tracking and audit log as the other LLM calls
+func (s *Server) embedText(ctx context.Context, text string) ([]float64, error) {
+	return s.llm.Embed(ctx, s.config.EmbeddingModel, text)
+}
+
+// deleteEmbedding removes the stored embedding of a deleted student
+func deleteEmbedding(id int) {
+	embeddingsMu.Lock()
+	defer embeddingsMu.Unlock()
+	delete(embeddings, id)
+}
+
+// getSimilarStudents handles GET /students/{id}/similar to list the nearest neighbors of a student
+func (s *Server) getSimilarStudents(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	vector, err := s.studentEmbedding(r.Context(), student)
+	if err != nil {
+		s.writeEmbeddingError(w, err)
+		return
+	}
+
+	matches, err := s.nearestStudents(r.Context(), vector, id, searchLimit(r))
+	if err != nil {
+		s.writeEmbeddingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+// semanticSearch handles GET /students/search/semantic?q=... to find students matching free text
+func (s *Server) semanticSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+		return
+	}
+
+	vector, err := s.embedText(r.Context(), q)
+	if err != nil {
+		s.writeEmbeddingError(w, err)
+		return
+	}
+
+	matches, err := s.nearestStudents(r.Context(), vector, 0, searchLimit(r))
+	if err != nil {
+		s.writeEmbeddingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+// nearestStudents ranks every student except excludeID by cosine similarity to vector
+func (s *Server) nearestStudents(ctx context.Context, vector []float64, excludeID, limit int) ([]SimilarStudent, error) {
+	s.store.Lock()
+	var candidates []Student
+	for _, student := range s.store.List() {
+		if student.ID != excludeID {
+			candidates = append(candidates, student)
+		}
+	}
+	s.store.Unlock()
+
+	matches := []SimilarStudent{}
+	for _, student := range candidates {
+		candidate, err := s.studentEmbedding(ctx, student)
+		if errors.Is(err, ErrNoLLMConsent) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, SimilarStudent{Student: student, Score: cosineSimilarity(vector, candidate)})
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0 if they are incomparable
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// searchLimit reads ?limit= for similarity searches, defaulting to 5
+func searchLimit(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return 5
+	}
+	return limit
+}
+
+// writeEmbeddingError responds to a failed embedding request
+func (s *Server) writeEmbeddingError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrEmbeddingsUnsupported) {
+		writeProblem(w, http.StatusNotImplemented, "Embeddings unsupported", "Semantic search is not available with the configured LLM provider")
+		return
+	}
+	s.writeLLMError(w, err)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+
+	"student_api/student_api/studentpb"
+)
+
+// Binary media types accepted on the student routes besides JSON
+const (
+	mediaTypeProtobuf = "application/x-protobuf"
+	mediaTypeMsgpack  = "application/msgpack"
+)
+
+// negotiateEncoding returns the binary media type a client asks for in Accept, or "" for JSON
+func negotiateEncoding(r *http.Request) string {
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, mediaTypeProtobuf):
+		return mediaTypeProtobuf
+	case strings.Contains(accept, mediaTypeMsgpack), strings.Contains(accept, "application/x-msgpack"):
+		return mediaTypeMsgpack
+	}
+	return ""
+}
+
+// writeEncoded writes a JSON-encodable value, or its MessagePack form with the same field names
+// when the client accepts it; message builds the protobuf form, and nil means the value has none
+// so protobuf clients get JSON
+func writeEncoded(w http.ResponseWriter, r *http.Request, status int, value interface{}, message func() proto.Message) {
+	var body []byte
+	var err error
+	switch encoding := negotiateEncoding(r); {
+	case encoding == mediaTypeProtobuf && message != nil:
+		w.Header().Set("Content-Type", mediaTypeProtobuf)
+		body, err = proto.Marshal(message())
+	case encoding == mediaTypeMsgpack:
+		w.Header().Set("Content-Type", mediaTypeMsgpack)
+		body, err = msgpackFromJSON(value)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		body, err = json.Marshal(value)
+		body = append(body, '\n')
+	}
+	if err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// msgpackFromJSON encodes a value as MessagePack through its JSON form, so derived fields added
+// by MarshalJSON methods are kept
+func msgpackFromJSON(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(withIntegers(generic))
+}
+
+// withIntegers turns the json.Numbers of a decoded JSON value into int64 where they are whole,
+// so MessagePack keeps integers as integers, and float64 otherwise
+func withIntegers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = withIntegers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = withIntegers(item)
+		}
+	}
+	return value
+}
+
+// decodeStudentBody reads a student from a JSON, MessagePack or protobuf request body according
+// to its Content-Type
+func decodeStudentBody(r *http.Request, student *Student) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case mediaTypeProtobuf:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		var message studentpb.Student
+		if err := proto.Unmarshal(data, &message); err != nil {
+			return err
+		}
+		*student = studentFromProto(&message)
+		return nil
+	case mediaTypeMsgpack, "application/x-msgpack":
+		var generic map[string]interface{}
+		if err := msgpack.NewDecoder(r.Body).Decode(&generic); err != nil {
+			return err
+		}
+		data, err := json.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, student)
+	}
+	return json.NewDecoder(r.Body).Decode(student)
+}
+
+// studentMessage returns the protobuf form of a student for writeEncoded
+func studentMessage(student Student) func() proto.Message {
+	return func() proto.Message { return studentToProto(student) }
+}
+
+// studentListMessage returns the protobuf form of a list of students for writeEncoded
+func studentListMessage(list []Student) func() proto.Message {
+	return func() proto.Message {
+		response := &studentpb.ListStudentsResponse{}
+		for _, student := range list {
+			response.Students = append(response.Students, studentToProto(student))
+		}
+		return response
+	}
+}
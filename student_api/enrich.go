@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"student_api/student_api/internal/handlers"
+)
+
+var (
+	enrichments      = make(map[int]*EnrichmentProposal)
+	enrichmentsMu    sync.Mutex
+	nextEnrichmentID int
+)
+
+// EnrichmentProposal struct to hold model-suggested changes awaiting admin approval
+type EnrichmentProposal struct {
+	ID              int           `json:"id"`
+	StudentID       int           `json:"student_id"`
+	Changes         []FieldChange `json:"changes"`
+	EmailDomainType string        `json:"email_domain_type"`
+	Notes           string        `json:"notes,omitempty"`
+	Model           string        `json:"model"`
+	Status          string        `json:"status"`
+	CreatedAt       time.Time     `json:"created_at"`
+	ReviewedAt      *time.Time    `json:"reviewed_at,omitempty"`
+}
+
+// FieldChange struct to hold a single proposed field update
+type FieldChange struct {
+	Field    string `json:"field"`
+	Current  string `json:"current"`
+	Proposed string `json:"proposed"`
+}
+
+// enrichmentReply struct to hold the JSON reply expected from the model
+type enrichmentReply struct {
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	EmailDomainType string `json:"email_domain_type"`
+	Notes           string `json:"notes"`
+}
+
+// enrichInstructions describes the JSON reply expected from the model
+const enrichInstructions = `
+Normalize this record. Fix the casing and spacing of the name, lower-case and trim the email, and classify the email domain.
+Respond with a single JSON object and nothing else, using exactly these keys:
+{"name": "<normalized name>", "email": "<normalized email>", "email_domain_type": "personal|school|work|unknown", "notes": "<short explanation of the changes>"}`
+
+// enrichStudent handles POST /students/{id}/enrich to ask the model for normalized field values,
+// recording them as a proposal for an admin to approve rather than applying them directly
+func (s *Server) enrichStudent(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+	if err := s.checkLLMConsent(id); err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	prompt := fmt.Sprintf("Student record: Name: %s, Age: %d, Email: %s\n%s", student.Name, student.Age(), student.Email, enrichInstructions)
+	resp, err := s.llm.Generate(r.Context(), LLMRequest{Model: opts.Model, Prompt: prompt, JSON: true})
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	var reply enrichmentReply
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp.Text)), &reply); err != nil {
+		s.writeLLMError(w, fmt.Errorf("%w: %v", ErrInvalidLLMOutput, err))
+		return
+	}
+
+	proposal := EnrichmentProposal{
+		StudentID:       student.ID,
+		Changes:         []FieldChange{},
+		EmailDomainType: normalizeDomainType(reply.EmailDomainType),
+		Notes:           reply.Notes,
+		Model:           firstNonEmpty(resp.Model, opts.Model),
+		Status:          "pending",
+		CreatedAt:       time.Now(),
+	}
+	if name := strings.TrimSpace(reply.Name); name != "" && name != student.Name {
+		proposal.Changes = append(proposal.Changes, FieldChange{Field: "name", Current: student.Name, Proposed: name})
+	}
+	if email := strings.TrimSpace(reply.Email); email != "" && email != student.Email {
+		if !strings.Contains(email, "@") {
+			s.writeLLMError(w, fmt.Errorf("%w: proposed email %q is not an address", ErrInvalidLLMOutput, email))
+			return
+		}
+		proposal.Changes = append(proposal.Changes, FieldChange{Field: "email", Current: student.Email, Proposed: email})
+	}
+
+	enrichmentsMu.Lock()
+	nextEnrichmentID++
+	proposal.ID = nextEnrichmentID
+	enrichments[proposal.ID] = &proposal
+	enrichmentsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(proposal)
+}
+
+// normalizeDomainType maps the model's classification onto the supported values
+func normalizeDomainType(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "personal", "school", "work":
+		return value
+	}
+	return "unknown"
+}
+
+// listEnrichments handles GET /admin/enrichments?status=pending to list enrichment proposals
+func listEnrichments(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	enrichmentsMu.Lock()
+	items := []EnrichmentProposal{}
+	for _, item := range enrichments {
+		if status == "" || item.Status == status {
+			items = append(items, *item)
+		}
+	}
+	enrichmentsMu.Unlock()
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// reviewEnrichment handles POST /admin/enrichments/{id}/{action}, where action is approve
+// (apply the proposed changes through the student service) or reject. Approval fails with 409
+// when the record has changed since the proposal was made or the change conflicts with another
+// student, and with 422 when the changed record is invalid.
+func (s *Server) reviewEnrichment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid enrichment ID", http.StatusBadRequest)
+		return
+	}
+	action := vars["action"]
+	if action != "approve" && action != "reject" {
+		http.Error(w, "Action must be approve or reject", http.StatusBadRequest)
+		return
+	}
+
+	enrichmentsMu.Lock()
+	defer enrichmentsMu.Unlock()
+
+	proposal, exists := enrichments[id]
+	if !exists {
+		http.Error(w, "Enrichment proposal not found", http.StatusNotFound)
+		return
+	}
+	if proposal.Status != "pending" {
+		http.Error(w, "Enrichment proposal has already been "+proposal.Status, http.StatusConflict)
+		return
+	}
+
+	if action == "approve" {
+		var update Student
+		for _, change := range proposal.Changes {
+			switch change.Field {
+			case "name":
+				update.Name = change.Proposed
+			case "email":
+				update.Email = change.Proposed
+			}
+		}
+		unchanged := func(student Student) error {
+			for _, change := range proposal.Changes {
+				if current := map[string]string{"name": student.Name, "email": student.Email}[change.Field]; current != change.Current {
+					return errors.New("Student has changed since the proposal was made")
+				}
+			}
+			return nil
+		}
+		if _, err := s.students.UpdateIf(proposal.StudentID, unchanged, update); err != nil {
+			status := handlers.StatusCode(err)
+			if errors.Is(err, ErrInvalidStudent) {
+				status = http.StatusUnprocessableEntity
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		proposal.Status = "approved"
+	} else {
+		proposal.Status = "rejected"
+	}
+	now := time.Now()
+	proposal.ReviewedAt = &now
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(proposal)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// studentEventAvroSchema describes the Avro encoding of a StudentEvent; the student record is
+// carried as its JSON document so the schema stays fixed as the record gains fields
+const studentEventAvroSchema = `{"type":"record","name":"StudentEvent","namespace":"student_api","fields":[` +
+	`{"name":"id","type":"long"},` +
+	`{"name":"type","type":"string"},` +
+	`{"name":"student_id","type":"long"},` +
+	`{"name":"at","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+	`{"name":"student","type":"string"}]}`
+
+// EventPublisher is implemented by message brokers that student events are forwarded to
+type EventPublisher interface {
+	Publish(event StudentEvent) error
+}
+
+// newEventPublisher returns the broker selected by EVENT_BROKER, or nil when publishing is disabled
+func newEventPublisher(cfg Config) (EventPublisher, error) {
+	if cfg.EventFormat != "json" && cfg.EventFormat != "avro" {
+		return nil, fmt.Errorf("unknown EVENT_FORMAT %q", cfg.EventFormat)
+	}
+
+	switch cfg.EventBroker {
+	case "":
+		return nil, nil
+	case "kafka":
+		if cfg.KafkaRESTURL == "" {
+			return nil, fmt.Errorf("KAFKA_REST_URL is required for the kafka event broker")
+		}
+		return &KafkaRESTPublisher{BaseURL: strings.TrimRight(cfg.KafkaRESTURL, "/"), Topic: cfg.EventTopic,
+			Format: cfg.EventFormat, Client: &http.Client{Timeout: cfg.LLMTimeout}}, nil
+	case "nats":
+		conn, err := nats.Connect(cfg.NATSURL, nats.Name("student_api"), nats.MaxReconnects(-1), nats.RetryOnFailedConnect(true))
+		if err != nil {
+			return nil, err
+		}
+		return &NATSPublisher{Conn: conn, URL: cfg.NATSURL, Subject: cfg.EventTopic, Format: cfg.EventFormat}, nil
+	default:
+		return nil, fmt.Errorf("unknown EVENT_BROKER %q", cfg.EventBroker)
+	}
+}
+
+// startEventPublishing relays the event outbox to the configured broker
+func (s *Server) startEventPublishing() error {
+	publisher, err := newEventPublisher(s.config)
+	if publisher == nil || err != nil {
+		return err
+	}
+
+	eventSubscribersMu.Lock()
+	eventOutboxEnabled = true
+	eventOutboxMax = s.config.EventOutboxMax
+	eventSubscribersMu.Unlock()
+	go s.relayOutbox(publisher)
+	log.Printf("Publishing student events to %s topic %s as %s", s.config.EventBroker, s.config.EventTopic, s.config.EventFormat)
+	return nil
+}
+
+// avroStudentEvent returns the event in the shape of studentEventAvroSchema
+func avroStudentEvent(event StudentEvent) (map[string]interface{}, error) {
+	student, err := json.Marshal(event.Student)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":         event.ID,
+		"type":       event.Type,
+		"student_id": int64(event.StudentID),
+		"at":         event.At.UnixNano() / int64(time.Millisecond),
+		"student":    string(student),
+	}, nil
+}
+
+// encodeAvroStudentEvent writes the event in Avro binary encoding: longs as zig-zag varints and
+// strings length-prefixed, in schema field order
+func encodeAvroStudentEvent(event StudentEvent) ([]byte, error) {
+	record, err := avroStudentEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	for _, field := range []string{"id", "type", "student_id", "at", "student"} {
+		switch value := record[field].(type) {
+		case int64:
+			b = binary.AppendVarint(b, value)
+		case string:
+			b = binary.AppendVarint(b, int64(len(value)))
+			b = append(b, value...)
+		}
+	}
+	return b, nil
+}
+
+// KafkaRESTPublisher produces events to a Kafka topic through a Confluent REST Proxy; records are
+// keyed by student ID so each student's changes stay in order on one partition. Avro records are
+// registered with the proxy's schema registry
+type KafkaRESTPublisher struct {
+	BaseURL string
+	Topic   string
+	Format  string
+	Client  *http.Client
+}
+
+// Publish produces one event and checks the offset the proxy reports for it
+func (p *KafkaRESTPublisher) Publish(event StudentEvent) error {
+	var value interface{} = event
+	body := map[string]interface{}{}
+	contentType := "application/vnd.kafka.json.v2+json"
+	if p.Format == "avro" {
+		record, err := avroStudentEvent(event)
+		if err != nil {
+			return err
+		}
+		value = record
+		body["key_schema"] = `"string"`
+		body["value_schema"] = studentEventAvroSchema
+		contentType = "application/vnd.kafka.avro.v2+json"
+	}
+	body["records"] = []map[string]interface{}{{"key": strconv.Itoa(event.StudentID), "value": value}}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", p.BaseURL+"/topics/"+url.PathEscape(p.Topic), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka rest proxy returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return err
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rest proxy rejected the record: %s", offset.Error)
+		}
+	}
+	return nil
+}
+
+// NATSPublisher publishes events on a NATS subject, with the event type and encoding in headers
+type NATSPublisher struct {
+	Conn    *nats.Conn
+	URL     string
+	Subject string
+	Format  string
+}
+
+// Publish sends one event; NATS core publishing is fire and forget, so this flushes to surface
+// a lost connection
+func (p *NATSPublisher) Publish(event StudentEvent) error {
+	if !p.Conn.IsConnected() {
+		return fmt.Errorf("not connected to %s", p.URL)
+	}
+	msg := nats.NewMsg(p.Subject)
+	msg.Header.Set("Event-Type", event.Type)
+	msg.Header.Set("Event-ID", strconv.FormatInt(event.ID, 10))
+
+	var err error
+	if p.Format == "avro" {
+		msg.Header.Set("Content-Type", "avro/binary")
+		msg.Data, err = encodeAvroStudentEvent(event)
+	} else {
+		msg.Header.Set("Content-Type", "application/json")
+		msg.Data, err = json.Marshal(event)
+	}
+	if err != nil {
+		return err
+	}
+	if err := p.Conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.Conn.FlushTimeout(5 * time.Second)
+}
+
+// getEventSchema handles GET /students/events/schema.avsc to fetch the Avro schema of published
+// events
+func getEventSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(studentEventAvroSchema))
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// liveEventsOnly asks subscribeStudentEventsAfter for new events only
+const liveEventsOnly = -1
+
+// Types of StudentEvent
+const (
+	EventStudentCreated = "student.created"
+	EventStudentUpdated = "student.updated"
+	EventStudentDeleted = "student.deleted"
+)
+
+var (
+	eventSubscribers   = make(map[chan StudentEvent]bool)
+	eventSubscribersMu sync.Mutex
+	nextEventID        int64
+
+	// eventHistory keeps the last EVENT_HISTORY_SIZE events, oldest first, so feeds can resume
+	eventHistory []StudentEvent
+)
+
+// StudentEvent struct to hold a change to a student; Student is the record after the change, or
+// the last state of a deleted student
+type StudentEvent struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	StudentID int       `json:"student_id"`
+	Student   Student   `json:"student"`
+	At        time.Time `json:"at"`
+}
+
+// publishStudentEvent delivers a change to every subscriber without blocking; a subscriber whose
+// buffer is full misses the event, but the broker relay reads from the outbox and misses none.
+// Callers usually hold the student store, so events are published in the order the changes were made
+func (s *Server) publishStudentEvent(eventType string, student Student) {
+	eventSubscribersMu.Lock()
+	defer eventSubscribersMu.Unlock()
+
+	nextEventID++
+	event := StudentEvent{ID: nextEventID, Type: eventType, StudentID: student.ID, Student: student, At: time.Now()}
+	eventHistory = append(eventHistory, event)
+	if excess := len(eventHistory) - s.config.EventHistorySize; excess > 0 {
+		eventHistory = eventHistory[excess:]
+	}
+	addToOutbox(event)
+	addToChangeLog(event)
+	for subscriber := range eventSubscribers {
+		select {
+		case subscriber <- event:
+		default:
+			log.Printf("Dropping %s event %d for a slow subscriber", eventType, event.ID)
+		}
+	}
+}
+
+// subscribeStudentEvents registers a subscriber; the returned function unsubscribes it and must
+// be called once the subscriber stops reading
+func subscribeStudentEvents() (<-chan StudentEvent, func()) {
+	_, events, unsubscribe, _ := subscribeStudentEventsAfter(liveEventsOnly)
+	return events, unsubscribe
+}
+
+// subscribeStudentEventsAfter registers a subscriber and returns the retained events after
+// lastID, with no gap or overlap between them and the live events. complete is false when
+// events after lastID have already left the history
+func subscribeStudentEventsAfter(lastID int64) (backlog []StudentEvent, events <-chan StudentEvent, unsubscribe func(), complete bool) {
+	live := make(chan StudentEvent, 64)
+
+	eventSubscribersMu.Lock()
+	eventSubscribers[live] = true
+	complete = true
+	if lastID != liveEventsOnly {
+		for _, event := range eventHistory {
+			if event.ID > lastID {
+				backlog = append(backlog, event)
+			}
+		}
+		oldest := nextEventID + 1
+		if len(eventHistory) > 0 {
+			oldest = eventHistory[0].ID
+		}
+		complete = lastID >= oldest-1
+	}
+	eventSubscribersMu.Unlock()
+
+	return backlog, live, func() {
+		eventSubscribersMu.Lock()
+		delete(eventSubscribers, live)
+		eventSubscribersMu.Unlock()
+	}, complete
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed computed-field expression. The language has number, string ("..." or '...'),
+// true, false and null literals, variables (letters, digits, "_" and "."), parentheses, unary
+// ! and -, and the binary operators * / + - < <= > >= == != && || with the usual precedence
+type Expr interface {
+	Eval(vars func(name string) interface{}) (interface{}, error)
+}
+
+type (
+	literalExpr  struct{ value interface{} }
+	variableExpr struct{ name string }
+	unaryExpr    struct {
+		op      string
+		operand Expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right Expr
+	}
+)
+
+// binaryPrecedence orders the binary operators; higher binds tighter
+var binaryPrecedence = map[string]int{
+	"||": 1, "&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6,
+}
+
+// ParseExpr parses an expression, reporting syntax errors with their position
+func ParseExpr(source string) (Expr, error) {
+	tokens, err := tokenizeExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizeExpr splits an expression into literals, names, operators and parentheses
+func tokenizeExpr(source string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(source[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, source[i:i+end+2])
+			i += end + 2
+		case unicode.IsDigit(c) || c == '.' && i+1 < len(source) && unicode.IsDigit(rune(source[i+1])):
+			j := i
+			for j < len(source) && (unicode.IsDigit(rune(source[j])) || source[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(source) && (unicode.IsLetter(rune(source[j])) || unicode.IsDigit(rune(source[j])) || source[j] == '_' || source[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		default:
+			if i+1 < len(source) {
+				if two := source[i : i+2]; binaryPrecedence[two] > 0 {
+					tokens = append(tokens, two)
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("()!<>+-*/", c) {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser is a precedence-climbing parser over a token list
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) parseBinary(minPrecedence int) (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.tokens) {
+		op := p.tokens[p.pos]
+		precedence := binaryPrecedence[op]
+		if precedence == 0 || precedence < minPrecedence {
+			break
+		}
+		p.pos++
+		right, err := p.parseBinary(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case token == "!" || token == "-":
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: token, operand: operand}, nil
+	case token == "(":
+		inner, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return inner, nil
+	case token[0] == '"' || token[0] == '\'':
+		return literalExpr{token[1 : len(token)-1]}, nil
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		n, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return literalExpr{n}, nil
+	case token == "true" || token == "false":
+		return literalExpr{token == "true"}, nil
+	case token == "null":
+		return literalExpr{nil}, nil
+	case unicode.IsLetter(rune(token[0])) || token[0] == '_':
+		return variableExpr{token}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", token)
+}
+
+func (e literalExpr) Eval(vars func(string) interface{}) (interface{}, error) {
+	return e.value, nil
+}
+
+func (e variableExpr) Eval(vars func(string) interface{}) (interface{}, error) {
+	return vars(e.name), nil
+}
+
+func (e unaryExpr) Eval(vars func(string) interface{}) (interface{}, error) {
+	value, err := e.operand.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "!" {
+		return !truthy(value), nil
+	}
+	n, ok := value.(float64)
+	if !ok {
+		return nil, errors.New("- needs a number")
+	}
+	return -n, nil
+}
+
+func (e binaryExpr) Eval(vars func(string) interface{}) (interface{}, error) {
+	left, err := e.left.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	// && and || short-circuit and yield booleans
+	switch e.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+	}
+	right, err := e.right.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "&&", "||":
+		return truthy(right), nil
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	if ls, ok := left.(string); ok {
+		rs, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s needs two strings or two numbers", e.op)
+		}
+		switch e.op {
+		case "+":
+			return ls + rs, nil
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		}
+		return nil, fmt.Errorf("%s does not apply to strings", e.op)
+	}
+
+	ln, lok := left.(float64)
+	rn, rok := right.(float64)
+	if !lok || !rok {
+		// Arithmetic and comparisons with a missing value yield null
+		return nil, nil
+	}
+	switch e.op {
+	case "+":
+		return ln + rn, nil
+	case "-":
+		return ln - rn, nil
+	case "*":
+		return ln * rn, nil
+	case "/":
+		if rn == 0 {
+			return nil, nil
+		}
+		return ln / rn, nil
+	case "<":
+		return ln < rn, nil
+	case "<=":
+		return ln <= rn, nil
+	case ">":
+		return ln > rn, nil
+	default:
+		return ln >= rn, nil
+	}
+}
+
+// truthy reports whether a value counts as true: non-zero numbers, non-empty strings and true
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// externalSystemPattern restricts external ID namespaces such as "sis" or "library_card"
+var externalSystemPattern = regexp.MustCompile(`^[a-z][a-z0-9_\-]{0,31}$`)
+
+// normalizeExternalIDs lower-cases namespaces and trims identifiers
+func normalizeExternalIDs(ids map[string]string) map[string]string {
+	if ids == nil {
+		return nil
+	}
+	normalized := make(map[string]string, len(ids))
+	for system, id := range ids {
+		normalized[strings.ToLower(strings.TrimSpace(system))] = strings.TrimSpace(id)
+	}
+	return normalized
+}
+
+// validateExternalIDs checks the namespaces and identifiers of a student's external IDs
+func validateExternalIDs(ids map[string]string) error {
+	for system, id := range ids {
+		if !externalSystemPattern.MatchString(system) {
+			return fmt.Errorf("Invalid external ID system %q", system)
+		}
+		if id == "" || len(id) > 128 {
+			return fmt.Errorf("Invalid external ID for %s", system)
+		}
+	}
+	return nil
+}
+
+// mergeExternalIDs applies an update to a student's external IDs; an empty identifier removes
+// the namespace
+func mergeExternalIDs(current, update map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(update))
+	for system, id := range current {
+		merged[system] = id
+	}
+	for system, id := range update {
+		if id == "" {
+			delete(merged, system)
+		} else {
+			merged[system] = id
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// externalIDConflict returns an error when another student already holds one of a student's
+// external IDs; the caller must hold the student store
+func (s *Server) externalIDConflict(student Student) error {
+	for _, other := range s.store.List() {
+		if other.ID == student.ID {
+			continue
+		}
+		for system, id := range student.ExternalIDs {
+			if other.ExternalIDs[system] == id {
+				return errors.New("The " + system + " ID " + id + " is already assigned to another student")
+			}
+		}
+	}
+	return nil
+}
+
+// getStudentByExternalID handles GET /students/by-external/{system}/{id} to look up a student by
+// an identifier from another campus system
+func (s *Server) getStudentByExternalID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	system := strings.ToLower(vars["system"])
+
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	for _, student := range s.store.List() {
+		if id, ok := student.ExternalIDs[system]; ok && id == vars["id"] {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(withComputed(student))
+			return
+		}
+	}
+
+	http.Error(w, "Student not found", http.StatusNotFound)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// FallbackEntry struct to hold one step of the fallback chain
+type FallbackEntry struct {
+	Name     string
+	Provider LLMProvider
+	// Model overrides the requested model; empty keeps the request's model
+	Model string
+}
+
+// FallbackProvider tries each entry in order until one succeeds
+type FallbackProvider struct {
+	Entries []FallbackEntry
+}
+
+// Generate calls the entries in order, moving on when one fails for any reason other than the
+// caller canceling the request, and reports which provider and model served the response
+func (f *FallbackProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	var errs []error
+	for _, entry := range f.Entries {
+		attempt := req
+		if entry.Model != "" {
+			attempt.Model = entry.Model
+		}
+
+		resp, err := entry.Provider.Generate(ctx, attempt)
+		if err == nil {
+			resp.Provider = entry.Name
+			resp.Model = attempt.Model
+			return resp, nil
+		}
+		if errors.Is(err, context.Canceled) {
+			return LLMResponse{}, err
+		}
+
+		log.Printf("LLM %s:%s failed, trying next in chain: %v", entry.Name, attempt.Model, err)
+		errs = append(errs, err)
+	}
+
+	// Report the primary's failure, since that is what the caller asked for
+	return LLMResponse{}, errs[0]
+}
+
+// Stream relays the primary entry's stream; a stream cannot move on to the next entry once
+// output has been sent
+func (f *FallbackProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	if len(f.Entries) == 0 {
+		return LLMResponse{}, ErrStreamingUnsupported
+	}
+	entry := f.Entries[0]
+	if entry.Model != "" {
+		req.Model = entry.Model
+	}
+	resp, err := streamFrom(ctx, entry.Provider, req, chunks)
+	resp.Provider, resp.Model = entry.Name, req.Model
+	return resp, err
+}
+
+// Embed embeds text with the primary entry only; vectors from different models cannot be compared,
+// so an embedding never comes from a fallback
+func (f *FallbackProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	if len(f.Entries) == 0 {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	return embedFrom(ctx, f.Entries[0].Provider, model, text)
+}
+
+// parseFallbackChain builds the entries named in LLM_FALLBACK_CHAIN, a comma-separated list of
+// provider:model pairs tried after the primary provider
+func parseFallbackChain(cfg Config, newProvider func(name string) (LLMProvider, error)) ([]FallbackEntry, error) {
+	var entries []FallbackEntry
+	for _, item := range cfg.LLMFallbackChain {
+		name, model := item, ""
+		if i := strings.Index(item, ":"); i >= 0 {
+			name, model = item[:i], item[i+1:]
+		}
+		provider, err := newProvider(name)
+		if err != nil {
+			return nil, fmt.Errorf("fallback chain entry %q: %v", item, err)
+		}
+		entries = append(entries, FallbackEntry{Name: name, Provider: provider, Model: model})
+	}
+	return entries, nil
+}
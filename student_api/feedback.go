@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	summaryFeedback   = make(map[int][]Feedback)
+	summaryFeedbackMu sync.Mutex
+)
+
+// Feedback struct to hold a user's rating of a generated summary
+type Feedback struct {
+	SummaryID int       `json:"summary_id"`
+	StudentID int       `json:"student_id"`
+	Model     string    `json:"model"`
+	Rating    string    `json:"rating"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// createSummaryFeedback handles POST /students/{id}/summary/feedback to rate a summary with
+// thumbs up/down, defaulting to the student's latest summary when no summary_id is given
+func createSummaryFeedback(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var feedback Feedback
+	if err := json.NewDecoder(r.Body).Decode(&feedback); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	feedback.Rating = strings.ToLower(feedback.Rating)
+	if feedback.Rating != "up" && feedback.Rating != "down" {
+		http.Error(w, "Rating must be up or down", http.StatusBadRequest)
+		return
+	}
+
+	summariesMu.Lock()
+	history := summaryLog[id]
+	var summary *Summary
+	for i := len(history) - 1; i >= 0; i-- {
+		if feedback.SummaryID == 0 || history[i].ID == feedback.SummaryID {
+			summary = &history[i]
+			break
+		}
+	}
+	summariesMu.Unlock()
+	if summary == nil {
+		http.Error(w, "Summary not found", http.StatusNotFound)
+		return
+	}
+
+	feedback.SummaryID = summary.ID
+	feedback.StudentID = id
+	feedback.Model = summary.Model
+	feedback.CreatedAt = time.Now()
+
+	summaryFeedbackMu.Lock()
+	summaryFeedback[summary.ID] = append(summaryFeedback[summary.ID], feedback)
+	summaryFeedbackMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(feedback)
+}
+
+// getFeedbackReport handles GET /admin/summaries/feedback to aggregate ratings per model
+func getFeedbackReport(w http.ResponseWriter, r *http.Request) {
+	type modelReport struct {
+		Model     string     `json:"model"`
+		Up        int        `json:"up"`
+		Down      int        `json:"down"`
+		Approval  float64    `json:"approval"`
+		Negatives []Feedback `json:"negative_comments"`
+	}
+
+	reports := make(map[string]*modelReport)
+	summaryFeedbackMu.Lock()
+	for _, entries := range summaryFeedback {
+		for _, feedback := range entries {
+			report, ok := reports[feedback.Model]
+			if !ok {
+				report = &modelReport{Model: feedback.Model, Negatives: []Feedback{}}
+				reports[feedback.Model] = report
+			}
+			if feedback.Rating == "up" {
+				report.Up++
+			} else {
+				report.Down++
+				if feedback.Comment != "" {
+					report.Negatives = append(report.Negatives, feedback)
+				}
+			}
+		}
+	}
+	summaryFeedbackMu.Unlock()
+
+	result := []modelReport{}
+	for _, report := range reports {
+		report.Approval = float64(report.Up) / float64(report.Up+report.Down)
+		sort.Slice(report.Negatives, func(i, j int) bool {
+			return report.Negatives[i].CreatedAt.After(report.Negatives[j].CreatedAt)
+		})
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Model < result[j].Model })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
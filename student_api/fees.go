@@ -0,0 +1,447 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Amounts are integers in minor currency units (e.g. cents) of FEES_CURRENCY
+var (
+	invoices      = make(map[int]*Invoice)
+	payments      = make(map[int]*Payment)
+	feesMu        sync.Mutex
+	nextInvoiceID int
+	nextPaymentID int
+)
+
+// Invoice struct to hold a fee charged to a student
+type Invoice struct {
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	Description string    `json:"description"`
+	Amount      int64     `json:"amount"`
+	Discount    int64     `json:"discount,omitempty"`
+	Currency    string    `json:"currency"`
+	DueDate     string    `json:"due_date"`
+	IssuedAt    time.Time `json:"issued_at"`
+	Paid        int64     `json:"paid"`
+	Outstanding int64     `json:"outstanding"`
+	Status      string    `json:"status"`
+}
+
+// Payment struct to hold money received from a student; payments without an invoice_id are
+// applied to the student's oldest outstanding invoices
+type Payment struct {
+	ID        int       `json:"id"`
+	StudentID int       `json:"student_id"`
+	InvoiceID int       `json:"invoice_id,omitempty"`
+	Amount    int64     `json:"amount"`
+	Currency  string    `json:"currency"`
+	Method    string    `json:"method,omitempty"`
+	Reference string    `json:"reference,omitempty"`
+	PaidAt    time.Time `json:"paid_at"`
+}
+
+// Balance struct to hold a student's account position
+type Balance struct {
+	StudentID int       `json:"student_id"`
+	Currency  string    `json:"currency"`
+	Invoiced  int64     `json:"invoiced"`
+	Paid      int64     `json:"paid"`
+	Balance   int64     `json:"balance"`
+	Overdue   int64     `json:"overdue"`
+	Invoices  []Invoice `json:"invoices"`
+}
+
+// studentLedger allocates a student's payments to their invoices and totals the account as of
+// today; the caller must hold feesMu
+func (s *Server) studentLedger(studentID int, today string) Balance {
+	balance := Balance{StudentID: studentID, Currency: s.config.FeesCurrency, Invoices: []Invoice{}}
+
+	for _, invoice := range invoices {
+		if invoice.StudentID == studentID {
+			inv := *invoice
+			inv.Paid = 0
+			balance.Invoices = append(balance.Invoices, inv)
+			balance.Invoiced += inv.Amount
+		}
+	}
+	sort.Slice(balance.Invoices, func(i, j int) bool {
+		a, b := balance.Invoices[i], balance.Invoices[j]
+		if a.DueDate != b.DueDate {
+			return a.DueDate < b.DueDate
+		}
+		return a.ID < b.ID
+	})
+	index := make(map[int]int)
+	for i, inv := range balance.Invoices {
+		index[inv.ID] = i
+	}
+
+	// Targeted payments first, with any excess joining the unallocated pool
+	var pool int64
+	for _, payment := range payments {
+		if payment.StudentID != studentID {
+			continue
+		}
+		balance.Paid += payment.Amount
+		i, ok := index[payment.InvoiceID]
+		if !ok {
+			pool += payment.Amount
+			continue
+		}
+		applied := min(payment.Amount, balance.Invoices[i].Amount-balance.Invoices[i].Paid)
+		balance.Invoices[i].Paid += applied
+		pool += payment.Amount - applied
+	}
+	for i := range balance.Invoices {
+		inv := &balance.Invoices[i]
+		applied := min(pool, inv.Amount-inv.Paid)
+		inv.Paid += applied
+		pool -= applied
+
+		inv.Outstanding = inv.Amount - inv.Paid
+		switch {
+		case inv.Outstanding == 0:
+			inv.Status = "paid"
+		case inv.DueDate < today:
+			inv.Status = "overdue"
+			balance.Overdue += inv.Outstanding
+		default:
+			inv.Status = "open"
+		}
+	}
+
+	balance.Balance = balance.Invoiced - balance.Paid
+	return balance
+}
+
+// today returns the current date in the format used for due dates
+func today() string {
+	return time.Now().Format(dateLayout)
+}
+
+// createInvoice handles POST /students/{id}/invoices to charge a fee to a student; students with
+// linked siblings receive SIBLING_DISCOUNT_PERCENT off the amount
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var invoice Invoice
+	if err := json.NewDecoder(r.Body).Decode(&invoice); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(invoice.Description) == "" || invoice.Amount <= 0 {
+		http.Error(w, "A description and a positive amount are required", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse(dateLayout, invoice.DueDate); err != nil {
+		http.Error(w, "due_date must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkCurrency(invoice.Currency); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	if s.config.SiblingDiscountPercent > 0 && siblingCount(id) > 0 {
+		invoice.Discount = invoice.Amount * int64(s.config.SiblingDiscountPercent) / 100
+		invoice.Amount -= invoice.Discount
+	}
+
+	feesMu.Lock()
+	nextInvoiceID++
+	invoice.ID = nextInvoiceID
+	invoice.StudentID = id
+	invoice.Currency = s.config.FeesCurrency
+	invoice.IssuedAt = time.Now()
+	invoices[invoice.ID] = &invoice
+	ledger := s.studentLedger(id, today())
+	feesMu.Unlock()
+
+	for _, inv := range ledger.Invoices {
+		if inv.ID == invoice.ID {
+			invoice = inv
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invoice)
+}
+
+// getInvoices handles GET /students/{id}/invoices?status= to list a student's invoices with the
+// payments applied to them
+func (s *Server) getInvoices(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	status := r.URL.Query().Get("status")
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	feesMu.Lock()
+	ledger := s.studentLedger(id, today())
+	feesMu.Unlock()
+
+	list := []Invoice{}
+	for _, invoice := range ledger.Invoices {
+		if status == "" || invoice.Status == status {
+			list = append(list, invoice)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// createPayment handles POST /students/{id}/payments to record money received from a student
+func (s *Server) createPayment(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var payment Payment
+	if err := json.NewDecoder(r.Body).Decode(&payment); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if payment.Amount <= 0 {
+		http.Error(w, "A positive amount is required", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkCurrency(payment.Currency); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	feesMu.Lock()
+	defer feesMu.Unlock()
+
+	if payment.InvoiceID != 0 {
+		invoice, exists := invoices[payment.InvoiceID]
+		if !exists || invoice.StudentID != id {
+			http.Error(w, "Invoice not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	nextPaymentID++
+	payment.ID = nextPaymentID
+	payment.StudentID = id
+	payment.Currency = s.config.FeesCurrency
+	payment.PaidAt = time.Now()
+	payments[payment.ID] = &payment
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+// getPayments handles GET /students/{id}/payments to list a student's payments
+func (s *Server) getPayments(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	feesMu.Lock()
+	list := []Payment{}
+	for _, payment := range payments {
+		if payment.StudentID == id {
+			list = append(list, *payment)
+		}
+	}
+	feesMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getBalance handles GET /students/{id}/balance to return a student's invoiced, paid, outstanding
+// and overdue amounts
+func (s *Server) getBalance(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	feesMu.Lock()
+	balance := s.studentLedger(id, today())
+	feesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+// getOverdueFees handles GET /reports/fees/overdue to list students with overdue invoices
+func (s *Server) getOverdueFees(w http.ResponseWriter, r *http.Request) {
+	s.store.Lock()
+	defer s.store.Unlock()
+	feesMu.Lock()
+	defer feesMu.Unlock()
+
+	type overdueStudent struct {
+		StudentID int       `json:"student_id"`
+		Name      string    `json:"name"`
+		Overdue   int64     `json:"overdue"`
+		Invoices  []Invoice `json:"invoices"`
+	}
+	report := []overdueStudent{}
+	for _, studentID := range invoicedStudents() {
+		ledger := s.studentLedger(studentID, today())
+		if ledger.Overdue == 0 {
+			continue
+		}
+		student, _ := s.store.Get(studentID)
+		entry := overdueStudent{StudentID: studentID, Name: student.Name, Overdue: ledger.Overdue, Invoices: []Invoice{}}
+		for _, invoice := range ledger.Invoices {
+			if invoice.Status == "overdue" {
+				entry.Invoices = append(entry.Invoices, invoice)
+			}
+		}
+		report = append(report, entry)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Overdue > report[j].Overdue })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"currency": s.config.FeesCurrency, "students": report})
+}
+
+// exportFees handles GET /reports/fees/export to download every invoice and payment as CSV for
+// the finance office, with amounts in major currency units
+func (s *Server) exportFees(w http.ResponseWriter, r *http.Request) {
+	s.store.Lock()
+	defer s.store.Unlock()
+	feesMu.Lock()
+	defer feesMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="fees-`+today()+`.csv"`)
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"type", "id", "student_id", "student_name", "description", "amount", "currency", "date", "due_date", "invoice_id", "status", "method", "reference"})
+	for _, studentID := range invoicedStudents() {
+		student, _ := s.store.Get(studentID)
+		name := csvText(student.Name)
+		ledger := s.studentLedger(studentID, today())
+		for _, invoice := range ledger.Invoices {
+			out.Write([]string{"invoice", strconv.Itoa(invoice.ID), strconv.Itoa(studentID), name, csvText(invoice.Description),
+				formatAmount(invoice.Amount), invoice.Currency, invoice.IssuedAt.Format(dateLayout), invoice.DueDate, "", invoice.Status, "", ""})
+		}
+
+		var list []*Payment
+		for _, payment := range payments {
+			if payment.StudentID == studentID {
+				list = append(list, payment)
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		for _, payment := range list {
+			invoiceID := ""
+			if payment.InvoiceID != 0 {
+				invoiceID = strconv.Itoa(payment.InvoiceID)
+			}
+			out.Write([]string{"payment", strconv.Itoa(payment.ID), strconv.Itoa(studentID), name, "",
+				formatAmount(payment.Amount), payment.Currency, payment.PaidAt.Format(dateLayout), "", invoiceID, "", csvText(payment.Method), csvText(payment.Reference)})
+		}
+	}
+	out.Flush()
+}
+
+// invoicedStudents returns the IDs of students with invoices or payments in ascending order; the
+// caller must hold feesMu
+func invoicedStudents() []int {
+	seen := make(map[int]bool)
+	for _, invoice := range invoices {
+		seen[invoice.StudentID] = true
+	}
+	for _, payment := range payments {
+		seen[payment.StudentID] = true
+	}
+	ids := make([]int, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// csvText neutralizes free-text cells that a spreadsheet would otherwise evaluate as formulas
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// checkCurrency rejects amounts in a currency other than FEES_CURRENCY
+func (s *Server) checkCurrency(currency string) error {
+	if currency != "" && !strings.EqualFold(currency, s.config.FeesCurrency) {
+		return errors.New("Amounts must be in " + s.config.FeesCurrency)
+	}
+	return nil
+}
+
+// formatAmount renders minor units as a decimal amount with two places
+func formatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// deleteFees drops a deleted student's invoices and payments
+func deleteFees(id int) {
+	feesMu.Lock()
+	defer feesMu.Unlock()
+	for invoiceID, invoice := range invoices {
+		if invoice.StudentID == id {
+			delete(invoices, invoiceID)
+		}
+	}
+	for paymentID, payment := range payments {
+		if payment.StudentID == id {
+			delete(payments, paymentID)
+		}
+	}
+}
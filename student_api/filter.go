@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// StudentFilter struct to hold criteria for selecting a set of students
+type StudentFilter struct {
+	Name   string `json:"name,omitempty"`
+	MinAge int    `json:"min_age,omitempty"`
+	MaxAge int    `json:"max_age,omitempty"`
+	// Gender and Nationality match exactly; City matches the address city case-insensitively
+	Gender      string `json:"gender,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+	City        string `json:"city,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	Group       int    `json:"group,omitempty"`
+	GradeLevel  int    `json:"grade_level,omitempty"`
+	// HasScholarship selects students with (true) or without (false) a scholarship award
+	HasScholarship *bool `json:"has_scholarship,omitempty"`
+	// Alumni selects graduated (true) or current (false) students
+	Alumni *bool `json:"alumni,omitempty"`
+	// GraduationYear matches alumni who graduated in the given year
+	GraduationYear int `json:"graduation_year,omitempty"`
+	// Near and RadiusKM select students whose geocoded address lies within the radius
+	Near     *GeoPoint `json:"near,omitempty"`
+	RadiusKM float64   `json:"radius_km,omitempty"`
+	// Verified selects students whose email is (true) or is not (false) verified
+	Verified *bool `json:"verified,omitempty"`
+	// Custom matches custom field values by name, case-insensitively
+	Custom map[string]string `json:"custom,omitempty"`
+	// Computed matches computed field values by name, case-insensitively
+	Computed map[string]string `json:"computed,omitempty"`
+}
+
+// matches reports whether a student satisfies every criterion set on the filter
+func (f StudentFilter) matches(student Student) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(student.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.MinAge > 0 && student.Age() < f.MinAge {
+		return false
+	}
+	if f.MaxAge > 0 && student.Age() > f.MaxAge {
+		return false
+	}
+	if f.Gender != "" && !strings.EqualFold(student.Gender, f.Gender) {
+		return false
+	}
+	if f.Nationality != "" && !strings.EqualFold(student.Nationality, f.Nationality) {
+		return false
+	}
+	if f.Status != "" && student.Status != f.Status {
+		return false
+	}
+	if f.Tag != "" && !containsString(student.Tags, normalizeTag(f.Tag)) {
+		return false
+	}
+	if f.Group != 0 {
+		groupsMu.Lock()
+		member := inGroup(f.Group, student.ID)
+		groupsMu.Unlock()
+		if !member {
+			return false
+		}
+	}
+	if f.HasScholarship != nil && hasScholarship(student.ID) != *f.HasScholarship {
+		return false
+	}
+	if f.GradeLevel != 0 && student.GradeLevel != f.GradeLevel {
+		return false
+	}
+	if f.Alumni != nil && student.Alumni != *f.Alumni {
+		return false
+	}
+	if f.GraduationYear != 0 && student.GraduationYear != f.GraduationYear {
+		return false
+	}
+	if f.Near != nil {
+		if student.Address == nil || student.Address.Location == nil || distanceKM(*f.Near, *student.Address.Location) > f.RadiusKM {
+			return false
+		}
+	}
+	if f.Verified != nil && student.EmailVerified != *f.Verified {
+		return false
+	}
+	for name, value := range f.Custom {
+		if !strings.EqualFold(customValueText(student.Custom[name]), value) {
+			return false
+		}
+	}
+	if len(f.Computed) > 0 {
+		values := computedValues(student)
+		for name, value := range f.Computed {
+			if !strings.EqualFold(customValueText(values[name]), value) {
+				return false
+			}
+		}
+	}
+	if f.City != "" && (student.Address == nil || !strings.EqualFold(student.Address.City, f.City)) {
+		return false
+	}
+	return true
+}
+
+// filterStudents returns copies of the students matching the filter; the caller must hold the student store
+func (s *Server) filterStudents(f StudentFilter) []Student {
+	var matched []Student
+	for _, student := range s.store.List() {
+		if f.matches(student) {
+			matched = append(matched, student)
+		}
+	}
+	return matched
+}
+
+// studentFilterFromQuery builds a filter from the ?name=, ?min_age=, ?max_age=, ?gender=,
+// ?nationality=, ?city=, ?status=, ?tag=, ?group=, ?grade_level=, ?has_scholarship=, ?alumni=,
+// ?graduation_year=, ?near=lat,long with ?radius= (km, default 5), ?verified=, ?custom.<field>=
+// and ?computed.<field>= query parameters
+func studentFilterFromQuery(query url.Values) (StudentFilter, error) {
+	f := StudentFilter{
+		Name:        query.Get("name"),
+		Gender:      query.Get("gender"),
+		Nationality: query.Get("nationality"),
+		City:        query.Get("city"),
+		Status:      query.Get("status"),
+		Tag:         query.Get("tag"),
+	}
+	for key, target := range map[string]*int{"min_age": &f.MinAge, "max_age": &f.MaxAge, "group": &f.Group, "grade_level": &f.GradeLevel, "graduation_year": &f.GraduationYear} {
+		if value := query.Get(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return f, fmt.Errorf("Invalid %s", key)
+			}
+			*target = n
+		}
+	}
+	for key, target := range map[string]**bool{"has_scholarship": &f.HasScholarship, "alumni": &f.Alumni, "verified": &f.Verified} {
+		if value := query.Get(key); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return f, fmt.Errorf("Invalid %s", key)
+			}
+			*target = &b
+		}
+	}
+	if value := query.Get("near"); value != "" {
+		near, err := parseGeoPoint(value)
+		if err != nil {
+			return f, err
+		}
+		f.Near, f.RadiusKM = &near, 5
+		if radius := query.Get("radius"); radius != "" {
+			if f.RadiusKM, err = strconv.ParseFloat(radius, 64); err != nil || f.RadiusKM <= 0 {
+				return f, fmt.Errorf("Invalid radius")
+			}
+		}
+	}
+	for key := range query {
+		if name := strings.TrimPrefix(key, "custom."); name != key {
+			if f.Custom == nil {
+				f.Custom = make(map[string]string)
+			}
+			f.Custom[name] = query.Get(key)
+		}
+		if name := strings.TrimPrefix(key, "computed."); name != key {
+			if f.Computed == nil {
+				f.Computed = make(map[string]string)
+			}
+			f.Computed[name] = query.Get(key)
+		}
+	}
+	return f, nil
+}
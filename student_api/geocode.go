@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrAddressNotFound is returned when the geocoder cannot place an address
+var ErrAddressNotFound = errors.New("address not found")
+
+// errAddressReplaced stops a geocoding result from overwriting an address changed in the meantime
+var errAddressReplaced = errors.New("address replaced while it was geocoded")
+
+// Geocoder is implemented by address lookup backends; Geocode returns the address in canonical
+// form together with its location
+type Geocoder interface {
+	Geocode(ctx context.Context, address Address) (Address, error)
+}
+
+// newGeocoder returns the backend selected by GEOCODER, or nil when geocoding is disabled
+func newGeocoder(cfg Config) Geocoder {
+	switch cfg.Geocoder {
+	case "nominatim":
+		return &NominatimGeocoder{BaseURL: cfg.GeocoderURL, UserAgent: cfg.GeocoderUserAgent, Client: &http.Client{Timeout: cfg.LLMTimeout}}
+	default:
+		return nil
+	}
+}
+
+// NominatimGeocoder talks to an OpenStreetMap Nominatim search API
+type NominatimGeocoder struct {
+	BaseURL   string
+	UserAgent string
+	Client    *http.Client
+}
+
+// Geocode looks up an address, filling in the city, state, postal code and country Nominatim
+// reports; the street lines are kept as entered
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address Address) (Address, error) {
+	query := url.Values{"format": {"jsonv2"}, "addressdetails": {"1"}, "limit": {"1"}}
+	query.Set("street", strings.TrimSpace(address.Line1))
+	query.Set("city", address.City)
+	if address.State != "" {
+		query.Set("state", address.State)
+	}
+	if address.PostalCode != "" {
+		query.Set("postalcode", address.PostalCode)
+	}
+	if address.Country != "" {
+		query.Set("countrycodes", strings.ToLower(address.Country))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(g.BaseURL, "/")+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return address, err
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return address, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return address, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return address, fmt.Errorf("geocoder returned %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Lat     string            `json:"lat"`
+		Lon     string            `json:"lon"`
+		Address map[string]string `json:"address"`
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return address, err
+	}
+	if len(results) == 0 {
+		return address, ErrAddressNotFound
+	}
+
+	result := results[0]
+	lat, latErr := strconv.ParseFloat(result.Lat, 64)
+	lon, lonErr := strconv.ParseFloat(result.Lon, 64)
+	if latErr != nil || lonErr != nil {
+		return address, errors.New("geocoder returned an invalid location")
+	}
+	address.Location = &GeoPoint{Latitude: lat, Longitude: lon}
+	for _, key := range []string{"city", "town", "village"} {
+		if city := result.Address[key]; city != "" {
+			address.City = city
+			break
+		}
+	}
+	if state := result.Address["state"]; state != "" {
+		address.State = state
+	}
+	if postcode := result.Address["postcode"]; postcode != "" {
+		address.PostalCode = postcode
+	}
+	if country := result.Address["country_code"]; country != "" {
+		address.Country = strings.ToUpper(country)
+	}
+	return address, nil
+}
+
+// geocodeStudentAddress normalizes a student's address in the background, storing the result
+// only if the address has not changed in the meantime
+func (s *Server) geocodeStudentAddress(id int, address Address) {
+	if s.geocoder == nil {
+		return
+	}
+
+	normalized, err := s.geocoder.Geocode(context.Background(), address)
+	if err != nil {
+		log.Printf("Error geocoding address of student %d: %v", id, err)
+		return
+	}
+
+	_, err = s.students.Change(id, func(student *Student) error {
+		if student.Address == nil || !reflect.DeepEqual(*student.Address, address) {
+			return errAddressReplaced
+		}
+		student.Address = &normalized
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrStudentNotFound) && !errors.Is(err, errAddressReplaced) {
+		log.Printf("Error storing geocoded address of student %d: %v", id, err)
+	}
+}
+
+// distanceKM returns the great-circle distance between two points in kilometres
+func distanceKM(a, b GeoPoint) float64 {
+	const earthRadiusKM = 6371
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(h))
+}
+
+// parseGeoPoint parses a "lat,long" pair
+func parseGeoPoint(value string) (GeoPoint, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return GeoPoint{}, errors.New("Invalid near, expected lat,long")
+	}
+	lat, latErr := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, lonErr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if latErr != nil || lonErr != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return GeoPoint{}, errors.New("Invalid near, expected lat,long")
+	}
+	return GeoPoint{Latitude: lat, Longitude: lon}, nil
+}
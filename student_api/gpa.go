@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+)
+
+// gradingScales holds the built-in scales plus any loaded from GRADING_SCALES_FILE
+var gradingScales = map[string]GradingScale{
+	"4.0": {Name: "4.0", Max: 4, Bands: []GradeBand{
+		{MinScore: 93, Points: 4.0, Letter: "A"}, {MinScore: 90, Points: 3.7, Letter: "A-"},
+		{MinScore: 87, Points: 3.3, Letter: "B+"}, {MinScore: 83, Points: 3.0, Letter: "B"}, {MinScore: 80, Points: 2.7, Letter: "B-"},
+		{MinScore: 77, Points: 2.3, Letter: "C+"}, {MinScore: 73, Points: 2.0, Letter: "C"}, {MinScore: 70, Points: 1.7, Letter: "C-"},
+		{MinScore: 67, Points: 1.3, Letter: "D+"}, {MinScore: 60, Points: 1.0, Letter: "D"}, {MinScore: 0, Points: 0, Letter: "F"},
+	}},
+	"10": {Name: "10", Max: 10, Bands: []GradeBand{
+		{MinScore: 90, Points: 10, Letter: "O"}, {MinScore: 80, Points: 9, Letter: "A+"}, {MinScore: 70, Points: 8, Letter: "A"},
+		{MinScore: 60, Points: 7, Letter: "B+"}, {MinScore: 50, Points: 6, Letter: "B"}, {MinScore: 45, Points: 5, Letter: "C"},
+		{MinScore: 40, Points: 4, Letter: "P"}, {MinScore: 0, Points: 0, Letter: "F"},
+	}},
+	// The percentage scale has no bands: a course's points are its score
+	"percentage": {Name: "percentage", Max: 100},
+}
+
+// GradingScale struct to hold the mapping from percentage scores to grade points
+type GradingScale struct {
+	Name string  `json:"name"`
+	Max  float64 `json:"max"`
+	// Bands are ordered from the highest MinScore down
+	Bands []GradeBand `json:"bands,omitempty"`
+}
+
+// GradeBand struct to hold the points awarded for scores at or above MinScore
+type GradeBand struct {
+	MinScore float64 `json:"min_score"`
+	Points   float64 `json:"points"`
+	Letter   string  `json:"letter"`
+}
+
+// GPAReport struct to hold the response of GET /students/{id}/gpa
+type GPAReport struct {
+	StudentID int         `json:"student_id"`
+	Scale     string      `json:"scale"`
+	Max       float64     `json:"max"`
+	Term      string      `json:"term,omitempty"`
+	GPA       float64     `json:"gpa"`
+	Credits   float64     `json:"credits"`
+	Courses   []GPACourse `json:"courses"`
+}
+
+// GPACourse struct to hold one course's contribution to a GPA
+type GPACourse struct {
+	CourseCode string  `json:"course_code"`
+	Term       string  `json:"term"`
+	Score      float64 `json:"score"`
+	Credits    float64 `json:"credits"`
+	Points     float64 `json:"points"`
+	Letter     string  `json:"letter,omitempty"`
+}
+
+// loadGradingScales adds the scales defined in GRADING_SCALES_FILE, a JSON array of GradingScale,
+// replacing built-in scales with the same name, and checks that GPA_DEFAULT_SCALE exists
+func (s *Server) loadGradingScales() error {
+	if s.config.GradingScalesFile != "" {
+		if err := readGradingScales(s.config.GradingScalesFile); err != nil {
+			return err
+		}
+	}
+	if _, ok := gradingScales[s.config.DefaultGradingScale]; !ok {
+		return fmt.Errorf("unknown default grading scale %q", s.config.DefaultGradingScale)
+	}
+	return nil
+}
+
+// readGradingScales loads custom grading scales from a JSON file
+func readGradingScales(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var scales []GradingScale
+	if err := json.Unmarshal(data, &scales); err != nil {
+		return err
+	}
+	for _, scale := range scales {
+		if scale.Name == "" || scale.Max <= 0 || len(scale.Bands) == 0 {
+			return fmt.Errorf("grading scale %q needs a name, a positive max and at least one band", scale.Name)
+		}
+		sort.Slice(scale.Bands, func(i, j int) bool { return scale.Bands[i].MinScore > scale.Bands[j].MinScore })
+		gradingScales[scale.Name] = scale
+	}
+	return nil
+}
+
+// points returns the grade points and letter a percentage score earns on the scale
+func (s GradingScale) points(score float64) (float64, string) {
+	if len(s.Bands) == 0 {
+		return score, ""
+	}
+	for _, band := range s.Bands {
+		if score >= band.MinScore {
+			return band.Points, band.Letter
+		}
+	}
+	return 0, ""
+}
+
+// getStudentGPA handles GET /students/{id}/gpa?scale=4.0&term= to compute the credit-weighted
+// GPA of a student's grades; zero-credit courses are listed but do not count
+func (s *Server) getStudentGPA(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	scaleName := r.URL.Query().Get("scale")
+	if scaleName == "" {
+		scaleName = s.config.DefaultGradingScale
+	}
+	scale, ok := gradingScales[scaleName]
+	if !ok {
+		http.Error(w, "Unknown grading scale", http.StatusBadRequest)
+		return
+	}
+	term := r.URL.Query().Get("term")
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	report := GPAReport{StudentID: id, Scale: scale.Name, Max: scale.Max, Term: term, Courses: []GPACourse{}}
+	weighted := 0.0
+
+	coursesMu.Lock()
+	gradesMu.Lock()
+	for _, grade := range studentGrades(id) {
+		if term != "" && grade.Term != term {
+			continue
+		}
+		course := courses[grade.CourseID]
+		points, letter := scale.points(grade.Score)
+		report.Courses = append(report.Courses, GPACourse{
+			CourseCode: course.Code,
+			Term:       grade.Term,
+			Score:      grade.Score,
+			Credits:    course.Credits,
+			Points:     points,
+			Letter:     letter,
+		})
+		weighted += points * course.Credits
+		report.Credits += course.Credits
+	}
+	gradesMu.Unlock()
+	coursesMu.Unlock()
+
+	if report.Credits > 0 {
+		report.GPA = math.Round(weighted/report.Credits*100) / 100
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
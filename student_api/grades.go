@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// gradesMu guards grades; when several locks are needed take the student store, then coursesMu, then gradesMu
+var (
+	grades      = make(map[int]*Grade)
+	gradesMu    sync.Mutex
+	nextGradeID int
+)
+
+// Grade struct to hold a student's percentage score for a course in a term
+type Grade struct {
+	ID         int              `json:"id"`
+	StudentID  int              `json:"student_id"`
+	CourseID   int              `json:"course_id"`
+	Term       string           `json:"term"`
+	Score      float64          `json:"score"`
+	Comment    string           `json:"comment,omitempty"`
+	RecordedBy string           `json:"recorded_by"`
+	RecordedAt time.Time        `json:"recorded_at"`
+	Amendments []GradeAmendment `json:"amendments"`
+}
+
+// GradeAmendment struct to hold one audited change to a submitted grade
+type GradeAmendment struct {
+	PreviousScore float64   `json:"previous_score"`
+	Score         float64   `json:"score"`
+	Reason        string    `json:"reason"`
+	AmendedBy     string    `json:"amended_by"`
+	AmendedAt     time.Time `json:"amended_at"`
+}
+
+// Transcript struct to hold a student's full academic history grouped by term
+type Transcript struct {
+	Student Student          `json:"student"`
+	Terms   []TranscriptTerm `json:"terms"`
+}
+
+// TranscriptTerm struct to hold the courses graded in one term
+type TranscriptTerm struct {
+	Term    string            `json:"term"`
+	Courses []TranscriptEntry `json:"courses"`
+}
+
+// TranscriptEntry struct to hold one graded course on a transcript
+type TranscriptEntry struct {
+	GradeID    int     `json:"grade_id"`
+	CourseID   int     `json:"course_id"`
+	CourseCode string  `json:"course_code"`
+	Title      string  `json:"title"`
+	Credits    float64 `json:"credits"`
+	Score      float64 `json:"score"`
+	Amended    bool    `json:"amended"`
+}
+
+// validScore reports whether a score is a percentage
+func validScore(score float64) bool {
+	return score >= 0 && score <= 100
+}
+
+// createGrade handles POST /students/{id}/grades to submit a grade for a course the student is
+// enrolled in; each course can be graded once per term, which defaults to the current term
+func (s *Server) createGrade(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var grade Grade
+	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	term, err := resolveTerm(strings.TrimSpace(grade.Term))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	grade.Term = term
+	if grade.CourseID <= 0 || grade.Term == "" || !validScore(grade.Score) {
+		http.Error(w, "course_id, term and a score between 0 and 100 are required", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if _, exists := s.store.Get(id); !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	coursesMu.Lock()
+	_, courseExists := courses[grade.CourseID]
+	_, enrolled := enrollments[grade.CourseID][id]
+	coursesMu.Unlock()
+	if !courseExists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+	if !enrolled {
+		http.Error(w, "Student is not enrolled in this course", http.StatusConflict)
+		return
+	}
+
+	gradesMu.Lock()
+	defer gradesMu.Unlock()
+	for _, existing := range grades {
+		if existing.StudentID == id && existing.CourseID == grade.CourseID && existing.Term == grade.Term {
+			http.Error(w, "A grade for this course and term already exists; amend it instead", http.StatusConflict)
+			return
+		}
+	}
+
+	nextGradeID++
+	grade.ID = nextGradeID
+	grade.StudentID = id
+	grade.RecordedBy = callerID(r.Context())
+	grade.RecordedAt = time.Now()
+	grade.Amendments = []GradeAmendment{}
+	grades[grade.ID] = &grade
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(grade)
+}
+
+// getStudentGrades handles GET /students/{id}/grades?term= to list a student's grades
+func (s *Server) getStudentGrades(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	term := r.URL.Query().Get("term")
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	gradesMu.Lock()
+	list := []Grade{}
+	for _, grade := range studentGrades(id) {
+		if term == "" || grade.Term == term {
+			list = append(list, grade)
+		}
+	}
+	gradesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// amendGrade handles PUT /students/{id}/grades/{grade} to change a submitted score; a reason is
+// required and every change is kept in the grade's amendment history
+func amendGrade(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	gradeID, err := strconv.Atoi(mux.Vars(r)["grade"])
+	if err != nil {
+		http.Error(w, "Invalid grade ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Score  *float64 `json:"score"`
+		Reason string   `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if req.Score == nil || !validScore(*req.Score) || strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "A score between 0 and 100 and a reason are required", http.StatusBadRequest)
+		return
+	}
+
+	gradesMu.Lock()
+	defer gradesMu.Unlock()
+
+	grade, exists := grades[gradeID]
+	if !exists || grade.StudentID != id {
+		http.Error(w, "Grade not found", http.StatusNotFound)
+		return
+	}
+
+	amendment := GradeAmendment{
+		PreviousScore: grade.Score,
+		Score:         *req.Score,
+		Reason:        strings.TrimSpace(req.Reason),
+		AmendedBy:     callerID(r.Context()),
+		AmendedAt:     time.Now(),
+	}
+	grade.Amendments = append(grade.Amendments, amendment)
+	grade.Score = amendment.Score
+	log.Printf("Grade %d for student %d amended from %.2f to %.2f by %s: %s",
+		grade.ID, id, amendment.PreviousScore, amendment.Score, amendment.AmendedBy, amendment.Reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(grade)
+}
+
+// getTranscript handles GET /students/{id}/transcript to return a student's graded courses by term
+func (s *Server) getTranscript(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildTranscript(student))
+}
+
+// buildTranscript groups a student's grades by term, in the order the terms were first graded
+func buildTranscript(student Student) Transcript {
+	coursesMu.Lock()
+	gradesMu.Lock()
+	list := studentGrades(student.ID)
+	transcript := Transcript{Student: student, Terms: []TranscriptTerm{}}
+	termIndex := make(map[string]int)
+	for _, grade := range list {
+		course := courses[grade.CourseID]
+		entry := TranscriptEntry{
+			GradeID:    grade.ID,
+			CourseID:   grade.CourseID,
+			CourseCode: course.Code,
+			Title:      course.Title,
+			Credits:    course.Credits,
+			Score:      grade.Score,
+			Amended:    len(grade.Amendments) > 0,
+		}
+		i, ok := termIndex[grade.Term]
+		if !ok {
+			i = len(transcript.Terms)
+			termIndex[grade.Term] = i
+			transcript.Terms = append(transcript.Terms, TranscriptTerm{Term: grade.Term})
+		}
+		transcript.Terms[i].Courses = append(transcript.Terms[i].Courses, entry)
+	}
+	gradesMu.Unlock()
+	coursesMu.Unlock()
+	return transcript
+}
+
+// studentGrades returns copies of a student's grades in submission order; the caller must hold gradesMu
+func studentGrades(id int) []Grade {
+	list := []Grade{}
+	for _, grade := range grades {
+		if grade.StudentID == id {
+			list = append(list, *grade)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// courseHasGrades reports whether any grade refers to a course; the caller must hold gradesMu
+func courseHasGrades(courseID int) bool {
+	for _, grade := range grades {
+		if grade.CourseID == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteGrades drops a deleted student's grades
+func deleteGrades(id int) {
+	gradesMu.Lock()
+	defer gradesMu.Unlock()
+	for gradeID, grade := range grades {
+		if grade.StudentID == id {
+			delete(grades, gradeID)
+		}
+	}
+}
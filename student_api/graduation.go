@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"student_api/student_api/internal/handlers"
+)
+
+// defaultCertificateTemplate is used when no CERTIFICATE_TEMPLATE_FILE is configured; paragraphs
+// are separated by blank lines
+const defaultCertificateTemplate = `This is to certify that
+
+{{.Student.Name}}
+
+has completed all requirements of the program, earning {{printf "%.1f" .Credits}} credits, and is hereby awarded this certificate of graduation.
+
+Issued on {{.IssuedAt.Format "January 2, 2006"}}
+
+Certificate number {{.Number}}`
+
+var (
+	certificateTemplate = template.Must(template.New("certificate").Parse(defaultCertificateTemplate))
+	certificates        = make(map[int]Certificate)
+	certificatesMu      sync.Mutex
+)
+
+// Certificate struct to hold the graduation certificate issued to a student
+type Certificate struct {
+	StudentID int       `json:"student_id"`
+	Number    string    `json:"number"`
+	Credits   float64   `json:"credits"`
+	IssuedAt  time.Time `json:"issued_at"`
+	IssuedBy  string    `json:"issued_by"`
+	// Student is the record as it was at graduation, used to render the certificate
+	Student Student `json:"-"`
+}
+
+// errRequirementsUnmet stops a graduation when the student no longer meets the requirements
+var errRequirementsUnmet = errors.New("graduation requirements not met")
+
+// GraduationCheck struct to hold the outcome of checking a student's graduation requirements
+type GraduationCheck struct {
+	StudentID       int      `json:"student_id"`
+	Eligible        bool     `json:"eligible"`
+	Credits         float64  `json:"credits"`
+	RequiredCredits float64  `json:"required_credits"`
+	Outstanding     int64    `json:"outstanding_fees"`
+	Unmet           []string `json:"unmet"`
+}
+
+// loadCertificateTemplate parses the configured certificate template file
+func (s *Server) loadCertificateTemplate() error {
+	if s.config.CertificateTemplateFile == "" {
+		return nil
+	}
+
+	text, err := ioutil.ReadFile(s.config.CertificateTemplateFile)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("certificate").Parse(string(text))
+	if err != nil {
+		return err
+	}
+	certificateTemplate = tmpl
+	return nil
+}
+
+// checkGraduation evaluates a student's graduation requirements: enrolled status, passed credits
+// of at least GRADUATION_MIN_CREDITS (each course counted once, at its best score) and, when
+// GRADUATION_REQUIRE_PAID is set, no outstanding fees; the caller must hold the student store
+func (s *Server) checkGraduation(student Student) GraduationCheck {
+	check := GraduationCheck{StudentID: student.ID, RequiredCredits: s.config.GraduationMinCredits, Unmet: []string{}}
+	if student.Status != StatusEnrolled {
+		check.Unmet = append(check.Unmet, "student status is "+student.Status+", not enrolled")
+	}
+
+	coursesMu.Lock()
+	gradesMu.Lock()
+	best := make(map[int]float64)
+	for _, grade := range studentGrades(student.ID) {
+		if score, seen := best[grade.CourseID]; !seen || grade.Score > score {
+			best[grade.CourseID] = grade.Score
+		}
+	}
+	for courseID, score := range best {
+		if score >= s.config.GraduationPassScore {
+			check.Credits += courses[courseID].Credits
+		}
+	}
+	gradesMu.Unlock()
+	coursesMu.Unlock()
+	if check.Credits < check.RequiredCredits {
+		check.Unmet = append(check.Unmet, fmt.Sprintf("%.1f of %.1f required credits passed", check.Credits, check.RequiredCredits))
+	}
+
+	if s.config.GraduationRequirePaid {
+		feesMu.Lock()
+		check.Outstanding = s.studentLedger(student.ID, today()).Balance
+		feesMu.Unlock()
+		if check.Outstanding > 0 {
+			check.Unmet = append(check.Unmet, "outstanding fees of "+formatAmount(check.Outstanding)+" "+s.config.FeesCurrency)
+		}
+	}
+
+	check.Eligible = len(check.Unmet) == 0
+	return check
+}
+
+// graduateStudent handles POST /students/{id}/graduate to check a student's requirements, move
+// them to graduated and issue a certificate; ?dry_run=true only reports the check, and unmet
+// requirements are answered with 409 and the check as the body
+func (s *Server) graduateStudent(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	if r.URL.Query().Get("dry_run") == "true" {
+		s.store.Lock()
+		student, exists := s.store.Get(id)
+		var check GraduationCheck
+		if exists {
+			check = s.checkGraduation(student)
+		}
+		s.store.Unlock()
+		if !exists {
+			http.Error(w, "Student not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(check)
+		return
+	}
+
+	// The requirements are checked again under the store's lock, as part of the status change
+	var check GraduationCheck
+	issuedBy := callerID(r.Context())
+	student, err := s.students.Change(id, func(student *Student) error {
+		check = s.checkGraduation(*student)
+		if !check.Eligible {
+			return errRequirementsUnmet
+		}
+		graduated, err := transitionStatus(*student, StatusGraduated, "graduation requirements met", issuedBy)
+		*student = graduated
+		return err
+	})
+	switch {
+	case errors.Is(err, errRequirementsUnmet):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(check)
+		return
+	case errors.Is(err, ErrIllegalTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), handlers.StatusCode(err))
+		return
+	}
+
+	now := time.Now()
+	certificate := Certificate{
+		StudentID: id,
+		Number:    fmt.Sprintf("%s-%06d", now.Format("2006"), id),
+		Credits:   check.Credits,
+		IssuedAt:  now,
+		IssuedBy:  issuedBy,
+		Student:   student,
+	}
+	certificatesMu.Lock()
+	certificates[id] = certificate
+	certificatesMu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(certificate)
+}
+
+// getCertificate handles GET /students/{id}/certificate to download a graduate's certificate as
+// a PDF, or its metadata with Accept: application/json
+func getCertificate(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	certificatesMu.Lock()
+	certificate, exists := certificates[id]
+	certificatesMu.Unlock()
+	if !exists {
+		http.Error(w, "Certificate not found", http.StatusNotFound)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(certificate)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := certificateTemplate.Execute(&buf, certificate); err != nil {
+		http.Error(w, "Error rendering certificate: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"certificate-%s.pdf\"", certificate.Number))
+	w.Write(renderPDF("Certificate of Graduation", strings.Split(strings.TrimSpace(buf.String()), "\n\n")))
+}
+
+// deleteCertificate drops a deleted student's certificate
+func deleteCertificate(id int) {
+	certificatesMu.Lock()
+	defer certificatesMu.Unlock()
+	delete(certificates, id)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// mustBuildGraphQLSchema defines the GraphQL types, queries and mutations, exposing students with
+// their enrollments, courses and grades; field names follow the snake_case JSON of the REST routes
+func (s *Server) mustBuildGraphQLSchema() graphql.Schema {
+	courseType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Course",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"code":        &graphql.Field{Type: graphql.String},
+			"title":       &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"credits":     &graphql.Field{Type: graphql.Float},
+			"capacity":    &graphql.Field{Type: graphql.Int},
+			"enrolled":    &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	// course resolves the course an enrollment or grade refers to
+	course := &graphql.Field{
+		Type: courseType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			var id int
+			switch source := p.Source.(type) {
+			case Enrollment:
+				id = source.CourseID
+			case Grade:
+				id = source.CourseID
+			}
+			coursesMu.Lock()
+			defer coursesMu.Unlock()
+			if course, exists := courses[id]; exists {
+				return withEnrolled(course), nil
+			}
+			return nil, nil
+		},
+	}
+
+	enrollmentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Enrollment",
+		Fields: graphql.Fields{
+			"course_id":   &graphql.Field{Type: graphql.Int},
+			"term":        &graphql.Field{Type: graphql.String},
+			"enrolled_at": &graphql.Field{Type: graphql.DateTime},
+			"course":      course,
+		},
+	})
+
+	gradeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Grade",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.Int},
+			"course_id":   &graphql.Field{Type: graphql.Int},
+			"term":        &graphql.Field{Type: graphql.String},
+			"score":       &graphql.Field{Type: graphql.Float},
+			"comment":     &graphql.Field{Type: graphql.String},
+			"recorded_at": &graphql.Field{Type: graphql.DateTime},
+			"course":      course,
+		},
+	})
+
+	studentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Student",
+		Fields: graphql.Fields{
+			"id":              &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":            &graphql.Field{Type: graphql.String},
+			"email":           &graphql.Field{Type: graphql.String},
+			"phone":           &graphql.Field{Type: graphql.String},
+			"date_of_birth":   &graphql.Field{Type: graphql.String},
+			"gender":          &graphql.Field{Type: graphql.String},
+			"nationality":     &graphql.Field{Type: graphql.String},
+			"status":          &graphql.Field{Type: graphql.String},
+			"grade_level":     &graphql.Field{Type: graphql.Int},
+			"tags":            &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"email_verified":  &graphql.Field{Type: graphql.Boolean},
+			"alumni":          &graphql.Field{Type: graphql.Boolean},
+			"graduation_year": &graphql.Field{Type: graphql.Int},
+			"age": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(Student).Age(), nil
+				},
+			},
+			"enrollments": &graphql.Field{
+				Type: graphql.NewList(enrollmentType),
+				Args: graphql.FieldConfigArgument{"term": &graphql.ArgumentConfig{Type: graphql.String}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					term, _ := p.Args["term"].(string)
+					coursesMu.Lock()
+					defer coursesMu.Unlock()
+					list := []Enrollment{}
+					for _, enrollment := range studentEnrollments(p.Source.(Student).ID) {
+						if term == "" || enrollment.Term == term {
+							list = append(list, enrollment)
+						}
+					}
+					return list, nil
+				},
+			},
+			"grades": &graphql.Field{
+				Type: graphql.NewList(gradeType),
+				Args: graphql.FieldConfigArgument{"term": &graphql.ArgumentConfig{Type: graphql.String}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					term, _ := p.Args["term"].(string)
+					id := p.Source.(Student).ID
+					gradesMu.Lock()
+					list := []Grade{}
+					for _, grade := range grades {
+						if grade.StudentID == id && (term == "" || grade.Term == term) {
+							list = append(list, *grade)
+						}
+					}
+					gradesMu.Unlock()
+					sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+					return list, nil
+				},
+			},
+		},
+	})
+
+	studentInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "StudentInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"email":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"phone":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"date_of_birth": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"gender":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"nationality":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"age":           &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"status":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"grade_level":   &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"tags":          &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.String)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"student": &graphql.Field{
+				Type: studentType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s.store.Lock()
+					defer s.store.Unlock()
+					if student, exists := s.store.Get(p.Args["id"].(int)); exists {
+						return student, nil
+					}
+					return nil, nil
+				},
+			},
+			"students": &graphql.Field{
+				Type: graphql.NewList(studentType),
+				Args: graphql.FieldConfigArgument{
+					"name":        &graphql.ArgumentConfig{Type: graphql.String},
+					"min_age":     &graphql.ArgumentConfig{Type: graphql.Int},
+					"max_age":     &graphql.ArgumentConfig{Type: graphql.Int},
+					"gender":      &graphql.ArgumentConfig{Type: graphql.String},
+					"nationality": &graphql.ArgumentConfig{Type: graphql.String},
+					"city":        &graphql.ArgumentConfig{Type: graphql.String},
+					"status":      &graphql.ArgumentConfig{Type: graphql.String},
+					"tag":         &graphql.ArgumentConfig{Type: graphql.String},
+					"grade_level": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var filter StudentFilter
+					if err := decodeGraphQLArgs(p.Args, &filter); err != nil {
+						return nil, err
+					}
+					s.store.Lock()
+					matched := s.filterStudents(filter)
+					s.store.Unlock()
+					sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+					return matched, nil
+				},
+			},
+			"course": &graphql.Field{
+				Type: courseType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					coursesMu.Lock()
+					defer coursesMu.Unlock()
+					if course, exists := courses[p.Args["id"].(int)]; exists {
+						return withEnrolled(course), nil
+					}
+					return nil, nil
+				},
+			},
+			"courses": &graphql.Field{
+				Type: graphql.NewList(courseType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					coursesMu.Lock()
+					list := []Course{}
+					for _, course := range courses {
+						list = append(list, withEnrolled(course))
+					}
+					coursesMu.Unlock()
+					sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+					return list, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createStudent": &graphql.Field{
+				Type: studentType,
+				Args: graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(studentInput)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var student Student
+					if err := decodeGraphQLArgs(p.Args["input"], &student); err != nil {
+						return nil, err
+					}
+					return graphqlStudent(s.students.Create(student))
+				},
+			},
+			"updateStudent": &graphql.Field{
+				Type: studentType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(studentInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var student Student
+					if err := decodeGraphQLArgs(p.Args["input"], &student); err != nil {
+						return nil, err
+					}
+					return graphqlStudent(s.students.Update(p.Args["id"].(int), student))
+				},
+			},
+			"deleteStudent": &graphql.Field{
+				Type: graphql.Boolean,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"purge": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := s.students.Delete(p.Args["id"].(int), p.Args["purge"].(bool)); err != nil {
+						return false, err
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// graphqlStudent returns a mutated student, or no value with the error so the field resolves
+// to null
+func graphqlStudent(student Student, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// decodeGraphQLArgs copies resolved arguments onto a struct through its JSON tags
+func decodeGraphQLArgs(args interface{}, target interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// serveGraphQL handles GET and POST /graphql; POST takes {"query", "variables", "operationName"}
+// and GET the same as query parameters. Errors are reported in the response body as GraphQL
+// requires
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables"`
+		OperationName string                 `json:"operationName"`
+	}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+	} else {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				http.Error(w, "Invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.graphqlSchema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
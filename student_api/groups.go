@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"student_api/student_api/internal/handlers"
+)
+
+// groupsMu guards groups and groupMembers; take it after the student store when both are needed
+var (
+	groups       = make(map[int]Group)
+	groupMembers = make(map[int]map[int]bool)
+	groupsMu     sync.Mutex
+	nextGroupID  int
+)
+
+// Group struct to hold a named set of students such as a year or section
+type Group struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind,omitempty"`
+	Year        int    `json:"year,omitempty"`
+	Description string `json:"description,omitempty"`
+	Members     int    `json:"members"`
+}
+
+// validateGroup checks the fields a client may set on a group
+func validateGroup(group Group) error {
+	if strings.TrimSpace(group.Name) == "" {
+		return errors.New("Invalid group data")
+	}
+	return nil
+}
+
+// withMembers sets the group's member count; the caller must hold groupsMu
+func withMembers(group Group) Group {
+	group.Members = len(groupMembers[group.ID])
+	return group
+}
+
+// inGroup reports whether a student belongs to a group; the caller must hold groupsMu
+func inGroup(groupID, studentID int) bool {
+	return groupMembers[groupID][studentID]
+}
+
+// groupIDFromRequest parses the {id} route variable of a group route
+func groupIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// createGroup handles POST /groups to create a new group
+func createGroup(w http.ResponseWriter, r *http.Request) {
+	var group Group
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateGroup(group); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groupsMu.Lock()
+	nextGroupID++
+	group.ID = nextGroupID
+	group.Members = 0
+	groups[group.ID] = group
+	groupsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(group)
+}
+
+// getAllGroups handles GET /groups to fetch all groups
+func getAllGroups(w http.ResponseWriter, r *http.Request) {
+	groupsMu.Lock()
+	all := []Group{}
+	for _, group := range groups {
+		all = append(all, withMembers(group))
+	}
+	groupsMu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(all)
+}
+
+// getGroupByID handles GET /groups/{id} to fetch a group by ID
+func getGroupByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	group, exists := groups[id]
+	if !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withMembers(group))
+}
+
+// updateGroup handles PUT /groups/{id} to update a group by ID
+func updateGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var updated Group
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	group, exists := groups[id]
+	if !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	// Update fields
+	if updated.Name != "" {
+		group.Name = updated.Name
+	}
+	if updated.Kind != "" {
+		group.Kind = updated.Kind
+	}
+	if updated.Year != 0 {
+		group.Year = updated.Year
+	}
+	if updated.Description != "" {
+		group.Description = updated.Description
+	}
+	groups[id] = group
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withMembers(group))
+}
+
+// deleteGroup handles DELETE /groups/{id} to delete a group; its students are not affected
+func deleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	if _, exists := groups[id]; !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	delete(groups, id)
+	delete(groupMembers, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getGroupMembers handles GET /groups/{id}/members to list the students in a group
+func (s *Server) getGroupMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	s.store.Lock()
+	groupsMu.Lock()
+	_, exists := groups[id]
+	members := []Student{}
+	for studentID := range groupMembers[id] {
+		student, _ := s.store.Get(studentID)
+		members = append(members, student)
+	}
+	groupsMu.Unlock()
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// addGroupMembers handles POST /groups/{id}/members to add students ({"student_ids": [...]})
+// to a group; students already in the group are left as they are
+func (s *Server) addGroupMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		StudentIDs []int `json:"student_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.StudentIDs) == 0 {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	group, exists := groups[id]
+	if !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	for _, studentID := range req.StudentIDs {
+		if _, exists := s.store.Get(studentID); !exists {
+			http.Error(w, "Student "+strconv.Itoa(studentID)+" not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	if groupMembers[id] == nil {
+		groupMembers[id] = make(map[int]bool)
+	}
+	for _, studentID := range req.StudentIDs {
+		groupMembers[id][studentID] = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withMembers(group))
+}
+
+// removeGroupMember handles DELETE /groups/{id}/members/{student} to remove a student from a group
+func removeGroupMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	studentID, err := strconv.Atoi(mux.Vars(r)["student"])
+	if err != nil {
+		http.Error(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+
+	if !inGroup(id, studentID) {
+		http.Error(w, "Student is not in this group", http.StatusNotFound)
+		return
+	}
+	delete(groupMembers[id], studentID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tagGroupMembers handles POST /groups/{id}/tags to add and remove tags
+// ({"add": [...], "remove": [...]}) on every student in a group
+func (s *Server) tagGroupMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Add)+len(req.Remove) == 0 {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	groupsMu.Lock()
+	_, exists := groups[id]
+	members := []int{}
+	for studentID := range groupMembers[id] {
+		members = append(members, studentID)
+	}
+	groupsMu.Unlock()
+	if !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	// Members deleted since the list was copied are skipped
+	tagged := []Student{}
+	for _, studentID := range members {
+		student, err := s.students.Change(studentID, func(student *Student) error {
+			student.Tags = applyTags(student.Tags, req.Add, req.Remove)
+			return nil
+		})
+		if errors.Is(err, ErrStudentNotFound) {
+			continue
+		}
+		if err != nil {
+			http.Error(w, err.Error(), handlers.StatusCode(err))
+			return
+		}
+		tagged = append(tagged, student)
+	}
+	sort.Slice(tagged, func(i, j int) bool { return tagged[i].ID < tagged[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tagged)
+}
+
+// applyTags returns tags with add appended and remove dropped, normalized to lower case and
+// without duplicates
+func applyTags(tags, add, remove []string) []string {
+	removed := make(map[string]bool)
+	for _, tag := range remove {
+		removed[normalizeTag(tag)] = true
+	}
+
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range append(append([]string{}, tags...), add...) {
+		tag = normalizeTag(tag)
+		if tag == "" || removed[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// normalizeTag trims and lower-cases a tag
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// getGroupReport handles GET /groups/{id}/report to produce the cohort report for a group's members
+func (s *Server) getGroupReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := groupIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	groupsMu.Lock()
+	_, exists := groups[id]
+	groupsMu.Unlock()
+	if !exists {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	query.Set("group", strconv.Itoa(id))
+	r.URL.RawQuery = query.Encode()
+	s.getCohortReport(w, r)
+}
+
+// removeFromGroups drops a deleted student from every group
+func removeFromGroups(id int) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	for _, members := range groupMembers {
+		delete(members, id)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+//go:generate protoc -I proto --go_out=studentpb --go_opt=paths=source_relative --go-grpc_out=studentpb --go-grpc_opt=paths=source_relative --grpc-gateway_out=studentpb --grpc-gateway_opt=paths=source_relative student.proto
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"student_api/student_api/studentpb"
+)
+
+// studentServer implements studentpb.StudentServiceServer on top of the same store functions as
+// the REST handlers
+type studentServer struct {
+	studentpb.UnimplementedStudentServiceServer
+	*Server
+}
+
+// startGRPCServer serves the StudentService on GRPC_ADDR; an empty address disables it
+func (s *Server) startGRPCServer() error {
+	if s.config.GRPCAddr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.config.GRPCAddr)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(s.withGRPCMetadata))
+	studentpb.RegisterStudentServiceServer(server, studentServer{Server: s})
+
+	log.Printf("gRPC server is listening on %s...", s.config.GRPCAddr)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// gatewayHandler serves the REST routes generated from the google.api.http options in
+// proto/student.proto; requests are handled in process by studentServer, so the gateway works
+// whether or not GRPC_ADDR is listening, and keeps the caller's API key and tenant from the
+// request context
+func (s *Server) gatewayHandler() (http.Handler, error) {
+	// Keep the snake_case field names of the hand-written routes
+	gateway := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+	if err := studentpb.RegisterStudentServiceHandlerServer(context.Background(), gateway, studentServer{Server: s}); err != nil {
+		return nil, err
+	}
+	return gateway, nil
+}
+
+// withGRPCMetadata copies the x-api-key metadata and the tenant it belongs to onto the context, as
+// withAPIKey and withTenant do for HTTP headers
+func (s *Server) withGRPCMetadata(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	apiKey := first("x-api-key")
+	ctx = withCaller(ctx, apiKey)
+	ctx = context.WithValue(ctx, tenantContextKey, s.tenantForKey(apiKey))
+	return handler(ctx, req)
+}
+
+// grpcError converts a store or LLM error to a gRPC status
+func grpcError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrStudentNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrInvalidStudent):
+		code = codes.InvalidArgument
+	case errors.Is(err, ErrStudentConflict):
+		code = codes.AlreadyExists
+	case errors.Is(err, ErrNoLLMConsent):
+		code = codes.PermissionDenied
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrModerationUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, ErrLLMQueueFull):
+		code = codes.ResourceExhausted
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
+
+// studentToProto converts a student to its protobuf message
+func studentToProto(s Student) *studentpb.Student {
+	return &studentpb.Student{
+		Id:             int64(s.ID),
+		Name:           s.Name,
+		Email:          s.Email,
+		Phone:          s.Phone,
+		DateOfBirth:    s.DateOfBirth,
+		Gender:         s.Gender,
+		Nationality:    s.Nationality,
+		Age:            int32(s.Age()),
+		Status:         s.Status,
+		GradeLevel:     int32(s.GradeLevel),
+		Tags:           s.Tags,
+		EmailVerified:  s.EmailVerified,
+		Alumni:         s.Alumni,
+		GraduationYear: int32(s.GraduationYear),
+		ExternalIds:    s.ExternalIDs,
+	}
+}
+
+// studentFromProto converts a protobuf message to a student; read-only fields are ignored by
+// the student service
+func studentFromProto(p *studentpb.Student) Student {
+	if p == nil {
+		return Student{}
+	}
+	return Student{
+		Name:        p.Name,
+		Email:       p.Email,
+		Phone:       p.Phone,
+		DateOfBirth: p.DateOfBirth,
+		Gender:      p.Gender,
+		Nationality: p.Nationality,
+		Status:      p.Status,
+		GradeLevel:  int(p.GradeLevel),
+		Tags:        p.Tags,
+		ExternalIDs: p.ExternalIds,
+		RecordedAge: int(p.Age),
+	}
+}
+
+func (srv studentServer) CreateStudent(ctx context.Context, req *studentpb.CreateStudentRequest) (*studentpb.Student, error) {
+	student, err := srv.students.Create(studentFromProto(req.Student))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return studentToProto(student), nil
+}
+
+func (srv studentServer) GetStudent(ctx context.Context, req *studentpb.GetStudentRequest) (*studentpb.Student, error) {
+	srv.store.Lock()
+	student, exists := srv.store.Get(int(req.Id))
+	srv.store.Unlock()
+	if !exists {
+		return nil, status.Error(codes.NotFound, "Student not found")
+	}
+	return studentToProto(student), nil
+}
+
+func (srv studentServer) ListStudents(ctx context.Context, req *studentpb.ListStudentsRequest) (*studentpb.ListStudentsResponse, error) {
+	filter := StudentFilter{Name: req.Name, Status: req.Status, Tag: req.Tag, GradeLevel: int(req.GradeLevel)}
+
+	srv.store.Lock()
+	matched := srv.filterStudents(filter)
+	srv.store.Unlock()
+
+	response := &studentpb.ListStudentsResponse{}
+	for _, student := range matched {
+		response.Students = append(response.Students, studentToProto(student))
+	}
+	return response, nil
+}
+
+func (srv studentServer) UpdateStudent(ctx context.Context, req *studentpb.UpdateStudentRequest) (*studentpb.Student, error) {
+	student, err := srv.students.Update(int(req.Id), studentFromProto(req.Student))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return studentToProto(student), nil
+}
+
+func (srv studentServer) DeleteStudent(ctx context.Context, req *studentpb.DeleteStudentRequest) (*studentpb.DeleteStudentResponse, error) {
+	if err := srv.students.Delete(int(req.Id), req.Purge); err != nil {
+		return nil, grpcError(err)
+	}
+	return &studentpb.DeleteStudentResponse{}, nil
+}
+
+func (srv studentServer) GetStudentSummary(ctx context.Context, req *studentpb.GetStudentSummaryRequest) (*studentpb.StudentSummary, error) {
+	srv.store.Lock()
+	student, exists := srv.store.Get(int(req.Id))
+	srv.store.Unlock()
+	if !exists {
+		return nil, status.Error(codes.NotFound, "Student not found")
+	}
+
+	opts, err := srv.checkSummaryOptions(SummaryOptions{
+		Model:    req.Model,
+		Tone:     req.Tone,
+		Length:   req.Length,
+		Language: req.Language,
+		Tenant:   tenantFromContext(ctx),
+	})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	summary, err := srv.studentSummary(ctx, student, opts, req.Refresh)
+	if errors.Is(err, ErrCircuitOpen) && srv.config.DegradedMode == "template" {
+		summary, err = fallbackSummary(student), nil
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &studentpb.StudentSummary{
+		StudentId:       int64(summary.StudentID),
+		Summary:         summary.Summary,
+		Model:           summary.Model,
+		Cached:          summary.Cached,
+		Degraded:        summary.Degraded,
+		GeneratedAtUnix: summary.GeneratedAt.Unix(),
+	}, nil
+}
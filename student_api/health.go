@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	healthRecords   = make(map[int]HealthRecord)
+	healthRecordsMu sync.Mutex
+)
+
+// HealthRecord struct to hold a student's allergies and medical notes; the record is only
+// shared with the LLM or in exports when the matching consent flag is set
+type HealthRecord struct {
+	StudentID   int      `json:"student_id"`
+	Allergies   []string `json:"allergies"`
+	Conditions  []string `json:"conditions"`
+	Medications []string `json:"medications"`
+	Notes       string   `json:"notes,omitempty"`
+
+	ShareWithLLM   bool      `json:"share_with_llm"`
+	ShareInExports bool      `json:"share_in_exports"`
+	ConsentGivenBy string    `json:"consent_given_by,omitempty"`
+	UpdatedBy      string    `json:"updated_by"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// canAccessHealth reports whether the caller holds one of the HEALTH_RECORD_ROLES
+func (s *Server) canAccessHealth(r *http.Request) bool {
+	return containsString(s.config.HealthRecordRoles, s.callerRole(r))
+}
+
+// healthRecordFor returns a student's health record if one exists
+func healthRecordFor(id int) (HealthRecord, bool) {
+	healthRecordsMu.Lock()
+	defer healthRecordsMu.Unlock()
+	record, exists := healthRecords[id]
+	return record, exists
+}
+
+// trimList trims the entries of a list, dropping empty ones
+func trimList(list []string) []string {
+	trimmed := []string{}
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+	return trimmed
+}
+
+// putHealthRecord handles PUT /students/{id}/health to replace a student's health record; sharing
+// with the LLM or in exports requires consent_given_by to name who consented
+func (s *Server) putHealthRecord(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	if !s.canAccessHealth(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var record HealthRecord
+	if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	record.ConsentGivenBy = strings.TrimSpace(record.ConsentGivenBy)
+	if (record.ShareWithLLM || record.ShareInExports) && record.ConsentGivenBy == "" {
+		http.Error(w, "consent_given_by is required to share health data", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	record.StudentID = id
+	record.Allergies = trimList(record.Allergies)
+	record.Conditions = trimList(record.Conditions)
+	record.Medications = trimList(record.Medications)
+	record.Notes = strings.TrimSpace(record.Notes)
+	record.UpdatedBy = callerID(r.Context())
+	record.UpdatedAt = time.Now()
+
+	healthRecordsMu.Lock()
+	healthRecords[id] = record
+	healthRecordsMu.Unlock()
+	invalidateSummary(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
+
+// getHealthRecord handles GET /students/{id}/health to fetch a student's health record
+func (s *Server) getHealthRecord(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	if !s.canAccessHealth(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	record, exists := healthRecordFor(id)
+	if !exists {
+		http.Error(w, "Health record not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
+
+// deleteHealthRecord handles DELETE /students/{id}/health to remove a student's health record
+func (s *Server) deleteHealthRecord(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	if !s.canAccessHealth(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	healthRecordsMu.Lock()
+	_, exists := healthRecords[id]
+	delete(healthRecords, id)
+	healthRecordsMu.Unlock()
+	if !exists {
+		http.Error(w, "Health record not found", http.StatusNotFound)
+		return
+	}
+	invalidateSummary(id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteHealthRecords drops a deleted student's health record
+func deleteHealthRecords(id int) {
+	healthRecordsMu.Lock()
+	defer healthRecordsMu.Unlock()
+	delete(healthRecords, id)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// iCalendar date and date-time layouts; schedule times carry no zone and are read by calendar
+// apps in the subscriber's local time
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+)
+
+// calendarEvent struct to hold one VEVENT of an iCalendar feed; AllDay events use only the date
+// of Start, and RRule repeats the event
+type calendarEvent struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	RRule       string
+}
+
+// publicHostname returns the host of PUBLIC_BASE_URL, used to make calendar and card UIDs unique
+func (s *Server) publicHostname() string {
+	if base, err := url.Parse(s.config.PublicBaseURL); err == nil && base.Hostname() != "" {
+		return base.Hostname()
+	}
+	return "localhost"
+}
+
+// icalEscape escapes a text value for iCalendar
+func icalEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// icalLine folds a content line at 75 octets as RFC 5545 requires
+func icalLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// writeICalendar writes events as a text/calendar feed named name
+func (s *Server) writeICalendar(w http.ResponseWriter, name string, events []calendarEvent) {
+	stamp := time.Now().UTC().Format(icalDateTimeLayout) + "Z"
+	host := s.publicHostname()
+
+	var b strings.Builder
+	icalLine(&b, "BEGIN:VCALENDAR")
+	icalLine(&b, "VERSION:2.0")
+	icalLine(&b, "PRODID:-//student_api//calendar//EN")
+	icalLine(&b, "CALSCALE:GREGORIAN")
+	icalLine(&b, "X-WR-CALNAME:"+icalEscape(name))
+	for _, event := range events {
+		icalLine(&b, "BEGIN:VEVENT")
+		icalLine(&b, "UID:"+event.UID+"@"+host)
+		icalLine(&b, "DTSTAMP:"+stamp)
+		if event.AllDay {
+			icalLine(&b, "DTSTART;VALUE=DATE:"+event.Start.Format(icalDateLayout))
+		} else {
+			icalLine(&b, "DTSTART:"+event.Start.Format(icalDateTimeLayout))
+			icalLine(&b, "DTEND:"+event.End.Format(icalDateTimeLayout))
+		}
+		if event.RRule != "" {
+			icalLine(&b, "RRULE:"+event.RRule)
+		}
+		icalLine(&b, "SUMMARY:"+icalEscape(event.Summary))
+		if event.Location != "" {
+			icalLine(&b, "LOCATION:"+icalEscape(event.Location))
+		}
+		if event.Description != "" {
+			icalLine(&b, "DESCRIPTION:"+icalEscape(event.Description))
+		}
+		icalLine(&b, "END:VEVENT")
+	}
+	icalLine(&b, "END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+// firstMeetingOn returns the first date on or after from that falls on a meeting day
+func firstMeetingOn(from time.Time, day string) time.Time {
+	weekday := time.Weekday(0)
+	for i, d := range meetingDays {
+		if d == day {
+			weekday = time.Weekday((i + 1) % 7)
+		}
+	}
+	return from.AddDate(0, 0, (int(weekday)-int(from.Weekday())+7)%7)
+}
+
+// atClock returns day at an "HH:MM" time
+func atClock(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+}
+
+// getStudentScheduleICS handles GET /students/{id}/schedule.ics to publish a student's weekly
+// timetable as a calendar feed; classes repeat weekly from the start of the enrollment's term
+// until its end, or from the enrollment date when the term has no dates
+func (s *Server) getStudentScheduleICS(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	events := []calendarEvent{}
+	coursesMu.Lock()
+	for _, enrollment := range studentEnrollments(id) {
+		course := courses[enrollment.CourseID]
+		from, until := enrollment.EnrolledAt, time.Time{}
+		if start, end, ok := termDates(enrollment.Term); ok {
+			from, _ = time.ParseInLocation(dateLayout, start, time.Local)
+			until, _ = time.ParseInLocation(dateLayout, end, time.Local)
+		}
+		from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+
+		for _, meeting := range course.Meetings {
+			day := firstMeetingOn(from, meeting.Day)
+			rule := "FREQ=WEEKLY"
+			if !until.IsZero() {
+				if day.After(until) {
+					continue
+				}
+				rule += ";UNTIL=" + until.Format(icalDateLayout) + "T235959"
+			}
+			events = append(events, calendarEvent{
+				UID:         fmt.Sprintf("student-%d-course-%d-%s-%s", id, course.ID, meeting.Day, strings.ReplaceAll(meeting.Start, ":", "")),
+				Summary:     course.Code + " " + course.Title,
+				Location:    meeting.Room,
+				Description: enrollment.Term,
+				Start:       atClock(day, meeting.Start),
+				End:         atClock(day, meeting.End),
+				RRule:       rule,
+			})
+		}
+	}
+	coursesMu.Unlock()
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	s.writeICalendar(w, student.Name+" schedule", events)
+}
+
+// getBirthdaysICS handles GET /students/birthdays.ics to publish every known birthday as a yearly
+// all-day event; February 29 birthdays fall on the last day of February, as in birthdayIn
+func (s *Server) getBirthdaysICS(w http.ResponseWriter, r *http.Request) {
+	events := []calendarEvent{}
+	s.store.Lock()
+	for _, student := range s.store.List() {
+		dob, err := time.Parse(dateLayout, student.DateOfBirth)
+		if err != nil {
+			continue
+		}
+		rule := "FREQ=YEARLY"
+		if dob.Month() == time.February && dob.Day() == 29 {
+			rule += ";BYMONTH=2;BYMONTHDAY=-1"
+		}
+		events = append(events, calendarEvent{
+			UID:     fmt.Sprintf("student-%d-birthday", student.ID),
+			Summary: student.Name + "'s birthday",
+			Start:   dob,
+			AllDay:  true,
+			RRule:   rule,
+		})
+	}
+	s.store.Unlock()
+	sort.Slice(events, func(i, j int) bool { return events[i].UID < events[j].UID })
+
+	s.writeICalendar(w, "Student birthdays", events)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	incidents      = make(map[int]*Incident)
+	incidentsMu    sync.Mutex
+	nextIncidentID int
+
+	// Accepted values for Incident.Category and Incident.Severity
+	incidentCategories = map[string]bool{
+		"disruption": true, "bullying": true, "fighting": true, "truancy": true,
+		"academic_dishonesty": true, "property_damage": true, "substance": true, "other": true,
+	}
+	incidentSeverities = map[string]bool{"low": true, "medium": true, "high": true, "critical": true}
+)
+
+// Incident struct to hold a behavior incident involving a student
+type Incident struct {
+	ID          int       `json:"id"`
+	StudentID   int       `json:"student_id"`
+	Date        string    `json:"date"`
+	Category    string    `json:"category"`
+	Severity    string    `json:"severity"`
+	Description string    `json:"description"`
+	ActionTaken string    `json:"action_taken,omitempty"`
+	Reporter    string    `json:"reporter"`
+	ReportedAt  time.Time `json:"reported_at"`
+}
+
+// IncidentCategoryStats struct to hold the incident counts for one category
+type IncidentCategoryStats struct {
+	Category   string         `json:"category"`
+	Count      int            `json:"count"`
+	Students   int            `json:"students"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// validateIncident checks an incident submitted by a client
+func validateIncident(incident Incident) error {
+	if _, err := time.Parse(dateLayout, incident.Date); err != nil {
+		return errors.New("date must be in YYYY-MM-DD format")
+	}
+	if incident.Date > today() {
+		return errors.New("date must not be in the future")
+	}
+	if !incidentCategories[incident.Category] {
+		return errors.New("category must be one of disruption, bullying, fighting, truancy, academic_dishonesty, property_damage, substance or other")
+	}
+	if !incidentSeverities[incident.Severity] {
+		return errors.New("severity must be low, medium, high or critical")
+	}
+	if strings.TrimSpace(incident.Description) == "" {
+		return errors.New("description is required")
+	}
+	return nil
+}
+
+// canViewIncident reports whether the caller may read an incident: roles listed in
+// INCIDENT_VIEW_ROLES see every incident and callers with an API key only those they reported
+func (s *Server) canViewIncident(r *http.Request, incident *Incident) bool {
+	if containsString(s.config.IncidentViewRoles, s.callerRole(r)) {
+		return true
+	}
+	return apiKeyFromContext(r.Context()) != "" && incident.Reporter == callerID(r.Context())
+}
+
+// createIncident handles POST /students/{id}/incidents to report a behavior incident; the reporter
+// is the keyID of the caller's API key, so callers without a key cannot report incidents
+func (s *Server) createIncident(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	if apiKeyFromContext(r.Context()) == "" {
+		http.Error(w, "An API key is required to report incidents", http.StatusUnauthorized)
+		return
+	}
+
+	var incident Incident
+	if err := json.NewDecoder(r.Body).Decode(&incident); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	incident.Category = strings.ToLower(strings.TrimSpace(incident.Category))
+	incident.Severity = strings.ToLower(strings.TrimSpace(incident.Severity))
+	if incident.Date == "" {
+		incident.Date = today()
+	}
+	if err := validateIncident(incident); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	incidentsMu.Lock()
+	nextIncidentID++
+	incident.ID = nextIncidentID
+	incident.StudentID = id
+	incident.Reporter = callerID(r.Context())
+	incident.ReportedAt = time.Now()
+	incidents[incident.ID] = &incident
+	incidentsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(incident)
+}
+
+// getIncidents handles GET /students/{id}/incidents?category=&severity= to list the incidents
+// the caller may view, most recent first
+func (s *Server) getIncidents(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	category := r.URL.Query().Get("category")
+	severity := r.URL.Query().Get("severity")
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	incidentsMu.Lock()
+	list := []Incident{}
+	for _, incident := range incidents {
+		if incident.StudentID != id || !s.canViewIncident(r, incident) {
+			continue
+		}
+		if (category == "" || incident.Category == category) && (severity == "" || incident.Severity == severity) {
+			list = append(list, *incident)
+		}
+	}
+	incidentsMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Date != list[j].Date {
+			return list[i].Date > list[j].Date
+		}
+		return list[i].ID > list[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// deleteIncident handles DELETE /students/{id}/incidents/{incident} to remove an incident; only
+// roles in INCIDENT_VIEW_ROLES may delete
+func (s *Server) deleteIncident(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	incidentID, err := strconv.Atoi(mux.Vars(r)["incident"])
+	if err != nil {
+		http.Error(w, "Invalid incident ID", http.StatusBadRequest)
+		return
+	}
+	if !containsString(s.config.IncidentViewRoles, s.callerRole(r)) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	incidentsMu.Lock()
+	defer incidentsMu.Unlock()
+
+	incident, exists := incidents[incidentID]
+	if !exists || incident.StudentID != id {
+		http.Error(w, "Incident not found", http.StatusNotFound)
+		return
+	}
+	delete(incidents, incidentID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getIncidentReport handles GET /reports/incidents?from=&to= to count incidents by category and
+// severity; the report carries no student details and is open to every role
+func getIncidentReport(w http.ResponseWriter, r *http.Request) {
+	query, err := attendanceQueryFromURL(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	incidentsMu.Lock()
+	byCategory := make(map[string]*IncidentCategoryStats)
+	studentsSeen := make(map[string]map[int]bool)
+	total := 0
+	for _, incident := range incidents {
+		if (query.From != "" && incident.Date < query.From) || (query.To != "" && incident.Date > query.To) {
+			continue
+		}
+		stats, ok := byCategory[incident.Category]
+		if !ok {
+			stats = &IncidentCategoryStats{Category: incident.Category, BySeverity: make(map[string]int)}
+			byCategory[incident.Category] = stats
+			studentsSeen[incident.Category] = make(map[int]bool)
+		}
+		stats.Count++
+		stats.BySeverity[incident.Severity]++
+		studentsSeen[incident.Category][incident.StudentID] = true
+		total++
+	}
+	incidentsMu.Unlock()
+
+	report := []IncidentCategoryStats{}
+	for category, stats := range byCategory {
+		stats.Students = len(studentsSeen[category])
+		report = append(report, *stats)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Count > report[j].Count })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"from": query.From, "to": query.To, "total": total, "categories": report})
+}
+
+// deleteIncidents drops a deleted student's incidents
+func deleteIncidents(id int) {
+	incidentsMu.Lock()
+	defer incidentsMu.Unlock()
+	for incidentID, incident := range incidents {
+		if incident.StudentID == id {
+			delete(incidents, incidentID)
+		}
+	}
+}
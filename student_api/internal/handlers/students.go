@@ -0,0 +1,137 @@
+// Package handlers holds the HTTP handlers of the student routes, built on the service layer
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"student_api/student_api/internal/service"
+	"student_api/student_api/internal/store"
+)
+
+// Codec is implemented by the content negotiation of the API: it reads a student from a request
+// body and writes students in the encoding the client accepts
+type Codec interface {
+	DecodeStudent(r *http.Request, student *store.Student) error
+	WriteStudent(w http.ResponseWriter, r *http.Request, status int, student store.Student)
+	WriteStudents(w http.ResponseWriter, r *http.Request, status int, students []store.Student)
+}
+
+// FilterFunc builds the filter of GET /students from its query parameters, returning a message
+// suitable for the client when a parameter is invalid
+type FilterFunc func(query url.Values) (func(store.Student) bool, error)
+
+// Students serves the student CRUD routes
+type Students struct {
+	service *service.Students
+	codec   Codec
+	filter  FilterFunc
+}
+
+// NewStudents returns the handlers of the student CRUD routes; a nil filter lists every student
+func NewStudents(svc *service.Students, codec Codec, filter FilterFunc) *Students {
+	return &Students{service: svc, codec: codec, filter: filter}
+}
+
+// Register adds the student CRUD routes to router
+func (h *Students) Register(router *mux.Router) {
+	router.HandleFunc("/students", h.CreateStudent).Methods("POST")
+	router.HandleFunc("/students", h.GetAllStudents).Methods("GET")
+	router.HandleFunc("/students/{id}", h.GetStudentByID).Methods("GET")
+	router.HandleFunc("/students/{id}", h.UpdateStudent).Methods("PUT")
+	router.HandleFunc("/students/{id}", h.DeleteStudent).Methods("DELETE")
+}
+
+// CreateStudent handles POST /students to create a new student
+func (h *Students) CreateStudent(w http.ResponseWriter, r *http.Request) {
+	var student store.Student
+	if err := h.codec.DecodeStudent(r, &student); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	student, err := h.service.Create(student)
+	if err != nil {
+		http.Error(w, err.Error(), StatusCode(err))
+		return
+	}
+
+	h.codec.WriteStudent(w, r, http.StatusCreated, student)
+}
+
+// GetAllStudents handles GET /students to fetch all students, optionally narrowed by the filter
+// query parameters
+func (h *Students) GetAllStudents(w http.ResponseWriter, r *http.Request) {
+	var match func(store.Student) bool
+	if h.filter != nil {
+		var err error
+		if match, err = h.filter(r.URL.Query()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.codec.WriteStudents(w, r, http.StatusOK, h.service.List(match))
+}
+
+// GetStudentByID handles GET /students/{id} to fetch a student by ID
+func (h *Students) GetStudentByID(w http.ResponseWriter, r *http.Request) {
+	student, exists := h.service.Get(studentID(r))
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	h.codec.WriteStudent(w, r, http.StatusOK, student)
+}
+
+// UpdateStudent handles PUT /students/{id} to update a student by ID
+func (h *Students) UpdateStudent(w http.ResponseWriter, r *http.Request) {
+	var update store.Student
+	if err := h.codec.DecodeStudent(r, &update); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	student, err := h.service.Update(studentID(r), update)
+	if err != nil {
+		http.Error(w, err.Error(), StatusCode(err))
+		return
+	}
+
+	h.codec.WriteStudent(w, r, http.StatusOK, student)
+}
+
+// DeleteStudent handles DELETE /students/{id} to delete a student by ID; alumni are kept for the
+// alumni directory unless ?purge=true is given
+func (h *Students) DeleteStudent(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(studentID(r), r.URL.Query().Get("purge") == "true"); err != nil {
+		http.Error(w, err.Error(), StatusCode(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StatusCode returns the HTTP status for an error from the student service
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// studentID reads the {id} route variable; an invalid ID reads as 0, which no student has
+func studentID(r *http.Request) int {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	return id
+}
@@ -0,0 +1,235 @@
+// Package llm holds the clients of the language model backends used by the summary, chat and
+// search features, and the request and response types every provider wrapper shares
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrInvalidOutput is returned when the model's reply does not match the requested structure
+	ErrInvalidOutput = errors.New("llm returned an invalid structured summary")
+
+	// ErrQueueFull is returned when every LLM slot is busy and the wait queue is full
+	ErrQueueFull = errors.New("llm request queue is full")
+
+	// ErrStreamingUnsupported is returned when the configured provider cannot stream its output
+	ErrStreamingUnsupported = errors.New("llm provider does not support streaming")
+
+	// ErrEmbeddingsUnsupported is returned when the configured provider cannot produce embeddings
+	ErrEmbeddingsUnsupported = errors.New("llm provider does not support embeddings")
+)
+
+// Provider is implemented by every backend that can generate text for the summary features
+type Provider interface {
+	Generate(ctx context.Context, req Request) (Response, error)
+}
+
+// Embedder is implemented by providers that can turn text into an embedding vector
+type Embedder interface {
+	Embed(ctx context.Context, model, text string) ([]float64, error)
+}
+
+// Streamer is implemented by providers that can relay their output as it is generated
+type Streamer interface {
+	// Stream sends each generated piece of text on chunks, blocking while the channel is full,
+	// and returns the complete response once generation finishes
+	Stream(ctx context.Context, req Request, chunks chan<- string) (Response, error)
+}
+
+// Request struct to hold a single prompt sent to a provider
+type Request struct {
+	Model  string
+	Prompt string
+	// JSON asks the model to reply with a single JSON object
+	JSON bool
+	// Messages, when set, is sent as a chat conversation instead of Prompt
+	Messages []Message
+	// System is sent as the system prompt ahead of the prompt or conversation
+	System string
+}
+
+// Message struct to hold one turn of a chat conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Conversation returns the conversation to send, wrapping Prompt as a single user message when
+// needed
+func (req Request) Conversation() []Message {
+	messages := req.Messages
+	if len(messages) == 0 {
+		messages = []Message{{Role: "user", Content: req.Prompt}}
+	}
+	if req.System != "" {
+		messages = append([]Message{{Role: "system", Content: req.System}}, messages...)
+	}
+	return messages
+}
+
+// Response struct to hold the text a provider generated
+type Response struct {
+	Provider         string
+	Model            string
+	Text             string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// StatusError is returned when a provider answers with a non-200 status
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+	// Message is the error reported by the provider, when its body could be parsed
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s returned %d: %s", e.URL, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s returned %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+// ProviderErrorMessage extracts the error message from an Ollama ({"error": "..."}) or
+// OpenAI ({"error": {"message": "..."}}) error body
+func ProviderErrorMessage(body []byte) string {
+	var ollama struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &ollama) == nil && ollama.Error != "" {
+		return ollama.Error
+	}
+	var openai struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &openai) == nil {
+		return openai.Error.Message
+	}
+	return ""
+}
+
+// RetryProvider retries transient failures of the wrapped provider with exponential backoff
+type RetryProvider struct {
+	Provider   Provider
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// Generate calls the wrapped provider, retrying up to MaxRetries times while the error is transient
+func (p *RetryProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	backoff := p.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := p.Provider.Generate(ctx, req)
+		if err == nil || attempt >= p.MaxRetries || !IsTransient(err) {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Stream relays the wrapped provider's stream without retrying, since output that has been sent
+// cannot be taken back
+func (p *RetryProvider) Stream(ctx context.Context, req Request, chunks chan<- string) (Response, error) {
+	streamer, ok := p.Provider.(Streamer)
+	if !ok {
+		return Response{}, ErrStreamingUnsupported
+	}
+	return streamer.Stream(ctx, req, chunks)
+}
+
+// Embed embeds text with the wrapped provider, retrying transient failures like Generate
+func (p *RetryProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	embedder, ok := p.Provider.(Embedder)
+	if !ok {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	backoff := p.Backoff
+	for attempt := 0; ; attempt++ {
+		vector, err := embedder.Embed(ctx, model, text)
+		if err == nil || attempt >= p.MaxRetries || !IsTransient(err) {
+			return vector, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// IsTransient reports whether a failed LLM call is worth retrying
+func IsTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrQueueFull) || errors.Is(err, ErrInvalidOutput) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	// Network errors and timeouts
+	return true
+}
+
+// PostJSON sends payload as JSON to url and decodes a successful JSON response into out
+func PostJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{URL: url, StatusCode: resp.StatusCode, Body: string(respBody), Message: ProviderErrorMessage(respBody)}
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	return nil
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"hash/fnv"
+	"text/template"
+)
+
+// mockJSONResponse is returned by the mock provider when JSON mode is requested
+const mockJSONResponse = `{"profile": "Mock summary.", "strengths": ["Mock strength"], "suggested_actions": ["Mock action"], ` +
+	`"similarities": [], "differences": [], "mentoring_notes": "Mock notes.", "operation": "count", "filter": {}}`
+
+// MockProvider returns deterministic canned responses so tests and demos don't need a running LLM
+type MockProvider struct {
+	// Template, when set, renders the reply from the request's Model and Prompt
+	Template *template.Template
+}
+
+// NewMockProvider builds a mock provider, using text as the reply template when set
+func NewMockProvider(text string) (*MockProvider, error) {
+	if text == "" {
+		return &MockProvider{}, nil
+	}
+	tmpl, err := template.New("mock").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &MockProvider{Template: tmpl}, nil
+}
+
+// Generate returns the canned or templated reply
+func (p *MockProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+
+	prompt := req.Prompt
+	if len(req.Messages) > 0 {
+		prompt = req.Messages[len(req.Messages)-1].Content
+	}
+
+	text := "Mock summary."
+	switch {
+	case req.JSON:
+		text = mockJSONResponse
+	case p.Template != nil:
+		var buf bytes.Buffer
+		if err := p.Template.Execute(&buf, map[string]string{"Model": req.Model, "Prompt": prompt}); err != nil {
+			return Response{}, err
+		}
+		text = buf.String()
+	}
+
+	return Response{Model: req.Model, Text: text, PromptTokens: len(prompt) / 4, CompletionTokens: len(text) / 4}, nil
+}
+
+// Embed returns a deterministic pseudo-embedding derived from a hash of the text's words
+func (p *MockProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	vector := make([]float64, 16)
+	for _, word := range bytes.Fields([]byte(text)) {
+		h := fnv.New32a()
+		h.Write(bytes.ToLower(word))
+		vector[h.Sum32()%uint32(len(vector))]++
+	}
+	return vector, nil
+}
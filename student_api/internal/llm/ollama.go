@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// OllamaProvider talks to a local Ollama server through its native generate API
+type OllamaProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// Generate sends the prompt to Ollama's /api/generate endpoint, or a conversation to /api/chat
+func (p *OllamaProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	if len(req.Messages) > 0 {
+		return p.chat(ctx, req)
+	}
+
+	payload := map[string]interface{}{
+		"model":  req.Model,
+		"prompt": req.Prompt,
+		"stream": false,
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	if req.JSON {
+		payload["format"] = "json"
+	}
+
+	var result struct {
+		Model           string `json:"model"`
+		Response        string `json:"response"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
+		Error           string `json:"error"`
+	}
+	if err := PostJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/api/generate", nil, payload, &result); err != nil {
+		return Response{}, err
+	}
+	if result.Error != "" || result.Response == "" {
+		return Response{}, fmt.Errorf("%w: ollama: %s", ErrInvalidOutput, orDefault(result.Error, "empty response"))
+	}
+
+	return Response{Model: result.Model, Text: result.Response, PromptTokens: result.PromptEvalCount, CompletionTokens: result.EvalCount}, nil
+}
+
+// chat sends a conversation to Ollama's /api/chat endpoint
+func (p *OllamaProvider) chat(ctx context.Context, req Request) (Response, error) {
+	payload := map[string]interface{}{
+		"model":    req.Model,
+		"messages": req.Conversation(),
+		"stream":   false,
+	}
+	if req.JSON {
+		payload["format"] = "json"
+	}
+
+	var result struct {
+		Model           string  `json:"model"`
+		Message         Message `json:"message"`
+		PromptEvalCount int     `json:"prompt_eval_count"`
+		EvalCount       int     `json:"eval_count"`
+		Error           string  `json:"error"`
+	}
+	if err := PostJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/api/chat", nil, payload, &result); err != nil {
+		return Response{}, err
+	}
+	if result.Error != "" || result.Message.Content == "" {
+		return Response{}, fmt.Errorf("%w: ollama: %s", ErrInvalidOutput, orDefault(result.Error, "empty response"))
+	}
+
+	return Response{Model: result.Model, Text: result.Message.Content, PromptTokens: result.PromptEvalCount, CompletionTokens: result.EvalCount}, nil
+}
+
+// Embed returns the embedding of text from Ollama's /api/embeddings endpoint
+func (p *OllamaProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	payload := map[string]interface{}{"model": model, "prompt": text}
+
+	var result struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := PostJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/api/embeddings", nil, payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("ollama: empty embedding")
+	}
+	return result.Embedding, nil
+}
+
+// Stream sends the prompt to Ollama's /api/generate endpoint with streaming enabled. The response
+// body is only read as fast as chunks is drained, so a slow consumer slows Ollama down through
+// TCP flow control instead of buffering the output in memory.
+func (p *OllamaProvider) Stream(ctx context.Context, req Request, chunks chan<- string) (Response, error) {
+	payload := map[string]interface{}{
+		"model":  req.Model,
+		"prompt": req.Prompt,
+		"stream": true,
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Response{}, err
+	}
+
+	url := strings.TrimRight(p.BaseURL, "/") + "/api/generate"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Streams can legitimately outlive the client timeout, so only the context bounds them
+	client := *p.Client
+	client.Timeout = 0
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return Response{}, &StatusError{URL: url, StatusCode: resp.StatusCode, Body: string(respBody), Message: ProviderErrorMessage(respBody)}
+	}
+
+	var result Response
+	var text strings.Builder
+	decoder := json.NewDecoder(bufio.NewReader(resp.Body))
+	for {
+		var line struct {
+			Model           string `json:"model"`
+			Response        string `json:"response"`
+			Done            bool   `json:"done"`
+			PromptEvalCount int    `json:"prompt_eval_count"`
+			EvalCount       int    `json:"eval_count"`
+			Error           string `json:"error"`
+		}
+		if err := decoder.Decode(&line); err != nil {
+			if ctx.Err() != nil {
+				return Response{}, ctx.Err()
+			}
+			return Response{}, fmt.Errorf("%w: ollama stream: %v", ErrInvalidOutput, err)
+		}
+		if line.Error != "" {
+			return Response{}, fmt.Errorf("%w: ollama: %s", ErrInvalidOutput, line.Error)
+		}
+
+		if line.Response != "" {
+			text.WriteString(line.Response)
+			select {
+			case chunks <- line.Response:
+			case <-ctx.Done():
+				return Response{}, ctx.Err()
+			}
+		}
+		if line.Done {
+			result.Model = line.Model
+			result.PromptTokens = line.PromptEvalCount
+			result.CompletionTokens = line.EvalCount
+			break
+		}
+	}
+
+	result.Text = text.String()
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAIProvider talks to any OpenAI-compatible chat completions API (OpenAI, vLLM, Ollama's /v1)
+type OpenAIProvider struct {
+	BaseURL string
+	APIKey  string
+	Client  *http.Client
+}
+
+// Generate sends the conversation (or the prompt as a single user message) to /chat/completions
+func (p *OpenAIProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	payload := map[string]interface{}{
+		"model":    req.Model,
+		"messages": req.Conversation(),
+	}
+	if req.JSON {
+		payload["response_format"] = map[string]string{"type": "json_object"}
+	}
+
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var result struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := PostJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/chat/completions", headers, payload, &result); err != nil {
+		return Response{}, err
+	}
+	if len(result.Choices) == 0 {
+		return Response{}, fmt.Errorf("%w: openai: response contained no choices", ErrInvalidOutput)
+	}
+
+	return Response{
+		Model:            result.Model,
+		Text:             result.Choices[0].Message.Content,
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+	}, nil
+}
+
+// Embed returns the embedding of text from the /embeddings endpoint
+func (p *OpenAIProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	payload := map[string]interface{}{"model": model, "input": text}
+
+	headers := map[string]string{}
+	if p.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.APIKey
+	}
+
+	var result struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := PostJSON(ctx, p.Client, strings.TrimRight(p.BaseURL, "/")+"/embeddings", headers, payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("openai: empty embedding")
+	}
+	return result.Data[0].Embedding, nil
+}
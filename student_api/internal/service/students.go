@@ -0,0 +1,210 @@
+// Package service holds the rules for creating, changing and deleting students, independent of
+// the REST, gRPC and GraphQL front ends that call them
+package service
+
+import (
+	"errors"
+	"reflect"
+
+	"student_api/student_api/internal/store"
+)
+
+// Kinds of failed student write; every error the service returns wraps one, so REST and gRPC can
+// map it to their own status codes
+var (
+	ErrNotFound = errors.New("student not found")
+	ErrInvalid  = errors.New("invalid student")
+	ErrConflict = errors.New("student conflicts with an existing record")
+)
+
+// Error is a failed student write; Error is the message shown to clients and Kind one of the
+// sentinel errors above
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+func (e *Error) Unwrap() error { return e.Kind }
+
+// Hooks struct to hold the application rules and side effects a Students service runs. Prepare
+// runs before the store is locked; the others run with its lock held, so events are published in
+// the order the changes were made. Nil hooks are skipped
+type Hooks struct {
+	// Prepare normalizes and validates a new student, returning a message suitable for the client
+	Prepare func(student *store.Student) error
+	// Merge applies an update to a stored student, reporting whether the email or address changed
+	Merge func(student *store.Student, update store.Student) (emailChanged, addressChanged bool)
+	// Validate normalizes and checks an updated student
+	Validate func(student *store.Student) error
+	// Conflict checks a student against the other stored students
+	Conflict func(student store.Student) error
+	// Created, Updated and Deleted run after the store has changed
+	Created func(student store.Student)
+	Updated func(student store.Student, emailChanged, addressChanged bool)
+	Deleted func(student store.Student)
+}
+
+// Students creates, changes and deletes the students of a store
+type Students struct {
+	store store.Store
+	hooks Hooks
+}
+
+// NewStudents returns a service writing to st and running hooks around each change
+func NewStudents(st store.Store, hooks Hooks) *Students {
+	return &Students{store: st, hooks: hooks}
+}
+
+// Store returns the store the service writes to
+func (s *Students) Store() store.Store {
+	return s.store
+}
+
+// Get returns the student with the ID
+func (s *Students) Get(id int) (store.Student, bool) {
+	s.store.Lock()
+	defer s.store.Unlock()
+	return s.store.Get(id)
+}
+
+// List returns the students match accepts, ordered by ID; a nil match accepts every student
+func (s *Students) List(match func(store.Student) bool) []store.Student {
+	s.store.Lock()
+	defer s.store.Unlock()
+	var matched []store.Student
+	for _, student := range s.store.List() {
+		if match == nil || match(student) {
+			matched = append(matched, student)
+		}
+	}
+	return matched
+}
+
+// Create validates and stores a new student, resetting the fields clients may not set
+func (s *Students) Create(student store.Student) (store.Student, error) {
+	if s.hooks.Prepare != nil {
+		if err := s.hooks.Prepare(&student); err != nil {
+			return student, &Error{ErrInvalid, err.Error()}
+		}
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if err := s.conflict(student); err != nil {
+		return student, err
+	}
+	student.AdvisorID = 0
+	student.EmailVerified = false
+	student.Alumni, student.GraduationYear = false, 0
+	if student.Address != nil {
+		student.Address.Location = nil
+	}
+	student.ID = s.store.NextID()
+	s.store.Put(student)
+	if s.hooks.Created != nil {
+		s.hooks.Created(student)
+	}
+	return student, nil
+}
+
+// Update applies the non-empty fields of an update to a stored student
+func (s *Students) Update(id int, update store.Student) (store.Student, error) {
+	return s.UpdateIf(id, nil, update)
+}
+
+// UpdateIf applies an update like Update when check accepts the stored student, so a change
+// computed from an earlier read is not applied over a newer one; a check error is a conflict
+func (s *Students) UpdateIf(id int, check func(store.Student) error, update store.Student) (store.Student, error) {
+	return s.modify(id, func(student *store.Student) (bool, bool, error) {
+		if check != nil {
+			if err := check(*student); err != nil {
+				return false, false, &Error{ErrConflict, err.Error()}
+			}
+		}
+		var emailChanged, addressChanged bool
+		if s.hooks.Merge != nil {
+			emailChanged, addressChanged = s.hooks.Merge(student, update)
+		}
+		return emailChanged, addressChanged, nil
+	})
+}
+
+// Change applies change to a stored student, then validates and stores it like Update; it is how
+// the fields clients cannot set directly, such as the status, advisor or geocoded location, are
+// written. An error from change is returned as is and nothing is stored. The email counts as
+// changed when it differs, and the address when it differs and has not been geocoded
+func (s *Students) Change(id int, change func(student *store.Student) error) (store.Student, error) {
+	return s.modify(id, func(student *store.Student) (bool, bool, error) {
+		before := *student
+		if err := change(student); err != nil {
+			return false, false, err
+		}
+		emailChanged := student.Email != before.Email
+		addressChanged := student.Address != nil && student.Address.Location == nil && !reflect.DeepEqual(before.Address, student.Address)
+		return emailChanged, addressChanged, nil
+	})
+}
+
+// modify runs apply on a copy of a stored student, reporting whether the email or address changed,
+// then validates, checks and stores the result and runs the Updated hook. On failure the stored
+// student is returned unchanged
+func (s *Students) modify(id int, apply func(student *store.Student) (emailChanged, addressChanged bool, err error)) (store.Student, error) {
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	stored, exists := s.store.Get(id)
+	if !exists {
+		return stored, &Error{ErrNotFound, "Student not found"}
+	}
+	student := stored
+	emailChanged, addressChanged, err := apply(&student)
+	if err != nil {
+		return stored, err
+	}
+	if s.hooks.Validate != nil {
+		if err := s.hooks.Validate(&student); err != nil {
+			return stored, &Error{ErrInvalid, err.Error()}
+		}
+	}
+	if err := s.conflict(student); err != nil {
+		return stored, err
+	}
+
+	s.store.Put(student)
+	if s.hooks.Updated != nil {
+		s.hooks.Updated(student, emailChanged, addressChanged)
+	}
+	return student, nil
+}
+
+// Delete removes a student; alumni are only deleted when purge is set
+func (s *Students) Delete(id int, purge bool) error {
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	student, exists := s.store.Get(id)
+	if !exists {
+		return &Error{ErrNotFound, "Student not found"}
+	}
+	if student.Alumni && !purge {
+		return &Error{ErrConflict, "Alumni are kept in the alumni directory; use ?purge=true to delete"}
+	}
+
+	s.store.Delete(id)
+	if s.hooks.Deleted != nil {
+		s.hooks.Deleted(student)
+	}
+	return nil
+}
+
+// conflict runs the Conflict hook; the caller must hold the store's lock
+func (s *Students) conflict(student store.Student) error {
+	if s.hooks.Conflict == nil {
+		return nil
+	}
+	if err := s.hooks.Conflict(student); err != nil {
+		return &Error{ErrConflict, err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"student_api/student_api/internal/store"
+)
+
+func TestCreateAfterDeleteDoesNotReuseID(t *testing.T) {
+	svc := NewStudents(store.NewMemory(), Hooks{})
+	for _, name := range []string{"Ann", "Ben", "Cal"} {
+		if _, err := svc.Create(store.Student{Name: name}); err != nil {
+			t.Fatalf("Create(%s): %v", name, err)
+		}
+	}
+	if err := svc.Delete(2, false); err != nil {
+		t.Fatalf("Delete(2): %v", err)
+	}
+
+	created, err := svc.Create(store.Student{Name: "Dee"})
+	if err != nil {
+		t.Fatalf("Create(Dee): %v", err)
+	}
+	if created.ID != 4 {
+		t.Errorf("new student got ID %d, want 4", created.ID)
+	}
+	if cal, _ := svc.Get(3); cal.Name != "Cal" {
+		t.Errorf("student 3 is %q, want Cal", cal.Name)
+	}
+}
+
+func TestCreateResetsServerFields(t *testing.T) {
+	svc := NewStudents(store.NewMemory(), Hooks{})
+	created, err := svc.Create(store.Student{
+		ID: 42, Name: "Ann", AdvisorID: 7, EmailVerified: true, Alumni: true, GraduationYear: 2020,
+		Address: &store.Address{City: "Pune", Location: &store.GeoPoint{Latitude: 1, Longitude: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID != 1 || created.AdvisorID != 0 || created.EmailVerified || created.Alumni || created.GraduationYear != 0 {
+		t.Errorf("server fields not reset: %+v", created)
+	}
+	if created.Address.Location != nil {
+		t.Errorf("address location not cleared")
+	}
+}
+
+func TestCreateRunsHooks(t *testing.T) {
+	var created []store.Student
+	svc := NewStudents(store.NewMemory(), Hooks{
+		Prepare: func(student *store.Student) error {
+			if student.Name == "" {
+				return errors.New("Name is required")
+			}
+			return nil
+		},
+		Conflict: func(student store.Student) error {
+			if student.Email == "taken@example.com" {
+				return errors.New("Email already in use")
+			}
+			return nil
+		},
+		Created: func(student store.Student) { created = append(created, student) },
+	})
+
+	if _, err := svc.Create(store.Student{}); !errors.Is(err, ErrInvalid) || err.Error() != "Name is required" {
+		t.Errorf("Create without a name: got %v, want ErrInvalid", err)
+	}
+	if _, err := svc.Create(store.Student{Name: "Ann", Email: "taken@example.com"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Create with a taken email: got %v, want ErrConflict", err)
+	}
+	if _, err := svc.Create(store.Student{Name: "Ann"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(created) != 1 || created[0].Name != "Ann" {
+		t.Errorf("Created hook saw %v, want only Ann", created)
+	}
+	if n := svc.Store().Len(); n != 1 {
+		t.Errorf("store holds %d students, want 1", n)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	var updated []store.Student
+	svc := NewStudents(store.NewMemory(), Hooks{
+		Merge: func(student *store.Student, update store.Student) (bool, bool) {
+			emailChanged := update.Email != "" && update.Email != student.Email
+			if update.Name != "" {
+				student.Name = update.Name
+			}
+			if update.Email != "" {
+				student.Email = update.Email
+			}
+			return emailChanged, false
+		},
+		Validate: func(student *store.Student) error {
+			if student.Name == "invalid" {
+				return errors.New("Invalid name")
+			}
+			return nil
+		},
+		Updated: func(student store.Student, emailChanged, addressChanged bool) {
+			if !emailChanged {
+				t.Errorf("Updated hook: email change not reported")
+			}
+			updated = append(updated, student)
+		},
+	})
+	if _, err := svc.Create(store.Student{Name: "Ann", Email: "ann@example.com"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := svc.Update(9, store.Student{Name: "Ben"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update of a missing student: got %v, want ErrNotFound", err)
+	}
+	if _, err := svc.Update(1, store.Student{Name: "invalid"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Update with an invalid name: got %v, want ErrInvalid", err)
+	}
+	if stored, _ := svc.Get(1); stored.Name != "Ann" {
+		t.Errorf("rejected update was stored: name is %q", stored.Name)
+	}
+
+	student, err := svc.Update(1, store.Student{Email: "ann@school.example"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if student.Name != "Ann" || student.Email != "ann@school.example" {
+		t.Errorf("Update returned %+v", student)
+	}
+	if len(updated) != 1 {
+		t.Errorf("Updated hook ran %d times, want 1", len(updated))
+	}
+}
+
+func TestDeleteKeepsAlumniUnlessPurged(t *testing.T) {
+	st := store.NewMemory()
+	st.Put(store.Student{ID: 1, Name: "Ann", Alumni: true})
+	var deleted []int
+	svc := NewStudents(st, Hooks{Deleted: func(student store.Student) { deleted = append(deleted, student.ID) }})
+
+	if err := svc.Delete(1, false); !errors.Is(err, ErrConflict) {
+		t.Errorf("Delete of an alumnus: got %v, want ErrConflict", err)
+	}
+	if err := svc.Delete(1, true); err != nil {
+		t.Errorf("Delete with purge: %v", err)
+	}
+	if err := svc.Delete(1, true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: got %v, want ErrNotFound", err)
+	}
+	if len(deleted) != 1 || deleted[0] != 1 {
+		t.Errorf("Deleted hook saw %v, want [1]", deleted)
+	}
+}
+
+func TestList(t *testing.T) {
+	svc := NewStudents(store.NewMemory(), Hooks{})
+	for _, name := range []string{"Ann", "Ben", "Cal"} {
+		svc.Create(store.Student{Name: name})
+	}
+
+	if all := svc.List(nil); len(all) != 3 || all[0].Name != "Ann" || all[2].Name != "Cal" {
+		t.Errorf("List(nil) = %v", all)
+	}
+	matched := svc.List(func(student store.Student) bool { return student.Name != "Ben" })
+	if len(matched) != 2 || matched[1].ID != 3 {
+		t.Errorf("List(not Ben) = %v", matched)
+	}
+}
+
+func TestUpdateIfRejectsChangedStudent(t *testing.T) {
+	svc := NewStudents(store.NewMemory(), Hooks{
+		Merge: func(student *store.Student, update store.Student) (bool, bool) {
+			student.Name = update.Name
+			return false, false
+		},
+	})
+	svc.Create(store.Student{Name: "Ann"})
+	unchanged := func(name string) func(store.Student) error {
+		return func(student store.Student) error {
+			if student.Name != name {
+				return errors.New("Student has changed")
+			}
+			return nil
+		}
+	}
+
+	if _, err := svc.UpdateIf(1, unchanged("Anne"), store.Student{Name: "Ben"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("UpdateIf over a changed student: got %v, want ErrConflict", err)
+	}
+	if stored, _ := svc.Get(1); stored.Name != "Ann" {
+		t.Errorf("rejected update was stored: name is %q", stored.Name)
+	}
+	if student, err := svc.UpdateIf(1, unchanged("Ann"), store.Student{Name: "Ben"}); err != nil || student.Name != "Ben" {
+		t.Errorf("UpdateIf = %+v, %v", student, err)
+	}
+}
+
+func TestChange(t *testing.T) {
+	type change struct {
+		student                      store.Student
+		emailChanged, addressChanged bool
+	}
+	var changes []change
+	svc := NewStudents(store.NewMemory(), Hooks{
+		Updated: func(student store.Student, emailChanged, addressChanged bool) {
+			changes = append(changes, change{student, emailChanged, addressChanged})
+		},
+	})
+	svc.Create(store.Student{Name: "Ann", Email: "ann@example.com"})
+	refused := errors.New("refused")
+
+	if _, err := svc.Change(1, func(student *store.Student) error {
+		student.Name = "Ben"
+		return refused
+	}); err != refused {
+		t.Errorf("Change with a failing change: got %v, want the change's error", err)
+	}
+	if stored, _ := svc.Get(1); stored.Name != "Ann" || len(changes) != 0 {
+		t.Errorf("refused change was stored: %+v, %d hook calls", stored, len(changes))
+	}
+	if _, err := svc.Change(9, func(*store.Student) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Change of a missing student: got %v, want ErrNotFound", err)
+	}
+
+	student, err := svc.Change(1, func(student *store.Student) error {
+		student.AdvisorID = 3
+		return nil
+	})
+	if err != nil || student.AdvisorID != 3 {
+		t.Fatalf("Change = %+v, %v", student, err)
+	}
+	svc.Change(1, func(student *store.Student) error {
+		student.Email = "ann@school.example"
+		student.Address = &store.Address{City: "Pune"}
+		return nil
+	})
+	svc.Change(1, func(student *store.Student) error {
+		student.Address = &store.Address{City: "Pune", Location: &store.GeoPoint{Latitude: 18.5, Longitude: 73.8}}
+		return nil
+	})
+
+	if len(changes) != 3 {
+		t.Fatalf("Updated hook ran %d times, want 3", len(changes))
+	}
+	if changes[0].emailChanged || changes[0].addressChanged {
+		t.Errorf("advisor change reported email %v, address %v", changes[0].emailChanged, changes[0].addressChanged)
+	}
+	if !changes[1].emailChanged || !changes[1].addressChanged {
+		t.Errorf("email and address change reported email %v, address %v", changes[1].emailChanged, changes[1].addressChanged)
+	}
+	if changes[2].addressChanged {
+		t.Errorf("storing a geocoded address reported an address change")
+	}
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store is implemented by the backends that keep the student records. Lock and Unlock guard
+// every other method: callers hold the lock around each call, and across a read and the writes
+// that depend on it, so a check such as a uniqueness test and the write it guards are atomic
+type Store interface {
+	sync.Locker
+	// Get returns the student with the ID
+	Get(id int) (Student, bool)
+	// List returns every student ordered by ID
+	List() []Student
+	// Put stores a student under its ID, replacing any stored before
+	Put(student Student)
+	// Delete removes the student with the ID
+	Delete(id int)
+	// Len returns the number of students
+	Len() int
+	// NextID reserves an ID for a new student; IDs are never reused, even after a delete
+	NextID() int
+}
+
+// Memory keeps the students in a map; its contents are lost on restart unless a snapshot
+// restores them
+type Memory struct {
+	mu       sync.Mutex
+	students map[int]Student
+	lastID   int
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{students: make(map[int]Student)}
+}
+
+func (m *Memory) Lock()   { m.mu.Lock() }
+func (m *Memory) Unlock() { m.mu.Unlock() }
+
+func (m *Memory) Get(id int) (Student, bool) {
+	student, exists := m.students[id]
+	return student, exists
+}
+
+func (m *Memory) List() []Student {
+	list := make([]Student, 0, len(m.students))
+	for _, student := range m.students {
+		list = append(list, student)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (m *Memory) Put(student Student) {
+	m.students[student.ID] = student
+	if student.ID > m.lastID {
+		m.lastID = student.ID
+	}
+}
+
+func (m *Memory) Delete(id int) { delete(m.students, id) }
+
+func (m *Memory) Len() int { return len(m.students) }
+
+func (m *Memory) NextID() int {
+	m.lastID++
+	return m.lastID
+}
@@ -0,0 +1,17 @@
+package store
+
+import "testing"
+
+func TestMemoryNextIDFollowsRestoredStudents(t *testing.T) {
+	m := NewMemory()
+	m.Put(Student{ID: 5, Name: "Ann"})
+	m.Put(Student{ID: 2, Name: "Ben"})
+
+	if id := m.NextID(); id != 6 {
+		t.Errorf("NextID() = %d, want 6", id)
+	}
+	m.Delete(5)
+	if id := m.NextID(); id != 7 {
+		t.Errorf("NextID() after a delete = %d, want 7", id)
+	}
+}
@@ -0,0 +1,131 @@
+// Package store holds the student record and the storage it is kept in
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DateLayout is the format of calendar dates such as Student.DateOfBirth
+const DateLayout = "2006-01-02"
+
+// Student struct to hold student data; age is derived from DateOfBirth (see Age)
+type Student struct {
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Email       string           `json:"email"`
+	Phone       string           `json:"phone,omitempty"`
+	Address     *Address         `json:"address,omitempty"`
+	DateOfBirth string           `json:"date_of_birth,omitempty"`
+	Gender      string           `json:"gender,omitempty"`
+	Nationality string           `json:"nationality,omitempty"`
+	Guardian    *GuardianContact `json:"guardian,omitempty"`
+	// EmailVerified is cleared whenever the email changes and set through the verification link
+	EmailVerified bool `json:"email_verified"`
+	// AdvisorID is set through PUT /students/{id}/advisor
+	AdvisorID int      `json:"advisor_id,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	// Status is set on creation and then only changes through POST /students/{id}/status
+	Status string `json:"status"`
+	// GradeLevel is the student's year or grade, advanced by POST /admin/roster/promote
+	GradeLevel int `json:"grade_level,omitempty"`
+	// Alumni and GraduationYear are set when the student graduates
+	Alumni         bool `json:"alumni"`
+	GraduationYear int  `json:"graduation_year,omitempty"`
+	// ExternalIDs maps other campus systems (e.g. "sis", "library_card") to the student's ID
+	// there; each ID is unique within its system
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	// Custom holds the values of the admin-defined fields in /admin/custom-fields
+	Custom map[string]interface{} `json:"custom,omitempty"`
+
+	// RecordedAge is only stored for students created without a date of birth; clients send
+	// and read it as age
+	RecordedAge int `json:"-"`
+}
+
+// Address struct to hold a postal address; Country is an ISO 3166-1 alpha-2 code
+type Address struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	// Location is set by the geocoder and cleared whenever the address is replaced
+	Location *GeoPoint `json:"location,omitempty"`
+}
+
+// GeoPoint struct to hold a latitude and longitude in decimal degrees
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GuardianContact struct to hold the contact details of a student's parent or guardian
+type GuardianContact struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	Email        string `json:"email,omitempty"`
+}
+
+// Age returns the student's age derived from DateOfBirth, or the age recorded for students
+// created before a date of birth was collected
+func (s Student) Age() int {
+	dob, err := time.Parse(DateLayout, s.DateOfBirth)
+	if err != nil {
+		return s.RecordedAge
+	}
+	return AgeOn(dob, time.Now())
+}
+
+// AgeOn returns the age in whole years on the given day of someone born on dob
+func AgeOn(dob, day time.Time) int {
+	age := day.Year() - dob.Year()
+	if day.Month() < dob.Month() || (day.Month() == dob.Month() && day.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+// studentJSON has Student's fields without its JSON methods
+type studentJSON Student
+
+// MarshalJSON includes the derived age alongside the stored fields
+func (s Student) MarshalJSON() ([]byte, error) {
+	return s.marshalJSON(nil)
+}
+
+// marshalJSON encodes the stored fields, the derived age and any computed fields
+func (s Student) marshalJSON(computed map[string]interface{}) ([]byte, error) {
+	return json.Marshal(struct {
+		studentJSON
+		Age      int                    `json:"age"`
+		Computed map[string]interface{} `json:"computed,omitempty"`
+	}{studentJSON(s), s.Age(), computed})
+}
+
+// WithComputed struct to hold a student as the API returns it, with the values of the
+// application's computed fields
+type WithComputed struct {
+	Student
+	Computed map[string]interface{}
+}
+
+// MarshalJSON adds the computed fields to the student's JSON
+func (s WithComputed) MarshalJSON() ([]byte, error) {
+	return s.Student.marshalJSON(s.Computed)
+}
+
+// UnmarshalJSON accepts an explicit age from clients that do not send a date of birth
+func (s *Student) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*studentJSON
+		Age int `json:"age"`
+	}{studentJSON: (*studentJSON)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.RecordedAge = aux.Age
+	return nil
+}
@@ -0,0 +1,640 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Job states reported by GET /jobs/{id}; a pending job may be waiting to be retried
+const (
+	JobPending  = "pending"
+	JobRunning  = "running"
+	JobComplete = "complete"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// maxJobProgressErrors bounds the failed items a job's progress lists; Failed still counts them all
+const maxJobProgressErrors = 1000
+
+// jobIDContextKey stores the ID of the job a handler is running on its context
+const jobIDContextKey contextKey = "job_id"
+
+// jobsMu guards jobs, readyJobs and nextJobID; workers wait on jobsReady for readyJobs to fill
+var (
+	jobs        = make(map[int]*Job)
+	readyJobs   []int
+	jobsMu      sync.Mutex
+	jobsReady   = sync.NewCond(&jobsMu)
+	jobsChanged = make(chan struct{}, 1)
+	nextJobID   int
+
+	// jobHandlers runs each job type and jobFailureHandlers is told when a job of the type has
+	// used up its attempts; both are filled in by startJobQueue
+	jobHandlers        map[string]JobHandler
+	jobFailureHandlers map[string]JobFailureHandler
+)
+
+// JobHandler runs one attempt of a job with its payload; ctx is canceled when the job is, and an
+// error fails the attempt
+type JobHandler func(ctx context.Context, payload json.RawMessage) ([]byte, error)
+
+// JobFailureHandler is called with a job and its payload once the job has failed for good
+type JobFailureHandler func(job Job, payload json.RawMessage)
+
+// JobOptions struct to hold how a job is retried: it runs up to MaxAttempts times, waiting
+// Backoff after the first failure and twice as long after each further one. Delay holds the
+// first attempt back
+type JobOptions struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	Delay       time.Duration
+}
+
+// Job struct to hold the state of a background task
+type Job struct {
+	ID          int             `json:"id"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	NextRunAt   *time.Time      `json:"next_run_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Progress    *JobProgress    `json:"progress,omitempty"`
+	// QueuePosition is how many queued jobs, this one included, a worker takes before it; it is
+	// only reported by GET /jobs/{id} and while the job waits for a worker
+	QueuePosition int `json:"queue_position,omitempty"`
+
+	payload json.RawMessage
+	backoff time.Duration
+	caller  string
+	tenant  string
+	queued  bool
+	wake    int
+	cancel  context.CancelFunc
+}
+
+// JobProgress struct to hold how far a job that works through many items has got; Errors lists
+// the first failed items by their position, such as the row of an import. Progress is saved with
+// the job, so a job that runs again after a failure or restart can continue from Processed
+type JobProgress struct {
+	Total     int            `json:"total"`
+	Processed int            `json:"processed"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Percent   float64        `json:"percent"`
+	Errors    []JobItemError `json:"errors,omitempty"`
+}
+
+// JobItemError struct to hold why one item of a job failed
+type JobItemError struct {
+	Item  int    `json:"item"`
+	Error string `json:"error"`
+}
+
+// jobRecord struct to hold a job as saved in JOBS_FILE, with what is needed to run it again
+type jobRecord struct {
+	Job
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	BackoffMS int64           `json:"backoff_ms,omitempty"`
+	Caller    string          `json:"caller,omitempty"`
+	Tenant    string          `json:"tenant,omitempty"`
+}
+
+// startJobQueue registers the job types, reloads the jobs saved in JOBS_FILE and starts
+// JOB_WORKERS workers; jobs that were running when the server stopped run again. Old jobs are
+// pruned by the retention task
+func (s *Server) startJobQueue() error {
+	jobHandlers = map[string]JobHandler{
+		"summary":          s.runSummaryJob,
+		"batch-summary":    s.runBatchSummaryJob,
+		"llm-model-pull":   s.runModelPullJob,
+		"ldap-import":      s.runLDAPImportJob,
+		"csv-import":       s.runCSVImportJob,
+		"webhook-delivery": s.runWebhookDeliveryJob,
+		"email":            s.runEmailJob,
+		"sms":              s.runSMSJob,
+		"chat":             s.runChatJob,
+		"student-import":   s.runStudentImportJob,
+	}
+	jobFailureHandlers = map[string]JobFailureHandler{
+		"webhook-delivery": s.deadLetterWebhookDelivery,
+	}
+
+	if s.config.JobsFile != "" {
+		if err := s.loadJobs(); err != nil {
+			return err
+		}
+		go s.saveJobsOnChange()
+	}
+	for i := 0; i < s.config.JobWorkers; i++ {
+		go s.runJobWorker()
+	}
+	return nil
+}
+
+// enqueueJob queues a job of a registered type; ctx attributes the job to the caller's API key
+// and tenant, as backgroundContext does
+func (s *Server) enqueueJob(ctx context.Context, jobType string, payload interface{}, opts JobOptions) (Job, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, err
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+
+	jobsMu.Lock()
+	nextJobID++
+	job := &Job{
+		ID: nextJobID, Type: jobType, Status: JobPending, MaxAttempts: opts.MaxAttempts, CreatedAt: time.Now(),
+		payload: body, backoff: opts.Backoff, caller: callerID(ctx), tenant: tenantFromContext(ctx),
+	}
+	jobs[job.ID] = job
+	queueJobAfter(job, opts.Delay)
+	snapshot := *job
+	jobsMu.Unlock()
+
+	s.markJobsChanged()
+	return snapshot, nil
+}
+
+// queueJob hands a pending job to the workers; the caller must hold jobsMu
+func queueJob(job *Job) {
+	if job.queued {
+		return
+	}
+	job.queued = true
+	job.NextRunAt = nil
+	readyJobs = append(readyJobs, job.ID)
+	jobsReady.Signal()
+}
+
+// queuePosition returns the 1-based place of a job among the jobs waiting for a worker, or 0 when
+// it is not waiting; the caller must hold jobsMu
+func queuePosition(job *Job) int {
+	if job.Status != JobPending || !job.queued {
+		return 0
+	}
+	position := 0
+	for _, id := range readyJobs {
+		if queued := jobs[id]; queued != nil && queued.Status == JobPending {
+			position++
+		}
+		if id == job.ID {
+			return position
+		}
+	}
+	return 0
+}
+
+// queueJobAfter queues a pending job once wait has passed, unless it is canceled, retried or
+// rescheduled meanwhile; the caller must hold jobsMu
+func queueJobAfter(job *Job, wait time.Duration) {
+	if wait <= 0 {
+		queueJob(job)
+		return
+	}
+	next := time.Now().Add(wait)
+	job.NextRunAt = &next
+	job.wake++
+	wake := job.wake
+	time.AfterFunc(wait, func() {
+		jobsMu.Lock()
+		defer jobsMu.Unlock()
+		if jobs[job.ID] == job && job.Status == JobPending && job.wake == wake {
+			queueJob(job)
+		}
+	})
+}
+
+// runJobWorker runs queued jobs one at a time
+func (s *Server) runJobWorker() {
+	for {
+		jobsMu.Lock()
+		for len(readyJobs) == 0 {
+			jobsReady.Wait()
+		}
+		job := jobs[readyJobs[0]]
+		readyJobs = readyJobs[1:]
+		if job == nil || job.Status != JobPending {
+			if job != nil {
+				job.queued = false
+			}
+			jobsMu.Unlock()
+			continue
+		}
+
+		now := time.Now()
+		ctx, cancel := context.WithCancel(jobContext(job))
+		job.queued = false
+		job.cancel = cancel
+		job.Status = JobRunning
+		job.Attempts++
+		job.StartedAt = &now
+		handler, payload := jobHandlers[job.Type], job.payload
+		jobsMu.Unlock()
+		s.markJobsChanged()
+
+		var result []byte
+		var err error
+		if handler == nil {
+			err = fmt.Errorf("unknown job type %q", job.Type)
+		} else {
+			result, err = handler(ctx, payload)
+		}
+		cancel()
+		s.finishJob(job, result, err)
+	}
+}
+
+// jobContext returns a context attributed to the caller and tenant that queued the job, which
+// also lets the handler report progress
+func jobContext(job *Job) context.Context {
+	ctx := context.WithValue(context.Background(), callerContextKey, job.caller)
+	ctx = context.WithValue(ctx, jobIDContextKey, job.ID)
+	return context.WithValue(ctx, tenantContextKey, job.tenant)
+}
+
+// savedJobProgress returns the progress last reported by the job running with ctx, empty on its
+// first attempt
+func savedJobProgress(ctx context.Context) JobProgress {
+	id, _ := ctx.Value(jobIDContextKey).(int)
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if job := jobs[id]; job != nil && job.Progress != nil {
+		progress := *job.Progress
+		progress.Errors = append([]JobItemError{}, progress.Errors...)
+		return progress
+	}
+	return JobProgress{}
+}
+
+// reportJobProgress records the progress of the job running with ctx and asks for it to be saved
+func (s *Server) reportJobProgress(ctx context.Context, progress JobProgress) {
+	id, ok := ctx.Value(jobIDContextKey).(int)
+	if !ok {
+		return
+	}
+	if len(progress.Errors) > maxJobProgressErrors {
+		progress.Errors = progress.Errors[:maxJobProgressErrors]
+	}
+	progress.Errors = append([]JobItemError(nil), progress.Errors...)
+	progress.Percent = 100
+	if progress.Total > 0 {
+		progress.Percent = math.Round(float64(progress.Processed)*1000/float64(progress.Total)) / 10
+	}
+
+	jobsMu.Lock()
+	if job := jobs[id]; job != nil {
+		job.Progress = &progress
+	}
+	jobsMu.Unlock()
+	s.markJobsChanged()
+}
+
+// finishJob records the outcome of an attempt, scheduling a retry while attempts remain
+func (s *Server) finishJob(job *Job, result []byte, err error) {
+	defer s.markJobsChanged()
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	now := time.Now()
+	job.cancel = nil
+	if job.Status == JobCanceled {
+		job.CompletedAt = &now
+		return
+	}
+	if err != nil {
+		job.Error = err.Error()
+		if job.Attempts < job.MaxAttempts {
+			job.Status = JobPending
+			queueJobAfter(job, job.backoff<<uint(job.Attempts-1))
+			return
+		}
+		job.Status = JobFailed
+		job.CompletedAt = &now
+		log.Printf("Job %d (%s) failed after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+		if onFailed := jobFailureHandlers[job.Type]; onFailed != nil {
+			go onFailed(*job, job.payload)
+		}
+		return
+	}
+
+	job.Status = JobComplete
+	job.Error = ""
+	job.CompletedAt = &now
+	switch {
+	case len(result) == 0:
+		job.Result = nil
+	case json.Valid(result):
+		job.Result = result
+	default:
+		job.Result, _ = json.Marshal(string(result))
+	}
+}
+
+// markJobsChanged asks for the jobs to be saved to JOBS_FILE
+func (s *Server) markJobsChanged() {
+	if s.config.JobsFile == "" {
+		return
+	}
+	select {
+	case jobsChanged <- struct{}{}:
+	default:
+	}
+}
+
+// saveJobsOnChange writes the jobs to JOBS_FILE after changes, at most once a second
+func (s *Server) saveJobsOnChange() {
+	for range jobsChanged {
+		if err := s.saveJobs(); err != nil {
+			log.Printf("Error saving jobs to %s: %v", s.config.JobsFile, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// saveJobs replaces JOBS_FILE with the current jobs
+func (s *Server) saveJobs() error {
+	jobsMu.Lock()
+	records := make([]jobRecord, 0, len(jobs))
+	for _, job := range jobs {
+		records = append(records, jobRecord{Job: *job, Payload: job.payload, BackoffMS: job.backoff.Milliseconds(), Caller: job.caller, Tenant: job.tenant})
+	}
+	jobsMu.Unlock()
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	tmp := s.config.JobsFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, body, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.config.JobsFile)
+}
+
+// loadJobs restores the jobs saved in JOBS_FILE; unfinished jobs are queued again
+func (s *Server) loadJobs() error {
+	body, err := ioutil.ReadFile(s.config.JobsFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var records []jobRecord
+	if err := json.Unmarshal(body, &records); err != nil {
+		return fmt.Errorf("reading %s: %v", s.config.JobsFile, err)
+	}
+
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	requeued := 0
+	for _, record := range records {
+		job := record.Job
+		job.payload, job.caller, job.tenant = record.Payload, record.Caller, record.Tenant
+		job.backoff = time.Duration(record.BackoffMS) * time.Millisecond
+		job.queued, job.wake, job.cancel = false, 0, nil
+		jobs[job.ID] = &job
+		if job.ID > nextJobID {
+			nextJobID = job.ID
+		}
+
+		if job.Status == JobRunning {
+			job.Status = JobPending
+		}
+		if job.Status == JobPending {
+			wait := time.Duration(0)
+			if job.NextRunAt != nil {
+				wait = time.Until(*job.NextRunAt)
+			}
+			queueJobAfter(&job, wait)
+			requeued++
+		}
+	}
+	log.Printf("Loaded %d jobs from %s, %d to run", len(records), s.config.JobsFile, requeued)
+	return nil
+}
+
+// pruneJobs forgets finished jobs that completed more than JOB_RETENTION ago, returning how many
+func (s *Server) pruneJobs() int {
+	jobsMu.Lock()
+	pruned := 0
+	for id, job := range jobs {
+		if job.CompletedAt != nil && job.Status != JobRunning && time.Since(*job.CompletedAt) > s.config.JobRetention {
+			delete(jobs, id)
+			pruned++
+		}
+	}
+	jobsMu.Unlock()
+	if pruned > 0 {
+		s.markJobsChanged()
+	}
+	return pruned
+}
+
+// jobFromRequest looks up the job named by the {id} route variable, writing the error response
+// when there is none; the caller must hold jobsMu
+func jobFromRequest(w http.ResponseWriter, r *http.Request) (*Job, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return nil, false
+	}
+	job, exists := jobs[id]
+	if !exists {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return nil, false
+	}
+	return job, true
+}
+
+// summaryJobPayload struct to hold the payload of a summary job
+type summaryJobPayload struct {
+	StudentID int            `json:"student_id"`
+	Options   SummaryOptions `json:"options"`
+	Refresh   bool           `json:"refresh,omitempty"`
+}
+
+// runSummaryJob generates the summary of one student
+func (s *Server) runSummaryJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var req summaryJobPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	s.store.Lock()
+	student, exists := s.store.Get(req.StudentID)
+	s.store.Unlock()
+	if !exists {
+		return nil, errors.New("Student not found")
+	}
+
+	summary, err := s.studentSummary(ctx, student, req.Options, req.Refresh)
+	if errors.Is(err, ErrCircuitOpen) && s.config.DegradedMode == "template" {
+		summary, err = fallbackSummary(student), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(summary)
+}
+
+// createSummaryJob handles POST /students/{id}/summary to generate a summary asynchronously
+func (s *Server) createSummaryJob(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	refresh := r.URL.Query().Get("refresh") == "true"
+	job, err := s.enqueueJob(backgroundContext(r), "summary", summaryJobPayload{StudentID: id, Options: opts, Refresh: refresh}, JobOptions{})
+	if err != nil {
+		http.Error(w, "Error queueing job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+strconv.Itoa(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	jobsMu.Lock()
+	position := queuePosition(jobs[job.ID])
+	jobsMu.Unlock()
+	json.NewEncoder(w).Encode(map[string]interface{}{"job_id": job.ID, "status": job.Status, "queue_position": position})
+}
+
+// getJobs handles GET /jobs?type=&status=&limit= to list background jobs, newest first; limit
+// defaults to 100
+func getJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	jobsMu.Lock()
+	list := []Job{}
+	for _, job := range jobs {
+		if (query.Get("type") == "" || job.Type == query.Get("type")) && (query.Get("status") == "" || job.Status == query.Get("status")) {
+			list = append(list, *job)
+		}
+	}
+	jobsMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getJob handles GET /jobs/{id} to report the status of a background job and, while it waits for
+// a worker, its place in the queue
+func getJob(w http.ResponseWriter, r *http.Request) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	job, ok := jobFromRequest(w, r)
+	if !ok {
+		return
+	}
+	snapshot := *job
+	snapshot.QueuePosition = queuePosition(job)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// cancelJob handles POST /jobs/{id}/cancel to cancel a pending or running job; a running job
+// is asked to stop through its context and stays running until it does
+func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request) {
+	jobsMu.Lock()
+	job, ok := jobFromRequest(w, r)
+	if !ok {
+		jobsMu.Unlock()
+		return
+	}
+	switch job.Status {
+	case JobPending:
+		now := time.Now()
+		job.Status = JobCanceled
+		job.NextRunAt = nil
+		job.CompletedAt = &now
+	case JobRunning:
+		job.Status = JobCanceled
+		job.cancel()
+	default:
+		jobsMu.Unlock()
+		http.Error(w, "Job has already finished", http.StatusConflict)
+		return
+	}
+	snapshot := *job
+	jobsMu.Unlock()
+	s.markJobsChanged()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// retryJob handles POST /jobs/{id}/retry to run a failed or canceled job once more
+func (s *Server) retryJob(w http.ResponseWriter, r *http.Request) {
+	jobsMu.Lock()
+	job, ok := jobFromRequest(w, r)
+	if !ok {
+		jobsMu.Unlock()
+		return
+	}
+	if (job.Status != JobFailed && job.Status != JobCanceled) || job.cancel != nil {
+		jobsMu.Unlock()
+		http.Error(w, "Only failed or canceled jobs can be retried", http.StatusConflict)
+		return
+	}
+	job.Status = JobPending
+	job.Error, job.Result, job.CompletedAt = "", nil, nil
+	job.MaxAttempts = job.Attempts + 1
+	queueJob(job)
+	snapshot := *job
+	jobsMu.Unlock()
+	s.markJobsChanged()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapExternalSystem is the external ID namespace LDAP entries are matched on
+const ldapExternalSystem = "ldap"
+
+var (
+	ldapImportReport *LDAPImportReport
+	ldapImportMu     sync.Mutex
+
+	// ldapImportRunning is held for the duration of an import so runs never overlap
+	ldapImportRunning sync.Mutex
+)
+
+// LDAPImportReport struct to hold the outcome of an LDAP import; Skipped lists the entries that
+// could not be mapped to a student
+type LDAPImportReport struct {
+	Trigger    string             `json:"trigger"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+	Entries    int                `json:"entries"`
+	Skipped    []LDAPSkippedEntry `json:"skipped"`
+	Result     *SyncResult        `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// LDAPSkippedEntry struct to hold an LDAP entry left out of an import and why
+type LDAPSkippedEntry struct {
+	DN     string `json:"dn"`
+	Reason string `json:"reason"`
+}
+
+// startLDAPImports schedules the LDAP import when LDAP_IMPORT_SCHEDULE is set
+func (s *Server) startLDAPImports() error {
+	if s.config.LDAPImportSchedule == "" {
+		return nil
+	}
+	if s.config.LDAPURL == "" || s.config.LDAPBaseDN == "" {
+		return errors.New("LDAP_IMPORT_SCHEDULE requires LDAP_URL and LDAP_BASE_DN")
+	}
+	schedule, err := ParseCron(s.config.LDAPImportSchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("ldap-import", schedule, func() error {
+		_, err := s.enqueueJob(context.Background(), "ldap-import", nil, JobOptions{})
+		return err
+	})
+	return nil
+}
+
+// runLDAPImportJob runs a scheduled LDAP import as a background job
+func (s *Server) runLDAPImportJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	report, started := s.runLDAPImport("schedule", false)
+	if !started {
+		return nil, errors.New("an LDAP import is already running")
+	}
+	if report.Error != "" {
+		return nil, errors.New(report.Error)
+	}
+	return json.Marshal(report)
+}
+
+// runLDAPImport pulls the entries under LDAP_BASE_DN, maps them to students and reconciles them
+// into the store keyed on their LDAP ID; students without an LDAP ID are never touched and
+// students that left the OU are only deleted with LDAP_DELETE_MISSING. It returns false when
+// another import is already running
+func (s *Server) runLDAPImport(trigger string, dryRun bool) (LDAPImportReport, bool) {
+	if !ldapImportRunning.TryLock() {
+		return LDAPImportReport{}, false
+	}
+	defer ldapImportRunning.Unlock()
+
+	report := LDAPImportReport{Trigger: trigger, StartedAt: time.Now(), Skipped: []LDAPSkippedEntry{}}
+	entries, err := s.searchLDAPStudents()
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.Entries = len(entries)
+		desired := []Student{}
+		seen := make(map[string]string)
+		for _, entry := range entries {
+			student, err := s.studentFromLDAPEntry(entry)
+			if err != nil {
+				report.Skipped = append(report.Skipped, LDAPSkippedEntry{DN: entry.DN, Reason: err.Error()})
+				continue
+			}
+			id := student.ExternalIDs[ldapExternalSystem]
+			if other, duplicate := seen[id]; duplicate {
+				report.Skipped = append(report.Skipped, LDAPSkippedEntry{DN: entry.DN, Reason: "Duplicate ID " + id + " also used by " + other})
+				continue
+			}
+			seen[id] = entry.DN
+			desired = append(desired, student)
+		}
+
+		result := s.applyStudentSync(desired, SyncOptions{
+			Key:         "external:" + ldapExternalSystem,
+			DryRun:      dryRun,
+			Partial:     true,
+			KeepMissing: !s.config.LDAPDeleteMissing,
+		})
+		report.Result = &result
+	}
+	report.FinishedAt = time.Now()
+
+	if !dryRun {
+		ldapImportMu.Lock()
+		ldapImportReport = &report
+		ldapImportMu.Unlock()
+	}
+	if report.Error != "" {
+		s.postChatEvent(ChatImportFailed, "LDAP import failed", report.Error)
+	}
+	return report, true
+}
+
+// searchLDAPStudents binds to the directory and returns the entries matching LDAP_FILTER under
+// LDAP_BASE_DN, paging through large OUs
+func (s *Server) searchLDAPStudents() ([]*ldap.Entry, error) {
+	if s.config.LDAPURL == "" || s.config.LDAPBaseDN == "" {
+		return nil, errors.New("LDAP_URL and LDAP_BASE_DN must be set")
+	}
+	if s.config.LDAPAttributeMap["id"] == "" || s.config.LDAPAttributeMap["email"] == "" {
+		return nil, errors.New("LDAP_ATTRIBUTE_MAP must map id and email")
+	}
+
+	conn, err := ldap.DialURL(s.config.LDAPURL)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to LDAP: %w", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(30 * time.Second)
+
+	if s.config.LDAPStartTLS {
+		server, err := url.Parse(s.config.LDAPURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: server.Hostname()}); err != nil {
+			return nil, fmt.Errorf("starting TLS: %w", err)
+		}
+	}
+	if s.config.LDAPBindDN != "" {
+		if err := conn.Bind(s.config.LDAPBindDN, s.config.LDAPBindPassword); err != nil {
+			return nil, fmt.Errorf("binding to LDAP: %w", err)
+		}
+	}
+
+	attributes := make([]string, 0, len(s.config.LDAPAttributeMap))
+	for _, attribute := range s.config.LDAPAttributeMap {
+		attributes = append(attributes, attribute)
+	}
+	request := ldap.NewSearchRequest(s.config.LDAPBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, 0, false, s.config.LDAPFilter, attributes, nil)
+	result, err := conn.SearchWithPaging(request, 500)
+	if err != nil {
+		return nil, fmt.Errorf("searching LDAP: %w", err)
+	}
+	return result.Entries, nil
+}
+
+// studentFromLDAPEntry maps an entry's attributes to a student through LDAP_ATTRIBUTE_MAP; the
+// mapped id becomes the student's "ldap" external ID
+func (s *Server) studentFromLDAPEntry(entry *ldap.Entry) (Student, error) {
+	value := func(field string) string {
+		if attribute := s.config.LDAPAttributeMap[field]; attribute != "" {
+			return entry.GetAttributeValue(attribute)
+		}
+		return ""
+	}
+
+	id := value("id")
+	if id == "" {
+		return Student{}, errors.New("Missing " + s.config.LDAPAttributeMap["id"] + " attribute")
+	}
+	student := Student{
+		Name:        value("name"),
+		Email:       value("email"),
+		Phone:       value("phone"),
+		DateOfBirth: value("date_of_birth"),
+		Gender:      value("gender"),
+		Nationality: value("nationality"),
+		ExternalIDs: map[string]string{ldapExternalSystem: id},
+	}
+	for field, target := range map[string]*int{"grade_level": &student.GradeLevel, "age": &student.RecordedAge} {
+		if text := value(field); text != "" {
+			number, err := strconv.Atoi(text)
+			if err != nil {
+				return Student{}, fmt.Errorf("Invalid %s %q", field, text)
+			}
+			*target = number
+		}
+	}
+	normalizeStudent(&student)
+	return student, nil
+}
+
+// getLDAPImport handles GET /admin/ldap/import to return the report of the latest import
+func getLDAPImport(w http.ResponseWriter, r *http.Request) {
+	ldapImportMu.Lock()
+	report := ldapImportReport
+	ldapImportMu.Unlock()
+	if report == nil {
+		http.Error(w, "No LDAP import has run yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// runLDAPImportNow handles POST /admin/ldap/import/run?dry_run= to import from LDAP immediately;
+// a dry run returns the change report without applying it
+func (s *Server) runLDAPImportNow(w http.ResponseWriter, r *http.Request) {
+	report, started := s.runLDAPImport("admin", r.URL.Query().Get("dry_run") == "true")
+	if !started {
+		http.Error(w, "An LDAP import is already running", http.StatusConflict)
+		return
+	}
+	status := http.StatusOK
+	if report.Error != "" {
+		status = http.StatusBadGateway
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
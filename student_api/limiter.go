@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"student_api/student_api/internal/llm"
+)
+
+// ErrLLMQueueFull is returned when every LLM slot is busy and the wait queue is full
+var ErrLLMQueueFull = llm.ErrQueueFull
+
+// LimitProvider caps the number of concurrent calls to the wrapped provider and queues
+// a bounded number of callers waiting for a free slot
+type LimitProvider struct {
+	Provider LLMProvider
+	MaxQueue int
+
+	slots   chan struct{}
+	mu      sync.Mutex
+	waiting int
+}
+
+// NewLimitProvider wraps provider so that at most concurrency calls run at once
+func NewLimitProvider(provider LLMProvider, concurrency, maxQueue int) *LimitProvider {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &LimitProvider{Provider: provider, MaxQueue: maxQueue, slots: make(chan struct{}, concurrency)}
+}
+
+// Generate waits for a free slot, then calls the wrapped provider
+func (l *LimitProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	if err := l.acquire(ctx); err != nil {
+		return LLMResponse{}, err
+	}
+	defer func() { <-l.slots }()
+
+	return l.Provider.Generate(ctx, req)
+}
+
+// Stream waits for a free slot, then relays the wrapped provider's stream
+func (l *LimitProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	if err := l.acquire(ctx); err != nil {
+		return LLMResponse{}, err
+	}
+	defer func() { <-l.slots }()
+
+	return streamFrom(ctx, l.Provider, req, chunks)
+}
+
+// Embed waits for a free slot, then embeds text with the wrapped provider
+func (l *LimitProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer func() { <-l.slots }()
+
+	return embedFrom(ctx, l.Provider, model, text)
+}
+
+// acquire takes a slot, queueing behind other callers if none is free
+func (l *LimitProvider) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	if l.waiting >= l.MaxQueue {
+		l.mu.Unlock()
+		return ErrLLMQueueFull
+	}
+	l.waiting++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Waiting returns the number of callers queued for a slot
+func (l *LimitProvider) Waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiting
+}
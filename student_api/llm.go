@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"student_api/student_api/internal/llm"
+)
+
+// The provider types live in internal/llm; these names keep the wrappers and features of this
+// package reading as before
+type (
+	LLMProvider    = llm.Provider
+	Embedder       = llm.Embedder
+	Streamer       = llm.Streamer
+	LLMRequest     = llm.Request
+	LLMMessage     = llm.Message
+	LLMResponse    = llm.Response
+	LLMStatusError = llm.StatusError
+)
+
+// llmChain struct to hold a provider wrapped with the circuit breaker, tenant routing, the
+// concurrency limiter, usage tracking, the audit log and the deployment system prompt. Streams and
+// embeddings go through the same wrappers. The breaker is the default provider's; each tenant
+// provider has its own behind the tenant routing
+type llmChain struct {
+	LLMProvider
+	tenants  *TenantProvider
+	limiter  *LimitProvider
+	breaker  *CircuitBreakerProvider
+	streamer Streamer
+	// embeds is whether the primary provider can embed text at all
+	embeds bool
+}
+
+// newLLMChain wraps provider in the chain configured for the server
+func (s *Server) newLLMChain(provider LLMProvider) *llmChain {
+	breaker := s.newCircuitBreaker(provider)
+	tenants := &TenantProvider{Default: breaker, Lookup: s.tenantProvider}
+	limiter := NewLimitProvider(tenants, s.config.LLMConcurrency, s.config.LLMQueueDepth)
+	audit := &AuditProvider{Provider: &UsageProvider{Provider: limiter}, Record: s.auditLLMCall}
+	chain := &llmChain{
+		LLMProvider: &SystemPromptProvider{Provider: audit, Prompts: s.systemPrompts},
+		tenants:     tenants,
+		limiter:     limiter,
+		breaker:     breaker,
+	}
+	primary := primaryProvider(provider)
+	chain.streamer, _ = primary.(Streamer)
+	_, chain.embeds = primary.(Embedder)
+	return chain
+}
+
+// newCircuitBreaker wraps provider in a breaker with the configured threshold and cooldown
+func (s *Server) newCircuitBreaker(provider LLMProvider) *CircuitBreakerProvider {
+	return &CircuitBreakerProvider{Provider: provider, Threshold: s.config.BreakerThreshold, Cooldown: s.config.BreakerCooldown}
+}
+
+// Stream relays a stream through the same chain as Generate
+func (c *llmChain) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	return streamFrom(ctx, c.LLMProvider, req, chunks)
+}
+
+// Embed embeds text through the same chain as Generate; a provider that cannot embed is not
+// called at all, so the failure does not show up in the usage and audit logs
+func (c *llmChain) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	if !c.embeds {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	return embedFrom(ctx, c.LLMProvider, model, text)
+}
+
+// canStream reports whether the provider answering tenant relays its output as it is generated
+func (c *llmChain) canStream(tenant string) bool {
+	if provider, _, err := c.tenants.Lookup(tenant); err == nil && provider != nil {
+		_, ok := primaryProvider(provider).(Streamer)
+		return ok
+	}
+	return c.streamer != nil
+}
+
+// streamFrom relays the stream of provider, failing with ErrStreamingUnsupported when it cannot
+// stream
+func streamFrom(ctx context.Context, provider LLMProvider, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	streamer, ok := provider.(Streamer)
+	if !ok {
+		return LLMResponse{}, ErrStreamingUnsupported
+	}
+	return streamer.Stream(ctx, req, chunks)
+}
+
+// embedFrom embeds text with provider, failing with ErrEmbeddingsUnsupported when it cannot embed
+func embedFrom(ctx context.Context, provider LLMProvider, model, text string) ([]float64, error) {
+	embedder, ok := provider.(Embedder)
+	if !ok {
+		return nil, ErrEmbeddingsUnsupported
+	}
+	return embedder.Embed(ctx, model, text)
+}
+
+// primaryProvider returns the provider that answers first in p, looking through fallbacks,
+// retries and breakers
+func primaryProvider(p LLMProvider) LLMProvider {
+	for {
+		switch v := p.(type) {
+		case *FallbackProvider:
+			if len(v.Entries) == 0 {
+				return p
+			}
+			p = v.Entries[0].Provider
+		case *llm.RetryProvider:
+			p = v.Provider
+		case *CircuitBreakerProvider:
+			p = v.Provider
+		default:
+			return p
+		}
+	}
+}
+
+// newLLMProvider returns the provider selected by LLM_PROVIDER followed by any LLM_FALLBACK_CHAIN
+// entries, each with the retries of its policy; NewServer wraps it with the rest of the chain
+func newLLMProvider(cfg Config) (LLMProvider, error) {
+	newProvider := func(name string) (LLMProvider, error) {
+		return newBaseProvider(cfg, name, "", "")
+	}
+
+	primary, err := newProvider(cfg.LLMProvider)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := parseFallbackChain(cfg, newProvider)
+	if err != nil {
+		return nil, err
+	}
+	return &FallbackProvider{Entries: append([]FallbackEntry{{Name: cfg.LLMProvider, Provider: primary}}, chain...)}, nil
+}
+
+// newBaseProvider builds a single named provider wrapped with the retries of its LLM retry
+// policy; empty baseURL and apiKey fall back to the deployment-wide settings
+func newBaseProvider(cfg Config, name, baseURL, apiKey string) (LLMProvider, error) {
+	policy := cfg.retryPolicy("llm", name)
+	client := &http.Client{Timeout: policy.timeout()}
+
+	var provider LLMProvider
+	switch name {
+	case "openai":
+		provider = &llm.OpenAIProvider{BaseURL: firstNonEmpty(baseURL, cfg.OpenAIURL), APIKey: firstNonEmpty(apiKey, cfg.OpenAIKey), Client: client}
+	case "ollama":
+		provider = &llm.OllamaProvider{BaseURL: firstNonEmpty(baseURL, cfg.OllamaURL), Client: client}
+	case "mock":
+		mock, err := llm.NewMockProvider(cfg.MockLLMTemplate)
+		if err != nil {
+			return nil, err
+		}
+		provider = mock
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", name)
+	}
+	return &llm.RetryProvider{Provider: provider, MaxRetries: policy.MaxAttempts - 1, Backoff: policy.backoff()}, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"student_api/student_api/internal/llm"
+)
+
+// listLLMModels handles GET /admin/llm/models by proxying Ollama's tags API
+func (s *Server) listLLMModels(w http.ResponseWriter, r *http.Request) {
+	if s.config.LLMProvider != "ollama" {
+		http.Error(w, "Model management is only available for the Ollama provider", http.StatusNotImplemented)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, strings.TrimRight(s.config.OllamaURL, "/")+"/api/tags", nil)
+	if err != nil {
+		http.Error(w, "Error listing models", http.StatusInternalServerError)
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		http.Error(w, "Error contacting Ollama", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		http.Error(w, "Error listing models", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// modelPullPayload struct to hold the payload of a model pull job
+type modelPullPayload struct {
+	Name string `json:"name"`
+}
+
+// runModelPullJob downloads a model into Ollama
+func (s *Server) runModelPullJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var req modelPullPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	var result json.RawMessage
+	// Pulls can take minutes, so they use a client without the LLM request timeout
+	err := llm.PostJSON(ctx, http.DefaultClient, strings.TrimRight(s.config.OllamaURL, "/")+"/api/pull",
+		nil, map[string]interface{}{"name": req.Name, "stream": false}, &result)
+	return result, err
+}
+
+// pullLLMModel handles POST /admin/llm/models/pull to download a model into Ollama in the background
+func (s *Server) pullLLMModel(w http.ResponseWriter, r *http.Request) {
+	if s.config.LLMProvider != "ollama" {
+		http.Error(w, "Model management is only available for the Ollama provider", http.StatusNotImplemented)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.enqueueJob(backgroundContext(r), "llm-model-pull", modelPullPayload{Name: req.Name}, JobOptions{})
+	if err != nil {
+		http.Error(w, "Error queueing job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+strconv.Itoa(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"job_id": job.ID, "status": job.Status})
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	llmAuditLog    []LLMAuditEntry
+	llmAuditMu     sync.Mutex
+	nextLLMAuditID int
+
+	// auditNames matches the student names redacted from the audit log; it is rebuilt when the
+	// change log or the number of students shows the roster changed
+	auditNames        *regexp.Regexp
+	auditNamesVersion [2]int64
+	auditNamesMu      sync.Mutex
+
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-()]{7,}\d`)
+)
+
+// LLMAuditEntry struct to hold one recorded LLM interaction
+type LLMAuditEntry struct {
+	ID         int       `json:"id"`
+	Time       time.Time `json:"time"`
+	Caller     string    `json:"caller"`
+	Provider   string    `json:"provider,omitempty"`
+	Model      string    `json:"model"`
+	Prompt     string    `json:"prompt"`
+	Completion string    `json:"completion,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	LatencyMS  int64     `json:"latency_ms"`
+}
+
+// AuditProvider records every prompt and completion passing through the wrapped provider
+type AuditProvider struct {
+	Provider LLMProvider
+	Record   func(ctx context.Context, start time.Time, req LLMRequest, resp LLMResponse, err error)
+}
+
+// Generate calls the wrapped provider and appends the interaction to the audit log
+func (p *AuditProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	start := time.Now()
+	resp, err := p.Provider.Generate(ctx, req)
+	p.Record(ctx, start, req, resp, err)
+	return resp, err
+}
+
+// Stream relays the wrapped provider's stream and appends the interaction to the audit log
+func (p *AuditProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	start := time.Now()
+	resp, err := streamFrom(ctx, p.Provider, req, chunks)
+	p.Record(ctx, start, req, resp, err)
+	return resp, err
+}
+
+// Embed embeds text with the wrapped provider and appends the text embedded to the audit log
+func (p *AuditProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	start := time.Now()
+	vector, err := embedFrom(ctx, p.Provider, model, text)
+	p.Record(ctx, start, LLMRequest{Model: model, Prompt: text}, LLMResponse{Model: model}, err)
+	return vector, err
+}
+
+// auditLLMCall records a call that started at start in the audit log
+func (s *Server) auditLLMCall(ctx context.Context, start time.Time, req LLMRequest, resp LLMResponse, err error) {
+	var parts []string
+	for _, message := range req.Conversation() {
+		parts = append(parts, message.Role+": "+message.Content)
+	}
+	prompt := strings.Join(parts, "\n")
+
+	entry := LLMAuditEntry{
+		Time:       start,
+		Caller:     callerID(ctx),
+		Provider:   resp.Provider,
+		Model:      req.Model,
+		Prompt:     s.redactForAudit(prompt),
+		Completion: s.redactForAudit(resp.Text),
+		LatencyMS:  time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.recordLLMAudit(entry)
+}
+
+// recordLLMAudit stores an entry in memory, trimming the oldest entries beyond LLM_AUDIT_MAX_ENTRIES,
+// and appends it to LLM_AUDIT_FILE when configured
+func (s *Server) recordLLMAudit(entry LLMAuditEntry) {
+	llmAuditMu.Lock()
+	defer llmAuditMu.Unlock()
+
+	nextLLMAuditID++
+	entry.ID = nextLLMAuditID
+	llmAuditLog = append(llmAuditLog, entry)
+	if over := len(llmAuditLog) - s.config.LLMAuditMaxEntries; over > 0 {
+		llmAuditLog = append([]LLMAuditEntry{}, llmAuditLog[over:]...)
+	}
+
+	if s.config.LLMAuditFile == "" {
+		return
+	}
+	f, err := os.OpenFile(s.config.LLMAuditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("Error opening LLM audit file: %v", err)
+		return
+	}
+	defer f.Close()
+	json.NewEncoder(f).Encode(entry)
+}
+
+// redactForAudit applies the LLM_AUDIT_REDACT policy: "none" keeps text as is, "pii" masks
+// emails, phone numbers and student names, "full" keeps only a hash of the text
+func (s *Server) redactForAudit(text string) string {
+	switch s.config.LLMAuditRedact {
+	case "none":
+		return text
+	case "full":
+		if text == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(text))
+		return "[redacted sha256:" + hex.EncodeToString(sum[:8]) + " len:" + strconv.Itoa(len(text)) + "]"
+	}
+
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	text = phonePattern.ReplaceAllString(text, "[phone]")
+
+	if pattern := s.auditNamePattern(); pattern != nil {
+		text = pattern.ReplaceAllString(text, "[name]")
+	}
+	return text
+}
+
+// auditNamePattern returns a case-insensitive pattern matching any student's name as whole
+// words, longest names first so a full name is masked before a shorter name inside it; nil when
+// no student has a name
+func (s *Server) auditNamePattern() *regexp.Regexp {
+	eventSubscribersMu.Lock()
+	seq := nextEventID
+	eventSubscribersMu.Unlock()
+	s.store.Lock()
+	version := [2]int64{seq, int64(s.store.Len())}
+	s.store.Unlock()
+
+	auditNamesMu.Lock()
+	defer auditNamesMu.Unlock()
+	if version == auditNamesVersion && auditNames != nil {
+		return auditNames
+	}
+
+	s.store.Lock()
+	var names []string
+	for _, student := range s.store.List() {
+		if name := strings.TrimSpace(student.Name); name != "" {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+	s.store.Unlock()
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	auditNames = nil
+	if len(names) > 0 {
+		auditNames = regexp.MustCompile(`(?i)\b(?:` + strings.Join(names, "|") + `)\b`)
+	}
+	auditNamesVersion = version
+	return auditNames
+}
+
+// getLLMAuditLog handles GET /admin/llm/audit to query the audit log by ?caller=, ?model=,
+// ?since= (RFC 3339) and ?limit=, newest first
+func getLLMAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "Invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	llmAuditMu.Lock()
+	entries := []LLMAuditEntry{}
+	for i := len(llmAuditLog) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := llmAuditLog[i]
+		if (query.Get("caller") != "" && entry.Caller != query.Get("caller")) ||
+			(query.Get("model") != "" && entry.Model != query.Get("model")) ||
+			entry.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	llmAuditMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"student_api/student_api/internal/llm"
+)
+
+var (
+	llmStatus   LLMStatus
+	llmStatusMu sync.Mutex
+)
+
+// LLMStatus struct to hold the result of the latest LLM health check
+type LLMStatus struct {
+	Provider    string     `json:"provider"`
+	Healthy     bool       `json:"healthy"`
+	LastChecked *time.Time `json:"last_checked,omitempty"`
+	LatencyMS   int64      `json:"latency_ms"`
+	LastError   string     `json:"last_error,omitempty"`
+	WarmedUp    bool       `json:"warmed_up"`
+	BreakerOpen bool       `json:"breaker_open"`
+	// Providers lists each provider of the default chain and each tenant provider by the name
+	// responses report them under
+	Providers []ProviderHealth `json:"providers,omitempty"`
+}
+
+// ProviderHealth struct to hold the outcome of pinging one provider
+type ProviderHealth struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// startLLMHealthChecks warms up the model if configured, then checks LLM health on an interval
+func (s *Server) startLLMHealthChecks() {
+	go func() {
+		s.checkLLMHealth()
+		if s.config.LLMWarmup {
+			s.warmUpLLM()
+		}
+		if s.config.LLMHealthInterval <= 0 {
+			return
+		}
+		for range time.Tick(s.config.LLMHealthInterval) {
+			s.checkLLMHealth()
+		}
+	}()
+}
+
+// checkLLMHealth pings the provider and records the outcome
+func (s *Server) checkLLMHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	providers, err := s.pingLLM(ctx)
+	now := time.Now()
+
+	llmStatusMu.Lock()
+	wasDown := llmStatus.LastChecked != nil && !llmStatus.Healthy
+	llmStatus.Provider = s.config.LLMProvider
+	llmStatus.Healthy = err == nil
+	llmStatus.LastChecked = &now
+	llmStatus.LatencyMS = now.Sub(start).Milliseconds()
+	llmStatus.Providers = providers
+	llmStatus.LastError = ""
+	if err != nil {
+		llmStatus.LastError = err.Error()
+	}
+	llmStatusMu.Unlock()
+
+	// Only changes of state are posted, not every failed check
+	if err != nil && !wasDown {
+		s.postChatEvent(ChatLLMOutage, "LLM provider is down", err.Error())
+	} else if err == nil && wasDown {
+		s.postChatEvent(ChatLLMOutage, "LLM provider recovered", s.config.LLMProvider+" is responding again.")
+	}
+}
+
+// pingLLM pings every provider of the default chain and every tenant provider. The LLM is healthy
+// while any entry of the default chain answers, since the fallback chain still serves requests
+// then; tenant providers are reported but a tenant's outage leaves the other tenants unaffected
+func (s *Server) pingLLM(ctx context.Context) ([]ProviderHealth, error) {
+	entries := []FallbackEntry{{Name: s.config.LLMProvider, Provider: s.llm.breaker.Provider}}
+	if fallback, ok := s.llm.breaker.Provider.(*FallbackProvider); ok && len(fallback.Entries) > 0 {
+		entries = fallback.Entries
+	}
+
+	var providers []ProviderHealth
+	var errs []error
+	healthy := false
+	for _, entry := range entries {
+		err := pingProvider(ctx, entry.Provider)
+		providers = append(providers, newProviderHealth(entry.Name, err))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name, err))
+		} else {
+			healthy = true
+		}
+	}
+
+	tenantLLMMu.Lock()
+	var tenants []string
+	for tenant, entry := range tenantLLM {
+		if tenant != "" && entry.config.Provider != "" {
+			tenants = append(tenants, tenant)
+		}
+	}
+	tenantLLMMu.Unlock()
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		provider, name, err := s.tenantProvider(tenant)
+		if err == nil && provider != nil {
+			err = pingProvider(ctx, provider)
+		}
+		providers = append(providers, newProviderHealth("tenant:"+tenant+":"+name, err))
+	}
+
+	if healthy {
+		return providers, nil
+	}
+	return providers, errors.Join(errs...)
+}
+
+// newProviderHealth reports the outcome of pinging the provider called name
+func newProviderHealth(name string, err error) ProviderHealth {
+	health := ProviderHealth{Name: name, Healthy: err == nil}
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// pingProvider performs a cheap request against the model listing API of the provider behind p's
+// retries and breaker; providers without one, like the mock, are always healthy
+func pingProvider(ctx context.Context, p LLMProvider) error {
+	var url, apiKey string
+	switch v := primaryProvider(p).(type) {
+	case *llm.OllamaProvider:
+		url = strings.TrimRight(v.BaseURL, "/") + "/api/tags"
+	case *llm.OpenAIProvider:
+		url = strings.TrimRight(v.BaseURL, "/") + "/models"
+		apiKey = v.APIKey
+	default:
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// warmUpLLM sends a tiny prompt so the default model is loaded into memory before the first real request
+func (s *Server) warmUpLLM() {
+	_, err := s.llm.Generate(context.Background(), LLMRequest{Model: s.config.DefaultModel, Prompt: "Reply with OK."})
+	if err != nil {
+		log.Printf("LLM warm-up failed: %v", err)
+		return
+	}
+
+	llmStatusMu.Lock()
+	llmStatus.WarmedUp = true
+	llmStatusMu.Unlock()
+	log.Printf("LLM model %s warmed up", s.config.DefaultModel)
+}
+
+// currentLLMStatus returns a snapshot of the LLM health
+func (s *Server) currentLLMStatus() LLMStatus {
+	llmStatusMu.Lock()
+	status := llmStatus
+	llmStatusMu.Unlock()
+
+	status.Provider = s.config.LLMProvider
+	status.BreakerOpen = s.llm.breaker.RetryAfter() > 0
+	return status
+}
+
+// getLLMStatus handles GET /admin/llm/status to report LLM health
+func (s *Server) getLLMStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.currentLLMStatus())
+}
+
+// readyz handles GET /readyz; the service is ready once it is serving and the LLM answers. With
+// READYZ_REQUIRE_LLM=false an LLM outage is only reported as degraded, for deployments where
+// summary features may degrade rather than take the instance out of rotation
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	status := s.currentLLMStatus()
+	overall, code := "ok", http.StatusOK
+	if !status.Healthy {
+		overall = "degraded"
+		if s.config.ReadyzRequireLLM {
+			overall, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": overall, "llm": status})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"student_api/student_api/internal/llm"
+)
+
+func TestReadyzFollowsTheFallbackChain(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(up.Close)
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(down.Close)
+
+	primary := &llm.OllamaProvider{BaseURL: down.URL}
+	fallback := &llm.OpenAIProvider{BaseURL: up.URL}
+	s := newServer(t, &FallbackProvider{Entries: []FallbackEntry{
+		{Name: "ollama", Provider: primary},
+		{Name: "openai", Provider: fallback},
+	}})
+	tenantLLMMu.Lock()
+	tenantLLM["acme"] = &tenantLLMEntry{config: TenantLLMConfig{Provider: "ollama", BaseURL: down.URL}}
+	tenantLLMMu.Unlock()
+	t.Cleanup(func() {
+		tenantLLMMu.Lock()
+		delete(tenantLLM, "acme")
+		tenantLLMMu.Unlock()
+		llmStatusMu.Lock()
+		llmStatus = LLMStatus{}
+		llmStatusMu.Unlock()
+	})
+
+	readyz := func() (int, map[string]interface{}) {
+		s.checkLLMHealth()
+		rec := httptest.NewRecorder()
+		s.readyz(rec, httptest.NewRequest("GET", "/readyz", nil))
+		var body map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	code, body := readyz()
+	if code != http.StatusOK {
+		t.Fatalf("readyz with the fallback up = %d %v, want 200", code, body)
+	}
+	status := s.currentLLMStatus()
+	if len(status.Providers) != 3 || status.Providers[0].Healthy || !status.Providers[1].Healthy ||
+		status.Providers[2].Name != "tenant:acme:ollama" || status.Providers[2].Healthy {
+		t.Errorf("providers = %+v, want ollama down, openai up and the tenant's provider down", status.Providers)
+	}
+
+	fallback.BaseURL = down.URL
+	if code, body := readyz(); code != http.StatusServiceUnavailable || body["status"] != "unavailable" {
+		t.Errorf("readyz with every provider down = %d %v, want 503", code, body)
+	}
+
+	s.config.ReadyzRequireLLM = false
+	if code, body := readyz(); code != http.StatusOK || body["status"] != "degraded" {
+		t.Errorf("readyz without READYZ_REQUIRE_LLM = %d %v, want 200 degraded", code, body)
+	}
+}
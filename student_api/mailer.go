@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Mailer is implemented by outbound email backends
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// newMailer returns the backend selected by MAIL_PROVIDER; without one, messages are only logged
+func newMailer(cfg Config) Mailer {
+	switch cfg.MailProvider {
+	case "smtp":
+		return &SMTPMailer{Addr: cfg.SMTPAddr, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.MailFrom, ImplicitTLS: cfg.SMTPImplicitTLS}
+	default:
+		return LogMailer{}
+	}
+}
+
+// SMTPMailer sends plain text email through an SMTP relay, authenticating when a username is set;
+// STARTTLS is used when the relay offers it, and ImplicitTLS connects over TLS from the start as
+// port 465 relays expect
+type SMTPMailer struct {
+	Addr        string
+	Username    string
+	Password    string
+	From        string
+	ImplicitTLS bool
+}
+
+// Send delivers one message
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("invalid header value")
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, _ := net.SplitHostPort(m.Addr)
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	msg := "From: " + m.From + "\r\nTo: " + to + "\r\nSubject: " + mime.QEncoding.Encode("utf-8", subject) +
+		"\r\nDate: " + time.Now().Format(time.RFC1123Z) +
+		"\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n" + body
+	if !m.ImplicitTLS {
+		return smtp.SendMail(m.Addr, auth, m.From, []string{to}, []byte(msg))
+	}
+	return m.sendTLS(ctx, auth, to, []byte(msg))
+}
+
+// sendTLS delivers one message over a connection that is encrypted from the start
+func (m *SMTPMailer) sendTLS(ctx context.Context, auth smtp.Auth, to string, msg []byte) error {
+	host, _, _ := net.SplitHostPort(m.Addr)
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: host}}
+	conn, err := dialer.DialContext(ctx, "tcp", m.Addr)
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// LogMailer writes messages to the log instead of sending them, for local development
+type LogMailer struct{}
+
+// Send logs one message
+func (LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	log.Printf("Email to %s: %s\n%s", to, subject, body)
+	return nil
+}
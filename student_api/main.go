@@ -1,200 +1,284 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
-	"sync"
+	"strings"
 
-	"github.com/gorilla/mux"
+	"student_api/student_api/internal/service"
+	"student_api/student_api/internal/store"
 )
 
-var (
-	students = make(map[int]Student)
-	mu       sync.Mutex
+// The student record lives in internal/store
+type (
+	Student         = store.Student
+	Address         = store.Address
+	GeoPoint        = store.GeoPoint
+	GuardianContact = store.GuardianContact
 )
 
-// Student struct to hold student data
-type Student struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Age   int    `json:"age"`
-	Email string `json:"email"`
-}
-
 func main() {
-	router := mux.NewRouter()
+	cfg := loadConfig()
+	provider, err := newLLMProvider(cfg)
+	if err != nil {
+		log.Fatalf("Error configuring LLM provider: %v", err)
+	}
+	s, err := NewServer(cfg, store.NewMemory(), provider)
+	if err != nil {
+		log.Fatalf("Error building server: %v", err)
+	}
 
-	// Register routes
-	router.HandleFunc("/students", createStudent).Methods("POST")
-	router.HandleFunc("/students", getAllStudents).Methods("GET")
-	router.HandleFunc("/students/{id}", getStudentByID).Methods("GET")
-	router.HandleFunc("/students/{id}", updateStudent).Methods("PUT")
-	router.HandleFunc("/students/{id}", deleteStudent).Methods("DELETE")
-	router.HandleFunc("/students/{id}/summary", generateStudentSummary).Methods("GET")
+	if err := s.loadPromptTemplate(); err != nil {
+		log.Fatalf("Error loading prompt template: %v", err)
+	}
 
-	// Start the server
-	log.Println("Server is listening on port 8080...")
-	log.Fatal(http.ListenAndServe(":8081", router))
-}
+	if err := s.loadGradingScales(); err != nil {
+		log.Fatalf("Error loading grading scales: %v", err)
+	}
 
-// createStudent handles POST /students to create a new student
-func createStudent(w http.ResponseWriter, r *http.Request) {
-	var student Student
-	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
-		return
+	if err := s.loadCertificateTemplate(); err != nil {
+		log.Fatalf("Error loading certificate template: %v", err)
+	}
+	if err := s.loadProfileReportTemplate(); err != nil {
+		log.Fatalf("Error loading report template: %v", err)
 	}
 
-	// Basic validation
-	if student.Name == "" || student.Age <= 0 || student.Email == "" {
-		http.Error(w, "Invalid student data", http.StatusBadRequest)
-		return
+	if err := s.startChangeLog(); err != nil {
+		log.Fatalf("Error loading change log: %v", err)
+	}
+	if err := s.startJobQueue(); err != nil {
+		log.Fatalf("Error starting job queue: %v", err)
+	}
+	if err := s.startNotifications(); err != nil {
+		log.Fatalf("Error starting notifications: %v", err)
+	}
+	if err := s.startChatNotifications(); err != nil {
+		log.Fatalf("Error starting chat notifications: %v", err)
+	}
+	s.startLLMHealthChecks()
+	s.startEmbeddingIndexer()
+	s.startWebhookDispatcher()
+	if err := s.startEventPublishing(); err != nil {
+		log.Fatalf("Error starting event publishing: %v", err)
+	}
+	if err := s.startSummaryRefresh(); err != nil {
+		log.Fatalf("Error scheduling summary refresh: %v", err)
+	}
+	if err := s.startDataQualityChecks(); err != nil {
+		log.Fatalf("Error scheduling data quality checks: %v", err)
+	}
+	if err := s.startBirthdayNotifications(); err != nil {
+		log.Fatalf("Error scheduling birthday notifications: %v", err)
+	}
+	if err := s.startLDAPImports(); err != nil {
+		log.Fatalf("Error scheduling LDAP imports: %v", err)
+	}
+	if err := s.startCSVImports(); err != nil {
+		log.Fatalf("Error scheduling CSV imports: %v", err)
+	}
+	if err := s.startSnapshots(); err != nil {
+		log.Fatalf("Error scheduling snapshots: %v", err)
+	}
+	if err := s.startRetention(); err != nil {
+		log.Fatalf("Error scheduling retention: %v", err)
+	}
+	if err := s.startReportEmails(); err != nil {
+		log.Fatalf("Error scheduling report emails: %v", err)
 	}
 
-	mu.Lock()
-	defer mu.Unlock()
-	student.ID = len(students) + 1
-	students[student.ID] = student
+	if err := s.startGRPCServer(); err != nil {
+		log.Fatalf("Error starting gRPC server: %v", err)
+	}
+	if err := s.startPublicServer(); err != nil {
+		log.Fatalf("Error starting public directory: %v", err)
+	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(student)
+	// Start the server
+	log.Println("Server is listening on port 8080...")
+	log.Fatal(http.ListenAndServe(":8081", s))
 }
 
-// getAllStudents handles GET /students to fetch all students
-func getAllStudents(w http.ResponseWriter, r *http.Request) {
-	mu.Lock()
-	defer mu.Unlock()
+// Kinds of failed student write, kept under the names the gRPC and GraphQL servers use
+var (
+	ErrStudentNotFound = service.ErrNotFound
+	ErrInvalidStudent  = service.ErrInvalid
+	ErrStudentConflict = service.ErrConflict
+)
 
-	var allStudents []Student
-	for _, student := range students {
-		allStudents = append(allStudents, student)
-	}
+// newStudentService returns the student service of a store with the validation rules and side
+// effects of this API
+func (s *Server) newStudentService(st store.Store) *service.Students {
+	return service.NewStudents(st, service.Hooks{
+		Prepare:  prepareNewStudent,
+		Merge:    mergeStudentUpdate,
+		Validate: validateUpdatedStudent,
+		Conflict: s.externalIDConflict,
+		Created:  s.studentCreated,
+		Updated:  s.studentUpdated,
+		Deleted:  s.studentDeleted,
+	})
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(allStudents)
+// prepareNewStudent normalizes and validates a new student
+func prepareNewStudent(student *Student) error {
+	normalizeStudent(student)
+	if err := validateStudent(*student); err != nil {
+		return err
+	}
+	student.Tags = applyTags(nil, student.Tags, nil)
+	if student.Status == "" {
+		student.Status = StatusEnrolled
+	}
+	if !validInitialStatus(student.Status) {
+		return errors.New("New students must be applied or enrolled")
+	}
+	return nil
 }
 
-// getStudentByID handles GET /students/{id} to fetch a student by ID
-func getStudentByID(w http.ResponseWriter, r *http.Request) {
-	id := extractIDFromURL(r.URL.Path)
+// validateUpdatedStudent normalizes and validates a student after an update was merged into it
+func validateUpdatedStudent(student *Student) error {
+	normalizeStudent(student)
+	return validateStudent(*student)
+}
 
-	mu.Lock()
-	defer mu.Unlock()
+// studentCreated publishes a new student and starts its verification, indexing and geocoding
+func (s *Server) studentCreated(student Student) {
+	s.publishStudentEvent(EventStudentCreated, student)
+	s.embeddings.add(student.ID)
+	s.sendEmailVerification(student)
+	if student.Address != nil {
+		go s.geocodeStudentAddress(student.ID, *student.Address)
+	}
+}
 
-	student, exists := students[id]
-	if !exists {
-		http.Error(w, "Student not found", http.StatusNotFound)
-		return
+// studentUpdated publishes an updated student and refreshes what was derived from the old record
+func (s *Server) studentUpdated(student Student, emailChanged, addressChanged bool) {
+	s.publishStudentEvent(EventStudentUpdated, student)
+	invalidateSummary(student.ID)
+	s.embeddings.add(student.ID)
+	if emailChanged {
+		s.sendEmailVerification(student)
+	}
+	if addressChanged {
+		go s.geocodeStudentAddress(student.ID, *student.Address)
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(student)
+// studentDeleted publishes a deleted student and deletes everything recorded about them
+func (s *Server) studentDeleted(student Student) {
+	id := student.ID
+	s.publishStudentEvent(EventStudentDeleted, student)
+	deleteSummaries(id)
+	deleteQuarantined(id)
+	deleteChatSessions(id)
+	deleteEmbedding(id)
+	s.deleteEnrollments(id)
+	deleteGrades(id)
+	deleteAttendance(id)
+	deleteStatusHistory(id)
+	removeFromGroups(id)
+	deleteContacts(id)
+	forgetNotifyPrefs(id, 0)
+	s.deleteAttachments(id)
+	s.deletePhoto(id)
+	deleteFees(id)
+	deleteAwards(id)
+	deleteCertificate(id)
+	deleteRelatives(id)
+	deleteIncidents(id)
+	deleteHealthRecords(id)
+	deleteEmailVerifications(id)
+	deleteConsents(id)
 }
 
-// updateStudent handles PUT /students/{id} to update a student by ID
-func updateStudent(w http.ResponseWriter, r *http.Request) {
-	id := extractIDFromURL(r.URL.Path)
+// studentCodec reads and writes the student routes in JSON, MessagePack or protobuf (see
+// encoding.go), adding the computed fields to the students it writes
+type studentCodec struct{}
 
-	var updatedStudent Student
-	if err := json.NewDecoder(r.Body).Decode(&updatedStudent); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
-		return
-	}
+func (studentCodec) DecodeStudent(r *http.Request, student *Student) error {
+	return decodeStudentBody(r, student)
+}
 
-	mu.Lock()
-	defer mu.Unlock()
+func (studentCodec) WriteStudent(w http.ResponseWriter, r *http.Request, status int, student Student) {
+	writeEncoded(w, r, status, withComputed(student), studentMessage(student))
+}
 
-	student, exists := students[id]
-	if !exists {
-		http.Error(w, "Student not found", http.StatusNotFound)
-		return
+func (studentCodec) WriteStudents(w http.ResponseWriter, r *http.Request, status int, list []Student) {
+	views := make([]store.WithComputed, len(list))
+	for i, student := range list {
+		views[i] = withComputed(student)
 	}
+	writeEncoded(w, r, status, views, studentListMessage(list))
+}
 
-	// Update fields
+// studentFilter builds the filter of GET /students from the query parameters of
+// studentFilterFromQuery
+func studentFilter(query url.Values) (func(Student) bool, error) {
+	filter, err := studentFilterFromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return filter.matches, nil
+}
+
+// mergeStudentUpdate applies the non-empty fields of an update to a student, reporting whether
+// the email or address changed
+func mergeStudentUpdate(student *Student, updatedStudent Student) (emailChanged, addressChanged bool) {
 	if updatedStudent.Name != "" {
 		student.Name = updatedStudent.Name
 	}
-	if updatedStudent.Age > 0 {
-		student.Age = updatedStudent.Age
+	if updatedStudent.RecordedAge > 0 {
+		student.RecordedAge = updatedStudent.RecordedAge
 	}
-	if updatedStudent.Email != "" {
+	emailChanged = updatedStudent.Email != "" && updatedStudent.Email != student.Email
+	if emailChanged {
 		student.Email = updatedStudent.Email
+		student.EmailVerified = false
 	}
-
-	students[id] = student
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(student)
-}
-
-// deleteStudent handles DELETE /students/{id} to delete a student by ID
-func deleteStudent(w http.ResponseWriter, r *http.Request) {
-	id := extractIDFromURL(r.URL.Path)
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	_, exists := students[id]
-	if !exists {
-		http.Error(w, "Student not found", http.StatusNotFound)
-		return
+	if updatedStudent.Phone != "" {
+		student.Phone = updatedStudent.Phone
 	}
-
-	delete(students, id)
-
-	w.WriteHeader(http.StatusNoContent)
-}
-
-// generateStudentSummary calls the Ollama API (Llama2 model) to generate a summary for a student
-func generateStudentSummary(w http.ResponseWriter, r *http.Request) {
-	id := extractIDFromURL(r.URL.Path)
-
-	mu.Lock()
-	defer mu.Unlock()
-
-	student, exists := students[id]
-	if !exists {
-		http.Error(w, "Student not found", http.StatusNotFound)
-		return
+	addressChanged = updatedStudent.Address != nil
+	if addressChanged {
+		student.Address = updatedStudent.Address
+		student.Address.Location = nil
 	}
-
-	// Construct the input for Ollama with Llama2 model
-	summaryRequest := map[string]interface{}{
-		"input": fmt.Sprintf("Generate a detailed summary for the following student: Name: %s, Age: %d, Email: %s", student.Name, student.Age, student.Email),
-		"model": "llama2", // Specifying Llama2 model
+	if updatedStudent.DateOfBirth != "" {
+		student.DateOfBirth = updatedStudent.DateOfBirth
 	}
-
-	// Marshal the request to JSON
-	summaryRequestJSON, _ := json.Marshal(summaryRequest)
-
-	// Send request to Ollama's localhost server
-	resp, err := http.Post("http://localhost:11411/v1/chat/completions", "application/json", bytes.NewBuffer(summaryRequestJSON))
-	if err != nil {
-		http.Error(w, "Error generating summary", http.StatusInternalServerError)
-		return
+	if updatedStudent.Gender != "" {
+		student.Gender = updatedStudent.Gender
 	}
-	defer resp.Body.Close()
-
-	// Read the response from Ollama
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		http.Error(w, "Error reading Ollama response", http.StatusInternalServerError)
-		return
+	if updatedStudent.Nationality != "" {
+		student.Nationality = updatedStudent.Nationality
 	}
-
-	// Respond with the summary
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(body)
+	if updatedStudent.Guardian != nil {
+		student.Guardian = updatedStudent.Guardian
+	}
+	if updatedStudent.GradeLevel != 0 {
+		student.GradeLevel = updatedStudent.GradeLevel
+	}
+	if updatedStudent.Tags != nil {
+		student.Tags = applyTags(nil, updatedStudent.Tags, nil)
+	}
+	if updatedStudent.Custom != nil {
+		student.Custom = mergeCustomValues(student.Custom, updatedStudent.Custom)
+	}
+	if updatedStudent.ExternalIDs != nil {
+		student.ExternalIDs = mergeExternalIDs(student.ExternalIDs, normalizeExternalIDs(updatedStudent.ExternalIDs))
+	}
+	return emailChanged, addressChanged
 }
 
 // extractIDFromURL extracts student ID from the URL
 func extractIDFromURL(url string) int {
 	idStr := url[len("/students/"):]
+	if i := strings.Index(idStr, "/"); i >= 0 {
+		idStr = idStr[:i]
+	}
 	id, _ := strconv.Atoi(idStr)
 	return id
 }
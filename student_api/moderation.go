@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ErrContentFlagged is returned when a generated summary is withheld by the content filter
+var ErrContentFlagged = errors.New("generated content was flagged by the content filter")
+
+// ErrModerationUnavailable is returned when the moderation model gives no verdict, so generated
+// text can be neither shown nor quarantined
+var ErrModerationUnavailable = errors.New("moderation model unavailable")
+
+var (
+	quarantine       = make(map[int]*QuarantinedSummary)
+	quarantineMu     sync.Mutex
+	nextQuarantineID int
+)
+
+// QuarantinedSummary struct to hold a flagged summary awaiting admin review
+type QuarantinedSummary struct {
+	ID        int       `json:"id"`
+	Summary   Summary   `json:"summary"`
+	Reason    string    `json:"reason"`
+	FlaggedAt time.Time `json:"flagged_at"`
+	gen       int
+}
+
+// moderationPrompt asks the moderation model to classify a piece of generated text
+const moderationPrompt = `You are a content moderator for a school administration system.
+Reply with exactly SAFE if the text below is appropriate to show staff, otherwise reply with UNSAFE followed by a short reason.
+
+Text:
+%s`
+
+// moderationEnabled reports whether generated text is checked before it is shown
+func (s *Server) moderationEnabled() bool {
+	return len(s.config.ModerationBlocklist) > 0 || s.config.ModerationModel != ""
+}
+
+// moderateText checks generated text against the blocklist and, if configured, the moderation
+// model, returning the reason when the text is flagged. Without a verdict from the model it fails
+// closed with ErrModerationUnavailable rather than flagging text nobody judged unsafe
+func (s *Server) moderateText(ctx context.Context, text string) (string, bool, error) {
+	lower := strings.ToLower(text)
+	for _, term := range s.config.ModerationBlocklist {
+		if strings.Contains(lower, strings.ToLower(term)) {
+			return fmt.Sprintf("contains blocked term %q", term), true, nil
+		}
+	}
+
+	if s.config.ModerationModel == "" {
+		return "", false, nil
+	}
+	resp, err := s.llm.Generate(ctx, LLMRequest{Model: s.config.ModerationModel, Prompt: fmt.Sprintf(moderationPrompt, text)})
+	if err != nil {
+		log.Printf("Moderation model failed: %v", err)
+		return "", false, fmt.Errorf("%w: %v", ErrModerationUnavailable, err)
+	}
+	verdict := strings.TrimSpace(resp.Text)
+	switch upper := strings.ToUpper(verdict); {
+	case strings.HasPrefix(upper, "SAFE"):
+		return "", false, nil
+	case strings.HasPrefix(upper, "UNSAFE"):
+		return firstNonEmpty(strings.TrimSpace(verdict[len("UNSAFE"):]), "flagged by moderation model"), true, nil
+	}
+	log.Printf("Moderation model gave no verdict: %q", verdict)
+	return "", false, fmt.Errorf("%w: no SAFE or UNSAFE verdict", ErrModerationUnavailable)
+}
+
+// quarantineSummary holds a flagged summary for admin review
+func quarantineSummary(summary Summary, gen int, reason string) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	nextQuarantineID++
+	quarantine[nextQuarantineID] = &QuarantinedSummary{
+		ID:        nextQuarantineID,
+		Summary:   summary,
+		Reason:    reason,
+		FlaggedAt: time.Now(),
+		gen:       gen,
+	}
+	log.Printf("Summary for student %d quarantined: %s", summary.StudentID, reason)
+}
+
+// deleteQuarantined drops the quarantined summaries of a deleted student, so none can be approved
+// back into the history
+func deleteQuarantined(studentID int) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+	for id, item := range quarantine {
+		if item.Summary.StudentID == studentID {
+			delete(quarantine, id)
+		}
+	}
+}
+
+// listQuarantine handles GET /admin/moderation/quarantine to list summaries awaiting review
+func listQuarantine(w http.ResponseWriter, r *http.Request) {
+	quarantineMu.Lock()
+	items := []QuarantinedSummary{}
+	for _, item := range quarantine {
+		items = append(items, *item)
+	}
+	quarantineMu.Unlock()
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// reviewQuarantine handles POST /admin/moderation/quarantine/{id}/{action}, where action is
+// approve (release the summary into the history) or reject (discard it). A summary of a student
+// who has changed since it was generated cannot be approved, as it describes an older record
+func reviewQuarantine(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid quarantine ID", http.StatusBadRequest)
+		return
+	}
+	action := vars["action"]
+	if action != "approve" && action != "reject" {
+		http.Error(w, "Action must be approve or reject", http.StatusBadRequest)
+		return
+	}
+
+	quarantineMu.Lock()
+	item, exists := quarantine[id]
+	delete(quarantine, id)
+	quarantineMu.Unlock()
+	if !exists {
+		http.Error(w, "Quarantined summary not found", http.StatusNotFound)
+		return
+	}
+
+	if action == "reject" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	summary, stored := storeSummary(item.Summary, item.gen)
+	if !stored {
+		http.Error(w, "Student has changed since the summary was generated", http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Accepted values for NotificationPreference.Channel
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelNone  = "none"
+)
+
+// notificationPrefsMu guards notificationPrefs
+var (
+	notificationPrefs   = make(map[notificationPrefKey]NotificationPreference)
+	notificationPrefsMu sync.Mutex
+)
+
+// notificationPrefKey identifies whose preferences are stored: a student, or with ContactID one
+// of the student's guardians or emergency contacts
+type notificationPrefKey struct {
+	StudentID int
+	ContactID int
+}
+
+// NotificationPreference struct to hold how a student or contact wants to be notified. Channel
+// overrides the channel a notification would use and "none" stops them; during the quiet hours,
+// read in Timezone or the server's zone, notifications wait until the quiet hours end
+type NotificationPreference struct {
+	StudentID       int       `json:"student_id"`
+	ContactID       int       `json:"contact_id,omitempty"`
+	Channel         string    `json:"channel,omitempty"`
+	QuietHoursStart string    `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string    `json:"quiet_hours_end,omitempty"`
+	Timezone        string    `json:"timezone,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// notificationRecipient struct to hold who a notification is for and the addresses it can use
+type notificationRecipient struct {
+	StudentID int
+	ContactID int
+	Email     string
+	Phone     string
+}
+
+// validateNotificationPreference checks the fields a client may set
+func validateNotificationPreference(pref NotificationPreference) error {
+	switch pref.Channel {
+	case "", ChannelEmail, ChannelSMS, ChannelNone:
+	default:
+		return errors.New("channel must be email, sms or none")
+	}
+	if (pref.QuietHoursStart == "") != (pref.QuietHoursEnd == "") {
+		return errors.New("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	for _, clock := range []string{pref.QuietHoursStart, pref.QuietHoursEnd} {
+		if _, err := time.Parse("15:04", clock); clock != "" && err != nil {
+			return errors.New("quiet hours must be in HH:MM format")
+		}
+	}
+	if pref.Timezone != "" {
+		if _, err := time.LoadLocation(pref.Timezone); err != nil {
+			return errors.New("Unknown timezone " + pref.Timezone)
+		}
+	}
+	return nil
+}
+
+// quietHoursWait returns how long until a preference's quiet hours end, or 0 outside them; the
+// quiet hours may span midnight
+func quietHoursWait(pref NotificationPreference, now time.Time) time.Duration {
+	if pref.QuietHoursStart == "" || pref.QuietHoursStart == pref.QuietHoursEnd {
+		return 0
+	}
+	loc := time.Local
+	if pref.Timezone != "" {
+		if zone, err := time.LoadLocation(pref.Timezone); err == nil {
+			loc = zone
+		}
+	}
+	local := now.In(loc)
+	start, _ := time.Parse("15:04", pref.QuietHoursStart)
+	end, _ := time.Parse("15:04", pref.QuietHoursEnd)
+
+	minute := local.Hour()*60 + local.Minute()
+	from, until := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	quiet := minute >= from && minute < until
+	if from > until {
+		quiet = minute >= from || minute < until
+	}
+	if !quiet {
+		return 0
+	}
+	endAt := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !endAt.After(local) {
+		endAt = endAt.AddDate(0, 0, 1)
+	}
+	return endAt.Sub(local)
+}
+
+// sendNotification queues a notification for a student or contact on their preferred channel,
+// or on channel when they have none, falling back to the other channel when the chosen one has
+// no address. Urgent notifications ignore quiet hours. It returns the channel used, or "" when
+// the recipient opted out or has no address
+func (s *Server) sendNotification(ctx context.Context, to notificationRecipient, channel, subject, body string, urgent bool) (string, Job, error) {
+	notificationPrefsMu.Lock()
+	pref, hasPref := notificationPrefs[notificationPrefKey{to.StudentID, to.ContactID}]
+	notificationPrefsMu.Unlock()
+	if hasPref && pref.Channel != "" {
+		channel = pref.Channel
+	}
+
+	switch {
+	case channel == ChannelNone:
+		return "", Job{}, nil
+	case channel == ChannelEmail && to.Email == "":
+		channel = ChannelSMS
+	case channel == ChannelSMS && to.Phone == "":
+		channel = ChannelEmail
+	}
+	if (channel == ChannelEmail && to.Email == "") || (channel == ChannelSMS && to.Phone == "") {
+		return "", Job{}, nil
+	}
+
+	opts := s.config.retryPolicy(channel, "").jobOptions()
+	if hasPref && !urgent {
+		opts.Delay = quietHoursWait(pref, time.Now())
+	}
+	var job Job
+	var err error
+	if channel == ChannelSMS {
+		text := body
+		if len(text) > maxSMSLength {
+			text = subject
+		}
+		job, err = s.enqueueJob(ctx, "sms", smsJobPayload{To: to.Phone, Body: text}, opts)
+	} else {
+		job, err = s.enqueueJob(ctx, "email", emailJobPayload{To: to.Email, Subject: subject, Body: body}, opts)
+	}
+	return channel, job, err
+}
+
+// notificationPrefKeyFromRequest reads the {id} and optional {contact} route variables, writing
+// the error response when the student or contact does not exist
+func (s *Server) notificationPrefKeyFromRequest(w http.ResponseWriter, r *http.Request) (notificationPrefKey, bool) {
+	key := notificationPrefKey{StudentID: extractIDFromURL(r.URL.Path)}
+	if value, ok := mux.Vars(r)["contact"]; ok {
+		contactID, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid contact ID", http.StatusBadRequest)
+			return key, false
+		}
+		key.ContactID = contactID
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(key.StudentID)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return key, false
+	}
+	if key.ContactID == 0 {
+		return key, true
+	}
+	contactsMu.Lock()
+	defer contactsMu.Unlock()
+	for _, contact := range contacts[key.StudentID] {
+		if contact.ID == key.ContactID {
+			return key, true
+		}
+	}
+	http.Error(w, "Contact not found", http.StatusNotFound)
+	return key, false
+}
+
+// getNotifyPrefs handles GET /students/{id}/notification-preferences and
+// GET /students/{id}/contacts/{contact}/notification-preferences
+func (s *Server) getNotifyPrefs(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.notificationPrefKeyFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	notificationPrefsMu.Lock()
+	pref, exists := notificationPrefs[key]
+	notificationPrefsMu.Unlock()
+	if !exists {
+		http.Error(w, "No notification preferences set", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pref)
+}
+
+// putNotifyPrefs handles PUT /students/{id}/notification-preferences and
+// PUT /students/{id}/contacts/{contact}/notification-preferences to replace the preferences
+func (s *Server) putNotifyPrefs(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.notificationPrefKeyFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var pref NotificationPreference
+	if err := json.NewDecoder(r.Body).Decode(&pref); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateNotificationPreference(pref); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pref.StudentID, pref.ContactID = key.StudentID, key.ContactID
+	pref.UpdatedAt = time.Now()
+
+	notificationPrefsMu.Lock()
+	notificationPrefs[key] = pref
+	notificationPrefsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pref)
+}
+
+// deleteNotifyPrefs handles DELETE /students/{id}/notification-preferences and
+// DELETE /students/{id}/contacts/{contact}/notification-preferences to revert to the defaults
+func (s *Server) deleteNotifyPrefs(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.notificationPrefKeyFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	notificationPrefsMu.Lock()
+	_, exists := notificationPrefs[key]
+	delete(notificationPrefs, key)
+	notificationPrefsMu.Unlock()
+	if !exists {
+		http.Error(w, "No notification preferences set", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// forgetNotifyPrefs drops the preferences of a deleted student and their contacts,
+// or of one deleted contact when contactID is not 0
+func forgetNotifyPrefs(studentID, contactID int) {
+	notificationPrefsMu.Lock()
+	defer notificationPrefsMu.Unlock()
+	for key := range notificationPrefs {
+		if key.StudentID == studentID && (contactID == 0 || key.ContactID == contactID) {
+			delete(notificationPrefs, key)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Default notification templates; the first line of each rendered template is the subject and
+// the rest, after a blank line, the body. NOTIFICATION_TEMPLATE_DIR can override each one with a
+// file of the same name
+const (
+	defaultWelcomeTemplate = `Subject: Welcome, {{.Student.Name}}
+
+Hello {{.Student.Name}},
+
+Your student record has been created (ID {{.Student.ID}}).{{if not .Student.EmailVerified}} Please confirm this email address using the verification link sent separately.{{end}}
+
+Welcome aboard!`
+
+	defaultAdvisorChangeTemplate = `Subject: {{if .Assigned}}New advisee: {{.Student.Name}}{{else}}Changes to {{.Student.Name}}'s record{{end}}
+
+Hello {{.Advisor.Name}},
+
+{{if .Assigned}}{{.Student.Name}} (ID {{.Student.ID}}) has been assigned to you as an advisee.{{else}}The record of your advisee {{.Student.Name}} (ID {{.Student.ID}}) was {{if .Deleted}}deleted{{else}}updated{{end}}.{{end}}
+{{- if .Fields}}
+
+Changed fields: {{join .Fields ", "}}{{end}}`
+
+	defaultAdminDigestTemplate = `Subject: Student digest since {{.Since.Format "Jan 2 15:04"}}
+
+{{len .Created}} created, {{len .Updated}} updated, {{len .Deleted}} deleted between {{.Since.Format "2006-01-02 15:04"}} and {{.Until.Format "2006-01-02 15:04"}}.
+{{- with .Created}}
+
+Created:
+{{- range .}}
+  {{.Name}} (ID {{.StudentID}})
+{{- end}}{{end}}
+{{- with .Updated}}
+
+Updated:
+{{- range .}}
+  {{.Name}} (ID {{.StudentID}}){{if .Fields}}: {{join .Fields ", "}}{{end}}
+{{- end}}{{end}}
+{{- with .Deleted}}
+
+Deleted:
+{{- range .}}
+  {{.Name}} (ID {{.StudentID}})
+{{- end}}{{end}}
+{{- with .LowAttendance}}
+
+Attendance below {{$.AttendanceThreshold}}%:
+{{- range .}}
+  {{.Name}} (ID {{.StudentID}}): {{.Percentage}}%, {{.Absent}} absent of {{.Total}}
+{{- end}}{{end}}
+{{- if .LLMUsage.Calls}}
+
+LLM usage: {{.LLMUsage.Calls}} calls, {{.LLMUsage.Failures}} failed, {{.LLMUsage.PromptTokens}} prompt and {{.LLMUsage.CompletionTokens}} completion tokens{{if .LLMCost}}, estimated cost {{printf "%.2f" .LLMCost}}{{end}}{{end}}`
+)
+
+// notificationTemplates holds the parsed templates by name
+var notificationTemplates = make(map[string]*template.Template)
+
+// notifierMu guards knownStudents and the pending admin digest
+var (
+	knownStudents = make(map[int]Student)
+	digest        = AdminDigest{Since: time.Now()}
+	notifierMu    sync.Mutex
+)
+
+// AdminDigest struct to hold the student changes collected for the next admin digest, with the
+// attendance flags and LLM usage of the same period added when it is sent
+type AdminDigest struct {
+	Since               time.Time           `json:"since"`
+	Until               time.Time           `json:"until"`
+	Created             []DigestEntry       `json:"created"`
+	Updated             []DigestEntry       `json:"updated"`
+	Deleted             []DigestEntry       `json:"deleted"`
+	AttendanceThreshold float64             `json:"attendance_threshold"`
+	LowAttendance       []AttendanceSummary `json:"low_attendance"`
+	LLMUsage            UsageTotals         `json:"llm_usage"`
+	LLMCost             float64             `json:"llm_cost"`
+}
+
+// DigestEntry struct to hold one student's changes in an admin digest; updates to the same student
+// are merged
+type DigestEntry struct {
+	StudentID int      `json:"student_id"`
+	Name      string   `json:"name"`
+	Fields    []string `json:"fields,omitempty"`
+}
+
+// DigestPreview struct to hold a rendered admin digest
+type DigestPreview struct {
+	Subject string      `json:"subject"`
+	Body    string      `json:"body"`
+	Digest  AdminDigest `json:"digest"`
+}
+
+// AdvisorChangeNotice struct to hold the data of an advisor change notification
+type AdvisorChangeNotice struct {
+	Student  Student
+	Advisor  Teacher
+	Fields   []string
+	Assigned bool
+	Deleted  bool
+}
+
+// emailJobPayload struct to hold the payload of an email job
+type emailJobPayload struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// loadNotificationTemplates parses the notification templates, preferring files in
+// NOTIFICATION_TEMPLATE_DIR over the defaults
+func (s *Server) loadNotificationTemplates() error {
+	defaults := map[string]string{
+		"welcome":        defaultWelcomeTemplate,
+		"advisor_change": defaultAdvisorChangeTemplate,
+		"admin_digest":   defaultAdminDigestTemplate,
+	}
+	for name, text := range defaults {
+		if s.config.NotificationTemplateDir != "" {
+			custom, err := ioutil.ReadFile(filepath.Join(s.config.NotificationTemplateDir, name+".tmpl"))
+			if err == nil {
+				text = string(custom)
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+		}
+		tmpl, err := template.New(name).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+		if err != nil {
+			return err
+		}
+		notificationTemplates[name] = tmpl
+	}
+	return nil
+}
+
+// renderNotification renders a template into a subject and body
+func renderNotification(name string, data interface{}) (string, string, error) {
+	var buf bytes.Buffer
+	if err := notificationTemplates[name].Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	text := strings.TrimSpace(buf.String())
+	first, body := text, ""
+	if i := strings.Index(text, "\n"); i >= 0 {
+		first, body = text[:i], strings.TrimSpace(text[i+1:])
+	}
+	if !strings.HasPrefix(first, "Subject:") {
+		return "", "", errors.New("template " + name + " does not start with a Subject: line")
+	}
+	return strings.TrimSpace(strings.TrimPrefix(first, "Subject:")), body, nil
+}
+
+// queueEmail renders a notification and queues it for delivery, retried by the job queue
+func (s *Server) queueEmail(to, templateName string, data interface{}) {
+	if to == "" {
+		return
+	}
+	subject, body, err := renderNotification(templateName, data)
+	if err != nil {
+		log.Printf("Error rendering %s notification: %v", templateName, err)
+		return
+	}
+	opts := s.config.retryPolicy("email", "").jobOptions()
+	if _, err := s.enqueueJob(context.Background(), "email", emailJobPayload{To: to, Subject: subject, Body: body}, opts); err != nil {
+		log.Printf("Error queueing %s notification to %s: %v", templateName, to, err)
+	}
+}
+
+// sendWelcome queues the welcome notification for a new student, by email unless their
+// notification preferences say otherwise
+func (s *Server) sendWelcome(student Student) {
+	subject, body, err := renderNotification("welcome", map[string]interface{}{"Student": student})
+	if err != nil {
+		log.Printf("Error rendering welcome notification: %v", err)
+		return
+	}
+	to := notificationRecipient{StudentID: student.ID, Email: student.Email, Phone: student.Phone}
+	if _, _, err := s.sendNotification(context.Background(), to, ChannelEmail, subject, body, false); err != nil {
+		log.Printf("Error queueing welcome notification for student %d: %v", student.ID, err)
+	}
+}
+
+// runEmailJob sends one queued email
+func (s *Server) runEmailJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var msg emailJobPayload
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, err
+	}
+	if timeout := s.config.retryPolicy("email", "").timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := s.mailer.Send(ctx, msg.To, msg.Subject, msg.Body); err != nil {
+		return nil, err
+	}
+	return json.Marshal("Sent to " + msg.To)
+}
+
+// startNotifications loads the templates and, when any notification is enabled, follows student
+// events to send welcome emails, advisor notices and admin digests; all are off by default
+func (s *Server) startNotifications() error {
+	if err := s.loadNotificationTemplates(); err != nil {
+		return err
+	}
+	if s.config.AdminDigestSchedule != "" {
+		if len(s.config.AdminDigestTo) == 0 && s.chatWebhookFor(ChatDigest) == "" {
+			return errors.New("ADMIN_DIGEST_SCHEDULE requires ADMIN_DIGEST_TO or a digest chat channel")
+		}
+		schedule, err := ParseCron(s.config.AdminDigestSchedule)
+		if err != nil {
+			return err
+		}
+		runOnSchedule("admin-digest", schedule, s.sendAdminDigest)
+	}
+	if !s.config.NotifyWelcome && !s.config.NotifyAdvisors && !s.digestEnabled() {
+		return nil
+	}
+
+	// Holding the student store lock while subscribing means no change falls between the seeded states and the events
+	s.store.Lock()
+	events, _ := subscribeStudentEvents()
+	if s.config.NotifyAdvisors || s.digestEnabled() {
+		for _, student := range s.store.List() {
+			knownStudents[student.ID] = student
+		}
+	}
+	s.store.Unlock()
+
+	go func() {
+		for event := range events {
+			s.notifyStudentEvent(event)
+		}
+	}()
+	return nil
+}
+
+// notifyStudentEvent sends the notifications for one student change
+func (s *Server) notifyStudentEvent(event StudentEvent) {
+	student := event.Student
+
+	notifierMu.Lock()
+	before, known := knownStudents[student.ID]
+	if s.config.NotifyAdvisors || s.digestEnabled() {
+		if event.Type == EventStudentDeleted {
+			delete(knownStudents, student.ID)
+		} else {
+			knownStudents[student.ID] = student
+		}
+	}
+	var fields []string
+	if known && event.Type == EventStudentUpdated {
+		fields = changedStudentFields(before, student)
+	}
+	if s.digestEnabled() {
+		addToDigest(event, fields)
+	}
+	notifierMu.Unlock()
+
+	if event.Type == EventStudentCreated && s.config.NotifyWelcome {
+		s.sendWelcome(student)
+	}
+	if !s.config.NotifyAdvisors || student.AdvisorID == 0 || (event.Type == EventStudentUpdated && known && len(fields) == 0) {
+		return
+	}
+
+	teachersMu.Lock()
+	advisor, exists := teachers[student.AdvisorID]
+	teachersMu.Unlock()
+	if !exists {
+		return
+	}
+	s.queueEmail(advisor.Email, "advisor_change", AdvisorChangeNotice{
+		Student:  student,
+		Advisor:  advisor,
+		Fields:   fields,
+		Assigned: event.Type == EventStudentCreated || (known && before.AdvisorID != student.AdvisorID),
+		Deleted:  event.Type == EventStudentDeleted,
+	})
+}
+
+// addToDigest records a change for the next admin digest, merging repeated updates to a student;
+// the caller must hold notifierMu
+func addToDigest(event StudentEvent, fields []string) {
+	entry := DigestEntry{StudentID: event.StudentID, Name: event.Student.Name, Fields: fields}
+	switch event.Type {
+	case EventStudentCreated:
+		digest.Created = append(digest.Created, entry)
+	case EventStudentDeleted:
+		digest.Deleted = append(digest.Deleted, entry)
+	case EventStudentUpdated:
+		for i, existing := range digest.Updated {
+			if existing.StudentID == entry.StudentID {
+				for _, field := range fields {
+					if !containsString(existing.Fields, field) {
+						existing.Fields = append(existing.Fields, field)
+					}
+				}
+				existing.Name = entry.Name
+				digest.Updated[i] = existing
+				return
+			}
+		}
+		digest.Updated = append(digest.Updated, entry)
+	}
+}
+
+// digestEnabled reports whether changes are collected for the admin digest: it is either
+// scheduled or has somewhere to be sent on demand
+func (s *Server) digestEnabled() bool {
+	return s.config.AdminDigestSchedule != "" || len(s.config.AdminDigestTo) > 0 || s.chatWebhookFor(ChatDigest) != ""
+}
+
+// takeAdminDigest returns the changes collected since the last digest with the attendance flags
+// and LLM usage of the same days; with reset the next digest starts from now
+func (s *Server) takeAdminDigest(reset bool) AdminDigest {
+	notifierMu.Lock()
+	pending := digest
+	pending.Until = time.Now()
+	if reset {
+		digest = AdminDigest{Since: pending.Until}
+	}
+	notifierMu.Unlock()
+
+	pending.AttendanceThreshold = s.config.LowAttendanceThreshold
+	pending.LowAttendance = s.lowAttendance(AttendanceQuery{
+		From: pending.Since.Format(dateLayout),
+		To:   pending.Until.Format(dateLayout),
+	}, s.config.LowAttendanceThreshold)
+	pending.LLMUsage = llmUsageBetween(pending.Since.UTC().Format(dateLayout), pending.Until.UTC().Format(dateLayout))
+	pending.LLMCost = s.estimatedLLMCost(pending.LLMUsage)
+	return pending
+}
+
+// deliverAdminDigest mails a digest to ADMIN_DIGEST_TO and posts it to the digest chat channel
+func (s *Server) deliverAdminDigest(pending AdminDigest) error {
+	for _, to := range s.config.AdminDigestTo {
+		s.queueEmail(to, "admin_digest", pending)
+	}
+	if s.chatWebhookFor(ChatDigest) == "" {
+		return nil
+	}
+	subject, body, err := renderNotification("admin_digest", pending)
+	if err != nil {
+		return err
+	}
+	s.postChatEvent(ChatDigest, subject, body)
+	return nil
+}
+
+// sendAdminDigest sends the digest of the period since the last one; nothing is sent when there
+// was nothing to report
+func (s *Server) sendAdminDigest() error {
+	pending := s.takeAdminDigest(true)
+	if len(pending.Created)+len(pending.Updated)+len(pending.Deleted)+len(pending.LowAttendance) == 0 && pending.LLMUsage.Calls == 0 {
+		return nil
+	}
+	return s.deliverAdminDigest(pending)
+}
+
+// getAdminDigest handles GET /admin/digest to preview the next digest without sending it
+func (s *Server) getAdminDigest(w http.ResponseWriter, r *http.Request) {
+	pending := s.takeAdminDigest(false)
+	subject, body, err := renderNotification("admin_digest", pending)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DigestPreview{Subject: subject, Body: body, Digest: pending})
+}
+
+// sendAdminDigestNow handles POST /admin/digest/send to send the digest immediately, even when
+// there is nothing to report; the next scheduled digest starts from now
+func (s *Server) sendAdminDigestNow(w http.ResponseWriter, r *http.Request) {
+	if len(s.config.AdminDigestTo) == 0 && s.chatWebhookFor(ChatDigest) == "" {
+		http.Error(w, "Set ADMIN_DIGEST_TO or a digest chat channel to send digests", http.StatusConflict)
+		return
+	}
+	pending := s.takeAdminDigest(true)
+	if err := s.deliverAdminDigest(pending); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(pending)
+}
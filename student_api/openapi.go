@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	openAPISpec   []byte
+	openAPISpecMu sync.Mutex
+
+	pathParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]+)?\}`)
+	timeType         = reflect.TypeOf(time.Time{})
+)
+
+// openAPIBody names the Go types a handler decodes and encodes; the spec generator derives their
+// schemas by reflection so they follow the structs as fields are added
+type openAPIBody struct {
+	Request  interface{}
+	Response interface{}
+}
+
+// openAPIBodies maps handler names to their bodies; handlers without an entry are documented
+// with their path, parameters and security only
+var openAPIBodies = map[string]openAPIBody{
+	"createStudent":         {Student{}, Student{}},
+	"getAllStudents":        {nil, []Student{}},
+	"getStudentByID":        {nil, Student{}},
+	"updateStudent":         {Student{}, Student{}},
+	"syncStudents":          {[]Student{}, SyncResult{}},
+	"importStudents":        {nil, Job{}},
+	"createCourse":          {Course{}, Course{}},
+	"getAllCourses":         {nil, []Course{}},
+	"getCourseByID":         {nil, Course{}},
+	"updateCourse":          {Course{}, Course{}},
+	"createTeacher":         {Teacher{}, Teacher{}},
+	"getAllTeachers":        {nil, []Teacher{}},
+	"getTeacherByID":        {nil, Teacher{}},
+	"updateTeacher":         {Teacher{}, Teacher{}},
+	"createGroup":           {Group{}, Group{}},
+	"getAllGroups":          {nil, []Group{}},
+	"getGroupByID":          {nil, Group{}},
+	"updateGroup":           {Group{}, Group{}},
+	"createWebhook":         {Webhook{}, Webhook{}},
+	"getAllWebhooks":        {nil, []Webhook{}},
+	"getWebhookByID":        {nil, Webhook{}},
+	"getWebhookDeliveries":  {nil, []WebhookDelivery{}},
+	"getWebhookDeadLetters": {nil, []DeadLetter{}},
+	"redeliverDeadLetter":   {nil, Job{}},
+	"createTerm":            {Term{}, Term{}},
+	"getAllTerms":           {nil, []Term{}},
+	"getCurrentTerm":        {nil, Term{}},
+	"getTermByName":         {nil, Term{}},
+	"createEnrollment":      {Enrollment{}, Enrollment{}},
+	"getStudentEnrollments": {nil, []Enrollment{}},
+	"getStudentSchedule":    {nil, []ScheduleEntry{}},
+	"createGrade":           {Grade{}, Grade{}},
+	"getStudentGrades":      {nil, []Grade{}},
+	"getStudentGPA":         {nil, GPAReport{}},
+	"getTranscript":         {nil, Transcript{}},
+	"recordAttendance":      {AttendanceRecord{}, AttendanceRecord{}},
+	"createContact":         {Contact{}, Contact{}},
+	"messageContacts":       {nil, []ContactMessage{}},
+	"getNotifyPrefs":        {nil, NotificationPreference{}},
+	"putNotifyPrefs":        {NotificationPreference{}, NotificationPreference{}},
+	"getTenantSMSConfig":    {nil, SMSConfig{}},
+	"putTenantSMSConfig":    {SMSConfig{}, SMSConfig{}},
+	"getContacts":           {nil, []Contact{}},
+	"getRelatives":          {nil, Relatives{}},
+	"createConsent":         {ConsentRecord{}, ConsentRecord{}},
+	"getConsents":           {nil, []ConsentRecord{}},
+	"putHealthRecord":       {HealthRecord{}, HealthRecord{}},
+	"getHealthRecord":       {nil, HealthRecord{}},
+	"createIncident":        {Incident{}, Incident{}},
+	"getIncidents":          {nil, []Incident{}},
+	"getAttachments":        {nil, []Attachment{}},
+	"createInvoice":         {Invoice{}, Invoice{}},
+	"getInvoices":           {nil, []Invoice{}},
+	"createPayment":         {Payment{}, Payment{}},
+	"getPayments":           {nil, []Payment{}},
+	"getBalance":            {nil, Balance{}},
+	"createAward":           {Award{}, Award{}},
+	"getAwards":             {nil, []Award{}},
+	"getStatusHistory":      {nil, []StatusTransition{}},
+	"getCourseWaitlist":     {nil, []WaitlistEntry{}},
+	"getBirthdays":          {nil, []Birthday{}},
+	"getAlumniCohorts":      {nil, []AlumniCohort{}},
+	"getChanges":            {nil, ChangesPage{}},
+	"pollNewStudents":       {nil, []TriggerItem{}},
+	"pollUpdatedStudents":   {nil, []TriggerItem{}},
+	"receiveSISWebhook":     {SISNotification{}, SISLogEntry{}},
+	"getSISLog":             {nil, []SISLogEntry{}},
+	"getJobs":               {nil, []Job{}},
+	"getJob":                {nil, Job{}},
+	"cancelJob":             {nil, Job{}},
+	"retryJob":              {nil, Job{}},
+	"getCohortReport":       {nil, CohortReport{}},
+	"getCustomFields":       {nil, []CustomField{}},
+	"putCustomField":        {CustomField{}, CustomField{}},
+	"getComputedFields":     {nil, []ComputedField{}},
+	"putComputedField":      {ComputedField{}, ComputedField{}},
+	"promoteRoster":         {RosterPromotion{}, nil},
+	"getDataQuality":        {nil, DataQualityReport{}},
+	"getOutboxStatus":       {nil, OutboxStatus{}},
+	"replayEvents":          {ReplayRequest{}, ReplayResult{}},
+	"getRetryPolicies":      {nil, map[string]RetryPolicy{}},
+	"getScheduledTasks":     {nil, []ScheduledTask{}},
+	"getAdminDigest":        {nil, DigestPreview{}},
+	"sendAdminDigestNow":    {nil, AdminDigest{}},
+	"getLLMStatus":          {nil, LLMStatus{}},
+}
+
+// schemaBuilder collects the named component schemas referenced while walking Go types
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+// schemaFor returns the JSON schema of a Go type, registering structs as components
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schemaFor(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, seen := b.components[t.Name()]; !seen {
+			b.components[t.Name()] = nil // placeholder so recursive types terminate
+			b.components[t.Name()] = b.structSchema(t)
+		}
+		return ref
+	case t.Kind() == reflect.Struct:
+		return b.structSchema(t)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the object schema of a struct from its exported fields and JSON tags,
+// inlining embedded structs the way encoding/json does
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = b.schemaFor(field.Type)
+		}
+	}
+	addFields(t)
+
+	// Student adds its derived fields in MarshalJSON
+	if t == reflect.TypeOf(Student{}) {
+		properties["age"] = map[string]interface{}{"type": "integer"}
+		properties["computed"] = map[string]interface{}{"type": "object", "readOnly": true}
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// handlerName returns the function name of a route's handler; methods such as
+// (*handlers.Students).CreateStudent are named like functions, as createStudent
+func handlerName(handler http.Handler) string {
+	if handler == nil {
+		return ""
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = name[strings.LastIndex(name, ".")+1:]
+	if method := strings.TrimSuffix(name, "-fm"); method != name {
+		name = strings.ToLower(method[:1]) + method[1:]
+	}
+	return name
+}
+
+// humanize turns a handler name such as getStudentByID into "Get student by ID"
+func humanize(name string) string {
+	var words []string
+	start := 0
+	for i := 1; i <= len(name); i++ {
+		if i == len(name) || unicode.IsUpper(rune(name[i])) && !(unicode.IsUpper(rune(name[i-1])) && (i+1 == len(name) || unicode.IsUpper(rune(name[i+1])))) {
+			word := name[start:i]
+			if len(words) > 0 && !(len(word) > 1 && strings.ToUpper(word) == word) {
+				word = strings.ToLower(word)
+			}
+			words = append(words, word)
+			start = i
+		}
+	}
+	if len(words) > 0 {
+		words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// buildOpenAPISpec generates an OpenAPI 3 document by walking the registered routes, so every
+// route is documented without a hand-maintained spec
+func buildOpenAPISpec(router *mux.Router) ([]byte, error) {
+	b := &schemaBuilder{components: make(map[string]interface{})}
+	paths := make(map[string]map[string]interface{})
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil || route.GetHandler() == nil {
+			return nil
+		}
+		name := handlerName(route.GetHandler())
+		segments := strings.Split(strings.Trim(template, "/"), "/")
+
+		var parameters []interface{}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(template, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		path := pathParamPattern.ReplaceAllString(template, "{$1}")
+
+		for _, method := range methods {
+			operation := map[string]interface{}{
+				"operationId": name,
+				"summary":     humanize(name),
+				"tags":        []string{segments[0]},
+			}
+			if parameters != nil {
+				operation["parameters"] = parameters
+			}
+			if segments[0] == "admin" {
+				operation["security"] = []interface{}{map[string]interface{}{"adminToken": []string{}}}
+			}
+
+			status, description := "200", "OK"
+			switch {
+			case strings.HasPrefix(name, "create"):
+				status, description = "201", "Created"
+			case strings.HasPrefix(name, "delete"), strings.HasPrefix(name, "remove"):
+				status, description = "204", "No Content"
+			}
+			response := map[string]interface{}{"description": description}
+			body := openAPIBodies[name]
+			if body.Request != nil {
+				operation["requestBody"] = map[string]interface{}{
+					"required": true,
+					"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": b.schemaFor(reflect.TypeOf(body.Request))}},
+				}
+			}
+			if body.Response != nil {
+				response["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": b.schemaFor(reflect.TypeOf(body.Response))}}
+			}
+			operation["responses"] = map[string]interface{}{
+				status:    response,
+				"default": map[string]interface{}{"description": "Error", "content": map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}}},
+			}
+
+			if paths[path] == nil {
+				paths[path] = make(map[string]interface{})
+			}
+			paths[path][strings.ToLower(method)] = operation
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "Student API", "version": "1.0.0"},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"securitySchemes": map[string]interface{}{
+				"apiKey":     map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"adminToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"apiKey": []string{}}},
+	}, "", "  ")
+}
+
+// loadOpenAPISpec generates the spec once every route has been registered
+func loadOpenAPISpec(router *mux.Router) error {
+	spec, err := buildOpenAPISpec(router)
+	if err != nil {
+		return err
+	}
+	openAPISpecMu.Lock()
+	openAPISpec = spec
+	openAPISpecMu.Unlock()
+	return nil
+}
+
+// getOpenAPISpec handles GET /openapi.json to serve the generated OpenAPI document
+func getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	openAPISpecMu.Lock()
+	spec := openAPISpec
+	openAPISpecMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// swaggerUIPage loads Swagger UI from its CDN and points it at /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Student API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// getDocs handles GET /docs to serve Swagger UI for the API
+func getDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// The event outbox holds the student events not yet accepted by the broker. Events are added by
+// publishStudentEvent in the same critical section as the change itself, so a change is never
+// made without its event, and leave the outbox only once published. It holds at most
+// eventOutboxMax events: while the broker is down that long, further events are dropped and
+// counted rather than kept in memory without bound. eventSubscribersMu guards it
+var (
+	eventOutbox        []StudentEvent
+	eventOutboxEnabled bool
+	eventOutboxMax     int
+	// eventOutboxOverflowing is set while events are being dropped, so the overflow is logged once
+	eventOutboxOverflowing bool
+	eventOutboxSignal      = make(chan struct{}, 1)
+	outboxStatus           OutboxStatus
+)
+
+// OutboxStatus struct to hold the state of the event outbox relay
+type OutboxStatus struct {
+	Enabled         bool       `json:"enabled"`
+	Pending         int        `json:"pending"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	Delivered       int64      `json:"delivered"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+	Failures        int64      `json:"failures"`
+	LastError       string     `json:"last_error,omitempty"`
+	// Dropped counts the events never published because the outbox was full
+	Dropped       int64      `json:"dropped"`
+	LastDroppedAt *time.Time `json:"last_dropped_at,omitempty"`
+}
+
+// addToOutbox queues an event for the relay, dropping it when the outbox is full; the caller must
+// hold eventSubscribersMu
+func addToOutbox(event StudentEvent) {
+	if !eventOutboxEnabled {
+		return
+	}
+	if eventOutboxMax > 0 && len(eventOutbox) >= eventOutboxMax {
+		now := time.Now()
+		if !eventOutboxOverflowing {
+			log.Printf("Event outbox is full with %d events, dropping events from %s event %d until the broker catches up", len(eventOutbox), event.Type, event.ID)
+			eventOutboxOverflowing = true
+		}
+		outboxStatus.Dropped++
+		outboxStatus.LastDroppedAt = &now
+		return
+	}
+	eventOutbox = append(eventOutbox, event)
+	select {
+	case eventOutboxSignal <- struct{}{}:
+	default:
+	}
+}
+
+// relayOutbox publishes outbox events in order, one at a time; a failed event is retried with the
+// wait doubling up to a minute, holding back later events so each student's changes stay ordered
+func (s *Server) relayOutbox(publisher EventPublisher) {
+	wait := time.Second
+	for {
+		eventSubscribersMu.Lock()
+		if len(eventOutbox) == 0 {
+			eventSubscribersMu.Unlock()
+			<-eventOutboxSignal
+			continue
+		}
+		event := eventOutbox[0]
+		eventSubscribersMu.Unlock()
+
+		if err := publisher.Publish(event); err != nil {
+			eventSubscribersMu.Lock()
+			outboxStatus.Failures++
+			outboxStatus.LastError = err.Error()
+			eventSubscribersMu.Unlock()
+			log.Printf("Error publishing %s event %d to %s, retrying in %s: %v", event.Type, event.ID, s.config.EventBroker, wait, err)
+			time.Sleep(wait)
+			if wait *= 2; wait > time.Minute {
+				wait = time.Minute
+			}
+			continue
+		}
+		wait = time.Second
+
+		now := time.Now()
+		eventSubscribersMu.Lock()
+		eventOutbox[0] = StudentEvent{}
+		eventOutbox = eventOutbox[1:]
+		eventOutboxOverflowing = false
+		outboxStatus.Delivered++
+		outboxStatus.LastDeliveredAt = &now
+		eventSubscribersMu.Unlock()
+	}
+}
+
+// getOutboxStatus handles GET /admin/outbox to report how far the broker relay is behind
+func getOutboxStatus(w http.ResponseWriter, r *http.Request) {
+	eventSubscribersMu.Lock()
+	status := outboxStatus
+	status.Enabled = eventOutboxEnabled
+	status.Pending = len(eventOutbox)
+	if status.Pending > 0 {
+		at := eventOutbox[0].At
+		status.OldestPendingAt = &at
+	}
+	eventSubscribersMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
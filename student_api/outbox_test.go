@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOutboxDropsEventsOverItsCap(t *testing.T) {
+	eventSubscribersMu.Lock()
+	eventOutbox, eventOutboxEnabled, eventOutboxMax, outboxStatus = nil, true, 2, OutboxStatus{}
+	for id := int64(1); id <= 5; id++ {
+		addToOutbox(StudentEvent{ID: id, Type: EventStudentUpdated})
+	}
+	eventSubscribersMu.Unlock()
+	t.Cleanup(func() {
+		eventSubscribersMu.Lock()
+		eventOutbox, eventOutboxEnabled, eventOutboxMax, outboxStatus = nil, false, 0, OutboxStatus{}
+		eventOutboxOverflowing = false
+		eventSubscribersMu.Unlock()
+	})
+
+	rec := httptest.NewRecorder()
+	getOutboxStatus(rec, httptest.NewRequest("GET", "/admin/outbox", nil))
+	var status OutboxStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("GET /admin/outbox returned %s", rec.Body)
+	}
+	if status.Pending != 2 || status.Dropped != 3 || status.LastDroppedAt == nil {
+		t.Errorf("outbox status = %+v, want 2 pending and 3 dropped", status)
+	}
+	if eventOutbox[0].ID != 1 || eventOutbox[1].ID != 2 {
+		t.Errorf("outbox kept events %d and %d, want the first two", eventOutbox[0].ID, eventOutbox[1].ID)
+	}
+}
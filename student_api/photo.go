@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// photoSizes maps each generated thumbnail to the maximum length of its longer side
+var photoSizes = map[string]int{"small": 64, "medium": 256, "large": 512}
+
+// maxPhotoPixels rejects images whose decoded size would be unreasonable for a profile photo
+const maxPhotoPixels = 25_000_000
+
+var (
+	photos   = make(map[int]Photo)
+	photosMu sync.Mutex
+)
+
+// Photo struct to hold the metadata of a student's profile photo
+type Photo struct {
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// photoKey returns the blob key of one size of a student's photo
+func photoKey(id int, size string) string {
+	return fmt.Sprintf("students/%d/photo/%s", id, size)
+}
+
+// putStudentPhoto handles PUT /students/{id}/photo to upload a JPEG or PNG profile photo as the
+// request body, storing the original along with small, medium and large JPEG thumbnails
+func (s *Server) putStudentPhoto(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.config.PhotoMaxBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Photo exceeds the %d byte limit", s.config.PhotoMaxBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		http.Error(w, "Photo must be a JPEG or PNG image", http.StatusUnsupportedMediaType)
+		return
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		http.Error(w, "Invalid image", http.StatusBadRequest)
+		return
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		http.Error(w, "Image dimensions are too large", http.StatusBadRequest)
+		return
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		http.Error(w, "Invalid image", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.blobs.Put(r.Context(), photoKey(id, "original"), data, contentType); err != nil {
+		log.Printf("Storing photo for student %d failed: %v", id, err)
+		http.Error(w, "Error storing photo", http.StatusBadGateway)
+		return
+	}
+	for size, maxSide := range photoSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resizeImage(img, maxSide), &jpeg.Options{Quality: 85}); err != nil {
+			http.Error(w, "Error resizing photo", http.StatusInternalServerError)
+			return
+		}
+		if err := s.blobs.Put(r.Context(), photoKey(id, size), buf.Bytes(), "image/jpeg"); err != nil {
+			log.Printf("Storing %s photo for student %d failed: %v", size, id, err)
+			http.Error(w, "Error storing photo", http.StatusBadGateway)
+			return
+		}
+	}
+
+	photo := Photo{ContentType: contentType, Width: cfg.Width, Height: cfg.Height, UpdatedAt: time.Now()}
+	photosMu.Lock()
+	photos[id] = photo
+	photosMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(photo)
+}
+
+// getStudentPhoto handles GET /students/{id}/photo?size=small|medium|large|original to serve a
+// student's photo, defaulting to the original upload
+func (s *Server) getStudentPhoto(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	size := firstNonEmpty(r.URL.Query().Get("size"), "original")
+	if _, ok := photoSizes[size]; !ok && size != "original" {
+		http.Error(w, "size must be small, medium, large or original", http.StatusBadRequest)
+		return
+	}
+
+	photosMu.Lock()
+	photo, exists := photos[id]
+	photosMu.Unlock()
+	if !exists {
+		http.Error(w, "Photo not found", http.StatusNotFound)
+		return
+	}
+
+	etag := `"` + strconv.FormatInt(photo.UpdatedAt.UnixNano(), 36) + "-" + size + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := s.blobs.Get(r.Context(), photoKey(id, size))
+	if errors.Is(err, ErrBlobNotFound) {
+		http.Error(w, "Photo not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Reading photo for student %d failed: %v", id, err)
+		http.Error(w, "Error reading photo", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	contentType := "image/jpeg"
+	if size == "original" {
+		contentType = photo.ContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.Header().Set("ETag", etag)
+	io.Copy(w, body)
+}
+
+// deleteStudentPhoto handles DELETE /students/{id}/photo to remove a student's photo
+func (s *Server) deleteStudentPhoto(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	photosMu.Lock()
+	_, exists := photos[id]
+	photosMu.Unlock()
+	if !exists {
+		http.Error(w, "Photo not found", http.StatusNotFound)
+		return
+	}
+
+	s.deletePhoto(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deletePhoto drops a student's photo and removes every stored size in the background
+func (s *Server) deletePhoto(id int) {
+	photosMu.Lock()
+	_, exists := photos[id]
+	delete(photos, id)
+	photosMu.Unlock()
+	if !exists {
+		return
+	}
+
+	go func() {
+		for _, size := range []string{"original", "small", "medium", "large"} {
+			if err := s.blobs.Delete(context.Background(), photoKey(id, size)); err != nil {
+				log.Printf("Deleting %s photo for student %d failed: %v", size, id, err)
+			}
+		}
+	}()
+}
+
+// resizeImage scales img down so its longer side is at most maxSide, averaging the source pixels
+// covered by each destination pixel; smaller images are returned unscaled
+func resizeImage(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxSide && srcH <= maxSide {
+		return img
+	}
+
+	dstW, dstH := maxSide, srcH*maxSide/srcW
+	if srcH > srcW {
+		dstW, dstH = srcW*maxSide/srcH, maxSide
+	}
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, (y+1)*srcH/dstH
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, (x+1)*srcW/dstW
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"student_api/student_api/internal/store"
+)
+
+// dateLayout is the format of calendar dates such as Student.DateOfBirth
+const dateLayout = store.DateLayout
+
+var (
+	phoneNumberPattern = regexp.MustCompile(`^\+?[0-9][0-9 ()\-]{5,18}[0-9]$`)
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+	// Accepted values for Student.Gender
+	studentGenders = map[string]bool{"female": true, "male": true, "non-binary": true, "other": true, "undisclosed": true}
+)
+
+// normalizeStudent trims the profile fields and canonicalizes their case
+func normalizeStudent(s *Student) {
+	s.Phone = strings.TrimSpace(s.Phone)
+	s.DateOfBirth = strings.TrimSpace(s.DateOfBirth)
+	s.Gender = strings.ToLower(strings.TrimSpace(s.Gender))
+	s.Nationality = strings.ToUpper(strings.TrimSpace(s.Nationality))
+	s.ExternalIDs = normalizeExternalIDs(s.ExternalIDs)
+	if s.Address != nil {
+		s.Address.Country = strings.ToUpper(strings.TrimSpace(s.Address.Country))
+	}
+	if s.Guardian != nil {
+		s.Guardian.Phone = strings.TrimSpace(s.Guardian.Phone)
+		s.Guardian.Email = strings.TrimSpace(s.Guardian.Email)
+	}
+}
+
+// validateStudent checks a complete student record, returning a message suitable for the client
+func validateStudent(s Student) error {
+	if s.Name == "" || s.Email == "" {
+		return errors.New("Invalid student data")
+	}
+	if s.DateOfBirth != "" {
+		dob, err := time.Parse(dateLayout, s.DateOfBirth)
+		if err != nil {
+			return errors.New("date_of_birth must be in YYYY-MM-DD format")
+		}
+		if age := store.AgeOn(dob, time.Now()); dob.After(time.Now()) || age > 120 {
+			return errors.New("date_of_birth is out of range")
+		}
+	} else if s.RecordedAge <= 0 {
+		return errors.New("Invalid student data")
+	}
+	if s.Phone != "" && !phoneNumberPattern.MatchString(s.Phone) {
+		return errors.New("Invalid phone number")
+	}
+	if s.GradeLevel < 0 {
+		return errors.New("grade_level must not be negative")
+	}
+	if s.Gender != "" && !studentGenders[s.Gender] {
+		return errors.New("gender must be one of female, male, non-binary, other or undisclosed")
+	}
+	if s.Nationality != "" && !countryCodePattern.MatchString(s.Nationality) {
+		return errors.New("nationality must be an ISO 3166-1 alpha-2 country code")
+	}
+	if a := s.Address; a != nil {
+		if strings.TrimSpace(a.Line1) == "" || strings.TrimSpace(a.City) == "" {
+			return errors.New("address requires line1 and city")
+		}
+		if a.Country != "" && !countryCodePattern.MatchString(a.Country) {
+			return errors.New("address country must be an ISO 3166-1 alpha-2 country code")
+		}
+	}
+	if g := s.Guardian; g != nil {
+		if strings.TrimSpace(g.Name) == "" || (g.Phone == "" && g.Email == "") {
+			return errors.New("guardian requires a name and a phone or email")
+		}
+		if g.Phone != "" && !phoneNumberPattern.MatchString(g.Phone) {
+			return errors.New("Invalid guardian phone number")
+		}
+		if g.Email != "" && !strings.Contains(g.Email, "@") {
+			return errors.New("Invalid guardian email")
+		}
+	}
+	if err := validateExternalIDs(s.ExternalIDs); err != nil {
+		return err
+	}
+	return validateCustomValues(s.Custom)
+}
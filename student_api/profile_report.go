@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultProfileReportTemplate is used when no REPORT_TEMPLATE_FILE is configured; paragraphs are
+// separated by blank lines and single newlines break lines within a paragraph
+const defaultProfileReportTemplate = `{{with .Student}}Student: {{.Name}} (ID {{.ID}})
+Email: {{.Email}}{{if .Phone}}
+Phone: {{.Phone}}{{end}}
+Status: {{.Status}}{{if .GradeLevel}}, grade {{.GradeLevel}}{{end}}
+Age: {{.Age}}{{with .Guardian}}
+Guardian: {{.Name}}{{if .Phone}}, {{.Phone}}{{end}}{{if .Email}}, {{.Email}}{{end}}{{end}}{{end}}
+
+Attendance
+{{with .Attendance}}{{if .Total}}{{printf "%.1f" .Percentage}}% over {{.Total}} sessions: {{.Present}} present, {{.Late}} late, {{.Absent}} absent, {{.Excused}} excused{{else}}No attendance recorded{{end}}{{end}}
+
+Grades
+{{- range .Transcript.Terms}}
+{{or .Term "Unassigned term"}}:
+{{- range .Courses}}
+  {{.CourseCode}} {{.Title}} - {{printf "%.1f" .Score}}{{if .Amended}} (amended){{end}}
+{{- end}}
+{{- else}}
+No grades recorded
+{{- end}}
+
+Summary
+{{with .Summary}}{{.Text}}
+
+Summary generated {{.GeneratedAt.Format "January 2, 2006"}}{{else}}No summary has been generated yet{{end}}
+
+Report generated {{.GeneratedAt.Format "January 2, 2006 15:04"}}`
+
+var profileReportTemplate = template.Must(template.New("report").Parse(defaultProfileReportTemplate))
+
+// ProfileReport struct to hold the data a profile report template is rendered with
+type ProfileReport struct {
+	Student     Student
+	Attendance  AttendanceSummary
+	Transcript  Transcript
+	Summary     *ProfileReportSummary
+	GeneratedAt time.Time
+}
+
+// ProfileReportSummary struct to hold the latest LLM summary of a student as plain text
+type ProfileReportSummary struct {
+	Text        string
+	GeneratedAt time.Time
+}
+
+// loadProfileReportTemplate parses the configured profile report template file
+func (s *Server) loadProfileReportTemplate() error {
+	if s.config.ReportTemplateFile == "" {
+		return nil
+	}
+
+	text, err := ioutil.ReadFile(s.config.ReportTemplateFile)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("report").Parse(string(text))
+	if err != nil {
+		return err
+	}
+	profileReportTemplate = tmpl
+	return nil
+}
+
+// buildProfileReport gathers a student's attendance, grades and latest summary; the summary is
+// the last one generated, so rendering a report never calls the LLM
+func buildProfileReport(student Student) ProfileReport {
+	report := ProfileReport{Student: student, Transcript: buildTranscript(student), GeneratedAt: time.Now()}
+
+	records := []AttendanceRecord{}
+	attendanceMu.Lock()
+	for _, record := range attendance {
+		if record.StudentID == student.ID {
+			records = append(records, record)
+		}
+	}
+	attendanceMu.Unlock()
+	report.Attendance = summarizeAttendance(student.ID, records)
+
+	summariesMu.Lock()
+	if history := summaryLog[student.ID]; len(history) > 0 {
+		latest := history[len(history)-1]
+		report.Summary = &ProfileReportSummary{Text: summaryPlainText(latest), GeneratedAt: latest.GeneratedAt}
+	}
+	summariesMu.Unlock()
+	return report
+}
+
+// getProfileReport handles GET /students/{id}/report.pdf to render a student's record,
+// attendance, grades and latest summary into a PDF for parent-teacher meetings; the layout comes
+// from REPORT_TEMPLATE_FILE
+func (s *Server) getProfileReport(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := profileReportTemplate.Execute(&buf, buildProfileReport(student)); err != nil {
+		http.Error(w, "Error rendering report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"student-%d-report.pdf\"", id))
+	w.Write(renderPDF("Student report: "+student.Name, strings.Split(strings.TrimSpace(buf.String()), "\n\n")))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// errPromotionStale skips a planned promotion of a student whose grade level or status has since changed
+var errPromotionStale = errors.New("student changed since the promotion was planned")
+
+// RosterPromotion struct to hold one student's move in a roster promotion
+type RosterPromotion struct {
+	StudentID int    `json:"student_id"`
+	Name      string `json:"name"`
+	From      int    `json:"from_grade_level"`
+	To        int    `json:"to_grade_level"`
+	Graduated bool   `json:"graduated,omitempty"`
+}
+
+// promoteRoster handles POST /admin/roster/promote to move every enrolled student matching the
+// filter up by `by` grade levels (default 1) in one step. Students pushed past
+// graduate_after_grade_level, when set, graduate instead; with "dry_run": true the affected
+// students are returned without any change
+func (s *Server) promoteRoster(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Filter             StudentFilter `json:"filter"`
+		By                 int           `json:"by"`
+		GraduateAfterGrade int           `json:"graduate_after_grade_level"`
+		DryRun             bool          `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.By < 0 || req.GraduateAfterGrade < 0 {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if req.By == 0 {
+		req.By = 1
+	}
+	changedBy := callerID(r.Context())
+
+	// Students without a grade level or not currently enrolled are left out of the cohort
+	s.store.Lock()
+	plan := []RosterPromotion{}
+	for _, student := range s.filterStudents(req.Filter) {
+		if student.GradeLevel == 0 || student.Status != StatusEnrolled {
+			continue
+		}
+		move := RosterPromotion{StudentID: student.ID, Name: student.Name, From: student.GradeLevel, To: student.GradeLevel + req.By}
+		if req.GraduateAfterGrade > 0 && move.To > req.GraduateAfterGrade {
+			move.To = student.GradeLevel
+			move.Graduated = true
+		}
+		plan = append(plan, move)
+	}
+	s.store.Unlock()
+	sort.Slice(plan, func(i, j int) bool { return plan[i].StudentID < plan[j].StudentID })
+
+	if !req.DryRun {
+		// A student changed or deleted since the plan was made is left as they are and dropped
+		// from the result
+		applied := []RosterPromotion{}
+		for _, move := range plan {
+			_, err := s.students.Change(move.StudentID, func(student *Student) error {
+				if student.GradeLevel != move.From || student.Status != StatusEnrolled {
+					return errPromotionStale
+				}
+				student.GradeLevel = move.To
+				if !move.Graduated {
+					return nil
+				}
+				graduated, err := transitionStatus(*student, StatusGraduated, "roster promotion", changedBy)
+				*student = graduated
+				return err
+			})
+			if err != nil {
+				if !errors.Is(err, ErrStudentNotFound) && !errors.Is(err, errPromotionStale) {
+					log.Printf("Error promoting student %d: %v", move.StudentID, err)
+				}
+				continue
+			}
+			applied = append(applied, move)
+		}
+		plan = applied
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"dry_run": req.DryRun, "count": len(plan), "students": plan})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"text/template"
+)
+
+// defaultPromptTemplate is used when no PROMPT_TEMPLATE_FILE is configured
+const defaultPromptTemplate = "Generate a detailed summary for the following student: Name: {{.Name}}, Age: {{.Age}}, Email: {{.Email}}" +
+	"{{with .Advisor}}, Advisor: {{.Name}}{{with .Department}} ({{.}}){{end}}{{end}}" +
+	"{{with .Awards}}, Awards: {{range $i, $a := .}}{{if $i}}; {{end}}{{$a.Name}} ({{$a.Type}}){{end}}{{end}}" +
+	"{{with .Health}}{{with .Allergies}}, Allergies: {{range $i, $a := .}}{{if $i}}, {{end}}{{$a}}{{end}}{{end}}" +
+	"{{with .Conditions}}, Medical conditions: {{range $i, $c := .}}{{if $i}}, {{end}}{{$c}}{{end}}{{end}}{{end}}"
+
+var (
+	promptTemplate   = template.Must(template.New("summary").Parse(defaultPromptTemplate))
+	promptTemplateMu sync.RWMutex
+)
+
+// loadPromptTemplate parses the configured prompt template file, keeping the current template on error
+func (s *Server) loadPromptTemplate() error {
+	if s.config.PromptTemplateFile == "" {
+		return nil
+	}
+
+	text, err := ioutil.ReadFile(s.config.PromptTemplateFile)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("summary").Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	promptTemplateMu.Lock()
+	promptTemplate = tmpl
+	promptTemplateMu.Unlock()
+	return nil
+}
+
+// PromptData struct to hold the values available to the prompt template
+type PromptData struct {
+	Student
+	Options SummaryOptions
+	// Advisor is the student's assigned advisor, or nil
+	Advisor *Teacher
+	Awards  []Award
+	// Health is only set when the student's health record allows sharing with the LLM
+	Health *HealthRecord
+}
+
+// renderSummaryPrompt executes the prompt template against a student and appends the
+// tone, length and language instructions
+func (s *Server) renderSummaryPrompt(student Student, opts SummaryOptions) (string, error) {
+	if err := s.checkLLMConsent(student.ID); err != nil {
+		return "", err
+	}
+	promptTemplateMu.RLock()
+	tmpl := promptTemplate
+	promptTemplateMu.RUnlock()
+	if tenantTmpl := tenantPromptTemplate(opts.Tenant); tenantTmpl != nil {
+		tmpl = tenantTmpl
+	}
+
+	data := PromptData{Student: student, Options: opts, Awards: studentAwards(student.ID)}
+	if advisor, ok := teacherByID(student.AdvisorID); ok {
+		data.Advisor = &advisor
+	}
+	if health, ok := healthRecordFor(student.ID); ok && health.ShareWithLLM {
+		data.Health = &health
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	if opts.Tone != "" {
+		fmt.Fprintf(&buf, "\nWrite in a %s tone.", summaryTones[opts.Tone])
+	}
+	if opts.Length != "" {
+		fmt.Fprintf(&buf, "\nKeep the summary under %d words.", summaryLengths[opts.Length])
+	}
+	if opts.Language != "" {
+		fmt.Fprintf(&buf, "\nRespond only in %s.", summaryLanguages[opts.Language])
+	}
+	return buf.String(), nil
+}
+
+// reloadPromptTemplate handles POST /admin/prompt/reload to re-read the prompt template file
+func (s *Server) reloadPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := s.loadPromptTemplate(); err != nil {
+		http.Error(w, "Error loading prompt template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
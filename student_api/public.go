@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// publicRateBuckets holds a token bucket per client IP for the public API
+var (
+	publicRateBuckets   = make(map[string]*rateBucket)
+	publicRateBucketsMu sync.Mutex
+)
+
+// rateBucket struct to hold the tokens left for one client and when they were last refilled
+type rateBucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+// PublicStudent struct to hold the directory entry of a student on the public API; it carries
+// no contact details or other personal data
+type PublicStudent struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	GradeLevel     int    `json:"grade_level,omitempty"`
+	Alumni         bool   `json:"alumni"`
+	GraduationYear int    `json:"graduation_year,omitempty"`
+}
+
+// PublicCourse struct to hold the catalogue entry of a course on the public API
+type PublicCourse struct {
+	ID          int       `json:"id"`
+	Code        string    `json:"code"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Credits     float64   `json:"credits"`
+	Meetings    []Meeting `json:"meetings,omitempty"`
+}
+
+// startPublicServer serves the public read-only directory on PUBLIC_ADDR, a listener separate
+// from the full API; an empty address disables it
+func (s *Server) startPublicServer() error {
+	if s.config.PublicAddr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.config.PublicAddr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Public directory is listening on %s...", s.config.PublicAddr)
+	go func() {
+		if err := http.Serve(listener, s.publicRouter()); err != nil {
+			log.Printf("Public directory stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// publicRouter builds the routes of the public API; only GET routes exist, so every write is
+// rejected with 405
+func (s *Server) publicRouter() http.Handler {
+	router := mux.NewRouter()
+	router.Use(s.withPublicRateLimit)
+	router.HandleFunc("/students", s.getPublicStudents).Methods("GET")
+	router.HandleFunc("/students/{id}", s.getPublicStudent).Methods("GET")
+	router.HandleFunc("/courses", getPublicCourses).Methods("GET")
+	return router
+}
+
+// withPublicRateLimit allows each client IP PUBLIC_RATE_LIMIT requests a minute, answering 429
+// with Retry-After once its bucket is empty, and lets any origin read the responses
+func (s *Server) withPublicRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if wait := s.takePublicToken(client, time.Now()); wait > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// takePublicToken takes a token from a client's bucket, returning how long to wait when it is
+// empty; buckets idle long enough to have refilled are dropped
+func (s *Server) takePublicToken(client string, now time.Time) time.Duration {
+	limit := float64(s.config.PublicRateLimit)
+	if limit <= 0 {
+		return 0
+	}
+	perSecond := limit / 60
+
+	publicRateBucketsMu.Lock()
+	defer publicRateBucketsMu.Unlock()
+
+	if len(publicRateBuckets) > 10000 {
+		for key, bucket := range publicRateBuckets {
+			if now.Sub(bucket.seen) > time.Minute {
+				delete(publicRateBuckets, key)
+			}
+		}
+	}
+	bucket, ok := publicRateBuckets[client]
+	if !ok {
+		bucket = &rateBucket{tokens: limit, seen: now}
+		publicRateBuckets[client] = bucket
+	}
+	bucket.tokens = math.Min(limit, bucket.tokens+now.Sub(bucket.seen).Seconds()*perSecond)
+	bucket.seen = now
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+	}
+	bucket.tokens--
+	return 0
+}
+
+// publicStudent reports whether a student is listed in the public directory: enrolled students
+// and alumni, and with PUBLIC_REQUIRE_CONSENT only those who granted data_sharing
+func (s *Server) publicStudent(student Student) (PublicStudent, bool) {
+	if student.Status != StatusEnrolled && !student.Alumni {
+		return PublicStudent{}, false
+	}
+	if s.config.PublicRequireConsent && !hasConsent(student.ID, ConsentDataSharing) {
+		return PublicStudent{}, false
+	}
+	return PublicStudent{
+		ID:             student.ID,
+		Name:           student.Name,
+		GradeLevel:     student.GradeLevel,
+		Alumni:         student.Alumni,
+		GraduationYear: student.GraduationYear,
+	}, true
+}
+
+// getPublicStudents handles GET /students?name=&grade_level= on the public API to list the
+// directory, ordered by name
+func (s *Server) getPublicStudents(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("name")))
+	gradeLevel := 0
+	if value := r.URL.Query().Get("grade_level"); value != "" {
+		level, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid grade_level", http.StatusBadRequest)
+			return
+		}
+		gradeLevel = level
+	}
+
+	s.store.Lock()
+	all := make([]Student, 0, s.store.Len())
+	for _, student := range s.store.List() {
+		all = append(all, student)
+	}
+	s.store.Unlock()
+
+	list := []PublicStudent{}
+	for _, student := range all {
+		entry, listed := s.publicStudent(student)
+		if !listed || (name != "" && !strings.Contains(strings.ToLower(entry.Name), name)) ||
+			(gradeLevel != 0 && entry.GradeLevel != gradeLevel) {
+			continue
+		}
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getPublicStudent handles GET /students/{id} on the public API to fetch a directory entry;
+// students left out of the directory are reported as not found
+func (s *Server) getPublicStudent(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	entry, listed := s.publicStudent(student)
+	if !exists || !listed {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// getPublicCourses handles GET /courses on the public API to list the course catalogue without
+// capacities or enrollments
+func getPublicCourses(w http.ResponseWriter, r *http.Request) {
+	coursesMu.Lock()
+	list := make([]PublicCourse, 0, len(courses))
+	for _, course := range courses {
+		list = append(list, PublicCourse{
+			ID:          course.ID,
+			Code:        course.Code,
+			Title:       course.Title,
+			Description: course.Description,
+			Credits:     course.Credits,
+			Meetings:    course.Meetings,
+		})
+	}
+	coursesMu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Operations a natural language query can be translated into
+var queryOperations = map[string]bool{"count": true, "list": true, "average_age": true, "min_age": true, "max_age": true}
+
+// InterpretedQuery struct to hold the structured query the model derived from a question
+type InterpretedQuery struct {
+	Operation string        `json:"operation"`
+	Filter    StudentFilter `json:"filter"`
+}
+
+// queryTranslationPrompt instructs the model to translate a question into an InterpretedQuery
+const queryTranslationPrompt = `Translate the question below into a JSON query over a student dataset.
+Each student has the fields: id, name, age, email, gender, nationality (ISO country code), address city and status (applied, enrolled, on_leave, graduated or withdrawn).
+Respond with a single JSON object and nothing else, in this shape:
+{"operation": "count" | "list" | "average_age" | "min_age" | "max_age",
+ "filter": {"name": "<substring of the name, optional>", "min_age": <inclusive minimum age, optional>, "max_age": <inclusive maximum age, optional>,
+            "gender": "female" | "male" | "non-binary" | "other" | "undisclosed" (optional), "nationality": "<country code, optional>", "city": "<city, optional>",
+            "status": "<status, optional>"}}
+Use "over N" as min_age N+1 and "under N" as max_age N-1.
+
+Question: %s`
+
+// queryStudents handles POST /query to answer a natural language question about the dataset
+func (s *Server) queryStudents(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Question string `json:"question"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Question) == "" {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.llm.Generate(r.Context(), LLMRequest{
+		Model:  opts.Model,
+		Prompt: fmt.Sprintf(queryTranslationPrompt, req.Question),
+		JSON:   true,
+	})
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	var query InterpretedQuery
+	if err := json.Unmarshal([]byte(strings.TrimSpace(resp.Text)), &query); err != nil || !queryOperations[query.Operation] {
+		s.writeLLMError(w, fmt.Errorf("%w: could not interpret question", ErrInvalidLLMOutput))
+		return
+	}
+
+	s.store.Lock()
+	matched := s.filterStudents(query.Filter)
+	s.store.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"question":          req.Question,
+		"interpreted_query": query,
+		"answer":            executeQuery(query.Operation, matched),
+	})
+}
+
+// executeQuery applies an operation to the students matched by an interpreted query
+func executeQuery(operation string, matched []Student) interface{} {
+	switch operation {
+	case "count":
+		return len(matched)
+	case "list":
+		if matched == nil {
+			return []Student{}
+		}
+		return matched
+	}
+
+	if len(matched) == 0 {
+		return nil
+	}
+	stats := cohortStats(matched)
+	switch operation {
+	case "average_age":
+		return stats.MeanAge
+	case "min_age":
+		return stats.MinAge
+	default:
+		return stats.MaxAge
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// relatives holds symmetric links between students: relatives[a][b] is the relationship of b to a
+var (
+	relatives   = make(map[int]map[int]string)
+	relativesMu sync.Mutex
+
+	// Accepted values for a relative link
+	relativeRelationships = map[string]bool{"sibling": true, "household": true}
+)
+
+// Relative struct to hold a student linked to another as a sibling or household member
+type Relative struct {
+	StudentID    int    `json:"student_id"`
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+}
+
+// Relatives struct to hold the response of GET /students/{id}/relatives; Contacts are the
+// relatives' contacts, offered for reuse as the student's own
+type Relatives struct {
+	StudentID int        `json:"student_id"`
+	Relatives []Relative `json:"relatives"`
+	Contacts  []Contact  `json:"contacts"`
+}
+
+// linkRelatives records a symmetric link between two students; the caller must hold relativesMu
+func linkRelatives(a, b int, relationship string) {
+	for _, pair := range [][2]int{{a, b}, {b, a}} {
+		if relatives[pair[0]] == nil {
+			relatives[pair[0]] = make(map[int]string)
+		}
+		relatives[pair[0]][pair[1]] = relationship
+	}
+}
+
+// siblingCount returns how many students are linked to a student as siblings
+func siblingCount(id int) int {
+	relativesMu.Lock()
+	defer relativesMu.Unlock()
+	count := 0
+	for _, relationship := range relatives[id] {
+		if relationship == "sibling" {
+			count++
+		}
+	}
+	return count
+}
+
+// createRelative handles POST /students/{id}/relatives to link another student as a sibling or
+// household member; the link is recorded on both students
+func (s *Server) createRelative(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var req struct {
+		StudentID    int    `json:"student_id"`
+		Relationship string `json:"relationship"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StudentID <= 0 {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Relationship = strings.ToLower(strings.TrimSpace(req.Relationship))
+	if req.Relationship == "" {
+		req.Relationship = "sibling"
+	}
+	if !relativeRelationships[req.Relationship] {
+		http.Error(w, "relationship must be sibling or household", http.StatusBadRequest)
+		return
+	}
+	if req.StudentID == id {
+		http.Error(w, "A student cannot be linked to themselves", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if _, exists := s.store.Get(id); !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+	relative, exists := s.store.Get(req.StudentID)
+	if !exists {
+		http.Error(w, "Related student not found", http.StatusNotFound)
+		return
+	}
+
+	relativesMu.Lock()
+	linkRelatives(id, req.StudentID, req.Relationship)
+	relativesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Relative{StudentID: relative.ID, Name: relative.Name, Relationship: req.Relationship})
+}
+
+// getRelatives handles GET /students/{id}/relatives to list a student's siblings and household
+// members together with their contacts
+func (s *Server) getRelatives(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	if _, exists := s.store.Get(id); !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	response := Relatives{StudentID: id, Relatives: []Relative{}, Contacts: []Contact{}}
+	relativesMu.Lock()
+	for relativeID, relationship := range relatives[id] {
+		relative, _ := s.store.Get(relativeID)
+		response.Relatives = append(response.Relatives, Relative{StudentID: relativeID, Name: relative.Name, Relationship: relationship})
+	}
+	relativesMu.Unlock()
+	sort.Slice(response.Relatives, func(i, j int) bool { return response.Relatives[i].StudentID < response.Relatives[j].StudentID })
+
+	contactsMu.Lock()
+	for _, relative := range response.Relatives {
+		response.Contacts = append(response.Contacts, contacts[relative.StudentID]...)
+	}
+	contactsMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// deleteRelative handles DELETE /students/{id}/relatives/{relative} to remove a link from both students
+func deleteRelative(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	relativeID, err := strconv.Atoi(mux.Vars(r)["relative"])
+	if err != nil {
+		http.Error(w, "Invalid relative ID", http.StatusBadRequest)
+		return
+	}
+
+	relativesMu.Lock()
+	defer relativesMu.Unlock()
+
+	if _, linked := relatives[id][relativeID]; !linked {
+		http.Error(w, "Relative not found", http.StatusNotFound)
+		return
+	}
+	delete(relatives[id], relativeID)
+	delete(relatives[relativeID], id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteRelatives removes a deleted student's links from every relative
+func deleteRelatives(id int) {
+	relativesMu.Lock()
+	defer relativesMu.Unlock()
+	for relativeID := range relatives[id] {
+		delete(relatives[relativeID], id)
+	}
+	delete(relatives, id)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Targets of an event replay
+const (
+	ReplayToBroker  = "broker"
+	ReplayToWebhook = "webhook"
+)
+
+// ReplayRequest struct to hold the body of POST /admin/replay. Since and Until bound the change
+// sequence numbers replayed, since exclusive and until inclusive with 0 meaning the newest; Types
+// and StudentIDs narrow the slice further. DryRun only counts the matching changes
+type ReplayRequest struct {
+	Target     string   `json:"target"`
+	WebhookID  int      `json:"webhook_id,omitempty"`
+	Since      int64    `json:"since"`
+	Until      int64    `json:"until,omitempty"`
+	Types      []string `json:"types,omitempty"`
+	StudentIDs []int    `json:"student_ids,omitempty"`
+	DryRun     bool     `json:"dry_run,omitempty"`
+}
+
+// ReplayResult struct to hold the outcome of a replay; FirstID and LastID are the sequence
+// numbers of the first and last change replayed
+type ReplayResult struct {
+	Target    string `json:"target"`
+	WebhookID int    `json:"webhook_id,omitempty"`
+	Events    int    `json:"events"`
+	FirstID   int64  `json:"first_id,omitempty"`
+	LastID    int64  `json:"last_id,omitempty"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+}
+
+// validateReplayRequest checks a replay request submitted by an admin
+func validateReplayRequest(req ReplayRequest) error {
+	switch req.Target {
+	case ReplayToBroker:
+		if req.WebhookID != 0 {
+			return fmt.Errorf("webhook_id only applies to the %s target", ReplayToWebhook)
+		}
+	case ReplayToWebhook:
+		if req.WebhookID == 0 {
+			return fmt.Errorf("webhook_id is required for the %s target", ReplayToWebhook)
+		}
+	default:
+		return fmt.Errorf("target must be %s or %s", ReplayToBroker, ReplayToWebhook)
+	}
+	if req.Since < 0 || req.Until < 0 || (req.Until != 0 && req.Until <= req.Since) {
+		return errors.New("since and until must be sequence numbers with since below until")
+	}
+	for _, eventType := range req.Types {
+		switch eventType {
+		case EventStudentCreated, EventStudentUpdated, EventStudentDeleted:
+		default:
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// matchesReplay reports whether a change falls in the slice of the change log a replay covers
+func (req ReplayRequest) matchesReplay(event StudentEvent) bool {
+	if event.ID <= req.Since || (req.Until != 0 && event.ID > req.Until) {
+		return false
+	}
+	if len(req.Types) > 0 && !containsString(req.Types, event.Type) {
+		return false
+	}
+	if len(req.StudentIDs) == 0 {
+		return true
+	}
+	for _, id := range req.StudentIDs {
+		if id == event.StudentID {
+			return true
+		}
+	}
+	return false
+}
+
+// replayEvents handles POST /admin/replay to send a slice of the change log again, oldest first,
+// so downstream read models can be rebuilt after an outage. The broker target queues the changes
+// on the event outbox, behind any not yet relayed; the webhook target queues a delivery of each
+// change the webhook subscribes to. Replayed changes keep their sequence numbers, so consumers
+// that track them can tell replays apart, and in-process subscribers such as notifications do
+// not see them again. A since older than the retained log returns 410 Gone
+func (s *Server) replayEvents(w http.ResponseWriter, r *http.Request) {
+	var req ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateReplayRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var hook Webhook
+	if req.Target == ReplayToWebhook {
+		webhooksMu.Lock()
+		var exists bool
+		hook, exists = webhooks[req.WebhookID]
+		webhooksMu.Unlock()
+		if !exists {
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	eventSubscribersMu.Lock()
+	if req.Target == ReplayToBroker && !eventOutboxEnabled {
+		eventSubscribersMu.Unlock()
+		http.Error(w, "No event broker is configured", http.StatusConflict)
+		return
+	}
+	oldest := nextEventID + 1
+	if len(changeLog) > 0 {
+		oldest = changeLog[0].ID
+	}
+	if req.Since < oldest-1 {
+		eventSubscribersMu.Unlock()
+		http.Error(w, fmt.Sprintf("Changes before sequence %d are no longer retained", oldest), http.StatusGone)
+		return
+	}
+	var events []StudentEvent
+	for _, event := range changeLog {
+		if req.matchesReplay(event) && (req.Target == ReplayToBroker || hook.wantsEvent(event.Type)) {
+			events = append(events, event)
+		}
+	}
+	if req.Target == ReplayToBroker && !req.DryRun {
+		for _, event := range events {
+			addToOutbox(event)
+		}
+	}
+	eventSubscribersMu.Unlock()
+
+	if req.Target == ReplayToWebhook && !req.DryRun {
+		for _, event := range events {
+			if _, err := s.queueWebhookDelivery(hook.ID, event); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	result := ReplayResult{Target: req.Target, WebhookID: req.WebhookID, Events: len(events), DryRun: req.DryRun}
+	if len(events) > 0 {
+		result.FirstID, result.LastID = events[0].ID, events[len(events)-1].ID
+	}
+	if !req.DryRun {
+		target := req.Target
+		if req.Target == ReplayToWebhook {
+			target = fmt.Sprintf("webhook %d", hook.ID)
+		}
+		log.Printf("Replaying %d changes (%d to %d) to %s", result.Events, result.FirstID, result.LastID, target)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !req.DryRun {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportEmailSince is when the last roster report was sent, or the server start before the first
+var reportEmailSince = time.Now()
+
+// startReportEmails schedules the roster report email to REPORT_EMAIL_TO when
+// REPORT_EMAIL_SCHEDULE is set
+func (s *Server) startReportEmails() error {
+	if s.config.ReportEmailSchedule == "" {
+		return nil
+	}
+	if len(s.config.ReportEmailTo) == 0 {
+		return errors.New("REPORT_EMAIL_SCHEDULE requires REPORT_EMAIL_TO")
+	}
+	schedule, err := ParseCron(s.config.ReportEmailSchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("report-email", schedule, s.sendReportEmail)
+	return nil
+}
+
+// rosterReport renders the plain text roster report: head counts by status and grade level and
+// the status changes made since since
+func (s *Server) rosterReport(since, now time.Time) string {
+	byStatus := make(map[string]int)
+	byGrade := make(map[int]int)
+	var changes []string
+
+	s.store.Lock()
+	statusHistoryMu.Lock()
+	for _, student := range s.store.List() {
+		byStatus[student.Status]++
+		if student.GradeLevel > 0 {
+			byGrade[student.GradeLevel]++
+		}
+		for _, transition := range statusHistory[student.ID] {
+			if transition.ChangedAt.After(since) {
+				changes = append(changes, fmt.Sprintf("%s  %s (ID %d): %s -> %s", transition.ChangedAt.Format("2006-01-02 15:04"),
+					student.Name, student.ID, transition.From, transition.To))
+			}
+		}
+	}
+	total := s.store.Len()
+	statusHistoryMu.Unlock()
+	s.store.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Roster report for %s\n\nStudents: %d\n", now.Format("January 2, 2006"), total)
+	b.WriteString("\nBy status\n")
+	for _, status := range []string{StatusApplied, StatusEnrolled, StatusOnLeave, StatusGraduated, StatusWithdrawn} {
+		fmt.Fprintf(&b, "  %-10s %d\n", status, byStatus[status])
+	}
+	if len(byGrade) > 0 {
+		b.WriteString("\nBy grade level\n")
+		grades := make([]int, 0, len(byGrade))
+		for grade := range byGrade {
+			grades = append(grades, grade)
+		}
+		sort.Ints(grades)
+		for _, grade := range grades {
+			fmt.Fprintf(&b, "  Grade %-4d %d\n", grade, byGrade[grade])
+		}
+	}
+
+	sort.Strings(changes)
+	fmt.Fprintf(&b, "\nStatus changes since %s: %d\n", since.Format("2006-01-02 15:04"), len(changes))
+	for _, change := range changes {
+		b.WriteString("  " + change + "\n")
+	}
+	return b.String()
+}
+
+// sendReportEmail mails the roster report to every REPORT_EMAIL_TO address; the next report
+// covers the changes since this one only when every address received it
+func (s *Server) sendReportEmail() error {
+	now := time.Now()
+	body := s.rosterReport(reportEmailSince, now)
+	subject := "Roster report for " + now.Format("January 2, 2006")
+
+	var failed []string
+	for _, to := range s.config.ReportEmailTo {
+		if err := s.mailer.Send(context.Background(), to, subject, body); err != nil {
+			failed = append(failed, to+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.New("report email failed for " + strings.Join(failed, "; "))
+	}
+	reportEmailSince = now
+	return nil
+}
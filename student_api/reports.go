@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CohortStats struct to hold aggregate statistics for a set of students
+type CohortStats struct {
+	Count           int            `json:"count"`
+	MinAge          int            `json:"min_age"`
+	MaxAge          int            `json:"max_age"`
+	MeanAge         float64        `json:"mean_age"`
+	AgeDistribution map[string]int `json:"age_distribution"`
+	EmailDomains    map[string]int `json:"email_domains"`
+}
+
+// CohortReport struct to hold the response of GET /reports/cohort
+type CohortReport struct {
+	Filter StudentFilter `json:"filter"`
+	Stats  CohortStats   `json:"stats"`
+	Report string        `json:"report"`
+	Model  string        `json:"model"`
+}
+
+// getCohortReport handles GET /reports/cohort to produce an LLM narrative report on a filtered cohort
+func (s *Server) getCohortReport(w http.ResponseWriter, r *http.Request) {
+	filter, err := studentFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	cohort := s.filterStudents(filter)
+	s.store.Unlock()
+
+	if len(cohort) == 0 {
+		http.Error(w, "No students match the filter", http.StatusNotFound)
+		return
+	}
+
+	stats := cohortStats(cohort)
+	statsJSON, _ := json.MarshalIndent(stats, "", "  ")
+	prompt := fmt.Sprintf("Write a narrative report on the following cohort of %d students. "+
+		"Describe the age distribution and any notable patterns in the data. Do not invent individual details.\n\nStatistics:\n%s",
+		stats.Count, statsJSON)
+
+	resp, err := s.llm.Generate(r.Context(), LLMRequest{Model: opts.Model, Prompt: prompt})
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	report := CohortReport{Filter: filter, Stats: stats, Report: resp.Text, Model: opts.Model}
+
+	if r.URL.Query().Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		fmt.Fprint(w, renderCohortMarkdown(report))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// cohortStats computes aggregate statistics for a non-empty set of students
+func cohortStats(cohort []Student) CohortStats {
+	stats := CohortStats{
+		Count:           len(cohort),
+		MinAge:          cohort[0].Age(),
+		MaxAge:          cohort[0].Age(),
+		AgeDistribution: make(map[string]int),
+		EmailDomains:    make(map[string]int),
+	}
+
+	total := 0
+	for _, student := range cohort {
+		total += student.Age()
+		if student.Age() < stats.MinAge {
+			stats.MinAge = student.Age()
+		}
+		if student.Age() > stats.MaxAge {
+			stats.MaxAge = student.Age()
+		}
+		stats.AgeDistribution[ageBucket(student.Age())]++
+		if at := strings.LastIndex(student.Email, "@"); at >= 0 {
+			stats.EmailDomains[strings.ToLower(student.Email[at+1:])]++
+		}
+	}
+	stats.MeanAge = float64(total) / float64(len(cohort))
+
+	return stats
+}
+
+// ageBucket returns the distribution bucket an age falls into
+func ageBucket(age int) string {
+	switch {
+	case age < 18:
+		return "under 18"
+	case age <= 21:
+		return "18-21"
+	case age <= 25:
+		return "22-25"
+	default:
+		return "26+"
+	}
+}
+
+// renderCohortMarkdown renders a cohort report as a Markdown document
+func renderCohortMarkdown(report CohortReport) string {
+	var b strings.Builder
+	b.WriteString("# Cohort report\n\n")
+	fmt.Fprintf(&b, "- Students: %d\n", report.Stats.Count)
+	fmt.Fprintf(&b, "- Age range: %d-%d (mean %.1f)\n\n", report.Stats.MinAge, report.Stats.MaxAge, report.Stats.MeanAge)
+
+	b.WriteString("## Age distribution\n\n| Age | Students |\n|---|---|\n")
+	buckets := make([]string, 0, len(report.Stats.AgeDistribution))
+	for bucket := range report.Stats.AgeDistribution {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	for _, bucket := range buckets {
+		fmt.Fprintf(&b, "| %s | %d |\n", bucket, report.Stats.AgeDistribution[bucket])
+	}
+
+	b.WriteString("\n## Narrative\n\n")
+	b.WriteString(report.Report)
+	b.WriteString("\n")
+	return b.String()
+}
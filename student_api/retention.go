@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// startRetention schedules the retention policies on RETENTION_SCHEDULE
+func (s *Server) startRetention() error {
+	if s.config.RetentionSchedule == "" {
+		return nil
+	}
+	schedule, err := ParseCron(s.config.RetentionSchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("retention", schedule, s.enforceRetention)
+	return nil
+}
+
+// enforceRetention forgets finished jobs after JOB_RETENTION, deletes students withdrawn for
+// longer than WITHDRAWN_RETENTION and drops LLM audit entries older than LLM_AUDIT_RETENTION and
+// changes older than CHANGE_LOG_RETENTION; a zero retention keeps the data forever
+func (s *Server) enforceRetention() error {
+	jobsPruned := s.pruneJobs()
+	purged, err := s.purgeWithdrawnStudents()
+	audits := s.pruneLLMAudit()
+	changes, changesErr := s.pruneChangeLog()
+	if err == nil {
+		err = changesErr
+	}
+
+	log.Printf("Retention finished: %d jobs, %d withdrawn students, %d LLM audit entries and %d changes removed", jobsPruned, purged, audits, changes)
+	return err
+}
+
+// purgeWithdrawnStudents deletes the students who were withdrawn more than WITHDRAWN_RETENTION
+// ago, as DELETE /students/{id} would
+func (s *Server) purgeWithdrawnStudents() (int, error) {
+	if s.config.WithdrawnRetention <= 0 {
+		return 0, nil
+	}
+
+	var expired []int
+	cutoff := time.Now().Add(-s.config.WithdrawnRetention)
+	s.store.Lock()
+	statusHistoryMu.Lock()
+	for _, student := range s.store.List() {
+		if student.Status != StatusWithdrawn || student.Alumni {
+			continue
+		}
+		history := statusHistory[student.ID]
+		if len(history) > 0 && history[len(history)-1].ChangedAt.Before(cutoff) {
+			expired = append(expired, student.ID)
+		}
+	}
+	statusHistoryMu.Unlock()
+	s.store.Unlock()
+
+	purged, failed := 0, 0
+	for _, id := range expired {
+		if err := s.students.Delete(id, false); err != nil {
+			log.Printf("Error deleting withdrawn student %d: %v", id, err)
+			failed++
+			continue
+		}
+		purged++
+	}
+	if failed > 0 {
+		return purged, fmt.Errorf("%d of %d withdrawn students could not be deleted", failed, len(expired))
+	}
+	return purged, nil
+}
+
+// pruneLLMAudit drops the in-memory LLM audit entries older than LLM_AUDIT_RETENTION; entries
+// already appended to LLM_AUDIT_FILE are left to log rotation
+func (s *Server) pruneLLMAudit() int {
+	if s.config.LLMAuditRetention <= 0 {
+		return 0
+	}
+
+	cutoff := time.Now().Add(-s.config.LLMAuditRetention)
+	llmAuditMu.Lock()
+	defer llmAuditMu.Unlock()
+	dropped := 0
+	for dropped < len(llmAuditLog) && llmAuditLog[dropped].Time.Before(cutoff) {
+		dropped++
+	}
+	llmAuditLog = append([]LLMAuditEntry{}, llmAuditLog[dropped:]...)
+	return dropped
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of outbound destination a retry policy applies to; RETRY_POLICIES keys are a kind or a
+// kind, a colon and a destination name: the webhook host, the chat event or the LLM provider
+var retryPolicyKinds = []string{"webhook", "email", "sms", "chat", "llm"}
+
+// RetryPolicy struct to hold how calls to an outbound destination are retried: up to MaxAttempts
+// attempts, waiting BackoffMS after the first failure and twice as long after each further one,
+// with each attempt given TimeoutMS. Zero fields keep the value of the broader policy
+type RetryPolicy struct {
+	MaxAttempts int   `json:"max_attempts,omitempty"`
+	BackoffMS   int64 `json:"backoff_ms,omitempty"`
+	TimeoutMS   int64 `json:"timeout_ms,omitempty"`
+}
+
+// backoff returns the wait after the first failed attempt
+func (p RetryPolicy) backoff() time.Duration {
+	return time.Duration(p.BackoffMS) * time.Millisecond
+}
+
+// timeout returns the time each attempt is given, 0 for no limit
+func (p RetryPolicy) timeout() time.Duration {
+	return time.Duration(p.TimeoutMS) * time.Millisecond
+}
+
+// jobOptions returns the job queue options that retry a job with the policy
+func (p RetryPolicy) jobOptions() JobOptions {
+	return JobOptions{MaxAttempts: p.MaxAttempts, Backoff: p.backoff()}
+}
+
+// validate checks a policy submitted by a client
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts < 0 || p.BackoffMS < 0 || p.TimeoutMS < 0 {
+		return errors.New("retry policy values cannot be negative")
+	}
+	return nil
+}
+
+// override returns the policy with the non-zero fields of other applied over it
+func (p RetryPolicy) override(other RetryPolicy) RetryPolicy {
+	if other.MaxAttempts != 0 {
+		p.MaxAttempts = other.MaxAttempts
+	}
+	if other.BackoffMS != 0 {
+		p.BackoffMS = other.BackoffMS
+	}
+	if other.TimeoutMS != 0 {
+		p.TimeoutMS = other.TimeoutMS
+	}
+	return p
+}
+
+// parseRetryPolicies parses RETRY_POLICIES, a comma-separated list of key=attempts/backoff/timeout
+// entries such as "webhook:hooks.example.com=10/30s/5s,llm:openai=4" where empty or missing parts
+// are inherited; malformed entries are logged and dropped
+func parseRetryPolicies(value string) map[string]RetryPolicy {
+	policies := make(map[string]RetryPolicy)
+	for key, spec := range splitPairs(value) {
+		kind, _, _ := strings.Cut(key, ":")
+		if !containsString(retryPolicyKinds, kind) {
+			log.Printf("Ignoring RETRY_POLICIES entry %q: unknown kind %q", key, kind)
+			continue
+		}
+		policy, err := parseRetryPolicy(spec)
+		if err != nil {
+			log.Printf("Ignoring RETRY_POLICIES entry %q: %v", key, err)
+			continue
+		}
+		policies[key] = policy
+	}
+	return policies
+}
+
+// parseRetryPolicy parses one attempts/backoff/timeout specification
+func parseRetryPolicy(spec string) (RetryPolicy, error) {
+	parts := strings.Split(spec, "/")
+	if len(parts) > 3 {
+		return RetryPolicy{}, errors.New("expected attempts/backoff/timeout")
+	}
+	var policy RetryPolicy
+	if text := strings.TrimSpace(parts[0]); text != "" {
+		attempts, err := strconv.Atoi(text)
+		if err != nil || attempts < 1 {
+			return RetryPolicy{}, errors.New("attempts must be a positive number")
+		}
+		policy.MaxAttempts = attempts
+	}
+	for i, field := range []*int64{&policy.BackoffMS, &policy.TimeoutMS} {
+		if i+1 >= len(parts) || strings.TrimSpace(parts[i+1]) == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(parts[i+1]))
+		if err != nil || duration < 0 {
+			return RetryPolicy{}, errors.New("backoff and timeout must be durations such as 30s")
+		}
+		*field = duration.Milliseconds()
+	}
+	return policy, nil
+}
+
+// defaultRetryPolicy returns the policy of a kind of destination from the older settings, so
+// deployments without RETRY_POLICIES behave as before
+func (cfg Config) defaultRetryPolicy(kind string) RetryPolicy {
+	policy := func(attempts int, backoff, timeout time.Duration) RetryPolicy {
+		return RetryPolicy{MaxAttempts: attempts, BackoffMS: backoff.Milliseconds(), TimeoutMS: timeout.Milliseconds()}
+	}
+	switch kind {
+	case "webhook":
+		return policy(cfg.WebhookMaxAttempts, cfg.WebhookRetryBackoff, cfg.WebhookTimeout)
+	case "chat":
+		return policy(cfg.NotificationMaxAttempts, time.Minute, cfg.WebhookTimeout)
+	case "sms":
+		return policy(cfg.NotificationMaxAttempts, time.Minute, cfg.LLMTimeout)
+	case "llm":
+		return policy(cfg.LLMRetries+1, cfg.LLMBackoff, cfg.LLMTimeout)
+	default:
+		return policy(cfg.NotificationMaxAttempts, time.Minute, 0)
+	}
+}
+
+// retryPolicy returns the policy for a destination: the kind's default, then the RETRY_POLICIES
+// entry for the kind, then the entry for the named destination
+func (cfg Config) retryPolicy(kind, name string) RetryPolicy {
+	policy := cfg.defaultRetryPolicy(kind).override(cfg.RetryPolicies[kind])
+	if name != "" {
+		policy = policy.override(cfg.RetryPolicies[kind+":"+name])
+	}
+	return policy
+}
+
+// webhookRetryPolicy returns the policy for a webhook: the policy of its host with the webhook's
+// own retry_policy applied over it
+func (s *Server) webhookRetryPolicy(hook Webhook) RetryPolicy {
+	host := ""
+	if target, err := url.Parse(hook.URL); err == nil {
+		host = target.Hostname()
+	}
+	policy := s.config.retryPolicy("webhook", host)
+	if hook.RetryPolicy != nil {
+		policy = policy.override(*hook.RetryPolicy)
+	}
+	return policy
+}
+
+// getRetryPolicies handles GET /admin/retry-policies to list the effective policy of every kind
+// of destination and of every destination named in RETRY_POLICIES
+func (s *Server) getRetryPolicies(w http.ResponseWriter, r *http.Request) {
+	keys := append([]string{}, retryPolicyKinds...)
+	for key := range s.config.RetryPolicies {
+		if !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	policies := make(map[string]RetryPolicy, len(keys))
+	for _, key := range keys {
+		kind, name, _ := strings.Cut(key, ":")
+		policies[key] = s.config.retryPolicy(kind, name)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Weekday abbreviations accepted in Meeting.Day, in schedule order
+var meetingDays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// Meeting struct to hold a weekly class time of a course; Start and End are "HH:MM" in 24-hour time
+type Meeting struct {
+	Day   string `json:"day"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Room  string `json:"room,omitempty"`
+}
+
+// ScheduleEntry struct to hold one meeting in a student's weekly timetable
+type ScheduleEntry struct {
+	CourseID int    `json:"course_id"`
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Meeting
+}
+
+// normalizeMeetings lower-cases and trims the days and times of a course's meetings
+func normalizeMeetings(meetings []Meeting) {
+	for i := range meetings {
+		meetings[i].Day = strings.ToLower(strings.TrimSpace(meetings[i].Day))
+		if len(meetings[i].Day) > 3 {
+			meetings[i].Day = meetings[i].Day[:3]
+		}
+		meetings[i].Start = strings.TrimSpace(meetings[i].Start)
+		meetings[i].End = strings.TrimSpace(meetings[i].End)
+	}
+}
+
+// validateMeetings checks the days and times of a course's meetings
+func validateMeetings(meetings []Meeting) error {
+	for _, meeting := range meetings {
+		if !containsString(meetingDays, meeting.Day) {
+			return errors.New("meeting day must be one of mon, tue, wed, thu, fri, sat or sun")
+		}
+		start, err := time.Parse("15:04", meeting.Start)
+		if err != nil {
+			return errors.New("meeting start must be in HH:MM format")
+		}
+		end, err := time.Parse("15:04", meeting.End)
+		if err != nil {
+			return errors.New("meeting end must be in HH:MM format")
+		}
+		if !end.After(start) {
+			return errors.New("meeting end must be after its start")
+		}
+	}
+	return nil
+}
+
+// meetingsOverlap reports whether two meetings are on the same day with intersecting times;
+// a class ending exactly when another starts does not clash
+func meetingsOverlap(a, b Meeting) bool {
+	return a.Day == b.Day && a.Start < b.End && b.Start < a.End
+}
+
+// scheduleClash returns a course the student is enrolled in whose meetings overlap with the given
+// course; the caller must hold coursesMu
+func scheduleClash(studentID int, course Course) (Course, bool) {
+	for _, enrollment := range studentEnrollments(studentID) {
+		other := courses[enrollment.CourseID]
+		if other.ID == course.ID {
+			continue
+		}
+		for _, a := range course.Meetings {
+			for _, b := range other.Meetings {
+				if meetingsOverlap(a, b) {
+					return other, true
+				}
+			}
+		}
+	}
+	return Course{}, false
+}
+
+// getStudentSchedule handles GET /students/{id}/schedule to return the weekly timetable of the
+// courses a student is enrolled in, ordered by day and start time
+func (s *Server) getStudentSchedule(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	coursesMu.Lock()
+	schedule := []ScheduleEntry{}
+	for _, enrollment := range studentEnrollments(id) {
+		course := courses[enrollment.CourseID]
+		for _, meeting := range course.Meetings {
+			schedule = append(schedule, ScheduleEntry{CourseID: course.ID, Code: course.Code, Title: course.Title, Meeting: meeting})
+		}
+	}
+	coursesMu.Unlock()
+
+	dayIndex := make(map[string]int)
+	for i, day := range meetingDays {
+		dayIndex[day] = i
+	}
+	sort.SliceStable(schedule, func(i, j int) bool {
+		a, b := schedule[i], schedule[j]
+		if a.Day != b.Day {
+			return dayIndex[a.Day] < dayIndex[b.Day]
+		}
+		return a.Start < b.Start
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrNoSecretKey is returned when a credential must be stored but SECRET_KEY is not configured
+var ErrNoSecretKey = errors.New("SECRET_KEY must be set to store credentials")
+
+// secretCipher returns an AES-GCM cipher keyed from SECRET_KEY
+func (s *Server) secretCipher() (cipher.AEAD, error) {
+	if s.config.SecretKey == "" {
+		return nil, ErrNoSecretKey
+	}
+	key := sha256.Sum256([]byte(s.config.SecretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptSecret encrypts a credential for storage
+func (s *Server) encryptSecret(plain string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plain), nil)), nil
+}
+
+// decryptSecret decrypts a credential produced by encryptSecret
+func (s *Server) decryptSecret(sealed string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted secret")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+
+	"student_api/student_api/internal/handlers"
+	"student_api/student_api/internal/service"
+	"student_api/student_api/internal/store"
+)
+
+// Server struct to hold the dependencies of the REST API and the background features: the
+// configuration, the student store and service, the LLM provider chain and the backends built
+// from the configuration. The handlers are methods on the server; the records of the other
+// features (courses, summaries, attachments and so on) are still package state behind their own
+// locks
+type Server struct {
+	config Config
+	// store keeps the student records; take its lock before any other lock
+	store store.Store
+	// students runs the student writes of every front end: REST, gRPC, GraphQL and the imports
+	students      *service.Students
+	llm           *llmChain
+	systemPrompts *SystemPrompts
+	blobs         BlobStore
+	geocoder      Geocoder
+	mailer        Mailer
+	sms           SMSNotifier
+	tts           TTSProvider
+	// embeddings holds the students waiting for the background indexer
+	embeddings    *embeddingQueue
+	wsUpgrader    websocket.Upgrader
+	graphqlSchema graphql.Schema
+	router        *mux.Router
+}
+
+// NewServer returns the server of the students of st, with provider answering the LLM features
+// behind the limiter, breaker, usage tracking, audit log and system prompt of cfg
+func NewServer(cfg Config, st store.Store, provider LLMProvider) (*Server, error) {
+	s := &Server{
+		config:        cfg,
+		store:         st,
+		systemPrompts: newSystemPrompts(cfg.SystemPrompt),
+		blobs:         newBlobStore(cfg),
+		geocoder:      newGeocoder(cfg),
+		mailer:        newMailer(cfg),
+		sms:           newDefaultSMSNotifier(cfg),
+		tts:           newTTSProvider(cfg),
+		embeddings:    newEmbeddingQueue(),
+	}
+	s.students = s.newStudentService(st)
+	s.llm = s.newLLMChain(provider)
+	s.wsUpgrader = websocket.Upgrader{CheckOrigin: s.checkWSOrigin}
+	s.graphqlSchema = s.mustBuildGraphQLSchema()
+
+	students := handlers.NewStudents(s.students, studentCodec{}, studentFilter)
+
+	router := mux.NewRouter()
+	router.Use(withAPIKey)
+	router.Use(s.withTenant)
+
+	// Register routes; the fixed /students/... paths go before /students/{id}, which would match them
+	router.HandleFunc("/students/export", s.exportStudents).Methods("GET")
+	router.HandleFunc("/students/export.vcf", s.exportVCards).Methods("GET")
+	router.HandleFunc("/students/sync", s.syncStudents).Methods("PUT")
+	router.HandleFunc("/students/import", s.importStudents).Methods("POST")
+	router.HandleFunc("/students/verify-email", s.verifyEmail).Methods("GET")
+	router.HandleFunc("/students/birthdays", s.getBirthdays).Methods("GET")
+	router.HandleFunc("/students/birthdays.ics", s.getBirthdaysICS).Methods("GET")
+	router.HandleFunc("/students/events", s.streamStudentEvents).Methods("GET")
+	router.HandleFunc("/students/events/schema.avsc", getEventSchema).Methods("GET")
+	router.HandleFunc("/students/by-external/{system}/{id}", s.getStudentByExternalID).Methods("GET")
+	router.HandleFunc("/students/summaries", s.createBatchSummaryJob).Methods("POST")
+	router.HandleFunc("/students/compare", s.compareStudents).Methods("GET")
+	router.HandleFunc("/students/search/semantic", s.semanticSearch).Methods("GET")
+	students.Register(router)
+	router.HandleFunc("/students/{id}/email/verification", s.resendEmailVerification).Methods("POST")
+	router.HandleFunc("/students/{id}/summary", s.generateStudentSummary).Methods("GET")
+	router.HandleFunc("/students/{id}/summary", s.createSummaryJob).Methods("POST")
+	router.HandleFunc("/students/{id}/summary/stream", s.streamStudentSummary).Methods("GET")
+	router.HandleFunc("/students/{id}/summary/audio", s.getSummaryAudio).Methods("GET")
+	router.HandleFunc("/students/{id}/summary/feedback", createSummaryFeedback).Methods("POST")
+	router.HandleFunc("/students/{id}/summaries", s.getSummaryHistory).Methods("GET")
+	router.HandleFunc("/students/{id}/enrich", s.enrichStudent).Methods("POST")
+	router.HandleFunc("/students/{id}/enrollments", s.createEnrollment).Methods("POST")
+	router.HandleFunc("/students/{id}/enrollments", s.getStudentEnrollments).Methods("GET")
+	router.HandleFunc("/students/{id}/enrollments/{course}", s.deleteEnrollment).Methods("DELETE")
+	router.HandleFunc("/students/{id}/waitlist/{course}", deleteWaitlistEntry).Methods("DELETE")
+	router.HandleFunc("/students/{id}/schedule", s.getStudentSchedule).Methods("GET")
+	router.HandleFunc("/students/{id}/schedule.ics", s.getStudentScheduleICS).Methods("GET")
+	router.HandleFunc("/students/{id}/vcard", s.getStudentVCard).Methods("GET")
+	router.HandleFunc("/students/{id}/report.pdf", s.getProfileReport).Methods("GET")
+	router.HandleFunc("/students/{id}/grades", s.createGrade).Methods("POST")
+	router.HandleFunc("/students/{id}/grades", s.getStudentGrades).Methods("GET")
+	router.HandleFunc("/students/{id}/grades/{grade}", amendGrade).Methods("PUT")
+	router.HandleFunc("/students/{id}/attendance", s.recordAttendance).Methods("POST")
+	router.HandleFunc("/students/{id}/attendance", s.getStudentAttendance).Methods("GET")
+	router.HandleFunc("/students/{id}/gpa", s.getStudentGPA).Methods("GET")
+	router.HandleFunc("/students/{id}/transcript", s.getTranscript).Methods("GET")
+	router.HandleFunc("/students/{id}/status", s.changeStudentStatus).Methods("POST")
+	router.HandleFunc("/students/{id}/status/history", s.getStatusHistory).Methods("GET")
+	router.HandleFunc("/students/{id}/graduate", s.graduateStudent).Methods("POST")
+	router.HandleFunc("/students/{id}/certificate", getCertificate).Methods("GET")
+	router.HandleFunc("/students/{id}/contacts", s.createContact).Methods("POST")
+	router.HandleFunc("/students/{id}/contacts", s.getContacts).Methods("GET")
+	router.HandleFunc("/students/{id}/contacts/message", s.messageContacts).Methods("POST")
+	router.HandleFunc("/students/{id}/contacts/{contact}", deleteContact).Methods("DELETE")
+	router.HandleFunc("/students/{id}/contacts/{contact}/notification-preferences", s.getNotifyPrefs).Methods("GET")
+	router.HandleFunc("/students/{id}/contacts/{contact}/notification-preferences", s.putNotifyPrefs).Methods("PUT")
+	router.HandleFunc("/students/{id}/contacts/{contact}/notification-preferences", s.deleteNotifyPrefs).Methods("DELETE")
+	router.HandleFunc("/students/{id}/notification-preferences", s.getNotifyPrefs).Methods("GET")
+	router.HandleFunc("/students/{id}/notification-preferences", s.putNotifyPrefs).Methods("PUT")
+	router.HandleFunc("/students/{id}/notification-preferences", s.deleteNotifyPrefs).Methods("DELETE")
+	router.HandleFunc("/students/{id}/relatives", s.createRelative).Methods("POST")
+	router.HandleFunc("/students/{id}/relatives", s.getRelatives).Methods("GET")
+	router.HandleFunc("/students/{id}/relatives/{relative}", deleteRelative).Methods("DELETE")
+	router.HandleFunc("/students/{id}/consents", s.createConsent).Methods("POST")
+	router.HandleFunc("/students/{id}/consents", s.getConsents).Methods("GET")
+	router.HandleFunc("/students/{id}/health", s.putHealthRecord).Methods("PUT")
+	router.HandleFunc("/students/{id}/health", s.getHealthRecord).Methods("GET")
+	router.HandleFunc("/students/{id}/health", s.deleteHealthRecord).Methods("DELETE")
+	router.HandleFunc("/students/{id}/incidents", s.createIncident).Methods("POST")
+	router.HandleFunc("/students/{id}/incidents", s.getIncidents).Methods("GET")
+	router.HandleFunc("/students/{id}/incidents/{incident}", s.deleteIncident).Methods("DELETE")
+	router.HandleFunc("/students/{id}/attachments", s.uploadAttachment).Methods("POST")
+	router.HandleFunc("/students/{id}/attachments", s.getAttachments).Methods("GET")
+	router.HandleFunc("/students/{id}/attachments/{attachment}/download", s.downloadAttachment).Methods("GET")
+	router.HandleFunc("/students/{id}/attachments/{attachment}", s.deleteAttachment).Methods("DELETE")
+	router.HandleFunc("/students/{id}/photo", s.putStudentPhoto).Methods("PUT")
+	router.HandleFunc("/students/{id}/photo", s.getStudentPhoto).Methods("GET")
+	router.HandleFunc("/students/{id}/photo", s.deleteStudentPhoto).Methods("DELETE")
+	router.HandleFunc("/students/{id}/invoices", s.createInvoice).Methods("POST")
+	router.HandleFunc("/students/{id}/invoices", s.getInvoices).Methods("GET")
+	router.HandleFunc("/students/{id}/payments", s.createPayment).Methods("POST")
+	router.HandleFunc("/students/{id}/payments", s.getPayments).Methods("GET")
+	router.HandleFunc("/students/{id}/balance", s.getBalance).Methods("GET")
+	router.HandleFunc("/students/{id}/awards", s.createAward).Methods("POST")
+	router.HandleFunc("/students/{id}/awards", s.getAwards).Methods("GET")
+	router.HandleFunc("/students/{id}/awards/{award}", deleteAward).Methods("DELETE")
+	router.HandleFunc("/students/{id}/advisor", s.setStudentAdvisor).Methods("PUT")
+	router.HandleFunc("/students/{id}/similar", s.getSimilarStudents).Methods("GET")
+	router.HandleFunc("/students/{id}/chat", s.chatWithStudent).Methods("POST")
+	router.HandleFunc("/students/{id}/chat/{session}", getChatSession).Methods("GET")
+	router.HandleFunc("/courses", createCourse).Methods("POST")
+	router.HandleFunc("/courses", getAllCourses).Methods("GET")
+	router.HandleFunc("/courses/{id}", getCourseByID).Methods("GET")
+	router.HandleFunc("/courses/{id}", s.updateCourse).Methods("PUT")
+	router.HandleFunc("/courses/{id}", deleteCourse).Methods("DELETE")
+	router.HandleFunc("/courses/{id}/students", s.getCourseStudents).Methods("GET")
+	router.HandleFunc("/courses/{id}/waitlist", getCourseWaitlist).Methods("GET")
+	router.HandleFunc("/courses/{id}/attendance", s.markCourseAttendance).Methods("POST")
+	router.HandleFunc("/terms", createTerm).Methods("POST")
+	router.HandleFunc("/terms", getAllTerms).Methods("GET")
+	router.HandleFunc("/terms/current", getCurrentTerm).Methods("GET")
+	router.HandleFunc("/terms/{name}", getTermByName).Methods("GET")
+	router.HandleFunc("/terms/{name}", deleteTerm).Methods("DELETE")
+	router.HandleFunc("/alumni", s.getAlumni).Methods("GET")
+	router.HandleFunc("/alumni/cohorts", s.getAlumniCohorts).Methods("GET")
+	router.HandleFunc("/teachers", createTeacher).Methods("POST")
+	router.HandleFunc("/teachers", getAllTeachers).Methods("GET")
+	router.HandleFunc("/teachers/{id}", getTeacherByID).Methods("GET")
+	router.HandleFunc("/teachers/{id}", s.updateTeacher).Methods("PUT")
+	router.HandleFunc("/teachers/{id}", s.deleteTeacher).Methods("DELETE")
+	router.HandleFunc("/teachers/{id}/students", s.getTeacherStudents).Methods("GET")
+	router.HandleFunc("/groups", createGroup).Methods("POST")
+	router.HandleFunc("/groups", getAllGroups).Methods("GET")
+	router.HandleFunc("/groups/{id}", getGroupByID).Methods("GET")
+	router.HandleFunc("/groups/{id}", updateGroup).Methods("PUT")
+	router.HandleFunc("/groups/{id}", deleteGroup).Methods("DELETE")
+	router.HandleFunc("/groups/{id}/members", s.getGroupMembers).Methods("GET")
+	router.HandleFunc("/groups/{id}/members", s.addGroupMembers).Methods("POST")
+	router.HandleFunc("/groups/{id}/members/{student}", removeGroupMember).Methods("DELETE")
+	router.HandleFunc("/groups/{id}/tags", s.tagGroupMembers).Methods("POST")
+	router.HandleFunc("/groups/{id}/report", s.getGroupReport).Methods("GET")
+	router.HandleFunc("/changes", getChanges).Methods("GET")
+	router.HandleFunc("/triggers/students/new", pollNewStudents).Methods("GET")
+	router.HandleFunc("/triggers/students/updated", pollUpdatedStudents).Methods("GET")
+	router.HandleFunc("/integrations/sis/webhook", s.receiveSISWebhook).Methods("POST")
+	router.HandleFunc("/jobs", getJobs).Methods("GET")
+	router.HandleFunc("/jobs/{id}", getJob).Methods("GET")
+	router.HandleFunc("/jobs/{id}/cancel", s.cancelJob).Methods("POST")
+	router.HandleFunc("/jobs/{id}/retry", s.retryJob).Methods("POST")
+	router.HandleFunc("/webhooks", createWebhook).Methods("POST")
+	router.HandleFunc("/webhooks", getAllWebhooks).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", getWebhookByID).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", deleteWebhook).Methods("DELETE")
+	router.HandleFunc("/webhooks/{id}/deliveries", getWebhookDeliveries).Methods("GET")
+	router.HandleFunc("/webhooks/{id}/dead-letters", getWebhookDeadLetters).Methods("GET")
+	router.HandleFunc("/webhooks/{id}/dead-letters/{letter}/redeliver", s.redeliverDeadLetter).Methods("POST")
+	router.HandleFunc("/webhooks/{id}/dead-letters/{letter}", deleteDeadLetter).Methods("DELETE")
+	router.HandleFunc("/reports/cohort", s.getCohortReport).Methods("GET")
+	router.HandleFunc("/reports/fees/overdue", s.getOverdueFees).Methods("GET")
+	router.HandleFunc("/reports/fees/export", s.exportFees).Methods("GET")
+	router.HandleFunc("/reports/incidents", getIncidentReport).Methods("GET")
+	router.HandleFunc("/reports/attendance/low", s.getLowAttendanceReport).Methods("GET")
+	router.HandleFunc("/query", s.queryStudents).Methods("POST")
+	router.HandleFunc("/graphql", s.serveGraphQL).Methods("GET", "POST")
+	router.HandleFunc("/ws", s.serveWebSocket).Methods("GET")
+	router.HandleFunc("/readyz", s.readyz).Methods("GET")
+	router.HandleFunc("/openapi.json", getOpenAPISpec).Methods("GET")
+	router.HandleFunc("/docs", getDocs).Methods("GET")
+	router.Handle("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+	router.PathPrefix("/ui/").Handler(s.uiHandler())
+
+	// Routes generated from the StudentService protos
+	gateway, err := s.gatewayHandler()
+	if err != nil {
+		return nil, fmt.Errorf("registering gRPC gateway: %w", err)
+	}
+	router.PathPrefix("/v1/").Handler(gateway)
+
+	// Admin routes require the admin token
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(s.requireAdmin)
+	admin.HandleFunc("/prompt/reload", s.reloadPromptTemplate).Methods("POST")
+	admin.HandleFunc("/llm/status", s.getLLMStatus).Methods("GET")
+	admin.HandleFunc("/llm/system-prompt", s.getSystemPrompt).Methods("GET")
+	admin.HandleFunc("/llm/system-prompt", s.createSystemPrompt).Methods("POST")
+	admin.HandleFunc("/llm/system-prompt/{version}/activate", s.activateSystemPrompt).Methods("POST")
+	admin.HandleFunc("/tenants/{tenant}/llm", getTenantLLMConfig).Methods("GET")
+	admin.HandleFunc("/tenants/{tenant}/llm", s.putTenantLLMConfig).Methods("PUT")
+	admin.HandleFunc("/tenants/{tenant}/llm", deleteTenantLLMConfig).Methods("DELETE")
+	admin.HandleFunc("/tenants/{tenant}/sms", getTenantSMSConfig).Methods("GET")
+	admin.HandleFunc("/tenants/{tenant}/sms", s.putTenantSMSConfig).Methods("PUT")
+	admin.HandleFunc("/tenants/{tenant}/sms", deleteTenantSMSConfig).Methods("DELETE")
+	admin.HandleFunc("/roster/promote", s.promoteRoster).Methods("POST")
+	admin.HandleFunc("/consent-texts/{type}", getConsentTexts).Methods("GET")
+	admin.HandleFunc("/consent-texts/{type}", createConsentText).Methods("POST")
+	admin.HandleFunc("/custom-fields", getCustomFields).Methods("GET")
+	admin.HandleFunc("/custom-fields/{name}", putCustomField).Methods("PUT")
+	admin.HandleFunc("/custom-fields/{name}", s.deleteCustomField).Methods("DELETE")
+	admin.HandleFunc("/computed-fields", getComputedFields).Methods("GET")
+	admin.HandleFunc("/computed-fields/{name}", putComputedField).Methods("PUT")
+	admin.HandleFunc("/computed-fields/{name}", deleteComputedField).Methods("DELETE")
+	admin.HandleFunc("/data-quality", getDataQuality).Methods("GET")
+	admin.HandleFunc("/outbox", getOutboxStatus).Methods("GET")
+	admin.HandleFunc("/replay", s.replayEvents).Methods("POST")
+	admin.HandleFunc("/retry-policies", s.getRetryPolicies).Methods("GET")
+	admin.HandleFunc("/integrations/sis/log", getSISLog).Methods("GET")
+	admin.HandleFunc("/schedules", getScheduledTasks).Methods("GET")
+	admin.HandleFunc("/digest", s.getAdminDigest).Methods("GET")
+	admin.HandleFunc("/digest/send", s.sendAdminDigestNow).Methods("POST")
+	admin.HandleFunc("/schedules/{name}/run", runScheduledTaskNow).Methods("POST")
+	admin.HandleFunc("/data-quality/run", s.runDataQuality).Methods("POST")
+	admin.HandleFunc("/ldap/import", getLDAPImport).Methods("GET")
+	admin.HandleFunc("/ldap/import/run", s.runLDAPImportNow).Methods("POST")
+	admin.HandleFunc("/csv-import", getCSVImport).Methods("GET")
+	admin.HandleFunc("/csv-import/run", s.runCSVImportNow).Methods("POST")
+	admin.HandleFunc("/enrichments", listEnrichments).Methods("GET")
+	admin.HandleFunc("/enrichments/{id}/{action}", s.reviewEnrichment).Methods("POST")
+	admin.HandleFunc("/summaries/feedback", getFeedbackReport).Methods("GET")
+	admin.HandleFunc("/moderation/quarantine", listQuarantine).Methods("GET")
+	admin.HandleFunc("/moderation/quarantine/{id}/{action}", reviewQuarantine).Methods("POST")
+	admin.HandleFunc("/llm/audit", getLLMAuditLog).Methods("GET")
+	admin.HandleFunc("/llm/usage", s.getLLMUsage).Methods("GET")
+	admin.HandleFunc("/llm/models", s.listLLMModels).Methods("GET")
+	admin.HandleFunc("/llm/models/pull", s.pullLLMModel).Methods("POST")
+
+	if err := loadOpenAPISpec(router); err != nil {
+		return nil, fmt.Errorf("generating OpenAPI spec: %w", err)
+	}
+	s.router = router
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"student_api/student_api/internal/store"
+)
+
+// recordingProvider answers every request with a fixed summary and keeps the requests it saw
+type recordingProvider struct {
+	mu       sync.Mutex
+	requests []LLMRequest
+}
+
+func (p *recordingProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+	return LLMResponse{Model: req.Model, Text: "Ann is doing well.", PromptTokens: 10, CompletionTokens: 4}, nil
+}
+
+// baseConfig is the configuration loaded from the environment
+var baseConfig = loadConfig()
+
+// newServer returns the API on an in-memory store with provider answering the LLM features and
+// uploads kept in a temporary directory; configure, when given, adjusts the configuration first
+func newServer(t *testing.T, provider LLMProvider, configure ...func(*Config)) *Server {
+	t.Helper()
+	cfg := baseConfig
+	cfg.AdminToken = "test-admin"
+	cfg.LLMConsentRequired = false
+	cfg.BlobStorage = "local"
+	cfg.BlobDir = t.TempDir()
+	for _, f := range configure {
+		f(&cfg)
+	}
+	s, err := NewServer(cfg, store.NewMemory(), provider)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+// newTestServer serves newServer's API over HTTP
+func newTestServer(t *testing.T, provider LLMProvider, configure ...func(*Config)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(newServer(t, provider, configure...))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// request sends a request with the given headers and returns the status and body
+func request(t *testing.T, method, url, body string, header map[string]string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestServerSummaryGoesThroughProviderChain(t *testing.T) {
+	provider := &recordingProvider{}
+	srv := newTestServer(t, provider)
+	admin := map[string]string{"Authorization": "Bearer test-admin"}
+	caller := map[string]string{"X-API-Key": "test-key"}
+
+	if status, body := request(t, "POST", srv.URL+"/admin/llm/system-prompt", `{"text":"Answer in English."}`, admin); status != http.StatusCreated {
+		t.Fatalf("POST /admin/llm/system-prompt: %d %s", status, body)
+	}
+	status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, caller)
+	if status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	var student Student
+	if err := json.Unmarshal([]byte(body), &student); err != nil || student.ID == 0 {
+		t.Fatalf("POST /students returned %s", body)
+	}
+	if status, body := request(t, "GET", srv.URL+"/students", "", nil); status != http.StatusOK || !strings.Contains(body, "Ann Lee") {
+		t.Fatalf("GET /students: %d %s", status, body)
+	}
+
+	status, body = request(t, "GET", srv.URL+"/students/1/summary", "", caller)
+	if status != http.StatusOK || !strings.Contains(body, "Ann is doing well.") {
+		t.Fatalf("GET /students/1/summary: %d %s", status, body)
+	}
+
+	provider.mu.Lock()
+	requests := append([]LLMRequest{}, provider.requests...)
+	provider.mu.Unlock()
+	if len(requests) == 0 {
+		t.Fatal("provider was not called")
+	}
+	for _, req := range requests {
+		if !strings.HasPrefix(req.System, "Answer in English.") {
+			t.Errorf("request system prompt is %q, want the deployment system prompt", req.System)
+		}
+	}
+
+	status, body = request(t, "GET", srv.URL+"/admin/llm/usage?caller="+keyID("test-key"), "", admin)
+	if status != http.StatusOK || !strings.Contains(body, `"calls":1`) {
+		t.Errorf("usage was not recorded for the caller: %d %s", status, body)
+	}
+	status, body = request(t, "GET", srv.URL+"/admin/llm/audit?caller="+keyID("test-key"), "", admin)
+	if status != http.StatusOK || !strings.Contains(body, "Ann is doing well.") {
+		t.Errorf("call was not audited: %d %s", status, body)
+	}
+	if strings.Contains(body, "test-key") {
+		t.Errorf("audit log contains the raw API key: %s", body)
+	}
+}
+
+func TestServerEmbeddingsGoThroughProviderChain(t *testing.T) {
+	srv := newTestServer(t, &embeddingProvider{})
+	admin := map[string]string{"Authorization": "Bearer test-admin"}
+	caller := map[string]string{"X-API-Key": "embed-key"}
+
+	if status, body := request(t, "GET", srv.URL+"/students/search/semantic?q=chess", "", caller); status != http.StatusOK {
+		t.Fatalf("GET /students/search/semantic: %d %s", status, body)
+	}
+	status, body := request(t, "GET", srv.URL+"/admin/llm/usage?caller="+keyID("embed-key"), "", admin)
+	if status != http.StatusOK || !strings.Contains(body, `"calls":1`) {
+		t.Errorf("embedding usage was not recorded for the caller: %d %s", status, body)
+	}
+	status, body = request(t, "GET", srv.URL+"/admin/llm/audit?caller="+keyID("embed-key"), "", admin)
+	if status != http.StatusOK || !strings.Contains(body, "chess") {
+		t.Errorf("embedding was not audited: %d %s", status, body)
+	}
+}
+
+func TestTenantFailuresOpenOnlyTheTenantsBreaker(t *testing.T) {
+	var calls int
+	var callsMu sync.Mutex
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callsMu.Lock()
+		calls++
+		callsMu.Unlock()
+		http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+	srv := newTestServer(t, &recordingProvider{}, func(cfg *Config) {
+		cfg.APIKeyTenants = map[string]string{"tenant-key": "acme"}
+		cfg.BreakerThreshold = 1
+		cfg.BreakerCooldown = time.Hour
+		cfg.LLMRetries = 1
+		cfg.LLMBackoff = time.Millisecond
+		cfg.DegradedMode = "error"
+	})
+	admin := map[string]string{"Authorization": "Bearer test-admin"}
+	tenant := map[string]string{"X-API-Key": "tenant-key"}
+
+	if status, body := request(t, "PUT", srv.URL+"/admin/tenants/acme/llm", `{"provider":"ollama","base_url":"`+failing.URL+`"}`, admin); status != http.StatusOK {
+		t.Fatalf("PUT /admin/tenants/acme/llm: %d %s", status, body)
+	}
+	// Tenant settings and summaries outlive the test server
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/admin/tenants/acme/llm", "", admin) })
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/students/1", "", nil) })
+
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", tenant); status != http.StatusBadGateway && status != http.StatusServiceUnavailable {
+		t.Fatalf("tenant summary against a failing provider: %d %s", status, body)
+	}
+	callsMu.Lock()
+	if calls != 2 {
+		t.Errorf("tenant provider was called %d times, want 2 with one retry", calls)
+	}
+	callsMu.Unlock()
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", tenant); status != http.StatusServiceUnavailable {
+		t.Errorf("tenant summary after the tenant's breaker opened: %d %s, want 503", status, body)
+	}
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", nil); status != http.StatusOK {
+		t.Errorf("default tenant summary after another tenant's breaker opened: %d %s", status, body)
+	}
+}
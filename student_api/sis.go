@@ -0,0 +1,229 @@
+package main
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of SIS change notification
+const (
+	SISStudentUpserted = "student.upserted"
+	SISStudentDeleted  = "student.deleted"
+)
+
+// States of a processed SIS notification; failed notifications are processed again when the SIS
+// retries them, the others are answered from the log
+const (
+	SISApplied   = "applied"
+	SISUnchanged = "unchanged"
+	SISFailed    = "failed"
+)
+
+// sisMu guards sisLog and serializes processing, so a notification delivered twice at once is
+// still applied once
+var (
+	sisLog      = make(map[string]*SISLogEntry)
+	sisLogOrder []string
+	sisMu       sync.Mutex
+)
+
+// SISNotification struct to hold a change notification from the student information system;
+// Data holds the SIS fields, mapped to student fields with SIS_FIELD_MAP
+type SISNotification struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	StudentID string                 `json:"student_id"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// SISLogEntry struct to hold the outcome of processing one SIS notification
+type SISLogEntry struct {
+	NotificationID string    `json:"notification_id"`
+	Type           string    `json:"type"`
+	SISStudentID   string    `json:"sis_student_id"`
+	Status         string    `json:"status"`
+	StudentID      int       `json:"student_id,omitempty"`
+	Action         string    `json:"action,omitempty"`
+	Fields         []string  `json:"fields,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Attempts       int       `json:"attempts"`
+	ReceivedAt     time.Time `json:"received_at"`
+	ProcessedAt    time.Time `json:"processed_at"`
+}
+
+// verifySISSignature checks X-SIS-Signature, the hex HMAC-SHA256 of X-SIS-Timestamp, a dot and
+// the body keyed with SIS_WEBHOOK_SECRET, and rejects timestamps outside SIS_SIGNATURE_TOLERANCE
+func (s *Server) verifySISSignature(r *http.Request, body []byte) error {
+	timestamp := r.Header.Get("X-SIS-Timestamp")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid X-SIS-Timestamp")
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > s.config.SISSignatureTolerance || age < -s.config.SISSignatureTolerance {
+		return errors.New("X-SIS-Timestamp is outside the accepted window")
+	}
+	expected := signWebhookPayload(s.config.SISWebhookSecret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-SIS-Signature"))) {
+		return errors.New("invalid X-SIS-Signature")
+	}
+	return nil
+}
+
+// studentFromSISData maps the SIS fields of a notification onto a student with SIS_FIELD_MAP;
+// unmapped fields are ignored
+func (s *Server) studentFromSISData(sisID string, data map[string]interface{}) (Student, error) {
+	mapped := make(map[string]interface{})
+	for field, value := range data {
+		if target := s.config.SISFieldMap[field]; target != "" {
+			mapped[target] = value
+		}
+	}
+	body, err := json.Marshal(mapped)
+	if err != nil {
+		return Student{}, err
+	}
+	var student Student
+	if err := json.Unmarshal(body, &student); err != nil {
+		return Student{}, fmt.Errorf("Invalid SIS data: %v", err)
+	}
+	student.ID = 0
+	student.ExternalIDs = map[string]string{s.config.SISSystem: sisID}
+	return student, nil
+}
+
+// applySISNotification makes the change a notification describes: an upsert creates or updates
+// the student holding the SIS ID, matched as a roster sync would, and a delete removes it
+func (s *Server) applySISNotification(n SISNotification, entry *SISLogEntry) error {
+	if n.Type == SISStudentDeleted {
+		s.store.Lock()
+		id := 0
+		for _, student := range s.store.List() {
+			if student.ExternalIDs[s.config.SISSystem] == n.StudentID {
+				id = student.ID
+			}
+		}
+		s.store.Unlock()
+		if id == 0 {
+			entry.Status = SISUnchanged
+			return nil
+		}
+		entry.StudentID, entry.Action = id, "delete"
+		return s.students.Delete(id, false)
+	}
+
+	student, err := s.studentFromSISData(n.StudentID, n.Data)
+	if err != nil {
+		return err
+	}
+	result := s.applyStudentSync([]Student{student}, SyncOptions{Key: "external:" + s.config.SISSystem, Partial: true, KeepMissing: true})
+	change := result.Changes[0]
+	entry.StudentID, entry.Action, entry.Fields = change.StudentID, change.Action, change.Fields
+	if change.Action == "unchanged" {
+		entry.Status = SISUnchanged
+	}
+	if change.Error != "" {
+		return errors.New(change.Error)
+	}
+	return nil
+}
+
+// recordSISLogEntry adds an entry to the processing log, keeping the last SIS_LOG_SIZE
+// notifications; the caller must hold sisMu
+func (s *Server) recordSISLogEntry(entry *SISLogEntry) {
+	if _, exists := sisLog[entry.NotificationID]; !exists {
+		sisLogOrder = append(sisLogOrder, entry.NotificationID)
+	}
+	sisLog[entry.NotificationID] = entry
+	for len(sisLogOrder) > s.config.SISLogSize {
+		delete(sisLog, sisLogOrder[0])
+		sisLogOrder = sisLogOrder[1:]
+	}
+}
+
+// receiveSISWebhook handles POST /integrations/sis/webhook to apply a signed change notification
+// from the student information system. Notifications are idempotent on their id: one already
+// applied is answered from the processing log with Idempotent-Replayed: true, while a failed one
+// is processed again. A failure returns 422 with the log entry so the SIS can retry or alert
+func (s *Server) receiveSISWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.config.SISWebhookSecret == "" {
+		http.Error(w, "SIS integration is not configured", http.StatusNotFound)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := s.verifySISSignature(r, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var n SISNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	n.ID, n.StudentID = strings.TrimSpace(n.ID), strings.TrimSpace(n.StudentID)
+	if n.ID == "" || n.StudentID == "" {
+		http.Error(w, "id and student_id are required", http.StatusBadRequest)
+		return
+	}
+	if n.Type != SISStudentUpserted && n.Type != SISStudentDeleted {
+		http.Error(w, "type must be "+SISStudentUpserted+" or "+SISStudentDeleted, http.StatusBadRequest)
+		return
+	}
+
+	sisMu.Lock()
+	defer sisMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	previous, seen := sisLog[n.ID]
+	if seen && previous.Status != SISFailed {
+		w.Header().Set("Idempotent-Replayed", "true")
+		json.NewEncoder(w).Encode(previous)
+		return
+	}
+
+	entry := &SISLogEntry{NotificationID: n.ID, Type: n.Type, SISStudentID: n.StudentID, Status: SISApplied, Attempts: 1, ReceivedAt: time.Now()}
+	if seen {
+		entry.Attempts = previous.Attempts + 1
+	}
+	if err := s.applySISNotification(n, entry); err != nil {
+		entry.Status = SISFailed
+		entry.Error = err.Error()
+	}
+	entry.ProcessedAt = time.Now()
+	s.recordSISLogEntry(entry)
+
+	if entry.Status == SISFailed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(entry)
+}
+
+// getSISLog handles GET /admin/integrations/sis/log?status= to list the processed SIS
+// notifications, newest first
+func getSISLog(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	sisMu.Lock()
+	entries := []SISLogEntry{}
+	for i := len(sisLogOrder) - 1; i >= 0; i-- {
+		if entry := sisLog[sisLogOrder[i]]; status == "" || entry.Status == status {
+			entries = append(entries, *entry)
+		}
+	}
+	sisMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
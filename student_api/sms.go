@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// maxSMSLength is the longest message accepted; providers split it into segments
+const maxSMSLength = 1600
+
+var (
+	tenantSMS   = make(map[string]*tenantSMSEntry)
+	tenantSMSMu sync.Mutex
+)
+
+// SMSNotifier is implemented by outbound SMS backends
+type SMSNotifier interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// SMSConfig struct to hold an SMS provider's settings; AuthToken is write-only
+type SMSConfig struct {
+	Provider     string `json:"provider"`
+	AccountSID   string `json:"account_sid,omitempty"`
+	From         string `json:"from,omitempty"`
+	URL          string `json:"url,omitempty"`
+	AuthToken    string `json:"auth_token,omitempty"`
+	HasAuthToken bool   `json:"has_auth_token"`
+}
+
+// tenantSMSEntry holds a tenant's SMS settings with the auth token encrypted
+type tenantSMSEntry struct {
+	config             SMSConfig
+	encryptedAuthToken string
+}
+
+// smsJobPayload struct to hold the payload of an sms job
+type smsJobPayload struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// ContactMessage struct to hold one message queued for a contact; Channel is empty and JobID 0
+// when the contact opted out of notifications or has no address
+type ContactMessage struct {
+	ContactID int    `json:"contact_id"`
+	Name      string `json:"name"`
+	Channel   string `json:"channel,omitempty"`
+	JobID     int    `json:"job_id,omitempty"`
+}
+
+// newDefaultSMSNotifier returns the backend selected by SMS_PROVIDER; without one, messages are
+// only logged
+func newDefaultSMSNotifier(cfg Config) SMSNotifier {
+	smsConfig := SMSConfig{Provider: cfg.SMSProvider, From: cfg.SMSFrom}
+	switch cfg.SMSProvider {
+	case "twilio":
+		smsConfig.AccountSID, smsConfig.AuthToken = cfg.TwilioAccountSID, cfg.TwilioAuthToken
+	case "http":
+		smsConfig.URL, smsConfig.AuthToken = cfg.SMSHTTPURL, cfg.SMSHTTPToken
+	case "":
+		return LogSMS{}
+	}
+	notifier, err := newSMSNotifier(smsConfig, cfg)
+	if err != nil {
+		log.Printf("Error configuring SMS provider, messages will only be logged: %v", err)
+		return LogSMS{}
+	}
+	return notifier
+}
+
+// newSMSNotifier returns the backend for a provider's settings, using the deployment's Twilio URL
+// and SMS timeout
+func newSMSNotifier(cfg SMSConfig, deployment Config) (SMSNotifier, error) {
+	client := &http.Client{Timeout: deployment.retryPolicy("sms", "").timeout()}
+	switch cfg.Provider {
+	case "twilio":
+		if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.From == "" {
+			return nil, errors.New("twilio requires account_sid, auth_token and from")
+		}
+		return &TwilioSMS{BaseURL: deployment.TwilioAPIURL, AccountSID: cfg.AccountSID, AuthToken: cfg.AuthToken, From: cfg.From, Client: client}, nil
+	case "http":
+		if cfg.URL == "" {
+			return nil, errors.New("http requires url")
+		}
+		return &HTTPSMS{URL: cfg.URL, Token: cfg.AuthToken, From: cfg.From, Client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider %q", cfg.Provider)
+	}
+}
+
+// smsNotifierFor returns the SMS backend of the tenant on ctx, or the deployment default; the
+// tenant's token is only decrypted for the duration of the call
+func (s *Server) smsNotifierFor(ctx context.Context) (SMSNotifier, error) {
+	tenant := tenantFromContext(ctx)
+
+	tenantSMSMu.Lock()
+	entry, ok := tenantSMS[tenant]
+	tenantSMSMu.Unlock()
+	if !ok || tenant == "" {
+		return s.sms, nil
+	}
+
+	cfg := entry.config
+	if entry.encryptedAuthToken != "" {
+		var err error
+		if cfg.AuthToken, err = s.decryptSecret(entry.encryptedAuthToken); err != nil {
+			return nil, err
+		}
+	}
+	return newSMSNotifier(cfg, s.config)
+}
+
+// TwilioSMS sends messages through the Twilio Messages API
+type TwilioSMS struct {
+	BaseURL    string
+	AccountSID string
+	AuthToken  string
+	From       string
+	Client     *http.Client
+}
+
+// Send delivers one message
+func (t *TwilioSMS) Send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {t.From}, "Body": {body}}
+	endpoint := strings.TrimRight(t.BaseURL, "/") + "/2010-04-01/Accounts/" + url.PathEscape(t.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+	return sendSMSRequest(t.Client, req, "twilio")
+}
+
+// HTTPSMS posts messages as JSON to a generic SMS gateway, with the token as a bearer credential
+type HTTPSMS struct {
+	URL    string
+	Token  string
+	From   string
+	Client *http.Client
+}
+
+// Send delivers one message
+func (h *HTTPSMS) Send(ctx context.Context, to, body string) error {
+	payload, err := json.Marshal(map[string]string{"to": to, "from": h.From, "body": body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", h.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+	return sendSMSRequest(h.Client, req, "sms gateway")
+}
+
+// sendSMSRequest sends a provider request and turns a non-2xx response into an error
+func sendSMSRequest(client *http.Client, req *http.Request, provider string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", provider, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// LogSMS writes messages to the log instead of sending them, for local development
+type LogSMS struct{}
+
+// Send logs one message
+func (LogSMS) Send(ctx context.Context, to, body string) error {
+	log.Printf("SMS to %s: %s", to, body)
+	return nil
+}
+
+// runSMSJob sends one queued text
+func (s *Server) runSMSJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var msg smsJobPayload
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, err
+	}
+	notifier, err := s.smsNotifierFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := notifier.Send(ctx, msg.To, msg.Body); err != nil {
+		return nil, err
+	}
+	return json.Marshal("Sent to " + msg.To)
+}
+
+// alertContact returns the contact attendance alerts go to: the primary contact, or the first
+// contact with a phone when there is none; the caller must hold contactsMu
+func alertContact(studentID int) (Contact, bool) {
+	var found Contact
+	ok := false
+	for _, contact := range contacts[studentID] {
+		if contact.Primary {
+			return contact, true
+		}
+		if !ok && contact.Phone != "" {
+			found, ok = contact, true
+		}
+	}
+	return found, ok
+}
+
+// sendAbsenceAlerts texts a contact of each student marked absent when SMS_ATTENDANCE_ALERTS is
+// on, honoring the contact's notification preferences; the caller must not hold the student store or coursesMu
+func (s *Server) sendAbsenceAlerts(ctx context.Context, records []AttendanceRecord) {
+	if !s.config.SMSAttendanceAlerts {
+		return
+	}
+	for _, record := range records {
+		if record.Status != "absent" {
+			continue
+		}
+
+		s.store.Lock()
+		student, _ := s.store.Get(record.StudentID)
+		coursesMu.Lock()
+		course := courses[record.CourseID]
+		coursesMu.Unlock()
+		contactsMu.Lock()
+		contact, ok := alertContact(record.StudentID)
+		contactsMu.Unlock()
+		s.store.Unlock()
+		if !ok {
+			continue
+		}
+
+		body := student.Name + " was marked absent on " + record.Date
+		if record.CourseID != 0 {
+			body = student.Name + " was marked absent from " + course.Title + " on " + record.Date
+		}
+		if record.Period != 0 {
+			body += fmt.Sprintf(" (period %d)", record.Period)
+		}
+		to := notificationRecipient{StudentID: record.StudentID, ContactID: contact.ID, Email: contact.Email, Phone: contact.Phone}
+		if _, _, err := s.sendNotification(ctx, to, ChannelSMS, "Attendance alert: "+student.Name, body+".", false); err != nil {
+			log.Printf("Error queueing attendance alert for student %d: %v", record.StudentID, err)
+		}
+	}
+}
+
+// messageContacts handles POST /students/{id}/contacts/message to message the student's contacts,
+// or only the primary contact with "primary_only": true. Messages are texted unless a contact's
+// notification preferences say otherwise and, being urgent, ignore quiet hours
+func (s *Server) messageContacts(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var req struct {
+		Message     string `json:"message"`
+		PrimaryOnly bool   `json:"primary_only"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" || len(req.Message) > maxSMSLength {
+		http.Error(w, fmt.Sprintf("message must be 1 to %d characters", maxSMSLength), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	contactsMu.Lock()
+	var recipients []Contact
+	for _, contact := range contacts[id] {
+		if contact.Primary || !req.PrimaryOnly {
+			recipients = append(recipients, contact)
+		}
+	}
+	contactsMu.Unlock()
+	if len(recipients) == 0 {
+		http.Error(w, "Student has no contacts to message", http.StatusConflict)
+		return
+	}
+
+	sent := []ContactMessage{}
+	for _, contact := range recipients {
+		to := notificationRecipient{StudentID: id, ContactID: contact.ID, Email: contact.Email, Phone: contact.Phone}
+		channel, job, err := s.sendNotification(r.Context(), to, ChannelSMS, "Message about your student", req.Message, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sent = append(sent, ContactMessage{ContactID: contact.ID, Name: contact.Name, Channel: channel, JobID: job.ID})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(sent)
+}
+
+// getTenantSMSConfig handles GET /admin/tenants/{tenant}/sms
+func getTenantSMSConfig(w http.ResponseWriter, r *http.Request) {
+	tenant := mux.Vars(r)["tenant"]
+
+	tenantSMSMu.Lock()
+	entry, ok := tenantSMS[tenant]
+	tenantSMSMu.Unlock()
+	if !ok {
+		http.Error(w, "Tenant SMS configuration not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry.config)
+}
+
+// putTenantSMSConfig handles PUT /admin/tenants/{tenant}/sms to set the provider and credentials a
+// tenant's texts are sent with; the auth token is stored encrypted and never returned
+func (s *Server) putTenantSMSConfig(w http.ResponseWriter, r *http.Request) {
+	tenant := mux.Vars(r)["tenant"]
+
+	var cfg SMSConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if _, err := newSMSNotifier(cfg, s.config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entry := &tenantSMSEntry{}
+	if cfg.AuthToken != "" {
+		encrypted, err := s.encryptSecret(cfg.AuthToken)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entry.encryptedAuthToken = encrypted
+	}
+
+	cfg.HasAuthToken = cfg.AuthToken != ""
+	cfg.AuthToken = ""
+	entry.config = cfg
+
+	tenantSMSMu.Lock()
+	tenantSMS[tenant] = entry
+	tenantSMSMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cfg)
+}
+
+// deleteTenantSMSConfig handles DELETE /admin/tenants/{tenant}/sms to revert a tenant to the
+// deployment's SMS provider
+func deleteTenantSMSConfig(w http.ResponseWriter, r *http.Request) {
+	tenant := mux.Vars(r)["tenant"]
+
+	tenantSMSMu.Lock()
+	_, ok := tenantSMS[tenant]
+	delete(tenantSMS, tenant)
+	tenantSMSMu.Unlock()
+	if !ok {
+		http.Error(w, "Tenant SMS configuration not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
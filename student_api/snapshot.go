@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoreSnapshot struct to hold the records written to a snapshot file
+type StoreSnapshot struct {
+	TakenAt  time.Time `json:"taken_at"`
+	Students []Student `json:"students"`
+	Courses  []Course  `json:"courses"`
+}
+
+// startSnapshots schedules snapshots of the store into SNAPSHOT_DIR when SNAPSHOT_SCHEDULE is set
+func (s *Server) startSnapshots() error {
+	if s.config.SnapshotSchedule == "" {
+		return nil
+	}
+	schedule, err := ParseCron(s.config.SnapshotSchedule)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.config.SnapshotDir, 0700); err != nil {
+		return err
+	}
+	runOnSchedule("snapshot", schedule, s.takeSnapshot)
+	return nil
+}
+
+// takeSnapshot writes the students and courses to a timestamped JSON file, keeping the newest
+// SNAPSHOT_KEEP files
+func (s *Server) takeSnapshot() error {
+	snapshot := StoreSnapshot{TakenAt: time.Now(), Students: []Student{}, Courses: []Course{}}
+	s.store.Lock()
+	for _, student := range s.store.List() {
+		snapshot.Students = append(snapshot.Students, student)
+	}
+	coursesMu.Lock()
+	for _, course := range courses {
+		snapshot.Courses = append(snapshot.Courses, course)
+	}
+	coursesMu.Unlock()
+	s.store.Unlock()
+	sort.Slice(snapshot.Students, func(i, j int) bool { return snapshot.Students[i].ID < snapshot.Students[j].ID })
+	sort.Slice(snapshot.Courses, func(i, j int) bool { return snapshot.Courses[i].ID < snapshot.Courses[j].ID })
+
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.config.SnapshotDir, "snapshot-"+snapshot.TakenAt.UTC().Format("20060102T150405Z")+".json")
+	if err := ioutil.WriteFile(path+".tmp", body, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	log.Printf("Snapshot of %d students and %d courses written to %s", len(snapshot.Students), len(snapshot.Courses), path)
+
+	return s.pruneSnapshots()
+}
+
+// pruneSnapshots removes all but the newest SNAPSHOT_KEEP snapshot files; the timestamped names
+// sort oldest first
+func (s *Server) pruneSnapshots() error {
+	entries, err := ioutil.ReadDir(s.config.SnapshotDir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "snapshot-") && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > s.config.SnapshotKeep {
+		if err := os.Remove(filepath.Join(s.config.SnapshotDir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseKeepAliveInterval is how often an idle feed sends a comment so proxies keep it open
+const sseKeepAliveInterval = 15 * time.Second
+
+// streamStudentEvents handles GET /students/events to stream student changes as Server-Sent
+// Events; each event carries its ID, and a client reconnecting with Last-Event-ID (or
+// ?last_event_id=) receives the events it missed. When those have left the history a "resync"
+// event tells the client to reload the students before the feed continues. ?types= narrows the
+// event types as on /ws
+func (s *Server) streamStudentEvents(w http.ResponseWriter, r *http.Request) {
+	subscription := eventSubscription{Types: splitList(r.URL.Query().Get("types"))}
+	lastID := int64(liveEventsOnly)
+	if value := firstNonEmpty(r.Header.Get("Last-Event-ID"), r.URL.Query().Get("last_event_id")); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = id
+	}
+
+	backlog, events, unsubscribe, complete := subscribeStudentEventsAfter(lastID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	controller := http.NewResponseController(w)
+
+	write := func(text string) bool {
+		controller.SetWriteDeadline(time.Now().Add(s.config.StreamWriteTimeout))
+		if _, err := fmt.Fprint(w, text); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+	send := func(event StudentEvent) bool {
+		if !subscription.matches(event) {
+			return true
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return false
+		}
+		return write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data))
+	}
+
+	if !write(": connected\n\n") {
+		return
+	}
+	if !complete && !write("event: resync\ndata: {}\n\n") {
+		return
+	}
+	for _, event := range backlog {
+		if !send(event) {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if !send(event) {
+				return
+			}
+		case <-keepAlive.C:
+			if !write(": keep-alive\n\n") {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"student_api/student_api/internal/handlers"
+)
+
+// Student lifecycle states
+const (
+	StatusApplied   = "applied"
+	StatusEnrolled  = "enrolled"
+	StatusOnLeave   = "on_leave"
+	StatusGraduated = "graduated"
+	StatusWithdrawn = "withdrawn"
+)
+
+// ErrIllegalTransition is returned when a status change is not allowed from the current status
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+var (
+	// statusTransitions lists the states reachable from each state; graduated and withdrawn are final
+	statusTransitions = map[string][]string{
+		StatusApplied:   {StatusEnrolled, StatusWithdrawn},
+		StatusEnrolled:  {StatusOnLeave, StatusGraduated, StatusWithdrawn},
+		StatusOnLeave:   {StatusEnrolled, StatusWithdrawn},
+		StatusGraduated: {},
+		StatusWithdrawn: {},
+	}
+
+	statusHistory   = make(map[int][]StatusTransition)
+	statusHistoryMu sync.Mutex
+)
+
+// StatusTransition struct to hold one recorded change of a student's status
+type StatusTransition struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason,omitempty"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// validInitialStatus reports whether a new student may be created in the given status
+func validInitialStatus(status string) bool {
+	return status == StatusApplied || status == StatusEnrolled
+}
+
+// transitionStatus moves a student to a new status, recording the change in its history; it is
+// meant to run inside Students.Change, which stores the returned student
+func transitionStatus(student Student, to, reason, changedBy string) (Student, error) {
+	allowed := false
+	for _, next := range statusTransitions[student.Status] {
+		allowed = allowed || next == to
+	}
+	if !allowed {
+		return student, fmt.Errorf("%w: %s to %s", ErrIllegalTransition, student.Status, to)
+	}
+
+	statusHistoryMu.Lock()
+	statusHistory[student.ID] = append(statusHistory[student.ID], StatusTransition{
+		From:      student.Status,
+		To:        to,
+		Reason:    reason,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+	})
+	statusHistoryMu.Unlock()
+
+	student.Status = to
+	if to == StatusGraduated {
+		student.Alumni = true
+		student.GraduationYear = time.Now().Year()
+	}
+	return student, nil
+}
+
+// changeStudentStatus handles POST /students/{id}/status to move a student through the
+// applied → enrolled → on_leave → graduated/withdrawn lifecycle, answering 409 for illegal jumps
+func (s *Server) changeStudentStatus(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var req struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if _, known := statusTransitions[req.Status]; !known {
+		http.Error(w, "Unknown status", http.StatusBadRequest)
+		return
+	}
+
+	student, err := s.students.Change(id, func(student *Student) error {
+		changed, err := transitionStatus(*student, req.Status, strings.TrimSpace(req.Reason), callerID(r.Context()))
+		*student = changed
+		return err
+	})
+	if errors.Is(err, ErrIllegalTransition) {
+		http.Error(w, "Cannot change status from "+student.Status+" to "+req.Status, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), handlers.StatusCode(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withComputed(student))
+}
+
+// getStatusHistory handles GET /students/{id}/status/history to list a student's status changes
+func (s *Server) getStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	statusHistoryMu.Lock()
+	history := append([]StatusTransition{}, statusHistory[id]...)
+	statusHistoryMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// deleteStatusHistory drops a deleted student's status history
+func deleteStatusHistory(id int) {
+	statusHistoryMu.Lock()
+	defer statusHistoryMu.Unlock()
+	delete(statusHistory, id)
+}
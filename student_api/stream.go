@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"student_api/student_api/internal/llm"
+)
+
+// ErrStreamingUnsupported is returned when the configured provider cannot stream its output
+var ErrStreamingUnsupported = llm.ErrStreamingUnsupported
+
+// streamStudentSummary handles GET /students/{id}/summary/stream to relay a fresh summary to the
+// client as plain text while it is generated. The stream goes through the same provider chain as
+// other LLM calls. Each chunk is flushed as soon as it is written; at most STREAM_BUFFER_CHUNKS
+// chunks are held for a slow client, and a client that stops reading for STREAM_WRITE_TIMEOUT is
+// disconnected. The finished summary is cached like any other. Streaming is refused while
+// moderation is enabled, since text must be checked before the client sees it.
+func (s *Server) streamStudentSummary(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if opts.Structured {
+		http.Error(w, "Structured summaries cannot be streamed", http.StatusBadRequest)
+		return
+	}
+	if s.moderationEnabled() {
+		writeProblem(w, http.StatusNotImplemented, "Streaming unavailable", "Summaries are moderated before they are shown; use GET /students/{id}/summary")
+		return
+	}
+	if !s.llm.canStream(opts.Tenant) {
+		writeProblem(w, http.StatusNotImplemented, "Streaming unsupported", ErrStreamingUnsupported.Error())
+		return
+	}
+
+	prompt, citations, err := s.summaryPrompt(r.Context(), student, opts)
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+	req := LLMRequest{Model: opts.Model, Prompt: prompt}
+
+	summariesMu.Lock()
+	gen := summaryGen[student.ID]
+	summariesMu.Unlock()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	chunks := make(chan string, s.config.StreamBufferChunks)
+	type streamResult struct {
+		resp LLMResponse
+		err  error
+	}
+	done := make(chan streamResult, 1)
+	go func() {
+		resp, err := s.llm.Stream(ctx, req, chunks)
+		close(chunks)
+		done <- streamResult{resp, err}
+	}()
+
+	controller := http.NewResponseController(w)
+	started := false
+	var writeErr error
+	for chunk := range chunks {
+		if writeErr != nil {
+			continue
+		}
+		if !started {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.Header().Set("Cache-Control", "no-cache")
+			started = true
+		}
+
+		// Coalesce whatever is already buffered into a single write and flush
+		var batch strings.Builder
+		batch.WriteString(chunk)
+	drain:
+		for {
+			select {
+			case more, ok := <-chunks:
+				if !ok {
+					break drain
+				}
+				batch.WriteString(more)
+			default:
+				break drain
+			}
+		}
+
+		controller.SetWriteDeadline(time.Now().Add(s.config.StreamWriteTimeout))
+		if _, writeErr = w.Write([]byte(batch.String())); writeErr == nil {
+			writeErr = controller.Flush()
+		}
+		if writeErr != nil {
+			// The client is gone or too slow; stop generating and discard the remaining output
+			log.Printf("Streaming summary for student %d aborted: %v", student.ID, writeErr)
+			cancel()
+		}
+	}
+	result := <-done
+	controller.SetWriteDeadline(time.Time{})
+
+	if result.err != nil {
+		if !started {
+			s.writeLLMError(w, result.err)
+		} else if !errors.Is(result.err, context.Canceled) {
+			log.Printf("Streaming summary for student %d failed: %v", student.ID, result.err)
+		}
+		return
+	}
+	if writeErr != nil {
+		return
+	}
+
+	storeSummary(Summary{
+		StudentID:      student.ID,
+		Summary:        result.resp.Text,
+		SummaryOptions: opts,
+		Citations:      citations,
+		ServedBy:       result.resp.Provider + ":" + result.resp.Model,
+		GeneratedAt:    time.Now(),
+	}, gen)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// streamingProvider streams a fixed summary in two chunks
+type streamingProvider struct {
+	recordingProvider
+}
+
+func (p *streamingProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+	for _, chunk := range []string{"Ann is ", "doing well."} {
+		select {
+		case chunks <- chunk:
+		case <-ctx.Done():
+			return LLMResponse{}, ctx.Err()
+		}
+	}
+	return LLMResponse{Model: req.Model, Text: "Ann is doing well.", PromptTokens: 10, CompletionTokens: 4}, nil
+}
+
+func TestStreamGoesThroughProviderChain(t *testing.T) {
+	provider := &streamingProvider{}
+	srv := newTestServer(t, provider)
+	admin := map[string]string{"Authorization": "Bearer test-admin"}
+	caller := map[string]string{"X-API-Key": "stream-key"}
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+
+	status, body := request(t, "GET", srv.URL+"/students/1/summary/stream", "", caller)
+	if status != http.StatusOK || body != "Ann is doing well." {
+		t.Fatalf("GET /students/1/summary/stream: %d %q", status, body)
+	}
+
+	status, body = request(t, "GET", srv.URL+"/admin/llm/usage?caller="+keyID("stream-key"), "", admin)
+	if status != http.StatusOK || !strings.Contains(body, `"calls":1`) {
+		t.Errorf("stream usage was not recorded: %d %s", status, body)
+	}
+	status, body = request(t, "GET", srv.URL+"/admin/llm/audit?caller="+keyID("stream-key"), "", admin)
+	if status != http.StatusOK || !strings.Contains(body, "Ann is doing well.") {
+		t.Errorf("stream was not audited: %d %s", status, body)
+	}
+}
+
+func TestStreamRefusedWhenModerated(t *testing.T) {
+	provider := &streamingProvider{}
+	srv := newTestServer(t, provider, func(cfg *Config) { cfg.ModerationBlocklist = []string{"forbidden"} })
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary/stream", "", nil); status != http.StatusNotImplemented {
+		t.Errorf("GET /students/1/summary/stream with moderation: %d %s", status, body)
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if len(provider.requests) != 0 {
+		t.Errorf("provider was called %d times for a refused stream", len(provider.requests))
+	}
+}
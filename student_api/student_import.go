@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importBatchSize is how many rows an import applies between progress checkpoints; a crash
+// repeats at most one batch, which the import key makes harmless
+const importBatchSize = 100
+
+// studentImportPayload struct to hold the payload of a student import job; File is the upload
+// kept in IMPORT_DIR until the import finishes
+type studentImportPayload struct {
+	File   string `json:"file"`
+	Format string `json:"format"`
+}
+
+// importRow struct to hold one row of an uploaded file, numbered from the first data row, and the
+// student it maps to or why it could not be mapped
+type importRow struct {
+	Row     int
+	Student Student
+	Err     error
+}
+
+// importFormat picks the format of an upload from ?format= or its Content-Type
+func importFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		switch strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]) {
+		case "text/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/jsonl":
+			format = "ndjson"
+		}
+	}
+	if format != "csv" && format != "ndjson" {
+		return "", errors.New("format must be csv or ndjson; pass ?format= or a text/csv or application/x-ndjson Content-Type")
+	}
+	return format, nil
+}
+
+// readImportRows parses an uploaded file into rows. CSV columns map to student fields as in the
+// scheduled CSV import and every NDJSON line holds a student; rows repeating the import key of an
+// earlier row are marked as failed, so every attempt of the import sees the same rows
+func (s *Server) readImportRows(path, format string) ([]importRow, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []importRow
+	if format == "csv" {
+		reader := csv.NewReader(bytes.NewReader(body))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("parsing CSV: %w", err)
+		}
+		if len(records) == 0 {
+			return nil, errors.New("The CSV has no header row")
+		}
+		columns := make(map[string]int, len(records[0]))
+		for i, name := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		for i, record := range records[1:] {
+			student, err := s.studentFromCSVRow(columns, record)
+			rows = append(rows, importRow{Row: i + 1, Student: student, Err: err})
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			row := importRow{Row: line}
+			if err := json.Unmarshal(scanner.Bytes(), &row.Student); err != nil {
+				row.Err = fmt.Errorf("Invalid JSON: %v", err)
+			} else {
+				row.Student.ID = 0
+				normalizeStudent(&row.Student)
+				if syncKey(row.Student, s.config.CSVImportKey) == "" {
+					row.Err = errors.New("Missing value for the import key " + s.config.CSVImportKey)
+				}
+			}
+			rows = append(rows, row)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("parsing NDJSON: %w", err)
+		}
+	}
+
+	seen := make(map[string]int)
+	for i := range rows {
+		if rows[i].Err != nil {
+			continue
+		}
+		k := syncKey(rows[i].Student, s.config.CSVImportKey)
+		if other, duplicate := seen[k]; duplicate {
+			rows[i].Err = fmt.Errorf("Duplicate key %s also used by row %d", k, other)
+			continue
+		}
+		seen[k] = rows[i].Row
+	}
+	return rows, nil
+}
+
+// runStudentImportJob imports an uploaded file in batches, matching on CSV_IMPORT_KEY and
+// resolving differences with CSV_IMPORT_CONFLICT like the scheduled CSV import but never deleting
+// students. Progress is reported after every batch and a later attempt continues from the last
+// reported row; rows that fail are counted and listed without stopping the import
+func (s *Server) runStudentImportJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var req studentImportPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	rows, err := s.readImportRows(req.File, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := savedJobProgress(ctx)
+	progress.Total = len(rows)
+	s.reportJobProgress(ctx, progress)
+	for progress.Processed < len(rows) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		batch := rows[progress.Processed:]
+		if len(batch) > importBatchSize {
+			batch = batch[:importBatchSize]
+		}
+
+		var desired []Student
+		rowsByKey := make(map[string]int)
+		for _, row := range batch {
+			if row.Err != nil {
+				progress.Failed++
+				progress.Errors = append(progress.Errors, JobItemError{Item: row.Row, Error: row.Err.Error()})
+				continue
+			}
+			desired = append(desired, row.Student)
+			rowsByKey[syncKey(row.Student, s.config.CSVImportKey)] = row.Row
+		}
+		if len(desired) > 0 {
+			s.resolveCSVConflicts(desired, s.config.CSVImportKey, s.config.CSVImportConflict)
+			result := s.applyStudentSync(desired, SyncOptions{Key: s.config.CSVImportKey, Partial: true, KeepMissing: true})
+			for _, change := range result.Changes {
+				if change.Error == "" {
+					progress.Succeeded++
+					continue
+				}
+				progress.Failed++
+				progress.Errors = append(progress.Errors, JobItemError{Item: rowsByKey[change.Key], Error: change.Error})
+			}
+		}
+		progress.Processed += len(batch)
+		s.reportJobProgress(ctx, progress)
+	}
+
+	os.Remove(req.File)
+	if progress.Failed > 0 {
+		s.postChatEvent(ChatImportFailed, "Student import finished with failures",
+			fmt.Sprintf("%d of %d rows could not be imported", progress.Failed, progress.Total))
+	}
+	return json.Marshal(savedJobProgress(ctx))
+}
+
+// importStudents handles POST /students/import?format= to import a CSV or NDJSON upload as a
+// background job. It returns 202 with the job, whose progress GET /jobs/{id} reports; the upload
+// is kept in IMPORT_DIR so the import resumes after a crash when JOBS_FILE is set
+func (s *Server) importStudents(w http.ResponseWriter, r *http.Request) {
+	format, err := importFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := os.MkdirAll(s.config.ImportDir, 0700); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	path := filepath.Join(s.config.ImportDir, time.Now().UTC().Format("20060102T150405")+"-"+newSessionID()[:8]+"."+format)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	size, err := io.Copy(f, http.MaxBytesReader(w, r.Body, int64(s.config.ImportMaxBytes)))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || size == 0 {
+		os.Remove(path)
+		http.Error(w, fmt.Sprintf("The upload must be a non-empty file of at most %d bytes", s.config.ImportMaxBytes), http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.enqueueJob(backgroundContext(r), "student-import", studentImportPayload{File: path, Format: format},
+		JobOptions{MaxAttempts: 3, Backoff: time.Minute})
+	if err != nil {
+		os.Remove(path)
+		http.Error(w, "Error queueing job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+strconv.Itoa(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,836 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: student.proto
+
+package studentpb
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Student mirrors the REST representation; age is derived from date_of_birth when it is set
+type Student struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email          string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Phone          string                 `protobuf:"bytes,4,opt,name=phone,proto3" json:"phone,omitempty"`
+	DateOfBirth    string                 `protobuf:"bytes,5,opt,name=date_of_birth,json=dateOfBirth,proto3" json:"date_of_birth,omitempty"`
+	Gender         string                 `protobuf:"bytes,6,opt,name=gender,proto3" json:"gender,omitempty"`
+	Nationality    string                 `protobuf:"bytes,7,opt,name=nationality,proto3" json:"nationality,omitempty"`
+	Age            int32                  `protobuf:"varint,8,opt,name=age,proto3" json:"age,omitempty"`
+	Status         string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	GradeLevel     int32                  `protobuf:"varint,10,opt,name=grade_level,json=gradeLevel,proto3" json:"grade_level,omitempty"`
+	Tags           []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	EmailVerified  bool                   `protobuf:"varint,12,opt,name=email_verified,json=emailVerified,proto3" json:"email_verified,omitempty"`
+	Alumni         bool                   `protobuf:"varint,13,opt,name=alumni,proto3" json:"alumni,omitempty"`
+	GraduationYear int32                  `protobuf:"varint,14,opt,name=graduation_year,json=graduationYear,proto3" json:"graduation_year,omitempty"`
+	ExternalIds    map[string]string      `protobuf:"bytes,15,rep,name=external_ids,json=externalIds,proto3" json:"external_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Student) Reset() {
+	*x = Student{}
+	mi := &file_student_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Student) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Student) ProtoMessage() {}
+
+func (x *Student) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Student.ProtoReflect.Descriptor instead.
+func (*Student) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Student) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Student) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Student) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Student) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Student) GetDateOfBirth() string {
+	if x != nil {
+		return x.DateOfBirth
+	}
+	return ""
+}
+
+func (x *Student) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *Student) GetNationality() string {
+	if x != nil {
+		return x.Nationality
+	}
+	return ""
+}
+
+func (x *Student) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *Student) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Student) GetGradeLevel() int32 {
+	if x != nil {
+		return x.GradeLevel
+	}
+	return 0
+}
+
+func (x *Student) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Student) GetEmailVerified() bool {
+	if x != nil {
+		return x.EmailVerified
+	}
+	return false
+}
+
+func (x *Student) GetAlumni() bool {
+	if x != nil {
+		return x.Alumni
+	}
+	return false
+}
+
+func (x *Student) GetGraduationYear() int32 {
+	if x != nil {
+		return x.GraduationYear
+	}
+	return 0
+}
+
+func (x *Student) GetExternalIds() map[string]string {
+	if x != nil {
+		return x.ExternalIds
+	}
+	return nil
+}
+
+type CreateStudentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Student       *Student               `protobuf:"bytes,1,opt,name=student,proto3" json:"student,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateStudentRequest) Reset() {
+	*x = CreateStudentRequest{}
+	mi := &file_student_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateStudentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateStudentRequest) ProtoMessage() {}
+
+func (x *CreateStudentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateStudentRequest.ProtoReflect.Descriptor instead.
+func (*CreateStudentRequest) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateStudentRequest) GetStudent() *Student {
+	if x != nil {
+		return x.Student
+	}
+	return nil
+}
+
+type GetStudentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStudentRequest) Reset() {
+	*x = GetStudentRequest{}
+	mi := &file_student_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStudentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStudentRequest) ProtoMessage() {}
+
+func (x *GetStudentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStudentRequest.ProtoReflect.Descriptor instead.
+func (*GetStudentRequest) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetStudentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// ListStudentsRequest narrows the list like the GET /students query parameters; empty fields
+// are ignored
+type ListStudentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	GradeLevel    int32                  `protobuf:"varint,4,opt,name=grade_level,json=gradeLevel,proto3" json:"grade_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStudentsRequest) Reset() {
+	*x = ListStudentsRequest{}
+	mi := &file_student_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStudentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStudentsRequest) ProtoMessage() {}
+
+func (x *ListStudentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStudentsRequest.ProtoReflect.Descriptor instead.
+func (*ListStudentsRequest) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListStudentsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListStudentsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListStudentsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListStudentsRequest) GetGradeLevel() int32 {
+	if x != nil {
+		return x.GradeLevel
+	}
+	return 0
+}
+
+type ListStudentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Students      []*Student             `protobuf:"bytes,1,rep,name=students,proto3" json:"students,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStudentsResponse) Reset() {
+	*x = ListStudentsResponse{}
+	mi := &file_student_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStudentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStudentsResponse) ProtoMessage() {}
+
+func (x *ListStudentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStudentsResponse.ProtoReflect.Descriptor instead.
+func (*ListStudentsResponse) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListStudentsResponse) GetStudents() []*Student {
+	if x != nil {
+		return x.Students
+	}
+	return nil
+}
+
+// UpdateStudentRequest applies the non-empty fields of student, as PUT /students/{id} does
+type UpdateStudentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Student       *Student               `protobuf:"bytes,2,opt,name=student,proto3" json:"student,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStudentRequest) Reset() {
+	*x = UpdateStudentRequest{}
+	mi := &file_student_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStudentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStudentRequest) ProtoMessage() {}
+
+func (x *UpdateStudentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStudentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateStudentRequest) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateStudentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateStudentRequest) GetStudent() *Student {
+	if x != nil {
+		return x.Student
+	}
+	return nil
+}
+
+type DeleteStudentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// purge deletes alumni, who are otherwise kept for the alumni directory
+	Purge         bool `protobuf:"varint,2,opt,name=purge,proto3" json:"purge,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStudentRequest) Reset() {
+	*x = DeleteStudentRequest{}
+	mi := &file_student_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStudentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStudentRequest) ProtoMessage() {}
+
+func (x *DeleteStudentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStudentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteStudentRequest) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteStudentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteStudentRequest) GetPurge() bool {
+	if x != nil {
+		return x.Purge
+	}
+	return false
+}
+
+type DeleteStudentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStudentResponse) Reset() {
+	*x = DeleteStudentResponse{}
+	mi := &file_student_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStudentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStudentResponse) ProtoMessage() {}
+
+func (x *DeleteStudentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStudentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteStudentResponse) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{7}
+}
+
+type GetStudentSummaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Model         string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Tone          string                 `protobuf:"bytes,3,opt,name=tone,proto3" json:"tone,omitempty"`
+	Length        string                 `protobuf:"bytes,4,opt,name=length,proto3" json:"length,omitempty"`
+	Language      string                 `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`
+	Refresh       bool                   `protobuf:"varint,6,opt,name=refresh,proto3" json:"refresh,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStudentSummaryRequest) Reset() {
+	*x = GetStudentSummaryRequest{}
+	mi := &file_student_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStudentSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStudentSummaryRequest) ProtoMessage() {}
+
+func (x *GetStudentSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStudentSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetStudentSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetStudentSummaryRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetStudentSummaryRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GetStudentSummaryRequest) GetTone() string {
+	if x != nil {
+		return x.Tone
+	}
+	return ""
+}
+
+func (x *GetStudentSummaryRequest) GetLength() string {
+	if x != nil {
+		return x.Length
+	}
+	return ""
+}
+
+func (x *GetStudentSummaryRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *GetStudentSummaryRequest) GetRefresh() bool {
+	if x != nil {
+		return x.Refresh
+	}
+	return false
+}
+
+type StudentSummary struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	StudentId       int64                  `protobuf:"varint,1,opt,name=student_id,json=studentId,proto3" json:"student_id,omitempty"`
+	Summary         string                 `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	Model           string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Cached          bool                   `protobuf:"varint,4,opt,name=cached,proto3" json:"cached,omitempty"`
+	Degraded        bool                   `protobuf:"varint,5,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	GeneratedAtUnix int64                  `protobuf:"varint,6,opt,name=generated_at_unix,json=generatedAtUnix,proto3" json:"generated_at_unix,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StudentSummary) Reset() {
+	*x = StudentSummary{}
+	mi := &file_student_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StudentSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StudentSummary) ProtoMessage() {}
+
+func (x *StudentSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_student_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StudentSummary.ProtoReflect.Descriptor instead.
+func (*StudentSummary) Descriptor() ([]byte, []int) {
+	return file_student_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StudentSummary) GetStudentId() int64 {
+	if x != nil {
+		return x.StudentId
+	}
+	return 0
+}
+
+func (x *StudentSummary) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *StudentSummary) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *StudentSummary) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *StudentSummary) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *StudentSummary) GetGeneratedAtUnix() int64 {
+	if x != nil {
+		return x.GeneratedAtUnix
+	}
+	return 0
+}
+
+var File_student_proto protoreflect.FileDescriptor
+
+const file_student_proto_rawDesc = "" +
+	"\n" +
+	"\rstudent.proto\x12\n" +
+	"student.v1\x1a\x1cgoogle/api/annotations.proto\"\x87\x04\n" +
+	"\aStudent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x04 \x01(\tR\x05phone\x12\"\n" +
+	"\rdate_of_birth\x18\x05 \x01(\tR\vdateOfBirth\x12\x16\n" +
+	"\x06gender\x18\x06 \x01(\tR\x06gender\x12 \n" +
+	"\vnationality\x18\a \x01(\tR\vnationality\x12\x10\n" +
+	"\x03age\x18\b \x01(\x05R\x03age\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12\x1f\n" +
+	"\vgrade_level\x18\n" +
+	" \x01(\x05R\n" +
+	"gradeLevel\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12%\n" +
+	"\x0eemail_verified\x18\f \x01(\bR\remailVerified\x12\x16\n" +
+	"\x06alumni\x18\r \x01(\bR\x06alumni\x12'\n" +
+	"\x0fgraduation_year\x18\x0e \x01(\x05R\x0egraduationYear\x12G\n" +
+	"\fexternal_ids\x18\x0f \x03(\v2$.student.v1.Student.ExternalIdsEntryR\vexternalIds\x1a>\n" +
+	"\x10ExternalIdsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"E\n" +
+	"\x14CreateStudentRequest\x12-\n" +
+	"\astudent\x18\x01 \x01(\v2\x13.student.v1.StudentR\astudent\"#\n" +
+	"\x11GetStudentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"t\n" +
+	"\x13ListStudentsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x1f\n" +
+	"\vgrade_level\x18\x04 \x01(\x05R\n" +
+	"gradeLevel\"G\n" +
+	"\x14ListStudentsResponse\x12/\n" +
+	"\bstudents\x18\x01 \x03(\v2\x13.student.v1.StudentR\bstudents\"U\n" +
+	"\x14UpdateStudentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12-\n" +
+	"\astudent\x18\x02 \x01(\v2\x13.student.v1.StudentR\astudent\"<\n" +
+	"\x14DeleteStudentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05purge\x18\x02 \x01(\bR\x05purge\"\x17\n" +
+	"\x15DeleteStudentResponse\"\xa2\x01\n" +
+	"\x18GetStudentSummaryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x12\n" +
+	"\x04tone\x18\x03 \x01(\tR\x04tone\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\tR\x06length\x12\x1a\n" +
+	"\blanguage\x18\x05 \x01(\tR\blanguage\x12\x18\n" +
+	"\arefresh\x18\x06 \x01(\bR\arefresh\"\xbf\x01\n" +
+	"\x0eStudentSummary\x12\x1d\n" +
+	"\n" +
+	"student_id\x18\x01 \x01(\x03R\tstudentId\x12\x18\n" +
+	"\asummary\x18\x02 \x01(\tR\asummary\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x16\n" +
+	"\x06cached\x18\x04 \x01(\bR\x06cached\x12\x1a\n" +
+	"\bdegraded\x18\x05 \x01(\bR\bdegraded\x12*\n" +
+	"\x11generated_at_unix\x18\x06 \x01(\x03R\x0fgeneratedAtUnix2\x94\x05\n" +
+	"\x0eStudentService\x12e\n" +
+	"\rCreateStudent\x12 .student.v1.CreateStudentRequest\x1a\x13.student.v1.Student\"\x1d\x82\xd3\xe4\x93\x02\x17:\astudent\"\f/v1/students\x12[\n" +
+	"\n" +
+	"GetStudent\x12\x1d.student.v1.GetStudentRequest\x1a\x13.student.v1.Student\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/students/{id}\x12g\n" +
+	"\fListStudents\x12\x1f.student.v1.ListStudentsRequest\x1a .student.v1.ListStudentsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/students\x12j\n" +
+	"\rUpdateStudent\x12 .student.v1.UpdateStudentRequest\x1a\x13.student.v1.Student\"\"\x82\xd3\xe4\x93\x02\x1c:\astudent\x1a\x11/v1/students/{id}\x12o\n" +
+	"\rDeleteStudent\x12 .student.v1.DeleteStudentRequest\x1a!.student.v1.DeleteStudentResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/students/{id}\x12x\n" +
+	"\x11GetStudentSummary\x12$.student.v1.GetStudentSummaryRequest\x1a\x1a.student.v1.StudentSummary\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/students/{id}/summaryB-Z+student_api/student_api/studentpb;studentpbb\x06proto3"
+
+var (
+	file_student_proto_rawDescOnce sync.Once
+	file_student_proto_rawDescData []byte
+)
+
+func file_student_proto_rawDescGZIP() []byte {
+	file_student_proto_rawDescOnce.Do(func() {
+		file_student_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_student_proto_rawDesc), len(file_student_proto_rawDesc)))
+	})
+	return file_student_proto_rawDescData
+}
+
+var file_student_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_student_proto_goTypes = []any{
+	(*Student)(nil),                  // 0: student.v1.Student
+	(*CreateStudentRequest)(nil),     // 1: student.v1.CreateStudentRequest
+	(*GetStudentRequest)(nil),        // 2: student.v1.GetStudentRequest
+	(*ListStudentsRequest)(nil),      // 3: student.v1.ListStudentsRequest
+	(*ListStudentsResponse)(nil),     // 4: student.v1.ListStudentsResponse
+	(*UpdateStudentRequest)(nil),     // 5: student.v1.UpdateStudentRequest
+	(*DeleteStudentRequest)(nil),     // 6: student.v1.DeleteStudentRequest
+	(*DeleteStudentResponse)(nil),    // 7: student.v1.DeleteStudentResponse
+	(*GetStudentSummaryRequest)(nil), // 8: student.v1.GetStudentSummaryRequest
+	(*StudentSummary)(nil),           // 9: student.v1.StudentSummary
+	nil,                              // 10: student.v1.Student.ExternalIdsEntry
+}
+var file_student_proto_depIdxs = []int32{
+	10, // 0: student.v1.Student.external_ids:type_name -> student.v1.Student.ExternalIdsEntry
+	0,  // 1: student.v1.CreateStudentRequest.student:type_name -> student.v1.Student
+	0,  // 2: student.v1.ListStudentsResponse.students:type_name -> student.v1.Student
+	0,  // 3: student.v1.UpdateStudentRequest.student:type_name -> student.v1.Student
+	1,  // 4: student.v1.StudentService.CreateStudent:input_type -> student.v1.CreateStudentRequest
+	2,  // 5: student.v1.StudentService.GetStudent:input_type -> student.v1.GetStudentRequest
+	3,  // 6: student.v1.StudentService.ListStudents:input_type -> student.v1.ListStudentsRequest
+	5,  // 7: student.v1.StudentService.UpdateStudent:input_type -> student.v1.UpdateStudentRequest
+	6,  // 8: student.v1.StudentService.DeleteStudent:input_type -> student.v1.DeleteStudentRequest
+	8,  // 9: student.v1.StudentService.GetStudentSummary:input_type -> student.v1.GetStudentSummaryRequest
+	0,  // 10: student.v1.StudentService.CreateStudent:output_type -> student.v1.Student
+	0,  // 11: student.v1.StudentService.GetStudent:output_type -> student.v1.Student
+	4,  // 12: student.v1.StudentService.ListStudents:output_type -> student.v1.ListStudentsResponse
+	0,  // 13: student.v1.StudentService.UpdateStudent:output_type -> student.v1.Student
+	7,  // 14: student.v1.StudentService.DeleteStudent:output_type -> student.v1.DeleteStudentResponse
+	9,  // 15: student.v1.StudentService.GetStudentSummary:output_type -> student.v1.StudentSummary
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_student_proto_init() }
+func file_student_proto_init() {
+	if File_student_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_student_proto_rawDesc), len(file_student_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_student_proto_goTypes,
+		DependencyIndexes: file_student_proto_depIdxs,
+		MessageInfos:      file_student_proto_msgTypes,
+	}.Build()
+	File_student_proto = out.File
+	file_student_proto_goTypes = nil
+	file_student_proto_depIdxs = nil
+}
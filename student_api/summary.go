@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"student_api/student_api/internal/llm"
+)
+
+var (
+	summaries     = make(map[int]Summary)
+	summaryGen    = make(map[int]int)
+	summaryLog    = make(map[int][]Summary)
+	summariesMu   sync.Mutex
+	nextSummaryID int
+)
+
+// Supported values for the summary customization parameters
+var (
+	summaryTones     = map[string]string{"neutral": "neutral", "formal": "formal", "friendly": "warm and friendly", "encouraging": "encouraging"}
+	summaryLengths   = map[string]int{"short": 60, "medium": 150, "long": 300}
+	summaryLanguages = map[string]string{
+		"en": "English", "hi": "Hindi", "bn": "Bengali", "ta": "Tamil", "te": "Telugu", "mr": "Marathi",
+		"es": "Spanish", "fr": "French", "de": "German", "pt": "Portuguese", "zh": "Chinese", "ja": "Japanese",
+	}
+)
+
+// SummaryOptions struct to hold the parameters that shape a generated summary
+type SummaryOptions struct {
+	Model    string `json:"model"`
+	Tone     string `json:"tone,omitempty"`
+	Length   string `json:"length,omitempty"`
+	Language string `json:"language,omitempty"`
+	// Tenant whose LLM configuration produced the summary
+	Tenant string `json:"tenant,omitempty"`
+	// Structured requests a StructuredSummary instead of free text
+	Structured bool `json:"structured,omitempty"`
+}
+
+// StructuredSummary struct to hold the typed summary produced in JSON mode
+type StructuredSummary struct {
+	Profile          string   `json:"profile"`
+	Strengths        []string `json:"strengths"`
+	SuggestedActions []string `json:"suggested_actions"`
+}
+
+// ErrInvalidLLMOutput is returned when the model's reply does not match the requested structure
+var ErrInvalidLLMOutput = llm.ErrInvalidOutput
+
+// structuredSummaryInstructions is appended to the prompt in structured mode
+const structuredSummaryInstructions = `
+Respond with a single JSON object and nothing else, using exactly these keys:
+{"profile": "<short paragraph describing the student>", "strengths": ["<strength>", ...], "suggested_actions": ["<action>", ...]}`
+
+// Summary struct to hold a generated student summary
+type Summary struct {
+	ID        int    `json:"id,omitempty"`
+	StudentID int    `json:"student_id"`
+	Summary   string `json:"summary"`
+	SummaryOptions
+	Structured  *StructuredSummary `json:"structured,omitempty"`
+	Citations   []DocumentCitation `json:"citations,omitempty"`
+	ServedBy    string             `json:"served_by,omitempty"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Cached      bool               `json:"cached"`
+	Degraded    bool               `json:"degraded,omitempty"`
+}
+
+// generateStudentSummary handles GET /students/{id}/summary, serving a cached summary unless ?refresh=true
+func (s *Server) generateStudentSummary(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	// Copy the student and release the lock before the LLM call so other requests aren't blocked
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, ok := summaryFormat(r)
+	if !ok {
+		http.Error(w, "Unsupported format", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := s.studentSummary(r.Context(), student, opts, r.URL.Query().Get("refresh") == "true")
+	if errors.Is(err, ErrCircuitOpen) && s.config.DegradedMode == "template" {
+		summary, err = fallbackSummary(student), nil
+	}
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	// Respond with the summary in the negotiated format
+	writeSummary(w, format, student, summary)
+}
+
+// writeLLMError responds to a failed LLM call with a problem+json body whose status matches the cause
+func (s *Server) writeLLMError(w http.ResponseWriter, err error) {
+	var statusErr *LLMStatusError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrNoLLMConsent):
+		writeProblem(w, http.StatusForbidden, "LLM consent missing", "The student has not consented to LLM processing")
+	case errors.Is(err, ErrCircuitOpen):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(circuitRetryAfter(err).Seconds()))))
+		writeProblem(w, http.StatusServiceUnavailable, "LLM unavailable", "The summary service is temporarily unavailable")
+	case errors.Is(err, ErrStreamingUnsupported):
+		writeProblem(w, http.StatusNotImplemented, "Streaming unsupported", err.Error())
+	case errors.Is(err, ErrLLMQueueFull):
+		w.Header().Set("Retry-After", "5")
+		writeProblem(w, http.StatusTooManyRequests, "LLM busy", "The summary service is busy, try again later")
+	case errors.Is(err, ErrModerationUnavailable):
+		w.Header().Set("Retry-After", "30")
+		writeProblem(w, http.StatusServiceUnavailable, "Moderation unavailable", "The generated summary could not be checked by the content filter, try again later")
+	case errors.Is(err, ErrContentFlagged):
+		writeProblem(w, http.StatusBadGateway, "Summary withheld", "The generated summary was flagged by the content filter and is awaiting review")
+	case errors.Is(err, ErrInvalidLLMOutput):
+		writeProblem(w, http.StatusBadGateway, "Invalid LLM response", err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		writeProblem(w, http.StatusGatewayTimeout, "LLM timeout", "The LLM did not respond in time")
+	case errors.Is(err, context.Canceled):
+		writeProblem(w, http.StatusServiceUnavailable, "Request canceled", "The LLM request was canceled")
+	case errors.As(err, &statusErr):
+		detail := firstNonEmpty(statusErr.Message, http.StatusText(statusErr.StatusCode))
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			writeProblem(w, http.StatusTooManyRequests, "LLM rate limited", detail)
+		case statusErr.StatusCode == http.StatusNotFound:
+			writeProblem(w, http.StatusBadGateway, "LLM model not available", detail)
+		case statusErr.StatusCode < 500:
+			writeProblem(w, http.StatusBadGateway, "LLM rejected the request", detail)
+		default:
+			writeProblem(w, http.StatusBadGateway, "LLM error", detail)
+		}
+	case errors.As(err, &netErr):
+		writeProblem(w, http.StatusServiceUnavailable, "LLM unreachable", "Could not connect to the LLM")
+	default:
+		writeProblem(w, http.StatusInternalServerError, "Summary generation failed", "Error generating summary")
+	}
+}
+
+// summaryOptionsFromRequest reads and validates the ?model=, ?tone=, ?length= and ?language= parameters
+func (s *Server) summaryOptionsFromRequest(r *http.Request) (SummaryOptions, error) {
+	query := r.URL.Query()
+	opts := SummaryOptions{
+		Model:    query.Get("model"),
+		Tone:     query.Get("tone"),
+		Length:   query.Get("length"),
+		Language: strings.ToLower(query.Get("language")),
+		Tenant:   tenantFromContext(r.Context()),
+	}
+	if value := query.Get("structured"); value != "" {
+		structured, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, errors.New("Invalid structured flag")
+		}
+		opts.Structured = structured
+	}
+	return s.checkSummaryOptions(opts)
+}
+
+// checkSummaryOptions defaults the model to the tenant's or the configured default and rejects
+// models, tones, lengths and languages that are not allowed
+func (s *Server) checkSummaryOptions(opts SummaryOptions) (SummaryOptions, error) {
+	if opts.Model == "" {
+		opts.Model = firstNonEmpty(tenantLLMConfig(opts.Tenant).Model, s.config.DefaultModel)
+	}
+	if !containsString(s.config.AllowedModels, opts.Model) {
+		return opts, errors.New("Model not allowed")
+	}
+	if _, ok := summaryTones[opts.Tone]; opts.Tone != "" && !ok {
+		return opts, errors.New("Invalid tone")
+	}
+	if _, ok := summaryLengths[opts.Length]; opts.Length != "" && !ok {
+		return opts, errors.New("Invalid length")
+	}
+	if _, ok := summaryLanguages[opts.Language]; opts.Language != "" && !ok {
+		return opts, errors.New("Unsupported language")
+	}
+	return opts, nil
+}
+
+// studentSummary returns the cached summary for a student, generating and caching a new one when
+// none exists for the options or refresh is set
+func (s *Server) studentSummary(ctx context.Context, student Student, opts SummaryOptions, refresh bool) (Summary, error) {
+	summariesMu.Lock()
+	cached, ok := summaries[student.ID]
+	gen := summaryGen[student.ID]
+	summariesMu.Unlock()
+	if ok && cached.SummaryOptions == opts && !refresh {
+		cached.Cached = true
+		return cached, nil
+	}
+
+	resp, citations, err := s.requestSummary(ctx, student, opts)
+	if err != nil {
+		return Summary{}, err
+	}
+	text := resp.Text
+	summary := Summary{
+		StudentID:      student.ID,
+		Summary:        text,
+		SummaryOptions: opts,
+		Citations:      citations,
+		ServedBy:       resp.Provider + ":" + resp.Model,
+		GeneratedAt:    time.Now(),
+	}
+	if opts.Structured {
+		structured, err := parseStructuredSummary(text)
+		if err != nil {
+			return Summary{}, err
+		}
+		summary.Summary = structured.Profile
+		summary.Structured = structured
+	}
+
+	reason, flagged, err := s.moderateText(ctx, text)
+	if err != nil {
+		return Summary{}, err
+	}
+	if flagged {
+		quarantineSummary(summary, gen, reason)
+		return Summary{}, ErrContentFlagged
+	}
+
+	summary, _ = storeSummary(summary, gen)
+	return summary, nil
+}
+
+// storeSummary assigns the summary an ID and, if the student has not changed since generation gen
+// started, caches it and records it in the history; stored reports whether it did. A summary of
+// an older record, or of a deleted student, is still returned to its caller but kept nowhere
+func storeSummary(summary Summary, gen int) (Summary, bool) {
+	summariesMu.Lock()
+	defer summariesMu.Unlock()
+
+	nextSummaryID++
+	summary.ID = nextSummaryID
+	if summaryGen[summary.StudentID] != gen {
+		return summary, false
+	}
+	summaries[summary.StudentID] = summary
+	summaryLog[summary.StudentID] = append(summaryLog[summary.StudentID], summary)
+	return summary, true
+}
+
+// parseStructuredSummary decodes and validates a JSON-mode model reply
+func parseStructuredSummary(text string) (*StructuredSummary, error) {
+	var structured StructuredSummary
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &structured); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLLMOutput, err)
+	}
+	if strings.TrimSpace(structured.Profile) == "" {
+		return nil, fmt.Errorf("%w: missing profile", ErrInvalidLLMOutput)
+	}
+	if structured.Strengths == nil {
+		structured.Strengths = []string{}
+	}
+	if structured.SuggestedActions == nil {
+		structured.SuggestedActions = []string{}
+	}
+	return &structured, nil
+}
+
+// fallbackSummary builds a templated, non-LLM summary used while the LLM is unavailable
+func fallbackSummary(student Student) Summary {
+	return Summary{
+		StudentID:      student.ID,
+		Summary:        fmt.Sprintf("%s is a %d-year-old student who can be reached at %s.", student.Name, student.Age(), student.Email),
+		SummaryOptions: SummaryOptions{Model: "template"},
+		GeneratedAt:    time.Now(),
+		Degraded:       true,
+	}
+}
+
+// invalidateSummary drops the cached summary for a student after its record changes
+func invalidateSummary(id int) {
+	summariesMu.Lock()
+	defer summariesMu.Unlock()
+	delete(summaries, id)
+	summaryGen[id]++
+}
+
+// deleteSummaries removes the cached summary and summary history of a deleted student
+func deleteSummaries(id int) {
+	summariesMu.Lock()
+	defer summariesMu.Unlock()
+	delete(summaries, id)
+	delete(summaryLog, id)
+	summaryGen[id]++
+}
+
+// getSummaryHistory handles GET /students/{id}/summaries to list every summary generated for a student
+func (s *Server) getSummaryHistory(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	_, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	summariesMu.Lock()
+	history := append([]Summary{}, summaryLog[id]...)
+	summariesMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// requestSummary asks the configured LLM provider chain for a summary of the given student,
+// returning the attachment passages the summary was grounded in
+func (s *Server) requestSummary(ctx context.Context, student Student, opts SummaryOptions) (LLMResponse, []DocumentCitation, error) {
+	prompt, citations, err := s.summaryPrompt(ctx, student, opts)
+	if err != nil {
+		return LLMResponse{}, nil, err
+	}
+
+	if opts.Structured {
+		prompt += structuredSummaryInstructions
+	}
+
+	resp, err := s.llm.Generate(ctx, LLMRequest{Model: opts.Model, Prompt: prompt, JSON: opts.Structured})
+	return resp, citations, err
+}
+
+// summaryPrompt renders the summary prompt for a student and appends the passages of their text
+// attachments most relevant to it. A failed retrieval only drops the passages.
+func (s *Server) summaryPrompt(ctx context.Context, student Student, opts SummaryOptions) (string, []DocumentCitation, error) {
+	prompt, err := s.renderSummaryPrompt(student, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	citations, err := s.retrieveDocuments(ctx, student.ID, prompt)
+	if err != nil {
+		log.Printf("Retrieving documents for student %d failed: %v", student.ID, err)
+		return prompt, nil, nil
+	}
+	if len(citations) > 0 {
+		prompt += "\n\n" + documentContext(citations)
+	}
+	return prompt, citations, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// summaryFormats maps ?format= values to the Content-Type they produce
+var summaryFormats = map[string]string{
+	"json":     "application/json",
+	"text":     "text/plain; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+	"html":     "text/html; charset=utf-8",
+	"pdf":      "application/pdf",
+}
+
+var summaryHTMLTemplate = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Summary for {{.Name}}</title></head>
+<body>
+<h1>Summary for {{.Name}}</h1>
+<p>{{.Summary.Summary}}</p>
+{{- with .Summary.Structured}}
+<h2>Strengths</h2>
+<ul>{{range .Strengths}}<li>{{.}}</li>{{end}}</ul>
+<h2>Suggested actions</h2>
+<ul>{{range .SuggestedActions}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+<p><small>Generated by {{.Summary.Model}} at {{.Summary.GeneratedAt.Format "2006-01-02 15:04 MST"}}</small></p>
+</body>
+</html>
+`))
+
+// summaryFormat picks the output format from ?format=, falling back to the Accept header and then JSON
+func summaryFormat(r *http.Request) (string, bool) {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		_, ok := summaryFormats[format]
+		return format, ok
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/json"):
+		return "json", true
+	case strings.Contains(accept, "application/pdf"):
+		return "pdf", true
+	case strings.Contains(accept, "text/html"):
+		return "html", true
+	case strings.Contains(accept, "text/markdown"):
+		return "markdown", true
+	case strings.Contains(accept, "text/plain"):
+		return "text", true
+	}
+	return "json", true
+}
+
+// writeSummary renders a summary in the requested format
+func writeSummary(w http.ResponseWriter, format string, student Student, summary Summary) {
+	w.Header().Set("Content-Type", summaryFormats[format])
+
+	switch format {
+	case "text":
+		fmt.Fprintln(w, summaryPlainText(summary))
+	case "markdown":
+		fmt.Fprint(w, summaryMarkdown(student, summary))
+	case "html":
+		summaryHTMLTemplate.Execute(w, map[string]interface{}{"Name": student.Name, "Summary": summary})
+	case "pdf":
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"student-%d-summary.pdf\"", student.ID))
+		w.Write(renderPDF("Summary for "+student.Name, strings.Split(summaryPlainText(summary), "\n\n")))
+	default:
+		json.NewEncoder(w).Encode(summary)
+	}
+}
+
+// summaryPlainText renders a summary as plain text paragraphs
+func summaryPlainText(summary Summary) string {
+	text := summary.Summary
+	if s := summary.Structured; s != nil {
+		text += "\n\nStrengths:\n- " + strings.Join(s.Strengths, "\n- ")
+		text += "\n\nSuggested actions:\n- " + strings.Join(s.SuggestedActions, "\n- ")
+	}
+	return text
+}
+
+// summaryMarkdown renders a summary as a Markdown document
+func summaryMarkdown(student Student, summary Summary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Summary for %s\n\n%s\n", student.Name, summary.Summary)
+	if s := summary.Structured; s != nil {
+		b.WriteString("\n## Strengths\n\n")
+		for _, item := range s.Strengths {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+		b.WriteString("\n## Suggested actions\n\n")
+		for _, item := range s.SuggestedActions {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+	}
+	fmt.Fprintf(&b, "\n_Generated by %s at %s_\n", summary.Model, summary.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// startSummaryRefresh schedules regeneration of stale summaries when SUMMARY_REFRESH_SCHEDULE is set
+func (s *Server) startSummaryRefresh() error {
+	if s.config.SummaryRefreshSchedule == "" {
+		return nil
+	}
+
+	schedule, err := ParseCron(s.config.SummaryRefreshSchedule)
+	if err != nil {
+		return err
+	}
+	runOnSchedule("summary-refresh", schedule, s.refreshStaleSummaries)
+	return nil
+}
+
+// refreshStaleSummaries regenerates the summary of every student summarized before whose summary
+// was invalidated by a record change or is older than SUMMARY_MAX_AGE, with the options and tenant
+// of the cached summary, or of the last one generated when the cache was invalidated; students
+// never summarized are left for their first request
+func (s *Server) refreshStaleSummaries() error {
+	s.store.Lock()
+	var all []Student
+	for _, student := range s.store.List() {
+		all = append(all, student)
+	}
+	s.store.Unlock()
+
+	refreshed, failed := 0, 0
+	for _, student := range all {
+		summariesMu.Lock()
+		cached, ok := summaries[student.ID]
+		history := summaryLog[student.ID]
+		summariesMu.Unlock()
+		if len(history) == 0 || ok && time.Since(cached.GeneratedAt) < s.config.SummaryMaxAge {
+			continue
+		}
+		opts := history[len(history)-1].SummaryOptions
+		if ok {
+			opts = cached.SummaryOptions
+		}
+
+		ctx := context.WithValue(context.Background(), tenantContextKey, opts.Tenant)
+		if _, err := s.studentSummary(ctx, student, opts, true); err != nil {
+			failed++
+			log.Printf("Error refreshing summary for student %d: %v", student.ID, err)
+			continue
+		}
+		refreshed++
+	}
+
+	log.Printf("Summary refresh finished: %d refreshed, %d failed", refreshed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d summaries could not be refreshed", failed, refreshed+failed)
+	}
+	return nil
+}
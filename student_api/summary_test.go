@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingProvider holds every call open until release is closed, signalling started as each
+// call begins
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+// unblock releases the calls held open, now and in the future
+func (p *blockingProvider) unblock() {
+	p.once.Do(func() { close(p.release) })
+}
+
+func (p *blockingProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	select {
+	case p.started <- struct{}{}:
+	default:
+	}
+	select {
+	case <-p.release:
+		return LLMResponse{Model: req.Model, Text: "Ann is doing well."}, nil
+	case <-ctx.Done():
+		return LLMResponse{}, ctx.Err()
+	}
+}
+
+func TestSummaryDoesNotBlockOtherRequests(t *testing.T) {
+	provider := &blockingProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	srv := newTestServer(t, provider)
+	// Registered after the server's cleanup, so it runs first and a failed test does not leave
+	// the server waiting on the held call
+	t.Cleanup(provider.unblock)
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+
+	summaryStatus := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(srv.URL + "/students/1/summary?refresh=true")
+		if err != nil {
+			summaryStatus <- 0
+			return
+		}
+		resp.Body.Close()
+		summaryStatus <- resp.StatusCode
+	}()
+
+	select {
+	case <-provider.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("summary request never reached the provider")
+	}
+
+	// The summary call is now held open; listing students must not wait for it
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(srv.URL + "/students")
+	if err != nil {
+		t.Fatalf("GET /students while a summary is generated: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /students while a summary is generated: %d", resp.StatusCode)
+	}
+
+	provider.unblock()
+	select {
+	case status := <-summaryStatus:
+		if status != http.StatusOK {
+			t.Errorf("GET /students/1/summary: %d", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("summary request did not finish after the provider was released")
+	}
+}
+
+func TestRefreshStaleSummariesSkipsUnsummarizedStudents(t *testing.T) {
+	provider := &recordingProvider{}
+	s := newServer(t, provider)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	for _, body := range []string{
+		`{"name":"Ann Lee","email":"ann@example.com","age":20}`,
+		`{"name":"Ben Ray","email":"ben@example.com","age":21}`,
+	} {
+		if status, resp := request(t, "POST", srv.URL+"/students", body, nil); status != http.StatusCreated {
+			t.Fatalf("POST /students: %d %s", status, resp)
+		}
+	}
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", nil); status != http.StatusOK {
+		t.Fatalf("GET /students/1/summary: %d %s", status, body)
+	}
+	invalidateSummary(1)
+
+	provider.mu.Lock()
+	provider.requests = nil
+	provider.mu.Unlock()
+	if err := s.refreshStaleSummaries(); err != nil {
+		t.Fatalf("refreshStaleSummaries: %v", err)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if len(provider.requests) != 1 || !strings.Contains(provider.requests[0].Prompt, "Ann Lee") {
+		t.Errorf("refresh sent %d requests, want one for the invalidated summary", len(provider.requests))
+	}
+	for _, req := range provider.requests {
+		if strings.Contains(req.Prompt, "Ben Ray") {
+			t.Error("refresh summarized a student who was never summarized")
+		}
+	}
+}
+
+func TestGroupTaggingInvalidatesSummaries(t *testing.T) {
+	provider := &recordingProvider{}
+	srv := newTestServer(t, provider)
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	// Summaries are kept per student ID across servers, so later tests must not find this one
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/students/1", "", nil) })
+	status, body := request(t, "POST", srv.URL+"/groups", `{"name":"Chess club"}`, nil)
+	var group Group
+	if status != http.StatusCreated || json.Unmarshal([]byte(body), &group) != nil {
+		t.Fatalf("POST /groups: %d %s", status, body)
+	}
+	groupURL := srv.URL + "/groups/" + strconv.Itoa(group.ID)
+	if status, body := request(t, "POST", groupURL+"/members", `{"student_ids":[1]}`, nil); status != http.StatusOK {
+		t.Fatalf("POST %s/members: %d %s", groupURL, status, body)
+	}
+
+	summarize := func() int {
+		if status, body := request(t, "GET", srv.URL+"/students/1/summary", "", nil); status != http.StatusOK {
+			t.Fatalf("GET /students/1/summary: %d %s", status, body)
+		}
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
+		return len(provider.requests)
+	}
+	first := summarize()
+	if again := summarize(); again != first {
+		t.Fatalf("cached summary was generated again")
+	}
+
+	if status, body := request(t, "POST", groupURL+"/tags", `{"add":["chess"]}`, nil); status != http.StatusOK || !strings.Contains(body, "chess") {
+		t.Fatalf("POST %s/tags: %d %s", groupURL, status, body)
+	}
+	if after := summarize(); after == first {
+		t.Error("summary was served from the cache after the student's tags changed")
+	}
+}
+
+// moderatedProvider answers summaries like recordingProvider and fails every call to the
+// moderation model
+type moderatedProvider struct {
+	recordingProvider
+}
+
+func (p *moderatedProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	if req.Model == "moderator" {
+		return LLMResponse{}, &LLMStatusError{StatusCode: http.StatusInternalServerError}
+	}
+	return p.recordingProvider.Generate(ctx, req)
+}
+
+func TestModerationOutageIsNotQuarantined(t *testing.T) {
+	srv := newTestServer(t, &moderatedProvider{}, func(cfg *Config) {
+		cfg.ModerationModel = "moderator"
+		cfg.LLMRetries = 0
+	})
+	admin := map[string]string{"Authorization": "Bearer test-admin"}
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/students/1", "", nil) })
+
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", nil); status != http.StatusServiceUnavailable {
+		t.Errorf("summary while the moderation model fails: %d %s, want 503", status, body)
+	}
+	if status, body := request(t, "GET", srv.URL+"/admin/moderation/quarantine", "", admin); status != http.StatusOK || body != "[]\n" {
+		t.Errorf("quarantine after a moderation outage: %d %s, want empty", status, body)
+	}
+}
+
+func TestQuarantinedSummaryOfChangedStudent(t *testing.T) {
+	srv := newTestServer(t, &recordingProvider{}, func(cfg *Config) {
+		cfg.ModerationBlocklist = []string{"doing well"}
+	})
+	admin := map[string]string{"Authorization": "Bearer test-admin"}
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/students/1", "", nil) })
+	quarantined := func() []QuarantinedSummary {
+		status, body := request(t, "GET", srv.URL+"/admin/moderation/quarantine", "", admin)
+		var items []QuarantinedSummary
+		if status != http.StatusOK || json.Unmarshal([]byte(body), &items) != nil {
+			t.Fatalf("GET /admin/moderation/quarantine: %d %s", status, body)
+		}
+		return items
+	}
+
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", nil); status != http.StatusBadGateway {
+		t.Fatalf("flagged summary: %d %s, want 502", status, body)
+	}
+	items := quarantined()
+	if len(items) != 1 {
+		t.Fatalf("quarantine holds %d summaries, want 1", len(items))
+	}
+	if status, body := request(t, "PUT", srv.URL+"/students/1", `{"name":"Ann Lee-Ray"}`, nil); status != http.StatusOK {
+		t.Fatalf("PUT /students/1: %d %s", status, body)
+	}
+	approve := srv.URL + "/admin/moderation/quarantine/" + strconv.Itoa(items[0].ID) + "/approve"
+	if status, body := request(t, "POST", approve, "", admin); status != http.StatusConflict {
+		t.Errorf("approving a summary of a changed student: %d %s, want 409", status, body)
+	}
+	if status, body := request(t, "GET", srv.URL+"/students/1/summaries", "", nil); status != http.StatusOK || body != "[]\n" {
+		t.Errorf("summary history after the rejected approval: %d %s, want empty", status, body)
+	}
+
+	request(t, "GET", srv.URL+"/students/1/summary?refresh=true", "", nil)
+	if status, body := request(t, "DELETE", srv.URL+"/students/1", "", nil); status != http.StatusNoContent {
+		t.Fatalf("DELETE /students/1: %d %s", status, body)
+	}
+	if items := quarantined(); len(items) != 0 {
+		t.Errorf("quarantine still holds %d summaries of the deleted student", len(items))
+	}
+}
+
+func TestSummaryJobReportsQueuePosition(t *testing.T) {
+	srv := newTestServer(t, &recordingProvider{})
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/students/1", "", nil) })
+
+	// No workers run in tests, so the jobs stay queued behind any queued earlier
+	type queuedJob struct {
+		JobID         int `json:"job_id"`
+		QueuePosition int `json:"queue_position"`
+	}
+	var queued []queuedJob
+	for range 2 {
+		status, body := request(t, "POST", srv.URL+"/students/1/summary", "", nil)
+		var job queuedJob
+		if status != http.StatusAccepted || json.Unmarshal([]byte(body), &job) != nil {
+			t.Fatalf("POST /students/1/summary: %d %s", status, body)
+		}
+		queued = append(queued, job)
+	}
+	if queued[0].QueuePosition < 1 || queued[1].QueuePosition != queued[0].QueuePosition+1 {
+		t.Errorf("queue positions %d and %d, want consecutive", queued[0].QueuePosition, queued[1].QueuePosition)
+	}
+
+	status, body := request(t, "GET", srv.URL+"/jobs/"+strconv.Itoa(queued[1].JobID), "", nil)
+	var job Job
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &job) != nil || job.QueuePosition != queued[1].QueuePosition {
+		t.Errorf("GET /jobs/%d: %d %s, want queue position %d", queued[1].JobID, status, body, queued[1].QueuePosition)
+	}
+	if status, body := request(t, "POST", srv.URL+"/jobs/"+strconv.Itoa(queued[0].JobID)+"/cancel", "", nil); status != http.StatusOK {
+		t.Fatalf("POST /jobs/%d/cancel: %d %s", queued[0].JobID, status, body)
+	}
+	status, body = request(t, "GET", srv.URL+"/jobs/"+strconv.Itoa(queued[1].JobID), "", nil)
+	if json.Unmarshal([]byte(body), &job) != nil || job.QueuePosition != queued[0].QueuePosition {
+		t.Errorf("after the job ahead was canceled: %d %s, want queue position %d", status, body, queued[0].QueuePosition)
+	}
+}
+
+func TestRefreshStaleSummariesKeepsOptions(t *testing.T) {
+	provider := &recordingProvider{}
+	s := newServer(t, provider, func(cfg *Config) {
+		cfg.AllowedModels = append(cfg.AllowedModels, "mistral")
+	})
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	if status, body := request(t, "POST", srv.URL+"/students", `{"name":"Ann Lee","email":"ann@example.com","age":20}`, nil); status != http.StatusCreated {
+		t.Fatalf("POST /students: %d %s", status, body)
+	}
+	t.Cleanup(func() { request(t, "DELETE", srv.URL+"/students/1", "", nil) })
+	if status, body := request(t, "GET", srv.URL+"/students/1/summary?refresh=true&model=mistral&tone=formal", "", nil); status != http.StatusOK {
+		t.Fatalf("GET /students/1/summary: %d %s", status, body)
+	}
+	invalidateSummary(1)
+
+	if err := s.refreshStaleSummaries(); err != nil {
+		t.Fatalf("refreshStaleSummaries: %v", err)
+	}
+	status, body := request(t, "GET", srv.URL+"/students/1/summary?model=mistral&tone=formal", "", nil)
+	var summary Summary
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &summary) != nil {
+		t.Fatalf("GET /students/1/summary: %d %s", status, body)
+	}
+	if !summary.Cached {
+		t.Error("refresh did not regenerate the summary with the options it was requested with")
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if last := provider.requests[len(provider.requests)-1]; last.Model != "mistral" {
+		t.Errorf("refresh asked model %q, want mistral", last.Model)
+	}
+}
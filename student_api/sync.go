@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SyncChange struct to hold one create, update or delete planned by a roster sync; Fields lists
+// the JSON fields an update changes and Error why the change was rejected or failed
+type SyncChange struct {
+	Action    string   `json:"action"`
+	Key       string   `json:"key"`
+	StudentID int      `json:"student_id,omitempty"`
+	Fields    []string `json:"fields,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// SyncResult struct to hold the response of PUT /students/sync; the counts describe the planned
+// changes until Applied is set
+type SyncResult struct {
+	DryRun    bool         `json:"dry_run"`
+	Applied   bool         `json:"applied"`
+	Key       string       `json:"key"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Deleted   int          `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+	Failed    int          `json:"failed"`
+	Changes   []SyncChange `json:"changes"`
+}
+
+// SyncOptions struct to hold how a roster sync matches and applies changes; Partial applies the
+// valid changes even when others are invalid and KeepMissing never deletes students absent from
+// the desired list
+type SyncOptions struct {
+	Key         string
+	DryRun      bool
+	Partial     bool
+	KeepMissing bool
+}
+
+// syncKey returns the value a roster sync matches a student on: the lower-cased email, or the
+// student's ID in another system when key is "external:<system>"
+func syncKey(student Student, key string) string {
+	if system, ok := strings.CutPrefix(key, "external:"); ok {
+		return student.ExternalIDs[system]
+	}
+	return strings.ToLower(strings.TrimSpace(student.Email))
+}
+
+// sameAddress reports whether two addresses are equal, ignoring their geocoded locations
+func sameAddress(a, b *Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.Location, y.Location = nil, nil
+	return x == y
+}
+
+// changedStudentFields lists the JSON names of the fields that differ between two students
+func changedStudentFields(before, after Student) []string {
+	fields := []string{}
+	if before.RecordedAge != after.RecordedAge && after.DateOfBirth == "" {
+		fields = append(fields, "age")
+	}
+	beforeValue, afterValue := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < beforeValue.NumField(); i++ {
+		field := beforeValue.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" || reflect.DeepEqual(beforeValue.Field(i).Interface(), afterValue.Field(i).Interface()) {
+			continue
+		}
+		if field.Name == "Address" && sameAddress(before.Address, after.Address) {
+			continue
+		}
+		fields = append(fields, strings.Split(field.Tag.Get("json"), ",")[0])
+	}
+	return fields
+}
+
+// planStudentSync diffs a desired roster against the stored students; desired students must
+// already be normalized and carry unique keys. Updates only cover the fields a desired record
+// sets, as with PUT /students/{id}, and alumni are never planned for deletion
+func (s *Server) planStudentSync(desired []Student, opts SyncOptions) []SyncChange {
+	s.store.Lock()
+	defer s.store.Unlock()
+
+	current := make(map[string]Student, s.store.Len())
+	for _, student := range s.store.List() {
+		if k := syncKey(student, opts.Key); k != "" {
+			current[k] = student
+		}
+	}
+
+	changes := []SyncChange{}
+	wanted := make(map[string]bool, len(desired))
+	for i := range desired {
+		k := syncKey(desired[i], opts.Key)
+		wanted[k] = true
+		existing, exists := current[k]
+		if !exists {
+			change := SyncChange{Action: "create", Key: k}
+			if err := validateStudent(desired[i]); err != nil {
+				change.Error = err.Error()
+			} else if err := s.externalIDConflict(desired[i]); err != nil {
+				change.Error = err.Error()
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		if sameAddress(existing.Address, desired[i].Address) {
+			desired[i].Address = nil
+		}
+		merged := existing
+		mergeStudentUpdate(&merged, desired[i])
+		normalizeStudent(&merged)
+		fields := changedStudentFields(existing, merged)
+		if len(fields) == 0 {
+			changes = append(changes, SyncChange{Action: "unchanged", Key: k, StudentID: existing.ID})
+			continue
+		}
+		change := SyncChange{Action: "update", Key: k, StudentID: existing.ID, Fields: fields}
+		if err := validateStudent(merged); err != nil {
+			change.Error = err.Error()
+		} else if err := s.externalIDConflict(merged); err != nil {
+			change.Error = err.Error()
+		}
+		changes = append(changes, change)
+	}
+
+	deletes := []SyncChange{}
+	for k, student := range current {
+		if !wanted[k] && !student.Alumni && !opts.KeepMissing {
+			deletes = append(deletes, SyncChange{Action: "delete", Key: k, StudentID: student.ID})
+		}
+	}
+	sort.Slice(deletes, func(i, j int) bool { return deletes[i].StudentID < deletes[j].StudentID })
+	return append(changes, deletes...)
+}
+
+// applyStudentSync plans a roster sync and, unless it is a dry run, applies it through the same
+// create, update and delete paths as the single-student routes. Nothing is applied when a change
+// is invalid unless opts.Partial is set
+func (s *Server) applyStudentSync(desired []Student, opts SyncOptions) SyncResult {
+	result := SyncResult{DryRun: opts.DryRun, Key: opts.Key, Changes: s.planStudentSync(desired, opts)}
+	invalid := false
+	for _, change := range result.Changes {
+		invalid = invalid || change.Error != ""
+	}
+	result.Applied = !opts.DryRun && (opts.Partial || !invalid)
+
+	byKey := make(map[string]Student, len(desired))
+	for _, student := range desired {
+		byKey[syncKey(student, opts.Key)] = student
+	}
+	for i := range result.Changes {
+		change := &result.Changes[i]
+		if result.Applied && change.Error == "" {
+			var err error
+			switch change.Action {
+			case "create":
+				var created Student
+				created, err = s.students.Create(byKey[change.Key])
+				change.StudentID = created.ID
+			case "update":
+				_, err = s.students.Update(change.StudentID, byKey[change.Key])
+			case "delete":
+				err = s.students.Delete(change.StudentID, false)
+			}
+			if err != nil {
+				change.Error = err.Error()
+			}
+		}
+		switch {
+		case change.Error != "":
+			result.Failed++
+		case change.Action == "create":
+			result.Created++
+		case change.Action == "update":
+			result.Updated++
+		case change.Action == "delete":
+			result.Deleted++
+		default:
+			result.Unchanged++
+		}
+	}
+	return result
+}
+
+// syncStudents handles PUT /students/sync?key=&dry_run= to converge the roster on a desired list
+// of students: students missing from the store are created, those whose fields differ are
+// updated and stored students absent from the list are deleted. Students are matched on email,
+// or on an external ID with key=external:<system>; students the key cannot identify are left
+// alone. Nothing is applied when any change is invalid, and dry_run=true only returns the diff.
+// Restricted to the admin role since a short list deletes the rest of the roster
+func (s *Server) syncStudents(w http.ResponseWriter, r *http.Request) {
+	if s.callerRole(r) != "admin" {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	opts := SyncOptions{Key: strings.ToLower(strings.TrimSpace(r.URL.Query().Get("key"))), DryRun: r.URL.Query().Get("dry_run") == "true"}
+	if opts.Key == "" {
+		opts.Key = "email"
+	}
+	if system, ok := strings.CutPrefix(opts.Key, "external:"); opts.Key != "email" && (!ok || system == "") {
+		http.Error(w, "key must be email or external:<system>", http.StatusBadRequest)
+		return
+	}
+
+	var desired []Student
+	if err := json.NewDecoder(r.Body).Decode(&desired); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	seen := make(map[string]bool, len(desired))
+	for i := range desired {
+		normalizeStudent(&desired[i])
+		k := syncKey(desired[i], opts.Key)
+		if k == "" {
+			http.Error(w, "Every student needs a value for the sync key "+opts.Key, http.StatusBadRequest)
+			return
+		}
+		if seen[k] {
+			http.Error(w, "Duplicate sync key "+k, http.StatusBadRequest)
+			return
+		}
+		seen[k] = true
+	}
+
+	result := s.applyStudentSync(desired, opts)
+	status := http.StatusOK
+	if result.Failed > 0 && !result.Applied {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// SystemPromptVersion struct to hold one version of the deployment system prompt
+type SystemPromptVersion struct {
+	Version   int       `json:"version"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	Active    bool      `json:"active"`
+}
+
+// SystemPrompts struct to hold every version of the deployment system prompt and which one is
+// active (0 for none)
+type SystemPrompts struct {
+	mu       sync.Mutex
+	versions []SystemPromptVersion
+	active   int
+}
+
+// newSystemPrompts seeds version 1 from text (LLM_SYSTEM_PROMPT) when set
+func newSystemPrompts(text string) *SystemPrompts {
+	prompts := &SystemPrompts{}
+	if text != "" {
+		prompts.versions = []SystemPromptVersion{{Version: 1, Text: text, CreatedAt: time.Now()}}
+		prompts.active = 1
+	}
+	return prompts
+}
+
+// current returns the text of the active system prompt version, if any
+func (p *SystemPrompts) current() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == 0 {
+		return ""
+	}
+	return p.versions[p.active-1].Text
+}
+
+// SystemPromptProvider prepends the active deployment system prompt to every request
+type SystemPromptProvider struct {
+	Provider LLMProvider
+	Prompts  *SystemPrompts
+}
+
+// Generate adds the system prompt ahead of any request-specific system prompt
+func (p *SystemPromptProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	if system := p.Prompts.current(); system != "" {
+		req.System = strings.TrimSpace(system + "\n\n" + req.System)
+	}
+	return p.Provider.Generate(ctx, req)
+}
+
+// Stream adds the system prompt like Generate and relays the wrapped provider's stream
+func (p *SystemPromptProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	if system := p.Prompts.current(); system != "" {
+		req.System = strings.TrimSpace(system + "\n\n" + req.System)
+	}
+	return streamFrom(ctx, p.Provider, req, chunks)
+}
+
+// Embed embeds text with the wrapped provider; the system prompt only applies to generation
+func (p *SystemPromptProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	return embedFrom(ctx, p.Provider, model, text)
+}
+
+// getSystemPrompt handles GET /admin/llm/system-prompt to list every version, marking the active one
+func (s *Server) getSystemPrompt(w http.ResponseWriter, r *http.Request) {
+	prompts := s.systemPrompts
+	prompts.mu.Lock()
+	versions := make([]SystemPromptVersion, len(prompts.versions))
+	copy(versions, prompts.versions)
+	active := prompts.active
+	prompts.mu.Unlock()
+
+	for i := range versions {
+		versions[i].Active = versions[i].Version == active
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// createSystemPrompt handles POST /admin/llm/system-prompt to add and activate a new version
+func (s *Server) createSystemPrompt(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	prompts := s.systemPrompts
+	prompts.mu.Lock()
+	version := SystemPromptVersion{Version: len(prompts.versions) + 1, Text: strings.TrimSpace(req.Text), CreatedAt: time.Now(), Active: true}
+	prompts.versions = append(prompts.versions, version)
+	prompts.active = version.Version
+	prompts.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+// activateSystemPrompt handles POST /admin/llm/system-prompt/{version}/activate to roll back or
+// forward to an existing version
+func (s *Server) activateSystemPrompt(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	prompts := s.systemPrompts
+	prompts.mu.Lock()
+	defer prompts.mu.Unlock()
+
+	if version < 1 || version > len(prompts.versions) {
+		http.Error(w, "System prompt version not found", http.StatusNotFound)
+		return
+	}
+	prompts.active = version
+
+	activated := prompts.versions[version-1]
+	activated.Active = true
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activated)
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"student_api/student_api/internal/handlers"
+)
+
+// teachersMu guards teachers; take it after the student store when both are needed
+var (
+	teachers      = make(map[int]Teacher)
+	teachersMu    sync.Mutex
+	nextTeacherID int
+)
+
+// Teacher struct to hold teacher data
+type Teacher struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Department string `json:"department,omitempty"`
+}
+
+// validateTeacher checks the fields a client may set on a teacher
+func validateTeacher(teacher Teacher) error {
+	if strings.TrimSpace(teacher.Name) == "" || !strings.Contains(teacher.Email, "@") {
+		return errors.New("Invalid teacher data")
+	}
+	return nil
+}
+
+// teacherByID returns a copy of a teacher, reporting whether it exists
+func teacherByID(id int) (Teacher, bool) {
+	teachersMu.Lock()
+	defer teachersMu.Unlock()
+	teacher, exists := teachers[id]
+	return teacher, exists
+}
+
+// teacherIDFromRequest parses the {id} route variable of a teacher route
+func teacherIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid teacher ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// createTeacher handles POST /teachers to create a new teacher
+func createTeacher(w http.ResponseWriter, r *http.Request) {
+	var teacher Teacher
+	if err := json.NewDecoder(r.Body).Decode(&teacher); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateTeacher(teacher); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	teachersMu.Lock()
+	nextTeacherID++
+	teacher.ID = nextTeacherID
+	teachers[teacher.ID] = teacher
+	teachersMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(teacher)
+}
+
+// getAllTeachers handles GET /teachers to fetch all teachers
+func getAllTeachers(w http.ResponseWriter, r *http.Request) {
+	teachersMu.Lock()
+	all := []Teacher{}
+	for _, teacher := range teachers {
+		all = append(all, teacher)
+	}
+	teachersMu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(all)
+}
+
+// getTeacherByID handles GET /teachers/{id} to fetch a teacher by ID
+func getTeacherByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := teacherIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	teacher, exists := teacherByID(id)
+	if !exists {
+		http.Error(w, "Teacher not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teacher)
+}
+
+// updateTeacher handles PUT /teachers/{id} to update a teacher by ID
+func (s *Server) updateTeacher(w http.ResponseWriter, r *http.Request) {
+	id, ok := teacherIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var updated Teacher
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	teachersMu.Lock()
+	defer teachersMu.Unlock()
+
+	teacher, exists := teachers[id]
+	if !exists {
+		http.Error(w, "Teacher not found", http.StatusNotFound)
+		return
+	}
+
+	// Update fields
+	if updated.Name != "" {
+		teacher.Name = updated.Name
+	}
+	if updated.Email != "" {
+		teacher.Email = updated.Email
+	}
+	if updated.Department != "" {
+		teacher.Department = updated.Department
+	}
+	if err := validateTeacher(teacher); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	teachers[id] = teacher
+
+	// Advisees' summaries mention the teacher
+	for _, student := range s.store.List() {
+		if student.AdvisorID == id {
+			invalidateSummary(student.ID)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teacher)
+}
+
+// deleteTeacher handles DELETE /teachers/{id} to delete a teacher who advises no students
+func (s *Server) deleteTeacher(w http.ResponseWriter, r *http.Request) {
+	id, ok := teacherIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	teachersMu.Lock()
+	defer teachersMu.Unlock()
+
+	if _, exists := teachers[id]; !exists {
+		http.Error(w, "Teacher not found", http.StatusNotFound)
+		return
+	}
+	for _, student := range s.store.List() {
+		if student.AdvisorID == id {
+			http.Error(w, "Teacher still advises students", http.StatusConflict)
+			return
+		}
+	}
+
+	delete(teachers, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getTeacherStudents handles GET /teachers/{id}/students to list the students a teacher advises
+func (s *Server) getTeacherStudents(w http.ResponseWriter, r *http.Request) {
+	id, ok := teacherIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := teacherByID(id); !exists {
+		http.Error(w, "Teacher not found", http.StatusNotFound)
+		return
+	}
+
+	s.store.Lock()
+	advisees := []Student{}
+	for _, student := range s.store.List() {
+		if student.AdvisorID == id {
+			advisees = append(advisees, student)
+		}
+	}
+	s.store.Unlock()
+
+	sort.Slice(advisees, func(i, j int) bool { return advisees[i].ID < advisees[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(advisees)
+}
+
+// setStudentAdvisor handles PUT /students/{id}/advisor to assign a teacher as a student's
+// advisor; a teacher_id of 0 removes the assignment
+func (s *Server) setStudentAdvisor(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	var req struct {
+		TeacherID int `json:"teacher_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if _, exists := teacherByID(req.TeacherID); req.TeacherID != 0 && !exists {
+		http.Error(w, "Teacher not found", http.StatusNotFound)
+		return
+	}
+
+	student, err := s.students.Change(id, func(student *Student) error {
+		student.AdvisorID = req.TeacherID
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), handlers.StatusCode(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withComputed(student))
+}
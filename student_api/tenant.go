@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"text/template"
+
+	"github.com/gorilla/mux"
+)
+
+// tenantContextKey stores the caller's tenant on the request context
+const tenantContextKey contextKey = "tenant"
+
+var (
+	tenantLLM   = make(map[string]*tenantLLMEntry)
+	tenantLLMMu sync.Mutex
+)
+
+// TenantLLMConfig struct to hold a tenant's LLM settings; APIKey is write-only
+type TenantLLMConfig struct {
+	Provider       string `json:"provider,omitempty"`
+	BaseURL        string `json:"base_url,omitempty"`
+	Model          string `json:"model,omitempty"`
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	APIKey         string `json:"api_key,omitempty"`
+	HasAPIKey      bool   `json:"has_api_key"`
+}
+
+// tenantLLMEntry holds a tenant's settings with the API key encrypted, plus the parsed prompt
+// template and the provider built on the tenant's first call, with the tenant's own breaker
+type tenantLLMEntry struct {
+	config          TenantLLMConfig
+	encryptedAPIKey string
+	template        *template.Template
+	provider        LLMProvider
+}
+
+// withTenant attaches the tenant API_KEY_TENANTS assigns to the caller's X-API-Key to the request
+// context; it must run after withAPIKey
+func (s *Server) withTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), tenantContextKey, s.tenantForKey(apiKeyFromContext(r.Context())))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// tenantForKey returns the tenant of an API key, or "" for the default tenant
+func (s *Server) tenantForKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	return s.config.APIKeyTenants[apiKey]
+}
+
+// tenantFromContext returns the tenant stored on ctx, or "" for the default tenant
+func tenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey).(string)
+	return tenant
+}
+
+// tenantLLMConfig returns a tenant's settings without the API key
+func tenantLLMConfig(tenant string) TenantLLMConfig {
+	tenantLLMMu.Lock()
+	defer tenantLLMMu.Unlock()
+	if entry, ok := tenantLLM[tenant]; ok && tenant != "" {
+		return entry.config
+	}
+	return TenantLLMConfig{}
+}
+
+// tenantPromptTemplate returns a tenant's prompt template, or nil to use the deployment default
+func tenantPromptTemplate(tenant string) *template.Template {
+	tenantLLMMu.Lock()
+	defer tenantLLMMu.Unlock()
+	if entry, ok := tenantLLM[tenant]; ok && tenant != "" {
+		return entry.template
+	}
+	return nil
+}
+
+// TenantProvider routes calls to the caller's tenant provider when Lookup returns one
+type TenantProvider struct {
+	Default LLMProvider
+	Lookup  func(tenant string) (LLMProvider, string, error)
+}
+
+// Generate calls the tenant's provider, or the default provider chain
+func (p *TenantProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	tenant := tenantFromContext(ctx)
+	provider, name, err := p.Lookup(tenant)
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	if provider == nil {
+		return p.Default.Generate(ctx, req)
+	}
+
+	resp, err := provider.Generate(ctx, req)
+	resp.Provider = "tenant:" + tenant + ":" + name
+	return resp, err
+}
+
+// Stream relays the stream of the tenant's provider, or of the default provider chain
+func (p *TenantProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	tenant := tenantFromContext(ctx)
+	provider, name, err := p.Lookup(tenant)
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	if provider == nil {
+		return streamFrom(ctx, p.Default, req, chunks)
+	}
+
+	resp, err := streamFrom(ctx, provider, req, chunks)
+	resp.Provider = "tenant:" + tenant + ":" + name
+	return resp, err
+}
+
+// Embed embeds text with the default provider chain whatever the tenant, so every stored vector
+// comes from the same model and can be compared with the others
+func (p *TenantProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	return embedFrom(ctx, p.Default, model, text)
+}
+
+// tenantProvider returns a tenant's provider and its name, or nil when the tenant uses the
+// default chain. The provider is built on the tenant's first call, decrypting its credentials,
+// with the retries of its LLM retry policy and a breaker of its own, and kept until its settings
+// are replaced or deleted
+func (s *Server) tenantProvider(tenant string) (LLMProvider, string, error) {
+	tenantLLMMu.Lock()
+	defer tenantLLMMu.Unlock()
+	entry, ok := tenantLLM[tenant]
+	if !ok || tenant == "" || entry.config.Provider == "" {
+		return nil, "", nil
+	}
+	if entry.provider != nil {
+		return entry.provider, entry.config.Provider, nil
+	}
+
+	apiKey := ""
+	if entry.encryptedAPIKey != "" {
+		var err error
+		if apiKey, err = s.decryptSecret(entry.encryptedAPIKey); err != nil {
+			return nil, "", err
+		}
+	}
+	provider, err := newBaseProvider(s.config, entry.config.Provider, entry.config.BaseURL, apiKey)
+	if err != nil {
+		return nil, "", err
+	}
+	entry.provider = s.newCircuitBreaker(provider)
+	return entry.provider, entry.config.Provider, nil
+}
+
+// getTenantLLMConfig handles GET /admin/tenants/{tenant}/llm
+func getTenantLLMConfig(w http.ResponseWriter, r *http.Request) {
+	tenant := mux.Vars(r)["tenant"]
+
+	tenantLLMMu.Lock()
+	entry, ok := tenantLLM[tenant]
+	tenantLLMMu.Unlock()
+	if !ok {
+		http.Error(w, "Tenant LLM configuration not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry.config)
+}
+
+// putTenantLLMConfig handles PUT /admin/tenants/{tenant}/llm to set a tenant's provider, model,
+// prompt template and credentials; the API key is stored encrypted and never returned
+func (s *Server) putTenantLLMConfig(w http.ResponseWriter, r *http.Request) {
+	tenant := mux.Vars(r)["tenant"]
+
+	var cfg TenantLLMConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	entry := &tenantLLMEntry{}
+	if cfg.PromptTemplate != "" {
+		tmpl, err := template.New("summary").Parse(cfg.PromptTemplate)
+		if err != nil {
+			http.Error(w, "Invalid prompt template: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		entry.template = tmpl
+	}
+	if cfg.APIKey != "" {
+		encrypted, err := s.encryptSecret(cfg.APIKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		entry.encryptedAPIKey = encrypted
+	}
+	if cfg.Model != "" && !containsString(s.config.AllowedModels, cfg.Model) {
+		http.Error(w, "Model not allowed", http.StatusBadRequest)
+		return
+	}
+	if cfg.Provider != "" {
+		if _, err := newBaseProvider(s.config, cfg.Provider, cfg.BaseURL, ""); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	cfg.HasAPIKey = cfg.APIKey != ""
+	cfg.APIKey = ""
+	entry.config = cfg
+
+	tenantLLMMu.Lock()
+	tenantLLM[tenant] = entry
+	tenantLLMMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cfg)
+}
+
+// deleteTenantLLMConfig handles DELETE /admin/tenants/{tenant}/llm to revert a tenant to the defaults
+func deleteTenantLLMConfig(w http.ResponseWriter, r *http.Request) {
+	tenant := mux.Vars(r)["tenant"]
+
+	tenantLLMMu.Lock()
+	_, ok := tenantLLM[tenant]
+	delete(tenantLLM, tenant)
+	tenantLLMMu.Unlock()
+	if !ok {
+		http.Error(w, "Tenant LLM configuration not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
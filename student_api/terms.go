@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// terms holds the academic terms by name; Grade.Term and Enrollment.Term refer to these names
+var (
+	terms   = make(map[string]Term)
+	termsMu sync.Mutex
+)
+
+// Term struct to hold an academic term; Start and End are inclusive dates
+type Term struct {
+	Name         string `json:"name"`
+	AcademicYear string `json:"academic_year"`
+	Start        string `json:"start"`
+	End          string `json:"end"`
+	Current      bool   `json:"current"`
+}
+
+// validateTerm checks a term submitted by a client
+func validateTerm(term Term) error {
+	if term.Name == "" || strings.ContainsAny(term.Name, "/?#") {
+		return errors.New("name is required and must not contain /, ? or #")
+	}
+	if strings.TrimSpace(term.AcademicYear) == "" {
+		return errors.New("academic_year is required")
+	}
+	for _, date := range []string{term.Start, term.End} {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return errors.New("start and end must be in YYYY-MM-DD format")
+		}
+	}
+	if term.End < term.Start {
+		return errors.New("end must not be before start")
+	}
+	return nil
+}
+
+// withCurrent marks whether today falls inside the term
+func withCurrent(term Term) Term {
+	day := today()
+	term.Current = term.Start <= day && day <= term.End
+	return term
+}
+
+// termOn returns the term containing a date; the caller must hold termsMu
+func termOn(date string) (Term, bool) {
+	for _, term := range terms {
+		if term.Start <= date && date <= term.End {
+			return withCurrent(term), true
+		}
+	}
+	return Term{}, false
+}
+
+// resolveTerm checks a term name given on a grade or enrollment, defaulting an empty name to the
+// current term; free-form names are accepted until terms have been defined
+func resolveTerm(name string) (string, error) {
+	termsMu.Lock()
+	defer termsMu.Unlock()
+
+	if name == "" {
+		if term, ok := termOn(today()); ok {
+			return term.Name, nil
+		}
+		return "", nil
+	}
+	if _, exists := terms[name]; len(terms) > 0 && !exists {
+		return "", errors.New("Unknown term " + name)
+	}
+	return name, nil
+}
+
+// termDates returns the start and end of a term by name
+func termDates(name string) (string, string, bool) {
+	termsMu.Lock()
+	defer termsMu.Unlock()
+	term, exists := terms[name]
+	return term.Start, term.End, exists
+}
+
+// createTerm handles POST /terms to define an academic term, rejecting terms that overlap an
+// existing one with 409
+func createTerm(w http.ResponseWriter, r *http.Request) {
+	var term Term
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	term.Name = strings.TrimSpace(term.Name)
+	if err := validateTerm(term); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	termsMu.Lock()
+	defer termsMu.Unlock()
+	if _, exists := terms[term.Name]; exists {
+		http.Error(w, "A term with this name already exists", http.StatusConflict)
+		return
+	}
+	for _, existing := range terms {
+		if term.Start <= existing.End && existing.Start <= term.End {
+			http.Error(w, "Term overlaps "+existing.Name, http.StatusConflict)
+			return
+		}
+	}
+	term.Current = false
+	terms[term.Name] = term
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(withCurrent(term))
+}
+
+// getAllTerms handles GET /terms?academic_year= to list terms in date order
+func getAllTerms(w http.ResponseWriter, r *http.Request) {
+	year := r.URL.Query().Get("academic_year")
+
+	termsMu.Lock()
+	list := []Term{}
+	for _, term := range terms {
+		if year == "" || term.AcademicYear == year {
+			list = append(list, withCurrent(term))
+		}
+	}
+	termsMu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Start < list[j].Start })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// getCurrentTerm handles GET /terms/current to return the term containing today
+func getCurrentTerm(w http.ResponseWriter, r *http.Request) {
+	termsMu.Lock()
+	term, ok := termOn(today())
+	termsMu.Unlock()
+	if !ok {
+		http.Error(w, "No current term", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(term)
+}
+
+// getTermByName handles GET /terms/{name} to fetch a term
+func getTermByName(w http.ResponseWriter, r *http.Request) {
+	termsMu.Lock()
+	term, exists := terms[mux.Vars(r)["name"]]
+	termsMu.Unlock()
+	if !exists {
+		http.Error(w, "Term not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withCurrent(term))
+}
+
+// deleteTerm handles DELETE /terms/{name} to delete a term no grade or enrollment refers to
+func deleteTerm(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+	gradesMu.Lock()
+	defer gradesMu.Unlock()
+	termsMu.Lock()
+	defer termsMu.Unlock()
+
+	if _, exists := terms[name]; !exists {
+		http.Error(w, "Term not found", http.StatusNotFound)
+		return
+	}
+	for _, grade := range grades {
+		if grade.Term == name {
+			http.Error(w, "Term has recorded grades", http.StatusConflict)
+			return
+		}
+	}
+	for _, enrolled := range enrollments {
+		for _, enrollment := range enrolled {
+			if enrollment.Term == name {
+				http.Error(w, "Term has enrollments", http.StatusConflict)
+				return
+			}
+		}
+	}
+	delete(terms, name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxTriggerItems bounds the items returned by one trigger poll
+const maxTriggerItems = 100
+
+// TriggerItem struct to hold one item of a polling trigger. ID is the deduplication key tools
+// such as Zapier remember between polls: the student ID for new students, and the student ID
+// and change sequence for updates, so every update fires once. Cursor is the change sequence
+type TriggerItem struct {
+	ID            string    `json:"id"`
+	Cursor        int64     `json:"cursor"`
+	StudentID     int       `json:"student_id"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+	At            time.Time `json:"at"`
+	Student       Student   `json:"student"`
+}
+
+// pollStudentChanges returns the change log events of one type after since, newest first, with
+// the fields each update changed. Without a cursor it returns the latest limit events; with one,
+// the oldest limit events after it, so a client following the cursor never skips any
+func pollStudentChanges(eventType string, since int64, hasCursor bool, limit int) []TriggerItem {
+	eventSubscribersMu.Lock()
+	defer eventSubscribersMu.Unlock()
+
+	items := []TriggerItem{}
+	previous := make(map[int]Student)
+	for _, event := range changeLog {
+		before, known := previous[event.StudentID]
+		previous[event.StudentID] = event.Student
+		if event.Type != eventType || event.ID <= since {
+			continue
+		}
+		item := TriggerItem{ID: strconv.Itoa(event.StudentID), Cursor: event.ID, StudentID: event.StudentID, At: event.At, Student: event.Student}
+		if eventType == EventStudentUpdated {
+			item.ID = fmt.Sprintf("%d-%d", event.StudentID, event.ID)
+			if known {
+				item.ChangedFields = changedStudentFields(before, event.Student)
+			}
+		}
+		items = append(items, item)
+	}
+
+	if len(items) > limit {
+		if hasCursor {
+			items = items[:limit]
+		} else {
+			items = items[len(items)-limit:]
+		}
+	}
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items
+}
+
+// writeTriggerItems serves a polling trigger: ?since= is the cursor of the last item already seen
+// and ?limit= caps the items. The response is a plain array, newest first, as automation tools
+// expect, and X-Next-Cursor carries the cursor to pass on the next poll
+func writeTriggerItems(w http.ResponseWriter, r *http.Request, eventType string) {
+	var since int64
+	cursor := r.URL.Query().Get("since")
+	if cursor != "" {
+		var err error
+		if since, err = strconv.ParseInt(cursor, 10, 64); err != nil || since < 0 {
+			http.Error(w, "since must be a cursor", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxTriggerItems {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxTriggerItems), http.StatusBadRequest)
+			return
+		}
+	}
+
+	items := pollStudentChanges(eventType, since, cursor != "", limit)
+	next := since
+	if len(items) > 0 {
+		next = items[0].Cursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Next-Cursor", strconv.FormatInt(next, 10))
+	json.NewEncoder(w).Encode(items)
+}
+
+// pollNewStudents handles GET /triggers/students/new?since=&limit= to poll for students
+// created after a cursor
+func pollNewStudents(w http.ResponseWriter, r *http.Request) {
+	writeTriggerItems(w, r, EventStudentCreated)
+}
+
+// pollUpdatedStudents handles GET /triggers/students/updated?since=&limit= to poll for
+// student updates after a cursor
+func pollUpdatedStudents(w http.ResponseWriter, r *http.Request) {
+	writeTriggerItems(w, r, EventStudentUpdated)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"student_api/student_api/internal/llm"
+)
+
+// ErrTTSDisabled is returned when no TTS backend is configured
+var ErrTTSDisabled = errors.New("text-to-speech is not configured")
+
+// audioFormats maps ?format= values to their Content-Type
+var audioFormats = map[string]string{
+	"mp3": "audio/mpeg",
+	"ogg": "audio/ogg",
+}
+
+// TTSProvider is implemented by text-to-speech backends
+type TTSProvider interface {
+	Synthesize(ctx context.Context, text, format string) ([]byte, error)
+}
+
+// newTTSProvider returns the backend selected by TTS_PROVIDER, or nil when TTS is disabled
+func newTTSProvider(cfg Config) TTSProvider {
+	switch cfg.TTSProvider {
+	case "openai":
+		return &OpenAISpeechProvider{BaseURL: cfg.TTSURL, APIKey: cfg.TTSAPIKey, Model: cfg.TTSModel, Voice: cfg.TTSVoice,
+			Client: &http.Client{Timeout: cfg.LLMTimeout}}
+	default:
+		return nil
+	}
+}
+
+// OpenAISpeechProvider talks to an OpenAI-compatible /audio/speech API
+type OpenAISpeechProvider struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Voice   string
+	Client  *http.Client
+}
+
+// Synthesize converts text to audio in the given format (mp3 or ogg)
+func (p *OpenAISpeechProvider) Synthesize(ctx context.Context, text, format string) ([]byte, error) {
+	responseFormat := format
+	if format == "ogg" {
+		// OpenAI's Opus output is delivered in an Ogg container
+		responseFormat = "opus"
+	}
+	body, _ := json.Marshal(map[string]string{
+		"model":           p.Model,
+		"voice":           p.Voice,
+		"input":           text,
+		"response_format": responseFormat,
+	})
+
+	url := strings.TrimRight(p.BaseURL, "/") + "/audio/speech"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	audio, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &LLMStatusError{URL: url, StatusCode: resp.StatusCode, Body: string(audio), Message: llm.ProviderErrorMessage(audio)}
+	}
+	return audio, nil
+}
+
+// getSummaryAudio handles GET /students/{id}/summary/audio to return the student's summary as speech
+func (s *Server) getSummaryAudio(w http.ResponseWriter, r *http.Request) {
+	if s.tts == nil {
+		writeProblem(w, http.StatusNotImplemented, "Text-to-speech disabled", ErrTTSDisabled.Error())
+		return
+	}
+
+	id := extractIDFromURL(r.URL.Path)
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "mp3"
+	}
+	contentType, ok := audioFormats[format]
+	if !ok {
+		http.Error(w, "Unsupported audio format", http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	opts, err := s.summaryOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := s.studentSummary(r.Context(), student, opts, r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	audio, err := s.tts.Synthesize(r.Context(), summaryPlainText(summary), format)
+	if err != nil {
+		s.writeLLMError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"student-%d-summary.%s\"", id, format))
+	w.Write(audio)
+}
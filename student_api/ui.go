@@ -0,0 +1,68 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// uiFiles holds the admin single-page app served under /ui
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the admin UI; the sign-in page is public and every other asset requires the
+// admin session, redirecting browsers to sign in when it is missing
+func (s *Server) uiHandler() http.Handler {
+	assets, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/ui/", http.FileServer(http.FS(assets)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path := strings.TrimPrefix(r.URL.Path, "/ui/"); {
+		case path == "login" && r.Method == http.MethodPost:
+			s.uiLogin(w, r)
+		case path == "logout" && r.Method == http.MethodPost:
+			uiLogout(w, r)
+		case path == "login" || path == "style.css":
+			if path == "login" {
+				r.URL.Path = "/ui/login.html"
+			}
+			files.ServeHTTP(w, r)
+		case !s.hasAdminToken(r):
+			http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+		case path == "login.html":
+			http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+		default:
+			w.Header().Set("Cache-Control", "no-store")
+			files.ServeHTTP(w, r)
+		}
+	})
+}
+
+// uiLogin handles POST /ui/login to exchange the admin token for a session cookie
+func (s *Server) uiLogin(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.PostFormValue("token"))
+	if !s.isAdminToken(token) {
+		http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     adminSessionCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/ui/", http.StatusSeeOther)
+}
+
+// uiLogout handles POST /ui/logout to clear the session cookie
+func uiLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: adminSessionCookie, Path: "/", MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteStrictMode})
+	http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+}
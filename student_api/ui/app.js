@@ -0,0 +1,121 @@
+// Admin UI for browsing and editing students; requests carry the admin session cookie set by
+// the sign-in page
+"use strict";
+
+const $ = (selector) => document.querySelector(selector);
+let current = null;
+
+async function api(method, path, body) {
+  const response = await fetch(path, {
+    method,
+    credentials: "same-origin",
+    headers: body ? { "Content-Type": "application/json", "Accept": "application/json" } : { "Accept": "application/json" },
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  if (response.status === 401) {
+    window.location = "/ui/login";
+    throw new Error("Signed out");
+  }
+  const text = await response.text();
+  if (!response.ok) {
+    let message = text;
+    try { message = JSON.parse(text).detail || message; } catch (e) {}
+    throw new Error(message.trim() || response.statusText);
+  }
+  return text ? JSON.parse(text) : null;
+}
+
+function show(message) {
+  $("#message").textContent = message || "";
+}
+
+async function search(event) {
+  if (event) event.preventDefault();
+  const params = new URLSearchParams();
+  for (const [key, value] of new FormData($("#search"))) {
+    if (value) params.set(key, value);
+  }
+  try {
+    const students = (await api("GET", "/students?" + params)) || [];
+    students.sort((a, b) => a.id - b.id);
+    const rows = $("#rows");
+    rows.replaceChildren();
+    for (const student of students) {
+      const row = rows.insertRow();
+      for (const value of [student.id, student.name, student.email, student.age, student.status]) {
+        row.insertCell().textContent = value ?? "";
+      }
+      row.addEventListener("click", () => open(student));
+    }
+    show(students.length ? "" : "No students found");
+  } catch (err) {
+    show(err.message);
+  }
+}
+
+function open(student) {
+  current = student;
+  const form = $("#student");
+  form.reset();
+  $("#title").textContent = student ? `Student ${student.id}` : "New student";
+  if (student) {
+    for (const field of ["name", "email", "phone", "date_of_birth", "gender", "nationality", "grade_level"]) {
+      form.elements[field].value = student[field] ?? "";
+    }
+    form.elements.tags.value = (student.tags || []).join(", ");
+  }
+  $("#summarize").hidden = !student;
+  $("#delete").hidden = !student;
+  $("#summary").textContent = "";
+  $("#detail").hidden = false;
+  show("");
+}
+
+async function save(event) {
+  event.preventDefault();
+  const form = $("#student");
+  const body = {};
+  for (const [key, value] of new FormData(form)) {
+    if (value === "") continue;
+    body[key] = key === "grade_level" ? Number(value) : value;
+  }
+  body.tags = (form.elements.tags.value || "").split(",").map((tag) => tag.trim()).filter(Boolean);
+  try {
+    const saved = current ? await api("PUT", `/students/${current.id}`, body) : await api("POST", "/students", body);
+    open(saved);
+    show("Saved");
+    search();
+  } catch (err) {
+    show(err.message);
+  }
+}
+
+async function summarize() {
+  $("#summary").textContent = "Generating...";
+  try {
+    const summary = await api("GET", `/students/${current.id}/summary?refresh=true`);
+    $("#summary").textContent = summary.summary;
+  } catch (err) {
+    $("#summary").textContent = "";
+    show(err.message);
+  }
+}
+
+async function remove() {
+  if (!confirm(`Delete ${current.name}?`)) return;
+  try {
+    await api("DELETE", `/students/${current.id}`);
+    $("#detail").hidden = true;
+    search();
+  } catch (err) {
+    show(err.message);
+  }
+}
+
+$("#search").addEventListener("submit", search);
+$("#new").addEventListener("click", () => open(null));
+$("#student").addEventListener("submit", save);
+$("#summarize").addEventListener("click", summarize);
+$("#delete").addEventListener("click", remove);
+$("#close").addEventListener("click", () => { $("#detail").hidden = true; });
+search();
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type contextKey string
+
+// apiKeyContextKey stores the caller's API key on the request context, and callerContextKey the
+// name it is recorded under (see callerID); background work only carries the name
+const (
+	apiKeyContextKey contextKey = "api_key"
+	callerContextKey contextKey = "caller"
+)
+
+var (
+	llmUsage   = make(map[UsageKey]*UsageTotals)
+	llmUsageMu sync.Mutex
+)
+
+// UsageKey identifies one aggregation bucket of LLM usage; Caller is the callerID of the API key
+type UsageKey struct {
+	Day    string
+	Caller string
+	Model  string
+}
+
+// UsageTotals struct to hold aggregated LLM usage for a bucket
+type UsageTotals struct {
+	Calls            int   `json:"calls"`
+	Failures         int   `json:"failures"`
+	PromptTokens     int   `json:"prompt_tokens"`
+	CompletionTokens int   `json:"completion_tokens"`
+	TotalLatencyMS   int64 `json:"total_latency_ms"`
+}
+
+// UsageProvider records token counts and latency of every call to the wrapped provider
+type UsageProvider struct {
+	Provider LLMProvider
+}
+
+// Generate calls the wrapped provider and records the call's usage
+func (p *UsageProvider) Generate(ctx context.Context, req LLMRequest) (LLMResponse, error) {
+	start := time.Now()
+	resp, err := p.Provider.Generate(ctx, req)
+	recordLLMUsage(callerID(ctx), req.Model, time.Since(start), resp, err)
+	return resp, err
+}
+
+// Stream relays the wrapped provider's stream and records the call's usage
+func (p *UsageProvider) Stream(ctx context.Context, req LLMRequest, chunks chan<- string) (LLMResponse, error) {
+	start := time.Now()
+	resp, err := streamFrom(ctx, p.Provider, req, chunks)
+	recordLLMUsage(callerID(ctx), req.Model, time.Since(start), resp, err)
+	return resp, err
+}
+
+// Embed embeds text with the wrapped provider and records the call's usage
+func (p *UsageProvider) Embed(ctx context.Context, model, text string) ([]float64, error) {
+	start := time.Now()
+	vector, err := embedFrom(ctx, p.Provider, model, text)
+	recordLLMUsage(callerID(ctx), model, time.Since(start), LLMResponse{}, err)
+	return vector, err
+}
+
+// recordLLMUsage adds one call to today's usage bucket
+func recordLLMUsage(caller, model string, latency time.Duration, resp LLMResponse, err error) {
+	key := UsageKey{Day: time.Now().UTC().Format("2006-01-02"), Caller: caller, Model: model}
+
+	llmUsageMu.Lock()
+	defer llmUsageMu.Unlock()
+
+	totals, ok := llmUsage[key]
+	if !ok {
+		totals = &UsageTotals{}
+		llmUsage[key] = totals
+	}
+	totals.Calls++
+	totals.TotalLatencyMS += latency.Milliseconds()
+	if err != nil {
+		totals.Failures++
+		return
+	}
+	totals.PromptTokens += resp.PromptTokens
+	totals.CompletionTokens += resp.CompletionTokens
+}
+
+// withAPIKey attaches the caller's X-API-Key header and the name it is recorded under to the
+// request context
+func withAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), r.Header.Get("X-API-Key"))))
+	})
+}
+
+// withCaller returns ctx carrying an API key and its callerID
+func withCaller(ctx context.Context, apiKey string) context.Context {
+	ctx = context.WithValue(ctx, apiKeyContextKey, apiKey)
+	if apiKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, callerContextKey, keyID(apiKey))
+}
+
+// keyID names an API key by the first 12 hex digits of its SHA-256, so usage, audit entries and
+// records can be attributed to a key without storing or showing the key itself
+func keyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "key-" + hex.EncodeToString(sum[:6])
+}
+
+// apiKeyFromContext returns the API key stored on ctx, or "" when the caller sent none; it is a
+// secret, so record callerID instead
+func apiKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyContextKey).(string)
+	return key
+}
+
+// callerID returns the keyID of the caller's API key, or "anonymous" when the caller sent none
+func callerID(ctx context.Context) string {
+	if caller, ok := ctx.Value(callerContextKey).(string); ok && caller != "" {
+		return caller
+	}
+	return "anonymous"
+}
+
+// backgroundContext returns a context for work that outlives the request but is still
+// attributed to the caller and tenant
+func backgroundContext(r *http.Request) context.Context {
+	ctx := context.WithValue(context.Background(), callerContextKey, callerID(r.Context()))
+	return context.WithValue(ctx, tenantContextKey, tenantFromContext(r.Context()))
+}
+
+// getLLMUsage handles GET /admin/llm/usage to report usage per day, caller and model, optionally
+// restricted with ?from=, ?to= (YYYY-MM-DD) and ?caller=, a callerID such as key-1a2b3c4d5e6f
+func (s *Server) getLLMUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to, caller := query.Get("from"), query.Get("to"), query.Get("caller")
+
+	type usageRow struct {
+		Day    string `json:"day"`
+		Caller string `json:"caller"`
+		Model  string `json:"model"`
+		UsageTotals
+		AvgLatencyMS  int64   `json:"avg_latency_ms"`
+		EstimatedCost float64 `json:"estimated_cost"`
+	}
+
+	llmUsageMu.Lock()
+	rows := []usageRow{}
+	for key, totals := range llmUsage {
+		if (from != "" && key.Day < from) || (to != "" && key.Day > to) || (caller != "" && key.Caller != caller) {
+			continue
+		}
+		row := usageRow{Day: key.Day, Caller: key.Caller, Model: key.Model, UsageTotals: *totals}
+		if totals.Calls > 0 {
+			row.AvgLatencyMS = totals.TotalLatencyMS / int64(totals.Calls)
+		}
+		row.EstimatedCost = s.estimatedLLMCost(*totals)
+		rows = append(rows, row)
+	}
+	llmUsageMu.Unlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Day != rows[j].Day {
+			return rows[i].Day < rows[j].Day
+		}
+		if rows[i].Caller != rows[j].Caller {
+			return rows[i].Caller < rows[j].Caller
+		}
+		return rows[i].Model < rows[j].Model
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rows)
+}
+
+// estimatedLLMCost prices usage with the per 1000 token costs from LLM_PROMPT_COST_PER_1K and
+// LLM_COMPLETION_COST_PER_1K
+func (s *Server) estimatedLLMCost(totals UsageTotals) float64 {
+	return float64(totals.PromptTokens)/1000*s.config.PromptTokenCost +
+		float64(totals.CompletionTokens)/1000*s.config.CompletionTokenCost
+}
+
+// llmUsageBetween sums the usage of every caller and model from one day to another, inclusive
+func llmUsageBetween(from, to string) UsageTotals {
+	llmUsageMu.Lock()
+	defer llmUsageMu.Unlock()
+
+	var sum UsageTotals
+	for key, totals := range llmUsage {
+		if key.Day < from || key.Day > to {
+			continue
+		}
+		sum.Calls += totals.Calls
+		sum.Failures += totals.Failures
+		sum.PromptTokens += totals.PromptTokens
+		sum.CompletionTokens += totals.CompletionTokens
+		sum.TotalLatencyMS += totals.TotalLatencyMS
+	}
+	return sum
+}
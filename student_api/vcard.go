@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// vcardName splits a full name into the family and given name components of the N property,
+// taking the last word as the family name
+func vcardName(name string) string {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return icalEscape(name) + ";;;;"
+	}
+	last := len(words) - 1
+	return icalEscape(words[last]) + ";" + icalEscape(strings.Join(words[:last], " ")) + ";;;"
+}
+
+// writeVCard appends a vCard 3.0 entry; vCard shares iCalendar's escaping and line folding
+func writeVCard(b *strings.Builder, uid, name, email, phone, note string, extra ...string) {
+	icalLine(b, "BEGIN:VCARD")
+	icalLine(b, "VERSION:3.0")
+	icalLine(b, "UID:"+uid)
+	icalLine(b, "FN:"+icalEscape(name))
+	icalLine(b, "N:"+vcardName(name))
+	if email != "" {
+		icalLine(b, "EMAIL;TYPE=INTERNET:"+icalEscape(email))
+	}
+	if phone != "" {
+		icalLine(b, "TEL;TYPE=CELL:"+icalEscape(phone))
+	}
+	for _, line := range extra {
+		icalLine(b, line)
+	}
+	if note != "" {
+		icalLine(b, "NOTE:"+icalEscape(note))
+	}
+	icalLine(b, "END:VCARD")
+}
+
+// studentVCards writes the vCard of a student and, with includeContacts, one for their guardian
+// and each of their contacts; the caller must hold contactsMu
+func studentVCards(b *strings.Builder, student Student, host string, includeContacts bool) {
+	var extra []string
+	if a := student.Address; a != nil {
+		street := strings.TrimSpace(a.Line1 + "\n" + a.Line2)
+		adr := fmt.Sprintf("ADR;TYPE=HOME:;;%s;%s;%s;%s;%s", icalEscape(street), icalEscape(a.City),
+			icalEscape(a.State), icalEscape(a.PostalCode), icalEscape(a.Country))
+		extra = append(extra, adr)
+	}
+	if student.DateOfBirth != "" {
+		extra = append(extra, "BDAY:"+student.DateOfBirth)
+	}
+	if len(student.Tags) > 0 {
+		tags := make([]string, len(student.Tags))
+		for i, tag := range student.Tags {
+			tags[i] = icalEscape(tag)
+		}
+		extra = append(extra, "CATEGORIES:"+strings.Join(tags, ","))
+	}
+	writeVCard(b, fmt.Sprintf("student-%d@%s", student.ID, host), student.Name, student.Email, student.Phone, "", extra...)
+
+	if !includeContacts {
+		return
+	}
+	if g := student.Guardian; g != nil {
+		note := firstNonEmpty(g.Relationship, "Guardian") + " of " + student.Name
+		writeVCard(b, fmt.Sprintf("student-%d-guardian@%s", student.ID, host), g.Name, g.Email, g.Phone, note)
+	}
+	for _, contact := range contacts[student.ID] {
+		note := contact.Relationship + " of " + student.Name
+		if contact.Primary {
+			note += " (primary contact)"
+		}
+		writeVCard(b, fmt.Sprintf("contact-%d@%s", contact.ID, host), contact.Name, contact.Email, contact.Phone, note)
+	}
+}
+
+// writeVCards writes the vCards of students as a text/vcard download
+func (s *Server) writeVCards(w http.ResponseWriter, filename string, list []Student, includeContacts bool) {
+	host := s.publicHostname()
+
+	var b strings.Builder
+	contactsMu.Lock()
+	for _, student := range list {
+		studentVCards(&b, student, host, includeContacts)
+	}
+	contactsMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Write([]byte(b.String()))
+}
+
+// getStudentVCard handles GET /students/{id}/vcard?include_contacts= to download a student's
+// contact card; include_contacts=true adds cards for their guardian and contacts
+func (s *Server) getStudentVCard(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	s.writeVCards(w, fmt.Sprintf("student-%d.vcf", id), []Student{student}, r.URL.Query().Get("include_contacts") == "true")
+}
+
+// exportVCards handles GET /students/export.vcf to download the cards of the students matching
+// the list filters in one file, with include_contacts=true as for a single student
+func (s *Server) exportVCards(w http.ResponseWriter, r *http.Request) {
+	filter, err := studentFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Lock()
+	matched := s.filterStudents(filter)
+	s.store.Unlock()
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	s.writeVCards(w, "students-"+today()+".vcf", matched, r.URL.Query().Get("include_contacts") == "true")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"student_api/student_api/internal/handlers"
+)
+
+var (
+	verificationTokens   = make(map[string]emailVerification)
+	verificationTokensMu sync.Mutex
+)
+
+// errEmailReplaced stops a token from verifying an email that has changed since it was issued
+var errEmailReplaced = errors.New("email replaced since the token was issued")
+
+// emailVerification records which address a verification token confirms
+type emailVerification struct {
+	StudentID int
+	Email     string
+	ExpiresAt time.Time
+}
+
+// sendEmailVerification issues a verification token for a student's current email and mails
+// the link in the background; earlier tokens for the student stop working
+func (s *Server) sendEmailVerification(student Student) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("Error creating verification token for student %d: %v", student.ID, err)
+		return
+	}
+	token := hex.EncodeToString(buf)
+
+	verificationTokensMu.Lock()
+	for existing, verification := range verificationTokens {
+		if verification.StudentID == student.ID {
+			delete(verificationTokens, existing)
+		}
+	}
+	verificationTokens[token] = emailVerification{StudentID: student.ID, Email: student.Email, ExpiresAt: time.Now().Add(s.config.EmailVerificationTTL)}
+	verificationTokensMu.Unlock()
+
+	link := strings.TrimRight(s.config.PublicBaseURL, "/") + "/students/verify-email?token=" + token
+	body := "Hello " + student.Name + ",\n\nPlease confirm your email address by opening this link:\n\n" + link +
+		"\n\nThe link expires in " + s.config.EmailVerificationTTL.String() + "."
+	go func() {
+		if err := s.mailer.Send(context.Background(), student.Email, "Confirm your email address", body); err != nil {
+			log.Printf("Error sending verification email to student %d: %v", student.ID, err)
+		}
+	}()
+}
+
+// verifyEmail handles GET /students/verify-email?token= to confirm a student's email address; the
+// token only counts if the student's email has not changed since it was issued
+func (s *Server) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	verificationTokensMu.Lock()
+	verification, exists := verificationTokens[token]
+	delete(verificationTokens, token)
+	verificationTokensMu.Unlock()
+	if !exists || time.Now().After(verification.ExpiresAt) {
+		http.Error(w, "Invalid or expired verification link", http.StatusBadRequest)
+		return
+	}
+
+	student, err := s.students.Change(verification.StudentID, func(student *Student) error {
+		if student.Email != verification.Email {
+			return errEmailReplaced
+		}
+		student.EmailVerified = true
+		return nil
+	})
+	if errors.Is(err, ErrStudentNotFound) || errors.Is(err, errEmailReplaced) {
+		http.Error(w, "Invalid or expired verification link", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), handlers.StatusCode(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"student_id": student.ID, "email": student.Email, "email_verified": true})
+}
+
+// resendEmailVerification handles POST /students/{id}/email/verification to send a new
+// verification link to an unverified address
+func (s *Server) resendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+
+	s.store.Lock()
+	student, exists := s.store.Get(id)
+	s.store.Unlock()
+	if !exists {
+		http.Error(w, "Student not found", http.StatusNotFound)
+		return
+	}
+	if student.EmailVerified {
+		http.Error(w, "Email is already verified", http.StatusConflict)
+		return
+	}
+
+	s.sendEmailVerification(student)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// deleteEmailVerifications drops a deleted student's outstanding verification tokens
+func deleteEmailVerifications(id int) {
+	verificationTokensMu.Lock()
+	defer verificationTokensMu.Unlock()
+	for token, verification := range verificationTokens {
+		if verification.StudentID == id {
+			delete(verificationTokens, token)
+		}
+	}
+}
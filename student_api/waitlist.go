@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// waitlists holds the ordered waitlist of each full course; it is guarded by coursesMu
+var waitlists = make(map[int][]WaitlistEntry)
+
+// WaitlistEntry struct to hold a student waiting for a seat in a full course
+type WaitlistEntry struct {
+	StudentID int       `json:"student_id"`
+	CourseID  int       `json:"course_id"`
+	Term      string    `json:"term,omitempty"`
+	Position  int       `json:"position"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// waitlistPosition returns a student's 1-based position on a course's waitlist, or 0; the caller
+// must hold coursesMu
+func waitlistPosition(courseID, studentID int) int {
+	for i, entry := range waitlists[courseID] {
+		if entry.StudentID == studentID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// addToWaitlist appends a student to a course's waitlist; the caller must hold coursesMu
+func addToWaitlist(entry WaitlistEntry) WaitlistEntry {
+	waitlists[entry.CourseID] = append(waitlists[entry.CourseID], entry)
+	entry.Position = len(waitlists[entry.CourseID])
+	return entry
+}
+
+// promoteWaitlist enrolls waitlisted students, in order, while the course has free seats;
+// students whose timetable would clash stay on the list. The caller must hold coursesMu
+func (s *Server) promoteWaitlist(courseID int) {
+	course, exists := courses[courseID]
+	if !exists {
+		return
+	}
+
+	var remaining, promoted []WaitlistEntry
+	for _, entry := range waitlists[courseID] {
+		full := course.Capacity > 0 && len(enrollments[courseID]) >= course.Capacity
+		if _, clash := scheduleClash(entry.StudentID, course); full || clash {
+			remaining = append(remaining, entry)
+			continue
+		}
+		if enrollments[courseID] == nil {
+			enrollments[courseID] = make(map[int]Enrollment)
+		}
+		enrollments[courseID][entry.StudentID] = Enrollment{StudentID: entry.StudentID, CourseID: courseID, Term: entry.Term, EnrolledAt: time.Now()}
+		promoted = append(promoted, entry)
+	}
+	waitlists[courseID] = remaining
+
+	if len(promoted) > 0 {
+		go s.notifyWaitlistPromotions(course, promoted)
+	}
+}
+
+// notifyWaitlistPromotions tells the students enrolled from a course's waitlist, by email unless
+// their notification preferences say otherwise
+func (s *Server) notifyWaitlistPromotions(course Course, promoted []WaitlistEntry) {
+	for _, entry := range promoted {
+		s.store.Lock()
+		student, exists := s.store.Get(entry.StudentID)
+		s.store.Unlock()
+		if !exists {
+			continue
+		}
+
+		body := "Hello " + student.Name + ",\n\nA seat opened up in " + course.Code + " " + course.Title +
+			" and you have been enrolled from the waitlist."
+		to := notificationRecipient{StudentID: student.ID, Email: student.Email, Phone: student.Phone}
+		if _, _, err := s.sendNotification(context.Background(), to, ChannelEmail, "Enrolled in "+course.Code, body, false); err != nil {
+			log.Printf("Error notifying student %d of waitlist promotion: %v", student.ID, err)
+		}
+	}
+}
+
+// getCourseWaitlist handles GET /courses/{id}/waitlist to list a course's waitlist in order
+func getCourseWaitlist(w http.ResponseWriter, r *http.Request) {
+	id, ok := courseIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	coursesMu.Lock()
+	_, exists := courses[id]
+	list := []WaitlistEntry{}
+	for i, entry := range waitlists[id] {
+		entry.Position = i + 1
+		list = append(list, entry)
+	}
+	coursesMu.Unlock()
+	if !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// deleteWaitlistEntry handles DELETE /students/{id}/waitlist/{course} to take a student off a
+// course's waitlist
+func deleteWaitlistEntry(w http.ResponseWriter, r *http.Request) {
+	id := extractIDFromURL(r.URL.Path)
+	courseID, err := strconv.Atoi(mux.Vars(r)["course"])
+	if err != nil {
+		http.Error(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	coursesMu.Lock()
+	defer coursesMu.Unlock()
+
+	position := waitlistPosition(courseID, id)
+	if position == 0 {
+		http.Error(w, "Waitlist entry not found", http.StatusNotFound)
+		return
+	}
+	list := waitlists[courseID]
+	waitlists[courseID] = append(list[:position-1], list[position:]...)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// webhooksMu guards webhooks, webhookDeliveries and webhookDeadLetters
+var (
+	webhooks              = make(map[int]Webhook)
+	webhookDeliveries     = make(map[int][]WebhookDelivery)
+	webhookDeadLetters    = make(map[int][]DeadLetter)
+	webhooksMu            sync.Mutex
+	nextWebhookID         int
+	nextWebhookDeliveryID int
+	nextDeadLetterID      int
+)
+
+// Webhook struct to hold an integrator's subscription to student changes; Events filters the
+// event types delivered, with none or "*" meaning all, and RetryPolicy overrides the retries of
+// the webhook's host. Secret is only shown when it is created
+type Webhook struct {
+	ID          int          `json:"id"`
+	URL         string       `json:"url"`
+	Events      []string     `json:"events,omitempty"`
+	Description string       `json:"description,omitempty"`
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+	Secret      string       `json:"secret,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// WebhookDelivery struct to hold one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID         int       `json:"id"`
+	WebhookID  int       `json:"webhook_id"`
+	EventID    int64     `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Succeeded  bool      `json:"succeeded"`
+	DurationMS int64     `json:"duration_ms"`
+	At         time.Time `json:"at"`
+}
+
+// DeadLetter struct to hold an event that could not be delivered to a webhook after every
+// attempt; it stays until it is redelivered or deleted
+type DeadLetter struct {
+	ID        int          `json:"id"`
+	WebhookID int          `json:"webhook_id"`
+	Event     StudentEvent `json:"event"`
+	JobID     int          `json:"job_id"`
+	Attempts  int          `json:"attempts"`
+	LastError string       `json:"last_error"`
+	FailedAt  time.Time    `json:"failed_at"`
+}
+
+// validateWebhook checks the fields a client may set on a webhook
+func validateWebhook(hook Webhook) error {
+	target, err := url.Parse(hook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	for _, eventType := range hook.Events {
+		switch eventType {
+		case "*", EventStudentCreated, EventStudentUpdated, EventStudentDeleted:
+		default:
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	if hook.RetryPolicy != nil {
+		return hook.RetryPolicy.validate()
+	}
+	return nil
+}
+
+// wantsEvent reports whether a webhook's filter matches an event type
+func (hook Webhook) wantsEvent(eventType string) bool {
+	return len(hook.Events) == 0 || containsString(hook.Events, "*") || containsString(hook.Events, eventType)
+}
+
+// signWebhookPayload returns the signature sent in X-Webhook-Signature: the hex HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the webhook's secret
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	return "sha256=" + hex.EncodeToString(hmacSHA256([]byte(secret), timestamp+"."+string(body)))
+}
+
+// webhookDeliveryPayload struct to hold the payload of a webhook delivery job
+type webhookDeliveryPayload struct {
+	WebhookID int          `json:"webhook_id"`
+	Event     StudentEvent `json:"event"`
+}
+
+// startWebhookDispatcher queues a delivery job for every student event and webhook subscribed to it
+func (s *Server) startWebhookDispatcher() {
+	events, _ := subscribeStudentEvents()
+	go func() {
+		for event := range events {
+			webhooksMu.Lock()
+			var matched []int
+			for _, hook := range webhooks {
+				if hook.wantsEvent(event.Type) {
+					matched = append(matched, hook.ID)
+				}
+			}
+			webhooksMu.Unlock()
+
+			for _, id := range matched {
+				if _, err := s.queueWebhookDelivery(id, event); err != nil {
+					log.Printf("Error queueing event %d for webhook %d: %v", event.ID, id, err)
+				}
+			}
+		}
+	}()
+}
+
+// queueWebhookDelivery queues the delivery of an event to a webhook with the webhook's retry policy
+func (s *Server) queueWebhookDelivery(webhookID int, event StudentEvent) (Job, error) {
+	webhooksMu.Lock()
+	policy := s.webhookRetryPolicy(webhooks[webhookID])
+	webhooksMu.Unlock()
+	return s.enqueueJob(context.Background(), "webhook-delivery", webhookDeliveryPayload{WebhookID: webhookID, Event: event}, policy.jobOptions())
+}
+
+// runWebhookDeliveryJob makes one attempt to deliver an event to a webhook; the job queue retries
+// failures as the webhook's retry policy says. Deliveries to deleted webhooks are dropped
+func (s *Server) runWebhookDeliveryJob(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	var req webhookDeliveryPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+
+	webhooksMu.Lock()
+	hook, exists := webhooks[req.WebhookID]
+	attempt := 1
+	for _, delivery := range webhookDeliveries[req.WebhookID] {
+		if delivery.EventID == req.Event.ID {
+			attempt = delivery.Attempt + 1
+		}
+	}
+	webhooksMu.Unlock()
+	if !exists {
+		return json.Marshal("Webhook was deleted")
+	}
+
+	body, err := json.Marshal(req.Event)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: s.webhookRetryPolicy(hook).timeout()}
+	delivery := WebhookDelivery{WebhookID: hook.ID, EventID: req.Event.ID, EventType: req.Event.Type, Attempt: attempt, At: time.Now()}
+	delivery.StatusCode, err = postWebhook(ctx, client, hook, req.Event, body)
+	delivery.DurationMS = time.Since(delivery.At).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		delivery.Succeeded = true
+	}
+	delivery = s.recordWebhookDelivery(delivery)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(delivery)
+}
+
+// postWebhook makes one signed delivery; any response other than 2xx is an error
+func postWebhook(ctx context.Context, client *http.Client, hook Webhook, event StudentEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "student_api-webhooks")
+	req.Header.Set("X-Webhook-ID", strconv.Itoa(hook.ID))
+	req.Header.Set("X-Webhook-Event", event.Type)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", signWebhookPayload(hook.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordWebhookDelivery adds a delivery to its webhook's log, keeping the last WEBHOOK_LOG_SIZE,
+// and returns it with its ID
+func (s *Server) recordWebhookDelivery(delivery WebhookDelivery) WebhookDelivery {
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+
+	if _, exists := webhooks[delivery.WebhookID]; !exists {
+		return delivery
+	}
+	nextWebhookDeliveryID++
+	delivery.ID = nextWebhookDeliveryID
+	entries := append(webhookDeliveries[delivery.WebhookID], delivery)
+	if excess := len(entries) - s.config.WebhookLogSize; excess > 0 {
+		entries = entries[excess:]
+	}
+	webhookDeliveries[delivery.WebhookID] = entries
+	return delivery
+}
+
+// webhookIDFromRequest parses the {id} route variable of a webhook route
+func webhookIDFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// createWebhook handles POST /webhooks to register a webhook; a secret is generated when none
+// is given and returned only in this response
+func createWebhook(w http.ResponseWriter, r *http.Request) {
+	var hook Webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := validateWebhook(hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if hook.Secret == "" {
+		buf := make([]byte, 24)
+		if _, err := rand.Read(buf); err != nil {
+			http.Error(w, "Error creating webhook secret", http.StatusInternalServerError)
+			return
+		}
+		hook.Secret = hex.EncodeToString(buf)
+	}
+
+	webhooksMu.Lock()
+	nextWebhookID++
+	hook.ID = nextWebhookID
+	hook.CreatedAt = time.Now()
+	webhooks[hook.ID] = hook
+	webhooksMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// getAllWebhooks handles GET /webhooks to list the registered webhooks without their secrets
+func getAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooksMu.Lock()
+	all := []Webhook{}
+	for _, hook := range webhooks {
+		hook.Secret = ""
+		all = append(all, hook)
+	}
+	webhooksMu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(all)
+}
+
+// getWebhookByID handles GET /webhooks/{id} to fetch a webhook without its secret
+func getWebhookByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	webhooksMu.Lock()
+	hook, exists := webhooks[id]
+	webhooksMu.Unlock()
+	if !exists {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	hook.Secret = ""
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// deleteWebhook handles DELETE /webhooks/{id} to unregister a webhook and drop its delivery log
+// and dead letters; queued deliveries to it are dropped
+func deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	webhooksMu.Lock()
+	_, exists := webhooks[id]
+	delete(webhooks, id)
+	delete(webhookDeliveries, id)
+	delete(webhookDeadLetters, id)
+	webhooksMu.Unlock()
+	if !exists {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getWebhookDeliveries handles GET /webhooks/{id}/deliveries to list a webhook's recent delivery
+// attempts, newest first
+func getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	webhooksMu.Lock()
+	_, exists := webhooks[id]
+	entries := webhookDeliveries[id]
+	list := make([]WebhookDelivery, len(entries))
+	for i, delivery := range entries {
+		list[len(entries)-1-i] = delivery
+	}
+	webhooksMu.Unlock()
+	if !exists {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// deadLetterWebhookDelivery moves a delivery job that used up its attempts to its webhook's dead
+// letters, keeping the last WEBHOOK_DEAD_LETTER_SIZE
+func (s *Server) deadLetterWebhookDelivery(job Job, payload json.RawMessage) {
+	var req webhookDeliveryPayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		log.Printf("Error reading failed webhook delivery job %d: %v", job.ID, err)
+		return
+	}
+
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+	if _, exists := webhooks[req.WebhookID]; !exists {
+		return
+	}
+	nextDeadLetterID++
+	letter := DeadLetter{
+		ID: nextDeadLetterID, WebhookID: req.WebhookID, Event: req.Event, JobID: job.ID,
+		Attempts: job.Attempts, LastError: job.Error, FailedAt: time.Now(),
+	}
+	letters := append(webhookDeadLetters[req.WebhookID], letter)
+	if excess := len(letters) - s.config.WebhookDeadLetterSize; excess > 0 {
+		letters = letters[excess:]
+	}
+	webhookDeadLetters[req.WebhookID] = letters
+}
+
+// getWebhookDeadLetters handles GET /webhooks/{id}/dead-letters to list the events that could not
+// be delivered to a webhook, oldest first
+func getWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	webhooksMu.Lock()
+	_, exists := webhooks[id]
+	list := append([]DeadLetter{}, webhookDeadLetters[id]...)
+	webhooksMu.Unlock()
+	if !exists {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// takeDeadLetter removes the {letter} dead letter of the {id} webhook, writing the error response
+// when either does not exist
+func takeDeadLetter(w http.ResponseWriter, r *http.Request) (DeadLetter, bool) {
+	id, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return DeadLetter{}, false
+	}
+	letterID, err := strconv.Atoi(mux.Vars(r)["letter"])
+	if err != nil {
+		http.Error(w, "Invalid dead letter ID", http.StatusBadRequest)
+		return DeadLetter{}, false
+	}
+
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+	if _, exists := webhooks[id]; !exists {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return DeadLetter{}, false
+	}
+	letters := webhookDeadLetters[id]
+	for i, letter := range letters {
+		if letter.ID == letterID {
+			webhookDeadLetters[id] = append(letters[:i:i], letters[i+1:]...)
+			return letter, true
+		}
+	}
+	http.Error(w, "Dead letter not found", http.StatusNotFound)
+	return DeadLetter{}, false
+}
+
+// redeliverDeadLetter handles POST /webhooks/{id}/dead-letters/{letter}/redeliver to queue the
+// event for delivery again with a fresh set of attempts; it returns to the dead letters if those
+// fail too
+func (s *Server) redeliverDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, ok := takeDeadLetter(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := s.queueWebhookDelivery(letter.WebhookID, letter.Event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// deleteDeadLetter handles DELETE /webhooks/{id}/dead-letters/{letter} to discard an event
+func deleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if _, ok := takeDeadLetter(w, r); !ok {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
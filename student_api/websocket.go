@@ -0,0 +1,115 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPingInterval = 30 * time.Second
+)
+
+// checkWSOrigin accepts handshakes without an Origin header or from one of WS_ALLOWED_ORIGINS
+func (s *Server) checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || containsString(s.config.WSAllowedOrigins, "*") || containsString(s.config.WSAllowedOrigins, origin)
+}
+
+// eventSubscription struct to hold which events a WebSocket client receives; empty lists match
+// every event type or student
+type eventSubscription struct {
+	Action     string   `json:"action"`
+	Types      []string `json:"types"`
+	StudentIDs []int    `json:"student_ids"`
+}
+
+// matches reports whether an event is selected by the subscription
+func (s eventSubscription) matches(event StudentEvent) bool {
+	if len(s.Types) > 0 && !containsString(s.Types, event.Type) {
+		return false
+	}
+	if len(s.StudentIDs) == 0 {
+		return true
+	}
+	for _, id := range s.StudentIDs {
+		if id == event.StudentID {
+			return true
+		}
+	}
+	return false
+}
+
+// serveWebSocket handles GET /ws to push student change events to a client as JSON messages.
+// ?types= (comma separated) and ?student_id= narrow the initial subscription, and the client
+// can replace it at any time by sending {"action": "subscribe", "types": [...], "student_ids": [...]}
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	subscription := eventSubscription{Types: splitList(r.URL.Query().Get("types"))}
+	for _, value := range splitList(r.URL.Query().Get("student_id")) {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid student_id", http.StatusBadRequest)
+			return
+		}
+		subscription.StudentIDs = append(subscription.StudentIDs, id)
+	}
+
+	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already responded to the client
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := subscribeStudentEvents()
+	defer unsubscribe()
+
+	// The reader applies subscription changes and notices when the client goes away
+	var subscriptionMu sync.Mutex
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			var update eventSubscription
+			if err := conn.ReadJSON(&update); err != nil {
+				return
+			}
+			if update.Action != "subscribe" {
+				continue
+			}
+			subscriptionMu.Lock()
+			subscription = update
+			subscriptionMu.Unlock()
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			subscriptionMu.Lock()
+			selected := subscription.matches(event)
+			subscriptionMu.Unlock()
+			if !selected {
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				log.Printf("Error writing WebSocket event: %v", err)
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}